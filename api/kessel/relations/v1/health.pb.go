@@ -147,9 +147,11 @@ func (*GetReadyzRequest) Descriptor() ([]byte, []int) {
 }
 
 type GetReadyzResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Code          uint32                 `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Code   uint32                 `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	// state of each component readiness depends on
+	Components    []*ComponentStatus `protobuf:"bytes,3,rep,name=components,proto3" json:"components,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -198,6 +200,75 @@ func (x *GetReadyzResponse) GetCode() uint32 {
 	return 0
 }
 
+func (x *GetReadyzResponse) GetComponents() []*ComponentStatus {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+type ComponentStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// "OK" or "Unavailable"
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// error of the last failed probe
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComponentStatus) Reset() {
+	*x = ComponentStatus{}
+	mi := &file_kessel_relations_v1_health_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComponentStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComponentStatus) ProtoMessage() {}
+
+func (x *ComponentStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1_health_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComponentStatus.ProtoReflect.Descriptor instead.
+func (*ComponentStatus) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1_health_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ComponentStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ComponentStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ComponentStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 var File_kessel_relations_v1_health_proto protoreflect.FileDescriptor
 
 const file_kessel_relations_v1_health_proto_rawDesc = "" +
@@ -207,10 +278,17 @@ const file_kessel_relations_v1_health_proto_rawDesc = "" +
 	"\x10GetLivezResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
 	"\x04code\x18\x02 \x01(\rR\x04code\"\x12\n" +
-	"\x10GetReadyzRequest\"?\n" +
+	"\x10GetReadyzRequest\"\x85\x01\n" +
 	"\x11GetReadyzResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
-	"\x04code\x18\x02 \x01(\rR\x04code2\xf4\x01\n" +
+	"\x04code\x18\x02 \x01(\rR\x04code\x12D\n" +
+	"\n" +
+	"components\x18\x03 \x03(\v2$.kessel.relations.v1.ComponentStatusR\n" +
+	"components\"W\n" +
+	"\x0fComponentStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage2\xf4\x01\n" +
 	"\x1cKesselRelationsHealthService\x12g\n" +
 	"\bGetLivez\x12$.kessel.relations.v1.GetLivezRequest\x1a%.kessel.relations.v1.GetLivezResponse\"\x0e\x82\xd3\xe4\x93\x02\b\x12\x06/livez\x12k\n" +
 	"\tGetReadyz\x12%.kessel.relations.v1.GetReadyzRequest\x1a&.kessel.relations.v1.GetReadyzResponse\"\x0f\x82\xd3\xe4\x93\x02\t\x12\a/readyzBh\n" +
@@ -228,23 +306,25 @@ func file_kessel_relations_v1_health_proto_rawDescGZIP() []byte {
 	return file_kessel_relations_v1_health_proto_rawDescData
 }
 
-var file_kessel_relations_v1_health_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_kessel_relations_v1_health_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_kessel_relations_v1_health_proto_goTypes = []any{
 	(*GetLivezRequest)(nil),   // 0: kessel.relations.v1.GetLivezRequest
 	(*GetLivezResponse)(nil),  // 1: kessel.relations.v1.GetLivezResponse
 	(*GetReadyzRequest)(nil),  // 2: kessel.relations.v1.GetReadyzRequest
 	(*GetReadyzResponse)(nil), // 3: kessel.relations.v1.GetReadyzResponse
+	(*ComponentStatus)(nil),   // 4: kessel.relations.v1.ComponentStatus
 }
 var file_kessel_relations_v1_health_proto_depIdxs = []int32{
-	0, // 0: kessel.relations.v1.KesselRelationsHealthService.GetLivez:input_type -> kessel.relations.v1.GetLivezRequest
-	2, // 1: kessel.relations.v1.KesselRelationsHealthService.GetReadyz:input_type -> kessel.relations.v1.GetReadyzRequest
-	1, // 2: kessel.relations.v1.KesselRelationsHealthService.GetLivez:output_type -> kessel.relations.v1.GetLivezResponse
-	3, // 3: kessel.relations.v1.KesselRelationsHealthService.GetReadyz:output_type -> kessel.relations.v1.GetReadyzResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	4, // 0: kessel.relations.v1.GetReadyzResponse.components:type_name -> kessel.relations.v1.ComponentStatus
+	0, // 1: kessel.relations.v1.KesselRelationsHealthService.GetLivez:input_type -> kessel.relations.v1.GetLivezRequest
+	2, // 2: kessel.relations.v1.KesselRelationsHealthService.GetReadyz:input_type -> kessel.relations.v1.GetReadyzRequest
+	1, // 3: kessel.relations.v1.KesselRelationsHealthService.GetLivez:output_type -> kessel.relations.v1.GetLivezResponse
+	3, // 4: kessel.relations.v1.KesselRelationsHealthService.GetReadyz:output_type -> kessel.relations.v1.GetReadyzResponse
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_kessel_relations_v1_health_proto_init() }
@@ -258,7 +338,7 @@ func file_kessel_relations_v1_health_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kessel_relations_v1_health_proto_rawDesc), len(file_kessel_relations_v1_health_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -12,6 +12,7 @@ import (
 	status "google.golang.org/genproto/googleapis/rpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -171,12 +172,119 @@ func (CheckBulkResponseItem_Allowed) EnumDescriptor() ([]byte, []int) {
 	return file_kessel_relations_v1beta1_check_proto_rawDescGZIP(), []int{5, 0}
 }
 
+type CheckDebugStep_Kind int32
+
+const (
+	CheckDebugStep_KIND_UNSPECIFIED CheckDebugStep_Kind = 0
+	// A relation stored as tuples.
+	CheckDebugStep_KIND_RELATION CheckDebugStep_Kind = 1
+	// A relation computed from other relations by the schema.
+	CheckDebugStep_KIND_PERMISSION CheckDebugStep_Kind = 2
+)
+
+// Enum value maps for CheckDebugStep_Kind.
+var (
+	CheckDebugStep_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_RELATION",
+		2: "KIND_PERMISSION",
+	}
+	CheckDebugStep_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_RELATION":    1,
+		"KIND_PERMISSION":  2,
+	}
+)
+
+func (x CheckDebugStep_Kind) Enum() *CheckDebugStep_Kind {
+	p := new(CheckDebugStep_Kind)
+	*p = x
+	return p
+}
+
+func (x CheckDebugStep_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CheckDebugStep_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_kessel_relations_v1beta1_check_proto_enumTypes[3].Descriptor()
+}
+
+func (CheckDebugStep_Kind) Type() protoreflect.EnumType {
+	return &file_kessel_relations_v1beta1_check_proto_enumTypes[3]
+}
+
+func (x CheckDebugStep_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CheckDebugStep_Kind.Descriptor instead.
+func (CheckDebugStep_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_check_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type CheckDebugStep_Result int32
+
+const (
+	CheckDebugStep_RESULT_UNSPECIFIED CheckDebugStep_Result = 0
+	CheckDebugStep_RESULT_ALLOWED     CheckDebugStep_Result = 1
+	CheckDebugStep_RESULT_DENIED      CheckDebugStep_Result = 2
+	// The result depends on a caveat whose context was not provided.
+	CheckDebugStep_RESULT_CONDITIONAL CheckDebugStep_Result = 3
+)
+
+// Enum value maps for CheckDebugStep_Result.
+var (
+	CheckDebugStep_Result_name = map[int32]string{
+		0: "RESULT_UNSPECIFIED",
+		1: "RESULT_ALLOWED",
+		2: "RESULT_DENIED",
+		3: "RESULT_CONDITIONAL",
+	}
+	CheckDebugStep_Result_value = map[string]int32{
+		"RESULT_UNSPECIFIED": 0,
+		"RESULT_ALLOWED":     1,
+		"RESULT_DENIED":      2,
+		"RESULT_CONDITIONAL": 3,
+	}
+)
+
+func (x CheckDebugStep_Result) Enum() *CheckDebugStep_Result {
+	p := new(CheckDebugStep_Result)
+	*p = x
+	return p
+}
+
+func (x CheckDebugStep_Result) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CheckDebugStep_Result) Descriptor() protoreflect.EnumDescriptor {
+	return file_kessel_relations_v1beta1_check_proto_enumTypes[4].Descriptor()
+}
+
+func (CheckDebugStep_Result) Type() protoreflect.EnumType {
+	return &file_kessel_relations_v1beta1_check_proto_enumTypes[4]
+}
+
+func (x CheckDebugStep_Result) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CheckDebugStep_Result.Descriptor instead.
+func (CheckDebugStep_Result) EnumDescriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_check_proto_rawDescGZIP(), []int{12, 1}
+}
+
 type CheckRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Resource      *ObjectReference       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
-	Relation      string                 `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
-	Subject       *SubjectReference      `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
-	Consistency   *Consistency           `protobuf:"bytes,4,opt,name=consistency,proto3" json:"consistency,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Resource    *ObjectReference       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Relation    string                 `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
+	Subject     *SubjectReference      `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	Consistency *Consistency           `protobuf:"bytes,4,opt,name=consistency,proto3" json:"consistency,omitempty"`
+	// Return how the result was reached in `debug_trace`. Only allowed for callers
+	// granted the `debug` operation.
+	Debug         bool `protobuf:"varint,5,opt,name=debug,proto3" json:"debug,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -239,12 +347,21 @@ func (x *CheckRequest) GetConsistency() *Consistency {
 	return nil
 }
 
+func (x *CheckRequest) GetDebug() bool {
+	if x != nil {
+		return x.Debug
+	}
+	return false
+}
+
 type CheckResponse struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Allowed          CheckResponse_Allowed  `protobuf:"varint,1,opt,name=allowed,proto3,enum=kessel.relations.v1beta1.CheckResponse_Allowed" json:"allowed,omitempty"`
 	ConsistencyToken *ConsistencyToken      `protobuf:"bytes,2,opt,name=consistency_token,json=consistencyToken,proto3" json:"consistency_token,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// Set if `debug` was requested.
+	DebugTrace    *CheckDebugTrace `protobuf:"bytes,3,opt,name=debug_trace,json=debugTrace,proto3" json:"debug_trace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CheckResponse) Reset() {
@@ -291,6 +408,13 @@ func (x *CheckResponse) GetConsistencyToken() *ConsistencyToken {
 	return nil
 }
 
+func (x *CheckResponse) GetDebugTrace() *CheckDebugTrace {
+	if x != nil {
+		return x.DebugTrace
+	}
+	return nil
+}
+
 type CheckForUpdateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Resource      *ObjectReference       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
@@ -404,10 +528,13 @@ func (x *CheckForUpdateResponse) GetConsistencyToken() *ConsistencyToken {
 }
 
 type CheckBulkRequestItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Resource      *ObjectReference       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
-	Relation      string                 `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
-	Subject       *SubjectReference      `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Resource *ObjectReference       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Relation string                 `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
+	Subject  *SubjectReference      `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Return how the result of this item was reached in its `debug_trace`. Only
+	// allowed for callers granted the `debug` operation.
+	Debug         bool `protobuf:"varint,4,opt,name=debug,proto3" json:"debug,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -463,9 +590,18 @@ func (x *CheckBulkRequestItem) GetSubject() *SubjectReference {
 	return nil
 }
 
+func (x *CheckBulkRequestItem) GetDebug() bool {
+	if x != nil {
+		return x.Debug
+	}
+	return false
+}
+
 type CheckBulkResponseItem struct {
-	state         protoimpl.MessageState        `protogen:"open.v1"`
-	Allowed       CheckBulkResponseItem_Allowed `protobuf:"varint,1,opt,name=allowed,proto3,enum=kessel.relations.v1beta1.CheckBulkResponseItem_Allowed" json:"allowed,omitempty"`
+	state   protoimpl.MessageState        `protogen:"open.v1"`
+	Allowed CheckBulkResponseItem_Allowed `protobuf:"varint,1,opt,name=allowed,proto3,enum=kessel.relations.v1beta1.CheckBulkResponseItem_Allowed" json:"allowed,omitempty"`
+	// Set if `debug` was requested for the item.
+	DebugTrace    *CheckDebugTrace `protobuf:"bytes,2,opt,name=debug_trace,json=debugTrace,proto3" json:"debug_trace,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -507,6 +643,13 @@ func (x *CheckBulkResponseItem) GetAllowed() CheckBulkResponseItem_Allowed {
 	return CheckBulkResponseItem_ALLOWED_UNSPECIFIED
 }
 
+func (x *CheckBulkResponseItem) GetDebugTrace() *CheckDebugTrace {
+	if x != nil {
+		return x.DebugTrace
+	}
+	return nil
+}
+
 type CheckBulkResponsePair struct {
 	state   protoimpl.MessageState `protogen:"open.v1"`
 	Request *CheckBulkRequestItem  `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
@@ -797,19 +940,206 @@ func (x *CheckForUpdateBulkResponse) GetConsistencyToken() *ConsistencyToken {
 	return nil
 }
 
+// How SpiceDB computed the result of a check, for incident analysis. The format
+// of traces is not stable and may change between releases.
+type CheckDebugTrace struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The evaluation of the checked relation, with the steps it took.
+	Root *CheckDebugStep `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// The tuples found by the evaluation that grant the subject the relation,
+	// directly, through the wildcard of its type or through a subject set. Tuples
+	// followed by arrows are not listed, their target is the resource of the
+	// arrow's step.
+	MatchedTuples []*Relationship `protobuf:"bytes,2,rep,name=matched_tuples,json=matchedTuples,proto3" json:"matched_tuples,omitempty"`
+	// The number of operations SpiceDB dispatched to compute the result. For bulk
+	// checks, the number dispatched for the whole request.
+	DispatchCount uint32 `protobuf:"varint,3,opt,name=dispatch_count,json=dispatchCount,proto3" json:"dispatch_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckDebugTrace) Reset() {
+	*x = CheckDebugTrace{}
+	mi := &file_kessel_relations_v1beta1_check_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckDebugTrace) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckDebugTrace) ProtoMessage() {}
+
+func (x *CheckDebugTrace) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_check_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckDebugTrace.ProtoReflect.Descriptor instead.
+func (*CheckDebugTrace) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_check_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CheckDebugTrace) GetRoot() *CheckDebugStep {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *CheckDebugTrace) GetMatchedTuples() []*Relationship {
+	if x != nil {
+		return x.MatchedTuples
+	}
+	return nil
+}
+
+func (x *CheckDebugTrace) GetDispatchCount() uint32 {
+	if x != nil {
+		return x.DispatchCount
+	}
+	return 0
+}
+
+// The evaluation of a relation of a resource for the subject.
+type CheckDebugStep struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The resource evaluated. When SpiceDB evaluated several resources of the
+	// same type at once, `id` lists them separated by commas.
+	Resource *ObjectReference      `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Relation string                `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
+	Kind     CheckDebugStep_Kind   `protobuf:"varint,3,opt,name=kind,proto3,enum=kessel.relations.v1beta1.CheckDebugStep_Kind" json:"kind,omitempty"`
+	Subject  *SubjectReference     `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	Result   CheckDebugStep_Result `protobuf:"varint,5,opt,name=result,proto3,enum=kessel.relations.v1beta1.CheckDebugStep_Result" json:"result,omitempty"`
+	// Whether the result was taken from SpiceDB's cache, in which case the
+	// step has no sub-steps.
+	Cached   bool                 `protobuf:"varint,6,opt,name=cached,proto3" json:"cached,omitempty"`
+	Duration *durationpb.Duration `protobuf:"bytes,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	// Whether the step was reached through an arrow, evaluating a relation of a
+	// resource related to the resource of the parent step.
+	Arrow         bool              `protobuf:"varint,8,opt,name=arrow,proto3" json:"arrow,omitempty"`
+	Steps         []*CheckDebugStep `protobuf:"bytes,9,rep,name=steps,proto3" json:"steps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckDebugStep) Reset() {
+	*x = CheckDebugStep{}
+	mi := &file_kessel_relations_v1beta1_check_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckDebugStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckDebugStep) ProtoMessage() {}
+
+func (x *CheckDebugStep) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_check_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckDebugStep.ProtoReflect.Descriptor instead.
+func (*CheckDebugStep) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_check_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CheckDebugStep) GetResource() *ObjectReference {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *CheckDebugStep) GetRelation() string {
+	if x != nil {
+		return x.Relation
+	}
+	return ""
+}
+
+func (x *CheckDebugStep) GetKind() CheckDebugStep_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return CheckDebugStep_KIND_UNSPECIFIED
+}
+
+func (x *CheckDebugStep) GetSubject() *SubjectReference {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *CheckDebugStep) GetResult() CheckDebugStep_Result {
+	if x != nil {
+		return x.Result
+	}
+	return CheckDebugStep_RESULT_UNSPECIFIED
+}
+
+func (x *CheckDebugStep) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *CheckDebugStep) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *CheckDebugStep) GetArrow() bool {
+	if x != nil {
+		return x.Arrow
+	}
+	return false
+}
+
+func (x *CheckDebugStep) GetSteps() []*CheckDebugStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
 var File_kessel_relations_v1beta1_check_proto protoreflect.FileDescriptor
 
 const file_kessel_relations_v1beta1_check_proto_rawDesc = "" +
 	"\n" +
-	"$kessel/relations/v1beta1/check.proto\x12\x18kessel.relations.v1beta1\x1a\x1cgoogle/api/annotations.proto\x1a%kessel/relations/v1beta1/common.proto\x1a\x1bbuf/validate/validate.proto\x1a\x17google/rpc/status.proto\"\x99\x02\n" +
+	"$kessel/relations/v1beta1/check.proto\x12\x18kessel.relations.v1beta1\x1a\x1cgoogle/api/annotations.proto\x1a%kessel/relations/v1beta1/common.proto\x1a\x1bbuf/validate/validate.proto\x1a\x17google/rpc/status.proto\x1a\x1egoogle/protobuf/duration.proto\"\xaf\x02\n" +
 	"\fCheckRequest\x12M\n" +
 	"\bresource\x18\x01 \x01(\v2).kessel.relations.v1beta1.ObjectReferenceB\x06\xbaH\x03\xc8\x01\x01R\bresource\x12#\n" +
 	"\brelation\x18\x02 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\brelation\x12L\n" +
 	"\asubject\x18\x03 \x01(\v2*.kessel.relations.v1beta1.SubjectReferenceB\x06\xbaH\x03\xc8\x01\x01R\asubject\x12G\n" +
-	"\vconsistency\x18\x04 \x01(\v2%.kessel.relations.v1beta1.ConsistencyR\vconsistency\"\xfc\x01\n" +
+	"\vconsistency\x18\x04 \x01(\v2%.kessel.relations.v1beta1.ConsistencyR\vconsistency\x12\x14\n" +
+	"\x05debug\x18\x05 \x01(\bR\x05debug\"\xc8\x02\n" +
 	"\rCheckResponse\x12I\n" +
 	"\aallowed\x18\x01 \x01(\x0e2/.kessel.relations.v1beta1.CheckResponse.AllowedR\aallowed\x12W\n" +
-	"\x11consistency_token\x18\x02 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\"G\n" +
+	"\x11consistency_token\x18\x02 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\x12J\n" +
+	"\vdebug_trace\x18\x03 \x01(\v2).kessel.relations.v1beta1.CheckDebugTraceR\n" +
+	"debugTrace\"G\n" +
 	"\aAllowed\x12\x17\n" +
 	"\x13ALLOWED_UNSPECIFIED\x10\x00\x12\x10\n" +
 	"\fALLOWED_TRUE\x10\x01\x12\x11\n" +
@@ -824,13 +1154,16 @@ const file_kessel_relations_v1beta1_check_proto_rawDesc = "" +
 	"\aAllowed\x12\x17\n" +
 	"\x13ALLOWED_UNSPECIFIED\x10\x00\x12\x10\n" +
 	"\fALLOWED_TRUE\x10\x01\x12\x11\n" +
-	"\rALLOWED_FALSE\x10\x02\"\xd8\x01\n" +
+	"\rALLOWED_FALSE\x10\x02\"\xee\x01\n" +
 	"\x14CheckBulkRequestItem\x12M\n" +
 	"\bresource\x18\x01 \x01(\v2).kessel.relations.v1beta1.ObjectReferenceB\x06\xbaH\x03\xc8\x01\x01R\bresource\x12#\n" +
 	"\brelation\x18\x02 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\brelation\x12L\n" +
-	"\asubject\x18\x03 \x01(\v2*.kessel.relations.v1beta1.SubjectReferenceB\x06\xbaH\x03\xc8\x01\x01R\asubject\"\xb3\x01\n" +
+	"\asubject\x18\x03 \x01(\v2*.kessel.relations.v1beta1.SubjectReferenceB\x06\xbaH\x03\xc8\x01\x01R\asubject\x12\x14\n" +
+	"\x05debug\x18\x04 \x01(\bR\x05debug\"\xff\x01\n" +
 	"\x15CheckBulkResponseItem\x12Q\n" +
-	"\aallowed\x18\x01 \x01(\x0e27.kessel.relations.v1beta1.CheckBulkResponseItem.AllowedR\aallowed\"G\n" +
+	"\aallowed\x18\x01 \x01(\x0e27.kessel.relations.v1beta1.CheckBulkResponseItem.AllowedR\aallowed\x12J\n" +
+	"\vdebug_trace\x18\x02 \x01(\v2).kessel.relations.v1beta1.CheckDebugTraceR\n" +
+	"debugTrace\"G\n" +
 	"\aAllowed\x12\x17\n" +
 	"\x13ALLOWED_UNSPECIFIED\x10\x00\x12\x10\n" +
 	"\fALLOWED_TRUE\x10\x01\x12\x11\n" +
@@ -851,7 +1184,30 @@ const file_kessel_relations_v1beta1_check_proto_rawDesc = "" +
 	"\x05items\x18\x01 \x03(\v2..kessel.relations.v1beta1.CheckBulkRequestItemB\b\xbaH\x05\x92\x01\x02\b\x01R\x05items\"\xc6\x01\n" +
 	"\x1aCheckForUpdateBulkResponse\x12O\n" +
 	"\x05pairs\x18\x01 \x03(\v2/.kessel.relations.v1beta1.CheckBulkResponsePairB\b\xbaH\x05\x92\x01\x02\b\x01R\x05pairs\x12W\n" +
-	"\x11consistency_token\x18\x02 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken2\xd3\x04\n" +
+	"\x11consistency_token\x18\x02 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\"\xc5\x01\n" +
+	"\x0fCheckDebugTrace\x12<\n" +
+	"\x04root\x18\x01 \x01(\v2(.kessel.relations.v1beta1.CheckDebugStepR\x04root\x12M\n" +
+	"\x0ematched_tuples\x18\x02 \x03(\v2&.kessel.relations.v1beta1.RelationshipR\rmatchedTuples\x12%\n" +
+	"\x0edispatch_count\x18\x03 \x01(\rR\rdispatchCount\"\x91\x05\n" +
+	"\x0eCheckDebugStep\x12E\n" +
+	"\bresource\x18\x01 \x01(\v2).kessel.relations.v1beta1.ObjectReferenceR\bresource\x12\x1a\n" +
+	"\brelation\x18\x02 \x01(\tR\brelation\x12A\n" +
+	"\x04kind\x18\x03 \x01(\x0e2-.kessel.relations.v1beta1.CheckDebugStep.KindR\x04kind\x12D\n" +
+	"\asubject\x18\x04 \x01(\v2*.kessel.relations.v1beta1.SubjectReferenceR\asubject\x12G\n" +
+	"\x06result\x18\x05 \x01(\x0e2/.kessel.relations.v1beta1.CheckDebugStep.ResultR\x06result\x12\x16\n" +
+	"\x06cached\x18\x06 \x01(\bR\x06cached\x125\n" +
+	"\bduration\x18\a \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x14\n" +
+	"\x05arrow\x18\b \x01(\bR\x05arrow\x12>\n" +
+	"\x05steps\x18\t \x03(\v2(.kessel.relations.v1beta1.CheckDebugStepR\x05steps\"D\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rKIND_RELATION\x10\x01\x12\x13\n" +
+	"\x0fKIND_PERMISSION\x10\x02\"_\n" +
+	"\x06Result\x12\x16\n" +
+	"\x12RESULT_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eRESULT_ALLOWED\x10\x01\x12\x11\n" +
+	"\rRESULT_DENIED\x10\x02\x12\x16\n" +
+	"\x12RESULT_CONDITIONAL\x10\x032\xd3\x04\n" +
 	"\x12KesselCheckService\x12s\n" +
 	"\x05Check\x12&.kessel.relations.v1beta1.CheckRequest\x1a'.kessel.relations.v1beta1.CheckResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1beta1/check\x12\x97\x01\n" +
 	"\x0eCheckForUpdate\x12/.kessel.relations.v1beta1.CheckForUpdateRequest\x1a0.kessel.relations.v1beta1.CheckForUpdateResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1beta1/checkforupdate\x12\x83\x01\n" +
@@ -871,65 +1227,81 @@ func file_kessel_relations_v1beta1_check_proto_rawDescGZIP() []byte {
 	return file_kessel_relations_v1beta1_check_proto_rawDescData
 }
 
-var file_kessel_relations_v1beta1_check_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_kessel_relations_v1beta1_check_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_kessel_relations_v1beta1_check_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_kessel_relations_v1beta1_check_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_kessel_relations_v1beta1_check_proto_goTypes = []any{
 	(CheckResponse_Allowed)(0),          // 0: kessel.relations.v1beta1.CheckResponse.Allowed
 	(CheckForUpdateResponse_Allowed)(0), // 1: kessel.relations.v1beta1.CheckForUpdateResponse.Allowed
 	(CheckBulkResponseItem_Allowed)(0),  // 2: kessel.relations.v1beta1.CheckBulkResponseItem.Allowed
-	(*CheckRequest)(nil),                // 3: kessel.relations.v1beta1.CheckRequest
-	(*CheckResponse)(nil),               // 4: kessel.relations.v1beta1.CheckResponse
-	(*CheckForUpdateRequest)(nil),       // 5: kessel.relations.v1beta1.CheckForUpdateRequest
-	(*CheckForUpdateResponse)(nil),      // 6: kessel.relations.v1beta1.CheckForUpdateResponse
-	(*CheckBulkRequestItem)(nil),        // 7: kessel.relations.v1beta1.CheckBulkRequestItem
-	(*CheckBulkResponseItem)(nil),       // 8: kessel.relations.v1beta1.CheckBulkResponseItem
-	(*CheckBulkResponsePair)(nil),       // 9: kessel.relations.v1beta1.CheckBulkResponsePair
-	(*CheckBulkRequest)(nil),            // 10: kessel.relations.v1beta1.CheckBulkRequest
-	(*CheckBulkResponse)(nil),           // 11: kessel.relations.v1beta1.CheckBulkResponse
-	(*CheckForUpdateBulkRequest)(nil),   // 12: kessel.relations.v1beta1.CheckForUpdateBulkRequest
-	(*CheckForUpdateBulkResponse)(nil),  // 13: kessel.relations.v1beta1.CheckForUpdateBulkResponse
-	(*ObjectReference)(nil),             // 14: kessel.relations.v1beta1.ObjectReference
-	(*SubjectReference)(nil),            // 15: kessel.relations.v1beta1.SubjectReference
-	(*Consistency)(nil),                 // 16: kessel.relations.v1beta1.Consistency
-	(*ConsistencyToken)(nil),            // 17: kessel.relations.v1beta1.ConsistencyToken
-	(*status.Status)(nil),               // 18: google.rpc.Status
+	(CheckDebugStep_Kind)(0),            // 3: kessel.relations.v1beta1.CheckDebugStep.Kind
+	(CheckDebugStep_Result)(0),          // 4: kessel.relations.v1beta1.CheckDebugStep.Result
+	(*CheckRequest)(nil),                // 5: kessel.relations.v1beta1.CheckRequest
+	(*CheckResponse)(nil),               // 6: kessel.relations.v1beta1.CheckResponse
+	(*CheckForUpdateRequest)(nil),       // 7: kessel.relations.v1beta1.CheckForUpdateRequest
+	(*CheckForUpdateResponse)(nil),      // 8: kessel.relations.v1beta1.CheckForUpdateResponse
+	(*CheckBulkRequestItem)(nil),        // 9: kessel.relations.v1beta1.CheckBulkRequestItem
+	(*CheckBulkResponseItem)(nil),       // 10: kessel.relations.v1beta1.CheckBulkResponseItem
+	(*CheckBulkResponsePair)(nil),       // 11: kessel.relations.v1beta1.CheckBulkResponsePair
+	(*CheckBulkRequest)(nil),            // 12: kessel.relations.v1beta1.CheckBulkRequest
+	(*CheckBulkResponse)(nil),           // 13: kessel.relations.v1beta1.CheckBulkResponse
+	(*CheckForUpdateBulkRequest)(nil),   // 14: kessel.relations.v1beta1.CheckForUpdateBulkRequest
+	(*CheckForUpdateBulkResponse)(nil),  // 15: kessel.relations.v1beta1.CheckForUpdateBulkResponse
+	(*CheckDebugTrace)(nil),             // 16: kessel.relations.v1beta1.CheckDebugTrace
+	(*CheckDebugStep)(nil),              // 17: kessel.relations.v1beta1.CheckDebugStep
+	(*ObjectReference)(nil),             // 18: kessel.relations.v1beta1.ObjectReference
+	(*SubjectReference)(nil),            // 19: kessel.relations.v1beta1.SubjectReference
+	(*Consistency)(nil),                 // 20: kessel.relations.v1beta1.Consistency
+	(*ConsistencyToken)(nil),            // 21: kessel.relations.v1beta1.ConsistencyToken
+	(*status.Status)(nil),               // 22: google.rpc.Status
+	(*Relationship)(nil),                // 23: kessel.relations.v1beta1.Relationship
+	(*durationpb.Duration)(nil),         // 24: google.protobuf.Duration
 }
 var file_kessel_relations_v1beta1_check_proto_depIdxs = []int32{
-	14, // 0: kessel.relations.v1beta1.CheckRequest.resource:type_name -> kessel.relations.v1beta1.ObjectReference
-	15, // 1: kessel.relations.v1beta1.CheckRequest.subject:type_name -> kessel.relations.v1beta1.SubjectReference
-	16, // 2: kessel.relations.v1beta1.CheckRequest.consistency:type_name -> kessel.relations.v1beta1.Consistency
+	18, // 0: kessel.relations.v1beta1.CheckRequest.resource:type_name -> kessel.relations.v1beta1.ObjectReference
+	19, // 1: kessel.relations.v1beta1.CheckRequest.subject:type_name -> kessel.relations.v1beta1.SubjectReference
+	20, // 2: kessel.relations.v1beta1.CheckRequest.consistency:type_name -> kessel.relations.v1beta1.Consistency
 	0,  // 3: kessel.relations.v1beta1.CheckResponse.allowed:type_name -> kessel.relations.v1beta1.CheckResponse.Allowed
-	17, // 4: kessel.relations.v1beta1.CheckResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
-	14, // 5: kessel.relations.v1beta1.CheckForUpdateRequest.resource:type_name -> kessel.relations.v1beta1.ObjectReference
-	15, // 6: kessel.relations.v1beta1.CheckForUpdateRequest.subject:type_name -> kessel.relations.v1beta1.SubjectReference
-	1,  // 7: kessel.relations.v1beta1.CheckForUpdateResponse.allowed:type_name -> kessel.relations.v1beta1.CheckForUpdateResponse.Allowed
-	17, // 8: kessel.relations.v1beta1.CheckForUpdateResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
-	14, // 9: kessel.relations.v1beta1.CheckBulkRequestItem.resource:type_name -> kessel.relations.v1beta1.ObjectReference
-	15, // 10: kessel.relations.v1beta1.CheckBulkRequestItem.subject:type_name -> kessel.relations.v1beta1.SubjectReference
-	2,  // 11: kessel.relations.v1beta1.CheckBulkResponseItem.allowed:type_name -> kessel.relations.v1beta1.CheckBulkResponseItem.Allowed
-	7,  // 12: kessel.relations.v1beta1.CheckBulkResponsePair.request:type_name -> kessel.relations.v1beta1.CheckBulkRequestItem
-	8,  // 13: kessel.relations.v1beta1.CheckBulkResponsePair.item:type_name -> kessel.relations.v1beta1.CheckBulkResponseItem
-	18, // 14: kessel.relations.v1beta1.CheckBulkResponsePair.error:type_name -> google.rpc.Status
-	7,  // 15: kessel.relations.v1beta1.CheckBulkRequest.items:type_name -> kessel.relations.v1beta1.CheckBulkRequestItem
-	16, // 16: kessel.relations.v1beta1.CheckBulkRequest.consistency:type_name -> kessel.relations.v1beta1.Consistency
-	9,  // 17: kessel.relations.v1beta1.CheckBulkResponse.pairs:type_name -> kessel.relations.v1beta1.CheckBulkResponsePair
-	17, // 18: kessel.relations.v1beta1.CheckBulkResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
-	7,  // 19: kessel.relations.v1beta1.CheckForUpdateBulkRequest.items:type_name -> kessel.relations.v1beta1.CheckBulkRequestItem
-	9,  // 20: kessel.relations.v1beta1.CheckForUpdateBulkResponse.pairs:type_name -> kessel.relations.v1beta1.CheckBulkResponsePair
-	17, // 21: kessel.relations.v1beta1.CheckForUpdateBulkResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
-	3,  // 22: kessel.relations.v1beta1.KesselCheckService.Check:input_type -> kessel.relations.v1beta1.CheckRequest
-	5,  // 23: kessel.relations.v1beta1.KesselCheckService.CheckForUpdate:input_type -> kessel.relations.v1beta1.CheckForUpdateRequest
-	10, // 24: kessel.relations.v1beta1.KesselCheckService.CheckBulk:input_type -> kessel.relations.v1beta1.CheckBulkRequest
-	12, // 25: kessel.relations.v1beta1.KesselCheckService.CheckForUpdateBulk:input_type -> kessel.relations.v1beta1.CheckForUpdateBulkRequest
-	4,  // 26: kessel.relations.v1beta1.KesselCheckService.Check:output_type -> kessel.relations.v1beta1.CheckResponse
-	6,  // 27: kessel.relations.v1beta1.KesselCheckService.CheckForUpdate:output_type -> kessel.relations.v1beta1.CheckForUpdateResponse
-	11, // 28: kessel.relations.v1beta1.KesselCheckService.CheckBulk:output_type -> kessel.relations.v1beta1.CheckBulkResponse
-	13, // 29: kessel.relations.v1beta1.KesselCheckService.CheckForUpdateBulk:output_type -> kessel.relations.v1beta1.CheckForUpdateBulkResponse
-	26, // [26:30] is the sub-list for method output_type
-	22, // [22:26] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	21, // 4: kessel.relations.v1beta1.CheckResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
+	16, // 5: kessel.relations.v1beta1.CheckResponse.debug_trace:type_name -> kessel.relations.v1beta1.CheckDebugTrace
+	18, // 6: kessel.relations.v1beta1.CheckForUpdateRequest.resource:type_name -> kessel.relations.v1beta1.ObjectReference
+	19, // 7: kessel.relations.v1beta1.CheckForUpdateRequest.subject:type_name -> kessel.relations.v1beta1.SubjectReference
+	1,  // 8: kessel.relations.v1beta1.CheckForUpdateResponse.allowed:type_name -> kessel.relations.v1beta1.CheckForUpdateResponse.Allowed
+	21, // 9: kessel.relations.v1beta1.CheckForUpdateResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
+	18, // 10: kessel.relations.v1beta1.CheckBulkRequestItem.resource:type_name -> kessel.relations.v1beta1.ObjectReference
+	19, // 11: kessel.relations.v1beta1.CheckBulkRequestItem.subject:type_name -> kessel.relations.v1beta1.SubjectReference
+	2,  // 12: kessel.relations.v1beta1.CheckBulkResponseItem.allowed:type_name -> kessel.relations.v1beta1.CheckBulkResponseItem.Allowed
+	16, // 13: kessel.relations.v1beta1.CheckBulkResponseItem.debug_trace:type_name -> kessel.relations.v1beta1.CheckDebugTrace
+	9,  // 14: kessel.relations.v1beta1.CheckBulkResponsePair.request:type_name -> kessel.relations.v1beta1.CheckBulkRequestItem
+	10, // 15: kessel.relations.v1beta1.CheckBulkResponsePair.item:type_name -> kessel.relations.v1beta1.CheckBulkResponseItem
+	22, // 16: kessel.relations.v1beta1.CheckBulkResponsePair.error:type_name -> google.rpc.Status
+	9,  // 17: kessel.relations.v1beta1.CheckBulkRequest.items:type_name -> kessel.relations.v1beta1.CheckBulkRequestItem
+	20, // 18: kessel.relations.v1beta1.CheckBulkRequest.consistency:type_name -> kessel.relations.v1beta1.Consistency
+	11, // 19: kessel.relations.v1beta1.CheckBulkResponse.pairs:type_name -> kessel.relations.v1beta1.CheckBulkResponsePair
+	21, // 20: kessel.relations.v1beta1.CheckBulkResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
+	9,  // 21: kessel.relations.v1beta1.CheckForUpdateBulkRequest.items:type_name -> kessel.relations.v1beta1.CheckBulkRequestItem
+	11, // 22: kessel.relations.v1beta1.CheckForUpdateBulkResponse.pairs:type_name -> kessel.relations.v1beta1.CheckBulkResponsePair
+	21, // 23: kessel.relations.v1beta1.CheckForUpdateBulkResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
+	17, // 24: kessel.relations.v1beta1.CheckDebugTrace.root:type_name -> kessel.relations.v1beta1.CheckDebugStep
+	23, // 25: kessel.relations.v1beta1.CheckDebugTrace.matched_tuples:type_name -> kessel.relations.v1beta1.Relationship
+	18, // 26: kessel.relations.v1beta1.CheckDebugStep.resource:type_name -> kessel.relations.v1beta1.ObjectReference
+	3,  // 27: kessel.relations.v1beta1.CheckDebugStep.kind:type_name -> kessel.relations.v1beta1.CheckDebugStep.Kind
+	19, // 28: kessel.relations.v1beta1.CheckDebugStep.subject:type_name -> kessel.relations.v1beta1.SubjectReference
+	4,  // 29: kessel.relations.v1beta1.CheckDebugStep.result:type_name -> kessel.relations.v1beta1.CheckDebugStep.Result
+	24, // 30: kessel.relations.v1beta1.CheckDebugStep.duration:type_name -> google.protobuf.Duration
+	17, // 31: kessel.relations.v1beta1.CheckDebugStep.steps:type_name -> kessel.relations.v1beta1.CheckDebugStep
+	5,  // 32: kessel.relations.v1beta1.KesselCheckService.Check:input_type -> kessel.relations.v1beta1.CheckRequest
+	7,  // 33: kessel.relations.v1beta1.KesselCheckService.CheckForUpdate:input_type -> kessel.relations.v1beta1.CheckForUpdateRequest
+	12, // 34: kessel.relations.v1beta1.KesselCheckService.CheckBulk:input_type -> kessel.relations.v1beta1.CheckBulkRequest
+	14, // 35: kessel.relations.v1beta1.KesselCheckService.CheckForUpdateBulk:input_type -> kessel.relations.v1beta1.CheckForUpdateBulkRequest
+	6,  // 36: kessel.relations.v1beta1.KesselCheckService.Check:output_type -> kessel.relations.v1beta1.CheckResponse
+	8,  // 37: kessel.relations.v1beta1.KesselCheckService.CheckForUpdate:output_type -> kessel.relations.v1beta1.CheckForUpdateResponse
+	13, // 38: kessel.relations.v1beta1.KesselCheckService.CheckBulk:output_type -> kessel.relations.v1beta1.CheckBulkResponse
+	15, // 39: kessel.relations.v1beta1.KesselCheckService.CheckForUpdateBulk:output_type -> kessel.relations.v1beta1.CheckForUpdateBulkResponse
+	36, // [36:40] is the sub-list for method output_type
+	32, // [32:36] is the sub-list for method input_type
+	32, // [32:32] is the sub-list for extension type_name
+	32, // [32:32] is the sub-list for extension extendee
+	0,  // [0:32] is the sub-list for field type_name
 }
 
 func init() { file_kessel_relations_v1beta1_check_proto_init() }
@@ -947,8 +1319,8 @@ func file_kessel_relations_v1beta1_check_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kessel_relations_v1beta1_check_proto_rawDesc), len(file_kessel_relations_v1beta1_check_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   11,
+			NumEnums:      5,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -435,6 +435,9 @@ func (*Consistency_MinimizeLatency) isConsistency_Requirement() {}
 func (*Consistency_AtLeastAsFresh) isConsistency_Requirement() {}
 
 // The ConsistencyToken is used to provide consistency between write and read requests.
+// Tokens are signed envelopes recording the issuing backend and issue time, which clients
+// may decode to compare the freshness of two tokens. A token is only valid for the backend
+// that issued it; requests carrying a malformed, foreign or expired token are rejected.
 type ConsistencyToken struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
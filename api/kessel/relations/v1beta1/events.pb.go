@@ -0,0 +1,393 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: kessel/relations/v1beta1/events.proto
+
+package v1beta1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TupleEvent_Operation int32
+
+const (
+	TupleEvent_OPERATION_UNSPECIFIED TupleEvent_Operation = 0
+	// Tuples were created and did not exist before.
+	TupleEvent_OPERATION_CREATE TupleEvent_Operation = 1
+	// Tuples were created, or left in place if they existed. SpiceDB may report created tuples as touched.
+	TupleEvent_OPERATION_TOUCH TupleEvent_Operation = 2
+	// Tuples were deleted.
+	TupleEvent_OPERATION_DELETE TupleEvent_Operation = 3
+)
+
+// Enum value maps for TupleEvent_Operation.
+var (
+	TupleEvent_Operation_name = map[int32]string{
+		0: "OPERATION_UNSPECIFIED",
+		1: "OPERATION_CREATE",
+		2: "OPERATION_TOUCH",
+		3: "OPERATION_DELETE",
+	}
+	TupleEvent_Operation_value = map[string]int32{
+		"OPERATION_UNSPECIFIED": 0,
+		"OPERATION_CREATE":      1,
+		"OPERATION_TOUCH":       2,
+		"OPERATION_DELETE":      3,
+	}
+)
+
+func (x TupleEvent_Operation) Enum() *TupleEvent_Operation {
+	p := new(TupleEvent_Operation)
+	*p = x
+	return p
+}
+
+func (x TupleEvent_Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TupleEvent_Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_kessel_relations_v1beta1_events_proto_enumTypes[0].Descriptor()
+}
+
+func (TupleEvent_Operation) Type() protoreflect.EnumType {
+	return &file_kessel_relations_v1beta1_events_proto_enumTypes[0]
+}
+
+func (x TupleEvent_Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TupleEvent_Operation.Descriptor instead.
+func (TupleEvent_Operation) EnumDescriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_events_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type TupleChange_Operation int32
+
+const (
+	TupleChange_OPERATION_UNSPECIFIED TupleChange_Operation = 0
+	// Creates the tuple, or leaves it in place if it exists.
+	TupleChange_OPERATION_TOUCH TupleChange_Operation = 1
+	// Deletes the tuple if it exists.
+	TupleChange_OPERATION_DELETE TupleChange_Operation = 2
+)
+
+// Enum value maps for TupleChange_Operation.
+var (
+	TupleChange_Operation_name = map[int32]string{
+		0: "OPERATION_UNSPECIFIED",
+		1: "OPERATION_TOUCH",
+		2: "OPERATION_DELETE",
+	}
+	TupleChange_Operation_value = map[string]int32{
+		"OPERATION_UNSPECIFIED": 0,
+		"OPERATION_TOUCH":       1,
+		"OPERATION_DELETE":      2,
+	}
+)
+
+func (x TupleChange_Operation) Enum() *TupleChange_Operation {
+	p := new(TupleChange_Operation)
+	*p = x
+	return p
+}
+
+func (x TupleChange_Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TupleChange_Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_kessel_relations_v1beta1_events_proto_enumTypes[1].Descriptor()
+}
+
+func (TupleChange_Operation) Type() protoreflect.EnumType {
+	return &file_kessel_relations_v1beta1_events_proto_enumTypes[1]
+}
+
+func (x TupleChange_Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TupleChange_Operation.Descriptor instead.
+func (TupleChange_Operation) EnumDescriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_events_proto_rawDescGZIP(), []int{1, 0}
+}
+
+// A change to the persisted tuples, published for every change SpiceDB reports through its Watch API, however it was
+// made.
+//
+// Events are delivered at least once: consumers should deduplicate them by `id`.
+type TupleEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique per change, the same for every delivery of the event and on every replica publishing it.
+	Id        string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Operation TupleEvent_Operation `protobuf:"varint,2,opt,name=operation,proto3,enum=kessel.relations.v1beta1.TupleEvent_Operation" json:"operation,omitempty"`
+	// The tuples created, touched or deleted. A change of more than 1000 tuples is published as several events.
+	Tuples []*Relationship `protobuf:"bytes,3,rep,name=tuples,proto3" json:"tuples,omitempty"`
+	// For deletes made by DeleteTuples, the filter of the call.
+	Filter *RelationTupleFilter `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	// For deletes, the number of tuples in `tuples`.
+	DeletedCount uint64 `protobuf:"varint,5,opt,name=deleted_count,json=deletedCount,proto3" json:"deleted_count,omitempty"`
+	// The authenticated caller of the CreateTuples or DeleteTuples call that made the change. Empty if authentication
+	// is disabled, the change was made otherwise, e.g. by an import, or SpiceDB does not record transaction metadata.
+	Principal string `protobuf:"bytes,6,opt,name=principal,proto3" json:"principal,omitempty"`
+	// The revision the change was written at.
+	ConsistencyToken *ConsistencyToken `protobuf:"bytes,7,opt,name=consistency_token,json=consistencyToken,proto3" json:"consistency_token,omitempty"`
+	// When the event was read from SpiceDB.
+	Time          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TupleEvent) Reset() {
+	*x = TupleEvent{}
+	mi := &file_kessel_relations_v1beta1_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TupleEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TupleEvent) ProtoMessage() {}
+
+func (x *TupleEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TupleEvent.ProtoReflect.Descriptor instead.
+func (*TupleEvent) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TupleEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TupleEvent) GetOperation() TupleEvent_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return TupleEvent_OPERATION_UNSPECIFIED
+}
+
+func (x *TupleEvent) GetTuples() []*Relationship {
+	if x != nil {
+		return x.Tuples
+	}
+	return nil
+}
+
+func (x *TupleEvent) GetFilter() *RelationTupleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *TupleEvent) GetDeletedCount() uint64 {
+	if x != nil {
+		return x.DeletedCount
+	}
+	return 0
+}
+
+func (x *TupleEvent) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *TupleEvent) GetConsistencyToken() *ConsistencyToken {
+	if x != nil {
+		return x.ConsistencyToken
+	}
+	return nil
+}
+
+func (x *TupleEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+// A change to a tuple, read from a Kafka topic by the consumer mode of the service.
+//
+// Changes are applied with touch and delete semantics, so applying one again has no effect.
+type TupleChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operation     TupleChange_Operation  `protobuf:"varint,1,opt,name=operation,proto3,enum=kessel.relations.v1beta1.TupleChange_Operation" json:"operation,omitempty"`
+	Tuple         *Relationship          `protobuf:"bytes,2,opt,name=tuple,proto3" json:"tuple,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TupleChange) Reset() {
+	*x = TupleChange{}
+	mi := &file_kessel_relations_v1beta1_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TupleChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TupleChange) ProtoMessage() {}
+
+func (x *TupleChange) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TupleChange.ProtoReflect.Descriptor instead.
+func (*TupleChange) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TupleChange) GetOperation() TupleChange_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return TupleChange_OPERATION_UNSPECIFIED
+}
+
+func (x *TupleChange) GetTuple() *Relationship {
+	if x != nil {
+		return x.Tuple
+	}
+	return nil
+}
+
+var File_kessel_relations_v1beta1_events_proto protoreflect.FileDescriptor
+
+const file_kessel_relations_v1beta1_events_proto_rawDesc = "" +
+	"\n" +
+	"%kessel/relations/v1beta1/events.proto\x12\x18kessel.relations.v1beta1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a%kessel/relations/v1beta1/common.proto\x1a.kessel/relations/v1beta1/relation_tuples.proto\x1a\x1bbuf/validate/validate.proto\"\xa6\x04\n" +
+	"\n" +
+	"TupleEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12L\n" +
+	"\toperation\x18\x02 \x01(\x0e2..kessel.relations.v1beta1.TupleEvent.OperationR\toperation\x12>\n" +
+	"\x06tuples\x18\x03 \x03(\v2&.kessel.relations.v1beta1.RelationshipR\x06tuples\x12E\n" +
+	"\x06filter\x18\x04 \x01(\v2-.kessel.relations.v1beta1.RelationTupleFilterR\x06filter\x12#\n" +
+	"\rdeleted_count\x18\x05 \x01(\x04R\fdeletedCount\x12\x1c\n" +
+	"\tprincipal\x18\x06 \x01(\tR\tprincipal\x12W\n" +
+	"\x11consistency_token\x18\a \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\x12.\n" +
+	"\x04time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"g\n" +
+	"\tOperation\x12\x19\n" +
+	"\x15OPERATION_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10OPERATION_CREATE\x10\x01\x12\x13\n" +
+	"\x0fOPERATION_TOUCH\x10\x02\x12\x14\n" +
+	"\x10OPERATION_DELETE\x10\x03\"\x81\x02\n" +
+	"\vTupleChange\x12Y\n" +
+	"\toperation\x18\x01 \x01(\x0e2/.kessel.relations.v1beta1.TupleChange.OperationB\n" +
+	"\xbaH\a\x82\x01\x04\x10\x01 \x00R\toperation\x12D\n" +
+	"\x05tuple\x18\x02 \x01(\v2&.kessel.relations.v1beta1.RelationshipB\x06\xbaH\x03\xc8\x01\x01R\x05tuple\"Q\n" +
+	"\tOperation\x12\x19\n" +
+	"\x15OPERATION_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fOPERATION_TOUCH\x10\x01\x12\x14\n" +
+	"\x10OPERATION_DELETE\x10\x02Br\n" +
+	"(org.project_kessel.api.relations.v1beta1P\x01ZDgithub.com/project-kessel/relations-api/api/kessel/relations/v1beta1b\x06proto3"
+
+var (
+	file_kessel_relations_v1beta1_events_proto_rawDescOnce sync.Once
+	file_kessel_relations_v1beta1_events_proto_rawDescData []byte
+)
+
+func file_kessel_relations_v1beta1_events_proto_rawDescGZIP() []byte {
+	file_kessel_relations_v1beta1_events_proto_rawDescOnce.Do(func() {
+		file_kessel_relations_v1beta1_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kessel_relations_v1beta1_events_proto_rawDesc), len(file_kessel_relations_v1beta1_events_proto_rawDesc)))
+	})
+	return file_kessel_relations_v1beta1_events_proto_rawDescData
+}
+
+var file_kessel_relations_v1beta1_events_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_kessel_relations_v1beta1_events_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_kessel_relations_v1beta1_events_proto_goTypes = []any{
+	(TupleEvent_Operation)(0),     // 0: kessel.relations.v1beta1.TupleEvent.Operation
+	(TupleChange_Operation)(0),    // 1: kessel.relations.v1beta1.TupleChange.Operation
+	(*TupleEvent)(nil),            // 2: kessel.relations.v1beta1.TupleEvent
+	(*TupleChange)(nil),           // 3: kessel.relations.v1beta1.TupleChange
+	(*Relationship)(nil),          // 4: kessel.relations.v1beta1.Relationship
+	(*RelationTupleFilter)(nil),   // 5: kessel.relations.v1beta1.RelationTupleFilter
+	(*ConsistencyToken)(nil),      // 6: kessel.relations.v1beta1.ConsistencyToken
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_kessel_relations_v1beta1_events_proto_depIdxs = []int32{
+	0, // 0: kessel.relations.v1beta1.TupleEvent.operation:type_name -> kessel.relations.v1beta1.TupleEvent.Operation
+	4, // 1: kessel.relations.v1beta1.TupleEvent.tuples:type_name -> kessel.relations.v1beta1.Relationship
+	5, // 2: kessel.relations.v1beta1.TupleEvent.filter:type_name -> kessel.relations.v1beta1.RelationTupleFilter
+	6, // 3: kessel.relations.v1beta1.TupleEvent.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
+	7, // 4: kessel.relations.v1beta1.TupleEvent.time:type_name -> google.protobuf.Timestamp
+	1, // 5: kessel.relations.v1beta1.TupleChange.operation:type_name -> kessel.relations.v1beta1.TupleChange.Operation
+	4, // 6: kessel.relations.v1beta1.TupleChange.tuple:type_name -> kessel.relations.v1beta1.Relationship
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_kessel_relations_v1beta1_events_proto_init() }
+func file_kessel_relations_v1beta1_events_proto_init() {
+	if File_kessel_relations_v1beta1_events_proto != nil {
+		return
+	}
+	file_kessel_relations_v1beta1_common_proto_init()
+	file_kessel_relations_v1beta1_relation_tuples_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kessel_relations_v1beta1_events_proto_rawDesc), len(file_kessel_relations_v1beta1_events_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_kessel_relations_v1beta1_events_proto_goTypes,
+		DependencyIndexes: file_kessel_relations_v1beta1_events_proto_depIdxs,
+		EnumInfos:         file_kessel_relations_v1beta1_events_proto_enumTypes,
+		MessageInfos:      file_kessel_relations_v1beta1_events_proto_msgTypes,
+	}.Build()
+	File_kessel_relations_v1beta1_events_proto = out.File
+	file_kessel_relations_v1beta1_events_proto_goTypes = nil
+	file_kessel_relations_v1beta1_events_proto_depIdxs = nil
+}
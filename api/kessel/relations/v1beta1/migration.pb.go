@@ -0,0 +1,943 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: kessel/relations/v1beta1/migration.proto
+
+package v1beta1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Migration_State int32
+
+const (
+	Migration_STATE_UNSPECIFIED Migration_State = 0
+	Migration_STATE_RUNNING     Migration_State = 1
+	Migration_STATE_SUCCEEDED   Migration_State = 2
+	Migration_STATE_FAILED      Migration_State = 3
+)
+
+// Enum value maps for Migration_State.
+var (
+	Migration_State_name = map[int32]string{
+		0: "STATE_UNSPECIFIED",
+		1: "STATE_RUNNING",
+		2: "STATE_SUCCEEDED",
+		3: "STATE_FAILED",
+	}
+	Migration_State_value = map[string]int32{
+		"STATE_UNSPECIFIED": 0,
+		"STATE_RUNNING":     1,
+		"STATE_SUCCEEDED":   2,
+		"STATE_FAILED":      3,
+	}
+)
+
+func (x Migration_State) Enum() *Migration_State {
+	p := new(Migration_State)
+	*p = x
+	return p
+}
+
+func (x Migration_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Migration_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_kessel_relations_v1beta1_migration_proto_enumTypes[0].Descriptor()
+}
+
+func (Migration_State) Type() protoreflect.EnumType {
+	return &file_kessel_relations_v1beta1_migration_proto_enumTypes[0]
+}
+
+func (x Migration_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Migration_State.Descriptor instead.
+func (Migration_State) EnumDescriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{8, 0}
+}
+
+type MigrationSpec struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Operation:
+	//
+	//	*MigrationSpec_RenameRelation
+	//	*MigrationSpec_RetypeSubject
+	//	*MigrationSpec_RewriteTuples
+	Operation isMigrationSpec_Operation `protobuf_oneof:"operation"`
+	// The lock fencing the writes of the migration.
+	LockId string `protobuf:"bytes,4,opt,name=lock_id,json=lockId,proto3" json:"lock_id,omitempty"`
+	// Tuples read and rewritten per transaction. Defaults to 100.
+	BatchSize *uint32 `protobuf:"varint,5,opt,name=batch_size,json=batchSize,proto3,oneof" json:"batch_size,omitempty"`
+	// Resumes after the last batch of an earlier run of the same spec.
+	ContinuationToken *string `protobuf:"bytes,6,opt,name=continuation_token,json=continuationToken,proto3,oneof" json:"continuation_token,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *MigrationSpec) Reset() {
+	*x = MigrationSpec{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MigrationSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrationSpec) ProtoMessage() {}
+
+func (x *MigrationSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrationSpec.ProtoReflect.Descriptor instead.
+func (*MigrationSpec) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MigrationSpec) GetOperation() isMigrationSpec_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+func (x *MigrationSpec) GetRenameRelation() *RenameRelation {
+	if x != nil {
+		if x, ok := x.Operation.(*MigrationSpec_RenameRelation); ok {
+			return x.RenameRelation
+		}
+	}
+	return nil
+}
+
+func (x *MigrationSpec) GetRetypeSubject() *RetypeSubject {
+	if x != nil {
+		if x, ok := x.Operation.(*MigrationSpec_RetypeSubject); ok {
+			return x.RetypeSubject
+		}
+	}
+	return nil
+}
+
+func (x *MigrationSpec) GetRewriteTuples() *RewriteTuples {
+	if x != nil {
+		if x, ok := x.Operation.(*MigrationSpec_RewriteTuples); ok {
+			return x.RewriteTuples
+		}
+	}
+	return nil
+}
+
+func (x *MigrationSpec) GetLockId() string {
+	if x != nil {
+		return x.LockId
+	}
+	return ""
+}
+
+func (x *MigrationSpec) GetBatchSize() uint32 {
+	if x != nil && x.BatchSize != nil {
+		return *x.BatchSize
+	}
+	return 0
+}
+
+func (x *MigrationSpec) GetContinuationToken() string {
+	if x != nil && x.ContinuationToken != nil {
+		return *x.ContinuationToken
+	}
+	return ""
+}
+
+type isMigrationSpec_Operation interface {
+	isMigrationSpec_Operation()
+}
+
+type MigrationSpec_RenameRelation struct {
+	RenameRelation *RenameRelation `protobuf:"bytes,1,opt,name=rename_relation,json=renameRelation,proto3,oneof"`
+}
+
+type MigrationSpec_RetypeSubject struct {
+	RetypeSubject *RetypeSubject `protobuf:"bytes,2,opt,name=retype_subject,json=retypeSubject,proto3,oneof"`
+}
+
+type MigrationSpec_RewriteTuples struct {
+	RewriteTuples *RewriteTuples `protobuf:"bytes,3,opt,name=rewrite_tuples,json=rewriteTuples,proto3,oneof"`
+}
+
+func (*MigrationSpec_RenameRelation) isMigrationSpec_Operation() {}
+
+func (*MigrationSpec_RetypeSubject) isMigrationSpec_Operation() {}
+
+func (*MigrationSpec_RewriteTuples) isMigrationSpec_Operation() {}
+
+// Moves the tuples of a relation to another relation.
+type RenameRelation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResourceType  *ObjectType            `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameRelation) Reset() {
+	*x = RenameRelation{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameRelation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameRelation) ProtoMessage() {}
+
+func (x *RenameRelation) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameRelation.ProtoReflect.Descriptor instead.
+func (*RenameRelation) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RenameRelation) GetResourceType() *ObjectType {
+	if x != nil {
+		return x.ResourceType
+	}
+	return nil
+}
+
+func (x *RenameRelation) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *RenameRelation) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+// Moves the tuples of a relation with subjects of one type to subjects of another
+// type with the same ids.
+type RetypeSubject struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResourceType  *ObjectType            `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	Relation      string                 `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
+	From          *ObjectType            `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *ObjectType            `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetypeSubject) Reset() {
+	*x = RetypeSubject{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetypeSubject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetypeSubject) ProtoMessage() {}
+
+func (x *RetypeSubject) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetypeSubject.ProtoReflect.Descriptor instead.
+func (*RetypeSubject) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RetypeSubject) GetResourceType() *ObjectType {
+	if x != nil {
+		return x.ResourceType
+	}
+	return nil
+}
+
+func (x *RetypeSubject) GetRelation() string {
+	if x != nil {
+		return x.Relation
+	}
+	return ""
+}
+
+func (x *RetypeSubject) GetFrom() *ObjectType {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *RetypeSubject) GetTo() *ObjectType {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+// Rewrites the tuples matching a filter, replacing the fields that are set.
+type RewriteTuples struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Filter       *RelationTupleFilter   `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	ResourceType *ObjectType            `protobuf:"bytes,2,opt,name=resource_type,json=resourceType,proto3,oneof" json:"resource_type,omitempty"`
+	Relation     *string                `protobuf:"bytes,3,opt,name=relation,proto3,oneof" json:"relation,omitempty"`
+	SubjectType  *ObjectType            `protobuf:"bytes,4,opt,name=subject_type,json=subjectType,proto3,oneof" json:"subject_type,omitempty"`
+	// Set to the empty string to rewrite subject sets to subjects.
+	SubjectRelation *string `protobuf:"bytes,5,opt,name=subject_relation,json=subjectRelation,proto3,oneof" json:"subject_relation,omitempty"`
+	// Keep the matching tuples, copying rather than moving them.
+	KeepSource    bool `protobuf:"varint,6,opt,name=keep_source,json=keepSource,proto3" json:"keep_source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewriteTuples) Reset() {
+	*x = RewriteTuples{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewriteTuples) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewriteTuples) ProtoMessage() {}
+
+func (x *RewriteTuples) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewriteTuples.ProtoReflect.Descriptor instead.
+func (*RewriteTuples) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RewriteTuples) GetFilter() *RelationTupleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *RewriteTuples) GetResourceType() *ObjectType {
+	if x != nil {
+		return x.ResourceType
+	}
+	return nil
+}
+
+func (x *RewriteTuples) GetRelation() string {
+	if x != nil && x.Relation != nil {
+		return *x.Relation
+	}
+	return ""
+}
+
+func (x *RewriteTuples) GetSubjectType() *ObjectType {
+	if x != nil {
+		return x.SubjectType
+	}
+	return nil
+}
+
+func (x *RewriteTuples) GetSubjectRelation() string {
+	if x != nil && x.SubjectRelation != nil {
+		return *x.SubjectRelation
+	}
+	return ""
+}
+
+func (x *RewriteTuples) GetKeepSource() bool {
+	if x != nil {
+		return x.KeepSource
+	}
+	return false
+}
+
+type StartMigrationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Spec  *MigrationSpec         `protobuf:"bytes,1,opt,name=spec,proto3" json:"spec,omitempty"`
+	// Read the matching tuples and report the rewrites without writing anything.
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartMigrationRequest) Reset() {
+	*x = StartMigrationRequest{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartMigrationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartMigrationRequest) ProtoMessage() {}
+
+func (x *StartMigrationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartMigrationRequest.ProtoReflect.Descriptor instead.
+func (*StartMigrationRequest) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StartMigrationRequest) GetSpec() *MigrationSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *StartMigrationRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type StartMigrationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Migration     *Migration             `protobuf:"bytes,1,opt,name=migration,proto3" json:"migration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartMigrationResponse) Reset() {
+	*x = StartMigrationResponse{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartMigrationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartMigrationResponse) ProtoMessage() {}
+
+func (x *StartMigrationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartMigrationResponse.ProtoReflect.Descriptor instead.
+func (*StartMigrationResponse) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StartMigrationResponse) GetMigration() *Migration {
+	if x != nil {
+		return x.Migration
+	}
+	return nil
+}
+
+type GetMigrationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMigrationRequest) Reset() {
+	*x = GetMigrationRequest{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMigrationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMigrationRequest) ProtoMessage() {}
+
+func (x *GetMigrationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMigrationRequest.ProtoReflect.Descriptor instead.
+func (*GetMigrationRequest) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetMigrationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetMigrationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Migration     *Migration             `protobuf:"bytes,1,opt,name=migration,proto3" json:"migration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMigrationResponse) Reset() {
+	*x = GetMigrationResponse{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMigrationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMigrationResponse) ProtoMessage() {}
+
+func (x *GetMigrationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMigrationResponse.ProtoReflect.Descriptor instead.
+func (*GetMigrationResponse) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetMigrationResponse) GetMigration() *Migration {
+	if x != nil {
+		return x.Migration
+	}
+	return nil
+}
+
+type Migration struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Spec   *MigrationSpec         `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	DryRun bool                   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	State  Migration_State        `protobuf:"varint,4,opt,name=state,proto3,enum=kessel.relations.v1beta1.Migration_State" json:"state,omitempty"`
+	// Tuples matching the spec read so far.
+	TuplesRead uint64 `protobuf:"varint,5,opt,name=tuples_read,json=tuplesRead,proto3" json:"tuples_read,omitempty"`
+	// Tuples rewritten so far, or that would be rewritten if `dry_run` is set.
+	TuplesRewritten uint64 `protobuf:"varint,6,opt,name=tuples_rewritten,json=tuplesRewritten,proto3" json:"tuples_rewritten,omitempty"`
+	Batches         uint64 `protobuf:"varint,7,opt,name=batches,proto3" json:"batches,omitempty"`
+	// Resumes after the last completed batch when set in the spec of a new migration.
+	ContinuationToken string `protobuf:"bytes,8,opt,name=continuation_token,json=continuationToken,proto3" json:"continuation_token,omitempty"`
+	// Why the migration failed.
+	Error string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// The first rewrites of a dry run.
+	Preview       []*TupleRewrite `protobuf:"bytes,10,rep,name=preview,proto3" json:"preview,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Migration) Reset() {
+	*x = Migration{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Migration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Migration) ProtoMessage() {}
+
+func (x *Migration) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Migration.ProtoReflect.Descriptor instead.
+func (*Migration) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Migration) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Migration) GetSpec() *MigrationSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *Migration) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *Migration) GetState() Migration_State {
+	if x != nil {
+		return x.State
+	}
+	return Migration_STATE_UNSPECIFIED
+}
+
+func (x *Migration) GetTuplesRead() uint64 {
+	if x != nil {
+		return x.TuplesRead
+	}
+	return 0
+}
+
+func (x *Migration) GetTuplesRewritten() uint64 {
+	if x != nil {
+		return x.TuplesRewritten
+	}
+	return 0
+}
+
+func (x *Migration) GetBatches() uint64 {
+	if x != nil {
+		return x.Batches
+	}
+	return 0
+}
+
+func (x *Migration) GetContinuationToken() string {
+	if x != nil {
+		return x.ContinuationToken
+	}
+	return ""
+}
+
+func (x *Migration) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Migration) GetPreview() []*TupleRewrite {
+	if x != nil {
+		return x.Preview
+	}
+	return nil
+}
+
+type TupleRewrite struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *Relationship          `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *Relationship          `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TupleRewrite) Reset() {
+	*x = TupleRewrite{}
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TupleRewrite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TupleRewrite) ProtoMessage() {}
+
+func (x *TupleRewrite) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_migration_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TupleRewrite.ProtoReflect.Descriptor instead.
+func (*TupleRewrite) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_migration_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TupleRewrite) GetFrom() *Relationship {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *TupleRewrite) GetTo() *Relationship {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+var File_kessel_relations_v1beta1_migration_proto protoreflect.FileDescriptor
+
+const file_kessel_relations_v1beta1_migration_proto_rawDesc = "" +
+	"\n" +
+	"(kessel/relations/v1beta1/migration.proto\x12\x18kessel.relations.v1beta1\x1a\x1cgoogle/api/annotations.proto\x1a%kessel/relations/v1beta1/common.proto\x1a.kessel/relations/v1beta1/relation_tuples.proto\x1a\x1bbuf/validate/validate.proto\"\xc8\x03\n" +
+	"\rMigrationSpec\x12S\n" +
+	"\x0frename_relation\x18\x01 \x01(\v2(.kessel.relations.v1beta1.RenameRelationH\x00R\x0erenameRelation\x12P\n" +
+	"\x0eretype_subject\x18\x02 \x01(\v2'.kessel.relations.v1beta1.RetypeSubjectH\x00R\rretypeSubject\x12P\n" +
+	"\x0erewrite_tuples\x18\x03 \x01(\v2'.kessel.relations.v1beta1.RewriteTuplesH\x00R\rrewriteTuples\x12 \n" +
+	"\alock_id\x18\x04 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x06lockId\x12.\n" +
+	"\n" +
+	"batch_size\x18\x05 \x01(\rB\n" +
+	"\xbaH\a*\x05\x18\xf4\x03 \x00H\x01R\tbatchSize\x88\x01\x01\x122\n" +
+	"\x12continuation_token\x18\x06 \x01(\tH\x02R\x11continuationToken\x88\x01\x01B\x12\n" +
+	"\toperation\x12\x05\xbaH\x02\b\x01B\r\n" +
+	"\v_batch_sizeB\x15\n" +
+	"\x13_continuation_token\"\x99\x01\n" +
+	"\x0eRenameRelation\x12Q\n" +
+	"\rresource_type\x18\x01 \x01(\v2$.kessel.relations.v1beta1.ObjectTypeB\x06\xbaH\x03\xc8\x01\x01R\fresourceType\x12\x1b\n" +
+	"\x04from\x18\x02 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x04from\x12\x17\n" +
+	"\x02to\x18\x03 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x02to\"\x87\x02\n" +
+	"\rRetypeSubject\x12Q\n" +
+	"\rresource_type\x18\x01 \x01(\v2$.kessel.relations.v1beta1.ObjectTypeB\x06\xbaH\x03\xc8\x01\x01R\fresourceType\x12#\n" +
+	"\brelation\x18\x02 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\brelation\x12@\n" +
+	"\x04from\x18\x03 \x01(\v2$.kessel.relations.v1beta1.ObjectTypeB\x06\xbaH\x03\xc8\x01\x01R\x04from\x12<\n" +
+	"\x02to\x18\x04 \x01(\v2$.kessel.relations.v1beta1.ObjectTypeB\x06\xbaH\x03\xc8\x01\x01R\x02to\"\xb3\x03\n" +
+	"\rRewriteTuples\x12M\n" +
+	"\x06filter\x18\x01 \x01(\v2-.kessel.relations.v1beta1.RelationTupleFilterB\x06\xbaH\x03\xc8\x01\x01R\x06filter\x12N\n" +
+	"\rresource_type\x18\x02 \x01(\v2$.kessel.relations.v1beta1.ObjectTypeH\x00R\fresourceType\x88\x01\x01\x12\x1f\n" +
+	"\brelation\x18\x03 \x01(\tH\x01R\brelation\x88\x01\x01\x12L\n" +
+	"\fsubject_type\x18\x04 \x01(\v2$.kessel.relations.v1beta1.ObjectTypeH\x02R\vsubjectType\x88\x01\x01\x12.\n" +
+	"\x10subject_relation\x18\x05 \x01(\tH\x03R\x0fsubjectRelation\x88\x01\x01\x12\x1f\n" +
+	"\vkeep_source\x18\x06 \x01(\bR\n" +
+	"keepSourceB\x10\n" +
+	"\x0e_resource_typeB\v\n" +
+	"\t_relationB\x0f\n" +
+	"\r_subject_typeB\x13\n" +
+	"\x11_subject_relation\"u\n" +
+	"\x15StartMigrationRequest\x12C\n" +
+	"\x04spec\x18\x01 \x01(\v2'.kessel.relations.v1beta1.MigrationSpecB\x06\xbaH\x03\xc8\x01\x01R\x04spec\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"[\n" +
+	"\x16StartMigrationResponse\x12A\n" +
+	"\tmigration\x18\x01 \x01(\v2#.kessel.relations.v1beta1.MigrationR\tmigration\".\n" +
+	"\x13GetMigrationRequest\x12\x17\n" +
+	"\x02id\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x02id\"Y\n" +
+	"\x14GetMigrationResponse\x12A\n" +
+	"\tmigration\x18\x01 \x01(\v2#.kessel.relations.v1beta1.MigrationR\tmigration\"\xf9\x03\n" +
+	"\tMigration\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12;\n" +
+	"\x04spec\x18\x02 \x01(\v2'.kessel.relations.v1beta1.MigrationSpecR\x04spec\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12?\n" +
+	"\x05state\x18\x04 \x01(\x0e2).kessel.relations.v1beta1.Migration.StateR\x05state\x12\x1f\n" +
+	"\vtuples_read\x18\x05 \x01(\x04R\n" +
+	"tuplesRead\x12)\n" +
+	"\x10tuples_rewritten\x18\x06 \x01(\x04R\x0ftuplesRewritten\x12\x18\n" +
+	"\abatches\x18\a \x01(\x04R\abatches\x12-\n" +
+	"\x12continuation_token\x18\b \x01(\tR\x11continuationToken\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12@\n" +
+	"\apreview\x18\n" +
+	" \x03(\v2&.kessel.relations.v1beta1.TupleRewriteR\apreview\"X\n" +
+	"\x05State\x12\x15\n" +
+	"\x11STATE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rSTATE_RUNNING\x10\x01\x12\x13\n" +
+	"\x0fSTATE_SUCCEEDED\x10\x02\x12\x10\n" +
+	"\fSTATE_FAILED\x10\x03\"\x82\x01\n" +
+	"\fTupleRewrite\x12:\n" +
+	"\x04from\x18\x01 \x01(\v2&.kessel.relations.v1beta1.RelationshipR\x04from\x126\n" +
+	"\x02to\x18\x02 \x01(\v2&.kessel.relations.v1beta1.RelationshipR\x02to2\xc0\x02\n" +
+	"\x16KesselMigrationService\x12\x93\x01\n" +
+	"\x0eStartMigration\x12/.kessel.relations.v1beta1.StartMigrationRequest\x1a0.kessel.relations.v1beta1.StartMigrationResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1beta1/migrations\x12\x8f\x01\n" +
+	"\fGetMigration\x12-.kessel.relations.v1beta1.GetMigrationRequest\x1a..kessel.relations.v1beta1.GetMigrationResponse\" \x82\xd3\xe4\x93\x02\x1a\x12\x18/v1beta1/migrations/{id}Br\n" +
+	"(org.project_kessel.api.relations.v1beta1P\x01ZDgithub.com/project-kessel/relations-api/api/kessel/relations/v1beta1b\x06proto3"
+
+var (
+	file_kessel_relations_v1beta1_migration_proto_rawDescOnce sync.Once
+	file_kessel_relations_v1beta1_migration_proto_rawDescData []byte
+)
+
+func file_kessel_relations_v1beta1_migration_proto_rawDescGZIP() []byte {
+	file_kessel_relations_v1beta1_migration_proto_rawDescOnce.Do(func() {
+		file_kessel_relations_v1beta1_migration_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kessel_relations_v1beta1_migration_proto_rawDesc), len(file_kessel_relations_v1beta1_migration_proto_rawDesc)))
+	})
+	return file_kessel_relations_v1beta1_migration_proto_rawDescData
+}
+
+var file_kessel_relations_v1beta1_migration_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_kessel_relations_v1beta1_migration_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_kessel_relations_v1beta1_migration_proto_goTypes = []any{
+	(Migration_State)(0),           // 0: kessel.relations.v1beta1.Migration.State
+	(*MigrationSpec)(nil),          // 1: kessel.relations.v1beta1.MigrationSpec
+	(*RenameRelation)(nil),         // 2: kessel.relations.v1beta1.RenameRelation
+	(*RetypeSubject)(nil),          // 3: kessel.relations.v1beta1.RetypeSubject
+	(*RewriteTuples)(nil),          // 4: kessel.relations.v1beta1.RewriteTuples
+	(*StartMigrationRequest)(nil),  // 5: kessel.relations.v1beta1.StartMigrationRequest
+	(*StartMigrationResponse)(nil), // 6: kessel.relations.v1beta1.StartMigrationResponse
+	(*GetMigrationRequest)(nil),    // 7: kessel.relations.v1beta1.GetMigrationRequest
+	(*GetMigrationResponse)(nil),   // 8: kessel.relations.v1beta1.GetMigrationResponse
+	(*Migration)(nil),              // 9: kessel.relations.v1beta1.Migration
+	(*TupleRewrite)(nil),           // 10: kessel.relations.v1beta1.TupleRewrite
+	(*ObjectType)(nil),             // 11: kessel.relations.v1beta1.ObjectType
+	(*RelationTupleFilter)(nil),    // 12: kessel.relations.v1beta1.RelationTupleFilter
+	(*Relationship)(nil),           // 13: kessel.relations.v1beta1.Relationship
+}
+var file_kessel_relations_v1beta1_migration_proto_depIdxs = []int32{
+	2,  // 0: kessel.relations.v1beta1.MigrationSpec.rename_relation:type_name -> kessel.relations.v1beta1.RenameRelation
+	3,  // 1: kessel.relations.v1beta1.MigrationSpec.retype_subject:type_name -> kessel.relations.v1beta1.RetypeSubject
+	4,  // 2: kessel.relations.v1beta1.MigrationSpec.rewrite_tuples:type_name -> kessel.relations.v1beta1.RewriteTuples
+	11, // 3: kessel.relations.v1beta1.RenameRelation.resource_type:type_name -> kessel.relations.v1beta1.ObjectType
+	11, // 4: kessel.relations.v1beta1.RetypeSubject.resource_type:type_name -> kessel.relations.v1beta1.ObjectType
+	11, // 5: kessel.relations.v1beta1.RetypeSubject.from:type_name -> kessel.relations.v1beta1.ObjectType
+	11, // 6: kessel.relations.v1beta1.RetypeSubject.to:type_name -> kessel.relations.v1beta1.ObjectType
+	12, // 7: kessel.relations.v1beta1.RewriteTuples.filter:type_name -> kessel.relations.v1beta1.RelationTupleFilter
+	11, // 8: kessel.relations.v1beta1.RewriteTuples.resource_type:type_name -> kessel.relations.v1beta1.ObjectType
+	11, // 9: kessel.relations.v1beta1.RewriteTuples.subject_type:type_name -> kessel.relations.v1beta1.ObjectType
+	1,  // 10: kessel.relations.v1beta1.StartMigrationRequest.spec:type_name -> kessel.relations.v1beta1.MigrationSpec
+	9,  // 11: kessel.relations.v1beta1.StartMigrationResponse.migration:type_name -> kessel.relations.v1beta1.Migration
+	9,  // 12: kessel.relations.v1beta1.GetMigrationResponse.migration:type_name -> kessel.relations.v1beta1.Migration
+	1,  // 13: kessel.relations.v1beta1.Migration.spec:type_name -> kessel.relations.v1beta1.MigrationSpec
+	0,  // 14: kessel.relations.v1beta1.Migration.state:type_name -> kessel.relations.v1beta1.Migration.State
+	10, // 15: kessel.relations.v1beta1.Migration.preview:type_name -> kessel.relations.v1beta1.TupleRewrite
+	13, // 16: kessel.relations.v1beta1.TupleRewrite.from:type_name -> kessel.relations.v1beta1.Relationship
+	13, // 17: kessel.relations.v1beta1.TupleRewrite.to:type_name -> kessel.relations.v1beta1.Relationship
+	5,  // 18: kessel.relations.v1beta1.KesselMigrationService.StartMigration:input_type -> kessel.relations.v1beta1.StartMigrationRequest
+	7,  // 19: kessel.relations.v1beta1.KesselMigrationService.GetMigration:input_type -> kessel.relations.v1beta1.GetMigrationRequest
+	6,  // 20: kessel.relations.v1beta1.KesselMigrationService.StartMigration:output_type -> kessel.relations.v1beta1.StartMigrationResponse
+	8,  // 21: kessel.relations.v1beta1.KesselMigrationService.GetMigration:output_type -> kessel.relations.v1beta1.GetMigrationResponse
+	20, // [20:22] is the sub-list for method output_type
+	18, // [18:20] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_kessel_relations_v1beta1_migration_proto_init() }
+func file_kessel_relations_v1beta1_migration_proto_init() {
+	if File_kessel_relations_v1beta1_migration_proto != nil {
+		return
+	}
+	file_kessel_relations_v1beta1_common_proto_init()
+	file_kessel_relations_v1beta1_relation_tuples_proto_init()
+	file_kessel_relations_v1beta1_migration_proto_msgTypes[0].OneofWrappers = []any{
+		(*MigrationSpec_RenameRelation)(nil),
+		(*MigrationSpec_RetypeSubject)(nil),
+		(*MigrationSpec_RewriteTuples)(nil),
+	}
+	file_kessel_relations_v1beta1_migration_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kessel_relations_v1beta1_migration_proto_rawDesc), len(file_kessel_relations_v1beta1_migration_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kessel_relations_v1beta1_migration_proto_goTypes,
+		DependencyIndexes: file_kessel_relations_v1beta1_migration_proto_depIdxs,
+		EnumInfos:         file_kessel_relations_v1beta1_migration_proto_enumTypes,
+		MessageInfos:      file_kessel_relations_v1beta1_migration_proto_msgTypes,
+	}.Build()
+	File_kessel_relations_v1beta1_migration_proto = out.File
+	file_kessel_relations_v1beta1_migration_proto_goTypes = nil
+	file_kessel_relations_v1beta1_migration_proto_depIdxs = nil
+}
@@ -0,0 +1,180 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             (unknown)
+// source: kessel/relations/v1beta1/migration.proto
+
+package v1beta1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	KesselMigrationService_StartMigration_FullMethodName = "/kessel.relations.v1beta1.KesselMigrationService/StartMigration"
+	KesselMigrationService_GetMigration_FullMethodName   = "/kessel.relations.v1beta1.KesselMigrationService/GetMigration"
+)
+
+// KesselMigrationServiceClient is the client API for KesselMigrationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// KesselMigrationService rewrites persisted tuples as the schema evolves.
+//
+// A migration reads the tuples matching its spec in batches and rewrites each batch
+// in a single transaction, fenced by a lock acquired when the migration starts.
+// Acquiring the same lock elsewhere stops the migration. A stopped migration is
+// resumed by starting it again with the continuation token from its progress.
+type KesselMigrationServiceClient interface {
+	// Starts a migration in the background, or previews it if `dry_run` is set.
+	StartMigration(ctx context.Context, in *StartMigrationRequest, opts ...grpc.CallOption) (*StartMigrationResponse, error)
+	// Reports the progress of a migration started by this instance of the service, until an hour after it
+	// finishes. Callers only see migrations in namespaces they are granted.
+	GetMigration(ctx context.Context, in *GetMigrationRequest, opts ...grpc.CallOption) (*GetMigrationResponse, error)
+}
+
+type kesselMigrationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKesselMigrationServiceClient(cc grpc.ClientConnInterface) KesselMigrationServiceClient {
+	return &kesselMigrationServiceClient{cc}
+}
+
+func (c *kesselMigrationServiceClient) StartMigration(ctx context.Context, in *StartMigrationRequest, opts ...grpc.CallOption) (*StartMigrationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartMigrationResponse)
+	err := c.cc.Invoke(ctx, KesselMigrationService_StartMigration_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kesselMigrationServiceClient) GetMigration(ctx context.Context, in *GetMigrationRequest, opts ...grpc.CallOption) (*GetMigrationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMigrationResponse)
+	err := c.cc.Invoke(ctx, KesselMigrationService_GetMigration_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KesselMigrationServiceServer is the server API for KesselMigrationService service.
+// All implementations must embed UnimplementedKesselMigrationServiceServer
+// for forward compatibility.
+//
+// KesselMigrationService rewrites persisted tuples as the schema evolves.
+//
+// A migration reads the tuples matching its spec in batches and rewrites each batch
+// in a single transaction, fenced by a lock acquired when the migration starts.
+// Acquiring the same lock elsewhere stops the migration. A stopped migration is
+// resumed by starting it again with the continuation token from its progress.
+type KesselMigrationServiceServer interface {
+	// Starts a migration in the background, or previews it if `dry_run` is set.
+	StartMigration(context.Context, *StartMigrationRequest) (*StartMigrationResponse, error)
+	// Reports the progress of a migration started by this instance of the service, until an hour after it
+	// finishes. Callers only see migrations in namespaces they are granted.
+	GetMigration(context.Context, *GetMigrationRequest) (*GetMigrationResponse, error)
+	mustEmbedUnimplementedKesselMigrationServiceServer()
+}
+
+// UnimplementedKesselMigrationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKesselMigrationServiceServer struct{}
+
+func (UnimplementedKesselMigrationServiceServer) StartMigration(context.Context, *StartMigrationRequest) (*StartMigrationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartMigration not implemented")
+}
+func (UnimplementedKesselMigrationServiceServer) GetMigration(context.Context, *GetMigrationRequest) (*GetMigrationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMigration not implemented")
+}
+func (UnimplementedKesselMigrationServiceServer) mustEmbedUnimplementedKesselMigrationServiceServer() {
+}
+func (UnimplementedKesselMigrationServiceServer) testEmbeddedByValue() {}
+
+// UnsafeKesselMigrationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KesselMigrationServiceServer will
+// result in compilation errors.
+type UnsafeKesselMigrationServiceServer interface {
+	mustEmbedUnimplementedKesselMigrationServiceServer()
+}
+
+func RegisterKesselMigrationServiceServer(s grpc.ServiceRegistrar, srv KesselMigrationServiceServer) {
+	// If the following call panics, it indicates UnimplementedKesselMigrationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&KesselMigrationService_ServiceDesc, srv)
+}
+
+func _KesselMigrationService_StartMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KesselMigrationServiceServer).StartMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KesselMigrationService_StartMigration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KesselMigrationServiceServer).StartMigration(ctx, req.(*StartMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KesselMigrationService_GetMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KesselMigrationServiceServer).GetMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KesselMigrationService_GetMigration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KesselMigrationServiceServer).GetMigration(ctx, req.(*GetMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// KesselMigrationService_ServiceDesc is the grpc.ServiceDesc for KesselMigrationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KesselMigrationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kessel.relations.v1beta1.KesselMigrationService",
+	HandlerType: (*KesselMigrationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartMigration",
+			Handler:    _KesselMigrationService_StartMigration_Handler,
+		},
+		{
+			MethodName: "GetMigration",
+			Handler:    _KesselMigrationService_GetMigration_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kessel/relations/v1beta1/migration.proto",
+}
@@ -339,11 +339,19 @@ func (x *ReadTuplesResponse) GetConsistencyToken() *ConsistencyToken {
 }
 
 type DeleteTuplesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filter        *RelationTupleFilter   `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
-	FencingCheck  *FencingCheck          `protobuf:"bytes,2,opt,name=fencing_check,json=fencingCheck,proto3,oneof" json:"fencing_check,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Filter       *RelationTupleFilter   `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	FencingCheck *FencingCheck          `protobuf:"bytes,2,opt,name=fencing_check,json=fencingCheck,proto3,oneof" json:"fencing_check,omitempty"`
+	// Report how many tuples match the filter, with a sample, without deleting anything.
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Delete at most `limit` tuples. Tuples beyond the limit are left in place and
+	// `more_remaining` is set on the response; repeat the call to continue deleting.
+	// Limited deletions are not transactional across calls.
+	Limit *uint32 `protobuf:"varint,4,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
+	// Allow the request to delete more tuples than the server's configured maximum.
+	OverrideThreshold bool `protobuf:"varint,5,opt,name=override_threshold,json=overrideThreshold,proto3" json:"override_threshold,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *DeleteTuplesRequest) Reset() {
@@ -390,11 +398,39 @@ func (x *DeleteTuplesRequest) GetFencingCheck() *FencingCheck {
 	return nil
 }
 
+func (x *DeleteTuplesRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *DeleteTuplesRequest) GetLimit() uint32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+func (x *DeleteTuplesRequest) GetOverrideThreshold() bool {
+	if x != nil {
+		return x.OverrideThreshold
+	}
+	return false
+}
+
 type DeleteTuplesResponse struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	ConsistencyToken *ConsistencyToken      `protobuf:"bytes,2,opt,name=consistency_token,json=consistencyToken,proto3" json:"consistency_token,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// The number of tuples deleted, or that would be deleted if `dry_run` is set.
+	DeletedCount uint64 `protobuf:"varint,3,opt,name=deleted_count,json=deletedCount,proto3" json:"deleted_count,omitempty"`
+	// Whether tuples matching the filter remain because `limit` was reached. A dry run counts no more than the
+	// server's configured maximum and sets it when more match.
+	MoreRemaining bool `protobuf:"varint,4,opt,name=more_remaining,json=moreRemaining,proto3" json:"more_remaining,omitempty"`
+	// Up to a server-defined number of the tuples that would be deleted. Only set if `dry_run` is set.
+	Sample        []*Relationship `protobuf:"bytes,5,rep,name=sample,proto3" json:"sample,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteTuplesResponse) Reset() {
@@ -434,6 +470,27 @@ func (x *DeleteTuplesResponse) GetConsistencyToken() *ConsistencyToken {
 	return nil
 }
 
+func (x *DeleteTuplesResponse) GetDeletedCount() uint64 {
+	if x != nil {
+		return x.DeletedCount
+	}
+	return 0
+}
+
+func (x *DeleteTuplesResponse) GetMoreRemaining() bool {
+	if x != nil {
+		return x.MoreRemaining
+	}
+	return false
+}
+
+func (x *DeleteTuplesResponse) GetSample() []*Relationship {
+	if x != nil {
+		return x.Sample
+	}
+	return nil
+}
+
 type AcquireLockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	LockId        string                 `protobuf:"bytes,1,opt,name=lock_id,json=lockId,proto3" json:"lock_id,omitempty"`
@@ -750,13 +807,20 @@ const file_kessel_relations_v1beta1_relation_tuples_proto_rawDesc = "" +
 	"\n" +
 	"pagination\x18\x02 \x01(\v2,.kessel.relations.v1beta1.ResponsePaginationR\n" +
 	"pagination\x12W\n" +
-	"\x11consistency_token\x18\x03 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\"\xc8\x01\n" +
+	"\x11consistency_token\x18\x03 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\"\xbe\x02\n" +
 	"\x13DeleteTuplesRequest\x12M\n" +
 	"\x06filter\x18\x01 \x01(\v2-.kessel.relations.v1beta1.RelationTupleFilterB\x06\xbaH\x03\xc8\x01\x01R\x06filter\x12P\n" +
-	"\rfencing_check\x18\x02 \x01(\v2&.kessel.relations.v1beta1.FencingCheckH\x00R\ffencingCheck\x88\x01\x01B\x10\n" +
-	"\x0e_fencing_check\"o\n" +
+	"\rfencing_check\x18\x02 \x01(\v2&.kessel.relations.v1beta1.FencingCheckH\x00R\ffencingCheck\x88\x01\x01\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12\"\n" +
+	"\x05limit\x18\x04 \x01(\rB\a\xbaH\x04*\x02 \x00H\x01R\x05limit\x88\x01\x01\x12-\n" +
+	"\x12override_threshold\x18\x05 \x01(\bR\x11overrideThresholdB\x10\n" +
+	"\x0e_fencing_checkB\b\n" +
+	"\x06_limit\"\xfb\x01\n" +
 	"\x14DeleteTuplesResponse\x12W\n" +
-	"\x11consistency_token\x18\x02 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\"5\n" +
+	"\x11consistency_token\x18\x02 \x01(\v2*.kessel.relations.v1beta1.ConsistencyTokenR\x10consistencyToken\x12#\n" +
+	"\rdeleted_count\x18\x03 \x01(\x04R\fdeletedCount\x12%\n" +
+	"\x0emore_remaining\x18\x04 \x01(\bR\rmoreRemaining\x12>\n" +
+	"\x06sample\x18\x05 \x03(\v2&.kessel.relations.v1beta1.RelationshipR\x06sample\"5\n" +
 	"\x12AcquireLockRequest\x12\x1f\n" +
 	"\alock_id\x18\x01 \x01(\tB\x06\xbaH\x03\xc8\x01\x01R\x06lockId\"4\n" +
 	"\x13AcquireLockResponse\x12\x1d\n" +
@@ -844,22 +908,23 @@ var file_kessel_relations_v1beta1_relation_tuples_proto_depIdxs = []int32{
 	11, // 10: kessel.relations.v1beta1.DeleteTuplesRequest.filter:type_name -> kessel.relations.v1beta1.RelationTupleFilter
 	10, // 11: kessel.relations.v1beta1.DeleteTuplesRequest.fencing_check:type_name -> kessel.relations.v1beta1.FencingCheck
 	14, // 12: kessel.relations.v1beta1.DeleteTuplesResponse.consistency_token:type_name -> kessel.relations.v1beta1.ConsistencyToken
-	12, // 13: kessel.relations.v1beta1.RelationTupleFilter.subject_filter:type_name -> kessel.relations.v1beta1.SubjectFilter
-	2,  // 14: kessel.relations.v1beta1.KesselTupleService.CreateTuples:input_type -> kessel.relations.v1beta1.CreateTuplesRequest
-	4,  // 15: kessel.relations.v1beta1.KesselTupleService.ReadTuples:input_type -> kessel.relations.v1beta1.ReadTuplesRequest
-	6,  // 16: kessel.relations.v1beta1.KesselTupleService.DeleteTuples:input_type -> kessel.relations.v1beta1.DeleteTuplesRequest
-	0,  // 17: kessel.relations.v1beta1.KesselTupleService.ImportBulkTuples:input_type -> kessel.relations.v1beta1.ImportBulkTuplesRequest
-	8,  // 18: kessel.relations.v1beta1.KesselTupleService.AcquireLock:input_type -> kessel.relations.v1beta1.AcquireLockRequest
-	3,  // 19: kessel.relations.v1beta1.KesselTupleService.CreateTuples:output_type -> kessel.relations.v1beta1.CreateTuplesResponse
-	5,  // 20: kessel.relations.v1beta1.KesselTupleService.ReadTuples:output_type -> kessel.relations.v1beta1.ReadTuplesResponse
-	7,  // 21: kessel.relations.v1beta1.KesselTupleService.DeleteTuples:output_type -> kessel.relations.v1beta1.DeleteTuplesResponse
-	1,  // 22: kessel.relations.v1beta1.KesselTupleService.ImportBulkTuples:output_type -> kessel.relations.v1beta1.ImportBulkTuplesResponse
-	9,  // 23: kessel.relations.v1beta1.KesselTupleService.AcquireLock:output_type -> kessel.relations.v1beta1.AcquireLockResponse
-	19, // [19:24] is the sub-list for method output_type
-	14, // [14:19] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	13, // 13: kessel.relations.v1beta1.DeleteTuplesResponse.sample:type_name -> kessel.relations.v1beta1.Relationship
+	12, // 14: kessel.relations.v1beta1.RelationTupleFilter.subject_filter:type_name -> kessel.relations.v1beta1.SubjectFilter
+	2,  // 15: kessel.relations.v1beta1.KesselTupleService.CreateTuples:input_type -> kessel.relations.v1beta1.CreateTuplesRequest
+	4,  // 16: kessel.relations.v1beta1.KesselTupleService.ReadTuples:input_type -> kessel.relations.v1beta1.ReadTuplesRequest
+	6,  // 17: kessel.relations.v1beta1.KesselTupleService.DeleteTuples:input_type -> kessel.relations.v1beta1.DeleteTuplesRequest
+	0,  // 18: kessel.relations.v1beta1.KesselTupleService.ImportBulkTuples:input_type -> kessel.relations.v1beta1.ImportBulkTuplesRequest
+	8,  // 19: kessel.relations.v1beta1.KesselTupleService.AcquireLock:input_type -> kessel.relations.v1beta1.AcquireLockRequest
+	3,  // 20: kessel.relations.v1beta1.KesselTupleService.CreateTuples:output_type -> kessel.relations.v1beta1.CreateTuplesResponse
+	5,  // 21: kessel.relations.v1beta1.KesselTupleService.ReadTuples:output_type -> kessel.relations.v1beta1.ReadTuplesResponse
+	7,  // 22: kessel.relations.v1beta1.KesselTupleService.DeleteTuples:output_type -> kessel.relations.v1beta1.DeleteTuplesResponse
+	1,  // 23: kessel.relations.v1beta1.KesselTupleService.ImportBulkTuples:output_type -> kessel.relations.v1beta1.ImportBulkTuplesResponse
+	9,  // 24: kessel.relations.v1beta1.KesselTupleService.AcquireLock:output_type -> kessel.relations.v1beta1.AcquireLockResponse
+	20, // [20:25] is the sub-list for method output_type
+	15, // [15:20] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_kessel_relations_v1beta1_relation_tuples_proto_init() }
@@ -0,0 +1,344 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: kessel/relations/v1beta1/schema.proto
+
+package v1beta1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DiffSchemaRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The schema to be deployed, in the SpiceDB schema language.
+	Schema string `protobuf:"bytes,1,opt,name=schema,proto3" json:"schema,omitempty"`
+	// The schema to diff against. Defaults to the schema currently written to the backend.
+	BaseSchema *string `protobuf:"bytes,2,opt,name=base_schema,json=baseSchema,proto3,oneof" json:"base_schema,omitempty"`
+	// Skips counting the tuples affected by breaking changes.
+	SkipTupleCounts bool `protobuf:"varint,3,opt,name=skip_tuple_counts,json=skipTupleCounts,proto3" json:"skip_tuple_counts,omitempty"`
+	// Counting stops at this many tuples per change. Defaults to 1000, at most 10000.
+	CountLimit    *uint32 `protobuf:"varint,4,opt,name=count_limit,json=countLimit,proto3,oneof" json:"count_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffSchemaRequest) Reset() {
+	*x = DiffSchemaRequest{}
+	mi := &file_kessel_relations_v1beta1_schema_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffSchemaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffSchemaRequest) ProtoMessage() {}
+
+func (x *DiffSchemaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_schema_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffSchemaRequest.ProtoReflect.Descriptor instead.
+func (*DiffSchemaRequest) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_schema_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DiffSchemaRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *DiffSchemaRequest) GetBaseSchema() string {
+	if x != nil && x.BaseSchema != nil {
+		return *x.BaseSchema
+	}
+	return ""
+}
+
+func (x *DiffSchemaRequest) GetSkipTupleCounts() bool {
+	if x != nil {
+		return x.SkipTupleCounts
+	}
+	return false
+}
+
+func (x *DiffSchemaRequest) GetCountLimit() uint32 {
+	if x != nil && x.CountLimit != nil {
+		return *x.CountLimit
+	}
+	return 0
+}
+
+type SchemaChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One of added_type, removed_type, added_relation, removed_relation, added_permission,
+	// removed_permission, widened_subject_types, narrowed_subject_types or permission_expression_changed.
+	Kind       string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Definition string `protobuf:"bytes,2,opt,name=definition,proto3" json:"definition,omitempty"`
+	// The relation or permission changed, empty for changes to a whole type.
+	Name     string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Detail   string `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	Breaking bool   `protobuf:"varint,5,opt,name=breaking,proto3" json:"breaking,omitempty"`
+	// The tuples orphaned by the change, counted for breaking changes only.
+	AffectedTuples *uint64 `protobuf:"varint,6,opt,name=affected_tuples,json=affectedTuples,proto3,oneof" json:"affected_tuples,omitempty"`
+	// Whether counting stopped at the count limit.
+	AffectedTuplesTruncated bool `protobuf:"varint,7,opt,name=affected_tuples_truncated,json=affectedTuplesTruncated,proto3" json:"affected_tuples_truncated,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *SchemaChange) Reset() {
+	*x = SchemaChange{}
+	mi := &file_kessel_relations_v1beta1_schema_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaChange) ProtoMessage() {}
+
+func (x *SchemaChange) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_schema_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaChange.ProtoReflect.Descriptor instead.
+func (*SchemaChange) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_schema_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SchemaChange) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *SchemaChange) GetDefinition() string {
+	if x != nil {
+		return x.Definition
+	}
+	return ""
+}
+
+func (x *SchemaChange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SchemaChange) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *SchemaChange) GetBreaking() bool {
+	if x != nil {
+		return x.Breaking
+	}
+	return false
+}
+
+func (x *SchemaChange) GetAffectedTuples() uint64 {
+	if x != nil && x.AffectedTuples != nil {
+		return *x.AffectedTuples
+	}
+	return 0
+}
+
+func (x *SchemaChange) GetAffectedTuplesTruncated() bool {
+	if x != nil {
+		return x.AffectedTuplesTruncated
+	}
+	return false
+}
+
+type DiffSchemaResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Changes  []*SchemaChange        `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Breaking bool                   `protobuf:"varint,2,opt,name=breaking,proto3" json:"breaking,omitempty"`
+	// Whether deploying the schema should be stopped: a breaking change orphans tuples, or
+	// tuples were not counted and there are breaking changes.
+	Blocked       bool `protobuf:"varint,3,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffSchemaResponse) Reset() {
+	*x = DiffSchemaResponse{}
+	mi := &file_kessel_relations_v1beta1_schema_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffSchemaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffSchemaResponse) ProtoMessage() {}
+
+func (x *DiffSchemaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_kessel_relations_v1beta1_schema_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffSchemaResponse.ProtoReflect.Descriptor instead.
+func (*DiffSchemaResponse) Descriptor() ([]byte, []int) {
+	return file_kessel_relations_v1beta1_schema_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DiffSchemaResponse) GetChanges() []*SchemaChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *DiffSchemaResponse) GetBreaking() bool {
+	if x != nil {
+		return x.Breaking
+	}
+	return false
+}
+
+func (x *DiffSchemaResponse) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+var File_kessel_relations_v1beta1_schema_proto protoreflect.FileDescriptor
+
+const file_kessel_relations_v1beta1_schema_proto_rawDesc = "" +
+	"\n" +
+	"%kessel/relations/v1beta1/schema.proto\x12\x18kessel.relations.v1beta1\x1a\x1cgoogle/api/annotations.proto\x1a\x1bbuf/validate/validate.proto\"\xcc\x01\n" +
+	"\x11DiffSchemaRequest\x12\x1f\n" +
+	"\x06schema\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x06schema\x12$\n" +
+	"\vbase_schema\x18\x02 \x01(\tH\x00R\n" +
+	"baseSchema\x88\x01\x01\x12*\n" +
+	"\x11skip_tuple_counts\x18\x03 \x01(\bR\x0fskipTupleCounts\x12$\n" +
+	"\vcount_limit\x18\x04 \x01(\rH\x01R\n" +
+	"countLimit\x88\x01\x01B\x0e\n" +
+	"\f_base_schemaB\x0e\n" +
+	"\f_count_limit\"\x88\x02\n" +
+	"\fSchemaChange\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1e\n" +
+	"\n" +
+	"definition\x18\x02 \x01(\tR\n" +
+	"definition\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\x12\x1a\n" +
+	"\bbreaking\x18\x05 \x01(\bR\bbreaking\x12,\n" +
+	"\x0faffected_tuples\x18\x06 \x01(\x04H\x00R\x0eaffectedTuples\x88\x01\x01\x12:\n" +
+	"\x19affected_tuples_truncated\x18\a \x01(\bR\x17affectedTuplesTruncatedB\x12\n" +
+	"\x10_affected_tuples\"\x8c\x01\n" +
+	"\x12DiffSchemaResponse\x12@\n" +
+	"\achanges\x18\x01 \x03(\v2&.kessel.relations.v1beta1.SchemaChangeR\achanges\x12\x1a\n" +
+	"\bbreaking\x18\x02 \x01(\bR\bbreaking\x12\x18\n" +
+	"\ablocked\x18\x03 \x01(\bR\ablocked2\xa0\x01\n" +
+	"\x13KesselSchemaService\x12\x88\x01\n" +
+	"\n" +
+	"DiffSchema\x12+.kessel.relations.v1beta1.DiffSchemaRequest\x1a,.kessel.relations.v1beta1.DiffSchemaResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1beta1/schema/diffBr\n" +
+	"(org.project_kessel.api.relations.v1beta1P\x01ZDgithub.com/project-kessel/relations-api/api/kessel/relations/v1beta1b\x06proto3"
+
+var (
+	file_kessel_relations_v1beta1_schema_proto_rawDescOnce sync.Once
+	file_kessel_relations_v1beta1_schema_proto_rawDescData []byte
+)
+
+func file_kessel_relations_v1beta1_schema_proto_rawDescGZIP() []byte {
+	file_kessel_relations_v1beta1_schema_proto_rawDescOnce.Do(func() {
+		file_kessel_relations_v1beta1_schema_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_kessel_relations_v1beta1_schema_proto_rawDesc), len(file_kessel_relations_v1beta1_schema_proto_rawDesc)))
+	})
+	return file_kessel_relations_v1beta1_schema_proto_rawDescData
+}
+
+var file_kessel_relations_v1beta1_schema_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_kessel_relations_v1beta1_schema_proto_goTypes = []any{
+	(*DiffSchemaRequest)(nil),  // 0: kessel.relations.v1beta1.DiffSchemaRequest
+	(*SchemaChange)(nil),       // 1: kessel.relations.v1beta1.SchemaChange
+	(*DiffSchemaResponse)(nil), // 2: kessel.relations.v1beta1.DiffSchemaResponse
+}
+var file_kessel_relations_v1beta1_schema_proto_depIdxs = []int32{
+	1, // 0: kessel.relations.v1beta1.DiffSchemaResponse.changes:type_name -> kessel.relations.v1beta1.SchemaChange
+	0, // 1: kessel.relations.v1beta1.KesselSchemaService.DiffSchema:input_type -> kessel.relations.v1beta1.DiffSchemaRequest
+	2, // 2: kessel.relations.v1beta1.KesselSchemaService.DiffSchema:output_type -> kessel.relations.v1beta1.DiffSchemaResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_kessel_relations_v1beta1_schema_proto_init() }
+func file_kessel_relations_v1beta1_schema_proto_init() {
+	if File_kessel_relations_v1beta1_schema_proto != nil {
+		return
+	}
+	file_kessel_relations_v1beta1_schema_proto_msgTypes[0].OneofWrappers = []any{}
+	file_kessel_relations_v1beta1_schema_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kessel_relations_v1beta1_schema_proto_rawDesc), len(file_kessel_relations_v1beta1_schema_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_kessel_relations_v1beta1_schema_proto_goTypes,
+		DependencyIndexes: file_kessel_relations_v1beta1_schema_proto_depIdxs,
+		MessageInfos:      file_kessel_relations_v1beta1_schema_proto_msgTypes,
+	}.Build()
+	File_kessel_relations_v1beta1_schema_proto = out.File
+	file_kessel_relations_v1beta1_schema_proto_goTypes = nil
+	file_kessel_relations_v1beta1_schema_proto_depIdxs = nil
+}
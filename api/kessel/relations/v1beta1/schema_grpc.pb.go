@@ -0,0 +1,125 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             (unknown)
+// source: kessel/relations/v1beta1/schema.proto
+
+package v1beta1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	KesselSchemaService_DiffSchema_FullMethodName = "/kessel.relations.v1beta1.KesselSchemaService/DiffSchema"
+)
+
+// KesselSchemaServiceClient is the client API for KesselSchemaService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KesselSchemaServiceClient interface {
+	// Diffs a schema against the schema currently in use, classifying the changes
+	// and counting the tuples each breaking change would orphan.
+	DiffSchema(ctx context.Context, in *DiffSchemaRequest, opts ...grpc.CallOption) (*DiffSchemaResponse, error)
+}
+
+type kesselSchemaServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKesselSchemaServiceClient(cc grpc.ClientConnInterface) KesselSchemaServiceClient {
+	return &kesselSchemaServiceClient{cc}
+}
+
+func (c *kesselSchemaServiceClient) DiffSchema(ctx context.Context, in *DiffSchemaRequest, opts ...grpc.CallOption) (*DiffSchemaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffSchemaResponse)
+	err := c.cc.Invoke(ctx, KesselSchemaService_DiffSchema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KesselSchemaServiceServer is the server API for KesselSchemaService service.
+// All implementations must embed UnimplementedKesselSchemaServiceServer
+// for forward compatibility.
+type KesselSchemaServiceServer interface {
+	// Diffs a schema against the schema currently in use, classifying the changes
+	// and counting the tuples each breaking change would orphan.
+	DiffSchema(context.Context, *DiffSchemaRequest) (*DiffSchemaResponse, error)
+	mustEmbedUnimplementedKesselSchemaServiceServer()
+}
+
+// UnimplementedKesselSchemaServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKesselSchemaServiceServer struct{}
+
+func (UnimplementedKesselSchemaServiceServer) DiffSchema(context.Context, *DiffSchemaRequest) (*DiffSchemaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DiffSchema not implemented")
+}
+func (UnimplementedKesselSchemaServiceServer) mustEmbedUnimplementedKesselSchemaServiceServer() {}
+func (UnimplementedKesselSchemaServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeKesselSchemaServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KesselSchemaServiceServer will
+// result in compilation errors.
+type UnsafeKesselSchemaServiceServer interface {
+	mustEmbedUnimplementedKesselSchemaServiceServer()
+}
+
+func RegisterKesselSchemaServiceServer(s grpc.ServiceRegistrar, srv KesselSchemaServiceServer) {
+	// If the following call panics, it indicates UnimplementedKesselSchemaServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&KesselSchemaService_ServiceDesc, srv)
+}
+
+func _KesselSchemaService_DiffSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KesselSchemaServiceServer).DiffSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KesselSchemaService_DiffSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KesselSchemaServiceServer).DiffSchema(ctx, req.(*DiffSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// KesselSchemaService_ServiceDesc is the grpc.ServiceDesc for KesselSchemaService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KesselSchemaService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kessel.relations.v1beta1.KesselSchemaService",
+	HandlerType: (*KesselSchemaServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DiffSchema",
+			Handler:    _KesselSchemaService_DiffSchema_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kessel/relations/v1beta1/schema.proto",
+}
@@ -0,0 +1,741 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+
+	"github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+// env is what a command runs with.
+type env struct {
+	conn *grpc.ClientConn
+	out  *printer
+	in   io.Reader
+}
+
+type command func(ctx context.Context, e *env, args []string) error
+
+var commands = map[string]command{
+	"check":            check,
+	"lookup-resources": lookupResources,
+	"lookup-subjects":  lookupSubjects,
+	"read":             read,
+	"write":            write,
+	"delete":           deleteTuples,
+	"import":           importTuples,
+	"export":           exportTuples,
+	"lock acquire":     acquireLock,
+	"schema diff":      diffSchema,
+	"migration start":  startMigration,
+	"migration get":    getMigration,
+}
+
+// defaultImportBatchSize is the number of tuples sent per import message.
+const defaultImportBatchSize = 1000
+
+// parseArgs parses the flags of a command, which precede its arguments, and checks the number of arguments.
+func parseArgs(fs *flag.FlagSet, args []string, want int, usage string) error {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kessel-admin %s\n", usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != want {
+		return fmt.Errorf("usage: kessel-admin %s", usage)
+	}
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+type consistencyFlags struct {
+	minimizeLatency bool
+	token           string
+}
+
+func (c *consistencyFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&c.minimizeLatency, "minimize-latency", false, "use the fastest snapshot available")
+	fs.StringVar(&c.token, "consistency-token", "", "use data at least as fresh as this token, returned by writes")
+}
+
+// consistency returns nil if neither flag is set, leaving the choice to the server.
+func (c *consistencyFlags) consistency() (*v1beta1.Consistency, error) {
+	switch {
+	case c.minimizeLatency && c.token != "":
+		return nil, errors.New("--minimize-latency and --consistency-token are mutually exclusive")
+	case c.minimizeLatency:
+		return &v1beta1.Consistency{Requirement: &v1beta1.Consistency_MinimizeLatency{MinimizeLatency: true}}, nil
+	case c.token != "":
+		return &v1beta1.Consistency{Requirement: &v1beta1.Consistency_AtLeastAsFresh{
+			AtLeastAsFresh: &v1beta1.ConsistencyToken{Token: c.token},
+		}}, nil
+	}
+	return nil, nil
+}
+
+type paginationFlags struct {
+	limit        uint
+	continuation string
+}
+
+func (p *paginationFlags) register(fs *flag.FlagSet) {
+	fs.UintVar(&p.limit, "limit", 0, "maximum number of results, 0 for all")
+	fs.StringVar(&p.continuation, "continuation-token", "", "continue after the last result of a previous call")
+}
+
+func (p *paginationFlags) pagination() (*v1beta1.RequestPagination, error) {
+	if p.limit == 0 && p.continuation == "" {
+		return nil, nil
+	}
+	limit, err := toUint32(p.limit, "--limit")
+	if err != nil {
+		return nil, err
+	}
+	pagination := &v1beta1.RequestPagination{Limit: limit}
+	if p.continuation != "" {
+		pagination.ContinuationToken = &p.continuation
+	}
+	return pagination, nil
+}
+
+type filterFlags struct {
+	resourceNamespace, resourceType, resourceID, relation     string
+	subjectNamespace, subjectType, subjectID, subjectRelation string
+}
+
+func (f *filterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.resourceNamespace, "resource-namespace", "", "match resources in this namespace")
+	fs.StringVar(&f.resourceType, "resource-type", "", "match resources of this type")
+	fs.StringVar(&f.resourceID, "resource-id", "", "match the resource with this id")
+	fs.StringVar(&f.relation, "relation", "", "match this relation")
+	fs.StringVar(&f.subjectNamespace, "subject-namespace", "", "match subjects in this namespace")
+	fs.StringVar(&f.subjectType, "subject-type", "", "match subjects of this type")
+	fs.StringVar(&f.subjectID, "subject-id", "", "match the subject with this id")
+	fs.StringVar(&f.subjectRelation, "subject-relation", "", "match subject sets with this relation")
+}
+
+func (f *filterFlags) filter() *v1beta1.RelationTupleFilter {
+	filter := &v1beta1.RelationTupleFilter{
+		ResourceNamespace: optional(f.resourceNamespace),
+		ResourceType:      optional(f.resourceType),
+		ResourceId:        optional(f.resourceID),
+		Relation:          optional(f.relation),
+	}
+	if f.subjectNamespace != "" || f.subjectType != "" || f.subjectID != "" || f.subjectRelation != "" {
+		filter.SubjectFilter = &v1beta1.SubjectFilter{
+			SubjectNamespace: optional(f.subjectNamespace),
+			SubjectType:      optional(f.subjectType),
+			SubjectId:        optional(f.subjectID),
+			Relation:         optional(f.subjectRelation),
+		}
+	}
+	return filter
+}
+
+func toUint32(v uint, flag string) (uint32, error) {
+	if v > math.MaxUint32 {
+		return 0, fmt.Errorf("%s must not exceed %d", flag, uint32(math.MaxUint32))
+	}
+	return uint32(v), nil
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type fencingFlags struct {
+	lockID, lockToken string
+}
+
+func (f *fencingFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.lockID, "lock-id", "", "fence the call with this lock, requires --lock-token")
+	fs.StringVar(&f.lockToken, "lock-token", "", "token returned by lock acquire")
+}
+
+func (f *fencingFlags) fencingCheck() (*v1beta1.FencingCheck, error) {
+	if (f.lockID == "") != (f.lockToken == "") {
+		return nil, errors.New("--lock-id and --lock-token must be given together")
+	}
+	if f.lockID == "" {
+		return nil, nil
+	}
+	return &v1beta1.FencingCheck{LockId: f.lockID, LockToken: f.lockToken}, nil
+}
+
+func check(ctx context.Context, e *env, args []string) error {
+	var cf consistencyFlags
+	fs := newFlagSet("check")
+	cf.register(fs)
+	if err := parseArgs(fs, args, 3, "check [flags] <namespace/type:id> <relation> <subject>"); err != nil {
+		return err
+	}
+	resource, err := parseObject(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	subject, err := parseSubject(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	consistency, err := cf.consistency()
+	if err != nil {
+		return err
+	}
+
+	resp, err := v1beta1.NewKesselCheckServiceClient(e.conn).Check(ctx, &v1beta1.CheckRequest{
+		Resource:    resource,
+		Relation:    fs.Arg(1),
+		Subject:     subject,
+		Consistency: consistency,
+	})
+	if err != nil {
+		return err
+	}
+	allowed := strings.TrimPrefix(resp.GetAllowed().String(), "ALLOWED_")
+	if err := e.out.row(resp, []string{"ALLOWED", "CONSISTENCY TOKEN"}, allowed, resp.GetConsistencyToken().GetToken()); err != nil {
+		return err
+	}
+	return e.out.flush()
+}
+
+func lookupResources(ctx context.Context, e *env, args []string) error {
+	var cf consistencyFlags
+	var pf paginationFlags
+	fs := newFlagSet("lookup-resources")
+	cf.register(fs)
+	pf.register(fs)
+	if err := parseArgs(fs, args, 3, "lookup-resources [flags] <namespace/type> <relation> <subject>"); err != nil {
+		return err
+	}
+	resourceType, err := parseType(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	subject, err := parseSubject(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	consistency, err := cf.consistency()
+	if err != nil {
+		return err
+	}
+	pagination, err := pf.pagination()
+	if err != nil {
+		return err
+	}
+
+	stream, err := v1beta1.NewKesselLookupServiceClient(e.conn).LookupResources(ctx, &v1beta1.LookupResourcesRequest{
+		ResourceType: resourceType,
+		Relation:     fs.Arg(1),
+		Subject:      subject,
+		Pagination:   pagination,
+		Consistency:  consistency,
+	})
+	if err != nil {
+		return err
+	}
+	var last *v1beta1.LookupResourcesResponse
+	if err := receive(stream, func(resp *v1beta1.LookupResourcesResponse) error {
+		last = resp
+		return e.out.row(resp, []string{"RESOURCE"}, formatObject(resp.GetResource()))
+	}); err != nil {
+		return err
+	}
+	noteTokens(e.out, last.GetPagination().GetContinuationToken(), last.GetConsistencyToken().GetToken())
+	return e.out.flush()
+}
+
+func lookupSubjects(ctx context.Context, e *env, args []string) error {
+	var cf consistencyFlags
+	var pf paginationFlags
+	var subjectRelation string
+	fs := newFlagSet("lookup-subjects")
+	cf.register(fs)
+	pf.register(fs)
+	fs.StringVar(&subjectRelation, "subject-relation", "", "look up subject sets with this relation")
+	if err := parseArgs(fs, args, 3, "lookup-subjects [flags] <namespace/type:id> <relation> <namespace/type>"); err != nil {
+		return err
+	}
+	resource, err := parseObject(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	subjectType, err := parseType(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	consistency, err := cf.consistency()
+	if err != nil {
+		return err
+	}
+	pagination, err := pf.pagination()
+	if err != nil {
+		return err
+	}
+
+	stream, err := v1beta1.NewKesselLookupServiceClient(e.conn).LookupSubjects(ctx, &v1beta1.LookupSubjectsRequest{
+		Resource:        resource,
+		Relation:        fs.Arg(1),
+		SubjectType:     subjectType,
+		SubjectRelation: optional(subjectRelation),
+		Pagination:      pagination,
+		Consistency:     consistency,
+	})
+	if err != nil {
+		return err
+	}
+	var last *v1beta1.LookupSubjectsResponse
+	if err := receive(stream, func(resp *v1beta1.LookupSubjectsResponse) error {
+		last = resp
+		return e.out.row(resp, []string{"SUBJECT"}, formatSubject(resp.GetSubject()))
+	}); err != nil {
+		return err
+	}
+	noteTokens(e.out, last.GetPagination().GetContinuationToken(), last.GetConsistencyToken().GetToken())
+	return e.out.flush()
+}
+
+func read(ctx context.Context, e *env, args []string) error {
+	var ff filterFlags
+	var cf consistencyFlags
+	var pf paginationFlags
+	fs := newFlagSet("read")
+	ff.register(fs)
+	cf.register(fs)
+	pf.register(fs)
+	if err := parseArgs(fs, args, 0, "read [flags]"); err != nil {
+		return err
+	}
+
+	var last *v1beta1.ReadTuplesResponse
+	if err := readTuples(ctx, e, ff, cf, pf, func(resp *v1beta1.ReadTuplesResponse) error {
+		last = resp
+		return e.out.row(resp, []string{"TUPLE"}, formatTuple(resp.GetTuple()))
+	}); err != nil {
+		return err
+	}
+	noteTokens(e.out, last.GetPagination().GetContinuationToken(), last.GetConsistencyToken().GetToken())
+	return e.out.flush()
+}
+
+func readTuples(ctx context.Context, e *env, ff filterFlags, cf consistencyFlags, pf paginationFlags, f func(*v1beta1.ReadTuplesResponse) error) error {
+	consistency, err := cf.consistency()
+	if err != nil {
+		return err
+	}
+	pagination, err := pf.pagination()
+	if err != nil {
+		return err
+	}
+	stream, err := v1beta1.NewKesselTupleServiceClient(e.conn).ReadTuples(ctx, &v1beta1.ReadTuplesRequest{
+		Filter:      ff.filter(),
+		Pagination:  pagination,
+		Consistency: consistency,
+	})
+	if err != nil {
+		return err
+	}
+	return receive(stream, f)
+}
+
+func write(ctx context.Context, e *env, args []string) error {
+	var fence fencingFlags
+	var upsert bool
+	fs := newFlagSet("write")
+	fence.register(fs)
+	fs.BoolVar(&upsert, "upsert", false, "succeed if a tuple already exists")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: kessel-admin write [flags] <tuple>...")
+	}
+	tuples := make([]*v1beta1.Relationship, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		tuple, err := parseTuple(arg)
+		if err != nil {
+			return err
+		}
+		tuples = append(tuples, tuple)
+	}
+	fencingCheck, err := fence.fencingCheck()
+	if err != nil {
+		return err
+	}
+
+	resp, err := v1beta1.NewKesselTupleServiceClient(e.conn).CreateTuples(ctx, &v1beta1.CreateTuplesRequest{
+		Upsert:       upsert,
+		Tuples:       tuples,
+		FencingCheck: fencingCheck,
+	})
+	if err != nil {
+		return err
+	}
+	if err := e.out.row(resp, []string{"WRITTEN", "CONSISTENCY TOKEN"}, strconv.Itoa(len(tuples)), resp.GetConsistencyToken().GetToken()); err != nil {
+		return err
+	}
+	return e.out.flush()
+}
+
+func deleteTuples(ctx context.Context, e *env, args []string) error {
+	var ff filterFlags
+	var fence fencingFlags
+	var dryRun, override bool
+	var limit uint
+	fs := newFlagSet("delete")
+	ff.register(fs)
+	fence.register(fs)
+	fs.BoolVar(&dryRun, "dry-run", false, "report the matching tuples without deleting them")
+	fs.UintVar(&limit, "limit", 0, "delete at most this many tuples, 0 for all")
+	fs.BoolVar(&override, "override-threshold", false, "allow deleting more tuples than the server's maximum")
+	if err := parseArgs(fs, args, 0, "delete [flags]"); err != nil {
+		return err
+	}
+	fencingCheck, err := fence.fencingCheck()
+	if err != nil {
+		return err
+	}
+	req := &v1beta1.DeleteTuplesRequest{
+		Filter:            ff.filter(),
+		FencingCheck:      fencingCheck,
+		DryRun:            dryRun,
+		OverrideThreshold: override,
+	}
+	if limit > 0 {
+		l, err := toUint32(limit, "--limit")
+		if err != nil {
+			return err
+		}
+		req.Limit = &l
+	}
+
+	resp, err := v1beta1.NewKesselTupleServiceClient(e.conn).DeleteTuples(ctx, req)
+	if err != nil {
+		return err
+	}
+	header := []string{"DELETED", "MORE REMAINING", "CONSISTENCY TOKEN"}
+	if dryRun {
+		header[0] = "MATCHING"
+	}
+	if err := e.out.row(resp, header,
+		strconv.FormatUint(resp.GetDeletedCount(), 10),
+		strconv.FormatBool(resp.GetMoreRemaining()),
+		resp.GetConsistencyToken().GetToken(),
+	); err != nil {
+		return err
+	}
+	for _, tuple := range resp.GetSample() {
+		e.out.note("sample: %s", formatTuple(tuple))
+	}
+	return e.out.flush()
+}
+
+func importTuples(ctx context.Context, e *env, args []string) error {
+	var batchSize int
+	fs := newFlagSet("import")
+	fs.IntVar(&batchSize, "batch-size", defaultImportBatchSize, "tuples sent per message")
+	if err := parseArgs(fs, args, 1, "import [flags] <file.jsonl|->"); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return errors.New("--batch-size must be positive")
+	}
+	in := e.in
+	if fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	stream, err := v1beta1.NewKesselTupleServiceClient(e.conn).ImportBulkTuples(ctx)
+	if err != nil {
+		return err
+	}
+	batch := make([]*v1beta1.Relationship, 0, batchSize)
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := stream.Send(&v1beta1.ImportBulkTuplesRequest{Tuples: batch})
+		batch = make([]*v1beta1.Relationship, 0, batchSize)
+		return err
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		tuple := &v1beta1.Relationship{}
+		if err := protojson.Unmarshal([]byte(text), tuple); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		batch = append(batch, tuple)
+		if len(batch) == batchSize {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := send(); err != nil {
+		return err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if err := e.out.row(resp, []string{"IMPORTED"}, strconv.FormatUint(resp.GetNumImported(), 10)); err != nil {
+		return err
+	}
+	return e.out.flush()
+}
+
+// exportTuples writes tuples as JSON lines regardless of the output format, so they can be imported again.
+func exportTuples(ctx context.Context, e *env, args []string) error {
+	var ff filterFlags
+	var cf consistencyFlags
+	fs := newFlagSet("export")
+	ff.register(fs)
+	cf.register(fs)
+	if err := parseArgs(fs, args, 0, "export [flags]"); err != nil {
+		return err
+	}
+	return readTuples(ctx, e, ff, cf, paginationFlags{}, func(resp *v1beta1.ReadTuplesResponse) error {
+		return e.out.jsonLine(resp.GetTuple())
+	})
+}
+
+func acquireLock(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet("lock acquire")
+	if err := parseArgs(fs, args, 1, "lock acquire <lock-id>"); err != nil {
+		return err
+	}
+	resp, err := v1beta1.NewKesselTupleServiceClient(e.conn).AcquireLock(ctx, &v1beta1.AcquireLockRequest{LockId: fs.Arg(0)})
+	if err != nil {
+		return err
+	}
+	if err := e.out.row(resp, []string{"LOCK ID", "LOCK TOKEN"}, fs.Arg(0), resp.GetLockToken()); err != nil {
+		return err
+	}
+	return e.out.flush()
+}
+
+// errBlocked reports that a diffed schema must not be deployed, after the changes were printed.
+var errBlocked = errors.New("schema diff is blocked by breaking changes")
+
+func diffSchema(ctx context.Context, e *env, args []string) error {
+	var baseFile string
+	var skipCounts bool
+	var countLimit uint
+	fs := newFlagSet("schema diff")
+	fs.StringVar(&baseFile, "base", "", "schema file to diff against instead of the deployed schema")
+	fs.BoolVar(&skipCounts, "skip-tuple-counts", false, "do not count the tuples affected by breaking changes, blocking on any breaking change")
+	fs.UintVar(&countLimit, "count-limit", 0, "stop counting the tuples affected by a change at this many, 0 for the server default")
+	if err := parseArgs(fs, args, 1, "schema diff [flags] <schema file>"); err != nil {
+		return err
+	}
+	b, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	req := &v1beta1.DiffSchemaRequest{Schema: string(b), SkipTupleCounts: skipCounts}
+	if baseFile != "" {
+		base, err := os.ReadFile(baseFile)
+		if err != nil {
+			return err
+		}
+		baseSchema := string(base)
+		req.BaseSchema = &baseSchema
+	}
+	if countLimit > 0 {
+		limit, err := toUint32(countLimit, "--count-limit")
+		if err != nil {
+			return err
+		}
+		req.CountLimit = &limit
+	}
+
+	resp, err := v1beta1.NewKesselSchemaServiceClient(e.conn).DiffSchema(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, c := range resp.GetChanges() {
+		target := c.GetDefinition()
+		if c.GetName() != "" {
+			target += "#" + c.GetName()
+		}
+		affected := "-"
+		if c.AffectedTuples != nil {
+			affected = strconv.FormatUint(c.GetAffectedTuples(), 10)
+			if c.GetAffectedTuplesTruncated() {
+				affected += "+"
+			}
+		}
+		if err := e.out.row(c, []string{"KIND", "TARGET", "BREAKING", "AFFECTED TUPLES", "DETAIL"},
+			c.GetKind(), target, strconv.FormatBool(c.GetBreaking()), affected, c.GetDetail(),
+		); err != nil {
+			return err
+		}
+	}
+	if len(resp.GetChanges()) == 0 {
+		e.out.note("no changes")
+	}
+	if err := e.out.flush(); err != nil {
+		return err
+	}
+	if resp.GetBlocked() {
+		return errBlocked
+	}
+	return nil
+}
+
+// migrationPollInterval is how often migration start --wait polls the progress of the migration.
+var migrationPollInterval = time.Second
+
+func startMigration(ctx context.Context, e *env, args []string) error {
+	var dryRun, wait bool
+	fs := newFlagSet("migration start")
+	fs.BoolVar(&dryRun, "dry-run", false, "preview the rewrites without writing anything")
+	fs.BoolVar(&wait, "wait", false, "wait for the migration to finish, within --timeout")
+	if err := parseArgs(fs, args, 1, "migration start [flags] <spec.yaml>"); err != nil {
+		return err
+	}
+	spec, err := readMigrationSpec(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client := v1beta1.NewKesselMigrationServiceClient(e.conn)
+	resp, err := client.StartMigration(ctx, &v1beta1.StartMigrationRequest{Spec: spec, DryRun: dryRun})
+	if err != nil {
+		return err
+	}
+	migration := resp.GetMigration()
+	for wait && migration.GetState() == v1beta1.Migration_STATE_RUNNING {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(migrationPollInterval):
+		}
+		got, err := client.GetMigration(ctx, &v1beta1.GetMigrationRequest{Id: migration.GetId()})
+		if err != nil {
+			return err
+		}
+		migration = got.GetMigration()
+	}
+	return printMigration(e, migration)
+}
+
+func getMigration(ctx context.Context, e *env, args []string) error {
+	fs := newFlagSet("migration get")
+	if err := parseArgs(fs, args, 1, "migration get <migration-id>"); err != nil {
+		return err
+	}
+	resp, err := v1beta1.NewKesselMigrationServiceClient(e.conn).GetMigration(ctx, &v1beta1.GetMigrationRequest{Id: fs.Arg(0)})
+	if err != nil {
+		return err
+	}
+	return printMigration(e, resp.GetMigration())
+}
+
+// readMigrationSpec reads a spec written in YAML, or JSON, with the field names of the API.
+func readMigrationSpec(path string) (*v1beta1.MigrationSpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	spec := &v1beta1.MigrationSpec{}
+	if err := protojson.Unmarshal(j, spec); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+func printMigration(e *env, m *v1beta1.Migration) error {
+	state := strings.TrimPrefix(m.GetState().String(), "STATE_")
+	if m.GetDryRun() {
+		state += " (dry run)"
+	}
+	if err := e.out.row(m, []string{"ID", "STATE", "READ", "REWRITTEN", "BATCHES"},
+		m.GetId(), state,
+		strconv.FormatUint(m.GetTuplesRead(), 10),
+		strconv.FormatUint(m.GetTuplesRewritten(), 10),
+		strconv.FormatUint(m.GetBatches(), 10),
+	); err != nil {
+		return err
+	}
+	if m.GetError() != "" {
+		e.out.note("error: %s", m.GetError())
+	}
+	if m.GetContinuationToken() != "" && m.GetState() != v1beta1.Migration_STATE_SUCCEEDED {
+		e.out.note("continuation token: %s", m.GetContinuationToken())
+	}
+	for _, r := range m.GetPreview() {
+		e.out.note("%s => %s", formatTuple(r.GetFrom()), formatTuple(r.GetTo()))
+	}
+	return e.out.flush()
+}
+
+// receive calls f with every message of stream until it ends.
+func receive[T any](stream grpc.ServerStreamingClient[T], f func(*T) error) error {
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func noteTokens(p *printer, continuation, consistency string) {
+	if continuation != "" {
+		p.note("continuation token: %s", continuation)
+	}
+	if consistency != "" {
+		p.note("consistency token: %s", consistency)
+	}
+}
@@ -0,0 +1,189 @@
+// Command kessel-admin manages tuples, checks and locks through the relations-api gRPC port, so operators work with
+// Kessel's namespace/type names instead of SpiceDB's encoding.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const usage = `Usage: kessel-admin [flags] <command> [command flags] [args]
+
+Commands:
+  check             check whether a subject has a relation to a resource
+  lookup-resources  list the resources a subject has a relation to
+  lookup-subjects   list the subjects with a relation to a resource
+  read              list the tuples matching a filter
+  write             create tuples
+  delete            delete the tuples matching a filter, or preview with --dry-run
+  import            create tuples from JSON lines
+  export            write the tuples matching a filter as JSON lines
+  lock acquire      acquire a lock, printing the token for fencing writes and deletes
+  schema diff       diff a schema file against the deployed schema, failing if breaking changes orphan tuples
+  migration start   start, or preview with --dry-run, a migration of tuples described by a spec file
+  migration get     report the progress of a migration
+
+Tuples are written as namespace/type:id#relation@namespace/type:id[#relation].
+Run kessel-admin <command> -h for the flags of a command.
+
+Flags:
+`
+
+// options are the flags shared by every command.
+type options struct {
+	addr      string
+	token     string
+	tokenFile string
+	useTLS    bool
+	caFile    string
+	certFile  string
+	keyFile   string
+	output    string
+	timeout   time.Duration
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if s, ok := status.FromError(err); ok {
+			err = fmt.Errorf("%s: %s", s.Code(), s.Message())
+		}
+		fmt.Fprintln(os.Stderr, "kessel-admin:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	var o options
+	fs := flag.NewFlagSet("kessel-admin", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&o.addr, "addr", envOr("KESSEL_ADMIN_ADDR", "localhost:9000"), "relations-api gRPC address, env KESSEL_ADMIN_ADDR")
+	fs.StringVar(&o.token, "token", os.Getenv("KESSEL_ADMIN_TOKEN"), "bearer token (JWT) sent with every call, env KESSEL_ADMIN_TOKEN")
+	fs.StringVar(&o.tokenFile, "token-file", "", "file containing the bearer token, used if --token is not set")
+	fs.BoolVar(&o.useTLS, "tls", false, "connect with TLS")
+	fs.StringVar(&o.caFile, "ca-file", "", "CA bundle the server certificate is verified against, defaults to the system roots")
+	fs.StringVar(&o.certFile, "cert-file", "", "client certificate for mutual TLS")
+	fs.StringVar(&o.keyFile, "key-file", "", "client key for mutual TLS")
+	fs.StringVar(&o.output, "output", "table", "output format: table or json")
+	fs.DurationVar(&o.timeout, "timeout", 30*time.Second, "deadline of the command, streaming commands (read, import, export) have none unless set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no command given")
+	}
+	if o.output != "table" && o.output != "json" {
+		return fmt.Errorf("unknown output format %q", o.output)
+	}
+
+	name, cmdArgs := fs.Arg(0), fs.Args()[1:]
+	if name == "lock" || name == "schema" || name == "migration" {
+		if len(cmdArgs) == 0 {
+			return fmt.Errorf("usage: kessel-admin %s <command>", name)
+		}
+		name, cmdArgs = name+" "+cmdArgs[0], cmdArgs[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	if streamingCommands[name] && !flagSet(fs, "timeout") {
+		cancel()
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+	ctx, err := withToken(ctx, o)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(o)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return cmd(ctx, &env{conn: conn, out: newPrinter(stdout, o.output), in: stdin}, cmdArgs)
+}
+
+// streamingCommands stream tuples for as long as there are tuples to read or write, which can take far longer than
+// the default deadline.
+var streamingCommands = map[string]bool{"read": true, "import": true, "export": true}
+
+// flagSet reports whether the flag name was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func withToken(ctx context.Context, o options) (context.Context, error) {
+	token := o.token
+	if token == "" && o.tokenFile != "" {
+		b, err := os.ReadFile(o.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading token file: %w", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	if token == "" {
+		return ctx, nil
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), nil
+}
+
+func dial(o options) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if o.useTLS {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if o.caFile != "" {
+			pem, err := os.ReadFile(o.caFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading CA file: %w", err)
+			}
+			config.RootCAs = x509.NewCertPool()
+			if !config.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", o.caFile)
+			}
+		}
+		if o.certFile != "" || o.keyFile != "" {
+			cert, err := tls.LoadX509KeyPair(o.certFile, o.keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error loading client certificate: %w", err)
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+		creds = credentials.NewTLS(config)
+	}
+	return grpc.NewClient(o.addr, grpc.WithTransportCredentials(creds))
+}
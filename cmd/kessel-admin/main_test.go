@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+// fakeServer records the requests of the commands under test.
+type fakeServer struct {
+	v1beta1.UnimplementedKesselCheckServiceServer
+	v1beta1.UnimplementedKesselTupleServiceServer
+	v1beta1.UnimplementedKesselSchemaServiceServer
+	v1beta1.UnimplementedKesselMigrationServiceServer
+	checks        []*v1beta1.CheckRequest
+	authorization []string
+	imported      [][]*v1beta1.Relationship
+	// deadlines records whether each call had a deadline
+	deadlines  []bool
+	diffs      []*v1beta1.DiffSchemaRequest
+	migrations []*v1beta1.StartMigrationRequest
+}
+
+func (f *fakeServer) Check(ctx context.Context, req *v1beta1.CheckRequest) (*v1beta1.CheckResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.authorization = md.Get("authorization")
+	f.checks = append(f.checks, req)
+	_, deadline := ctx.Deadline()
+	f.deadlines = append(f.deadlines, deadline)
+	return &v1beta1.CheckResponse{
+		Allowed:          v1beta1.CheckResponse_ALLOWED_TRUE,
+		ConsistencyToken: &v1beta1.ConsistencyToken{Token: "zed-token"},
+	}, nil
+}
+
+func (f *fakeServer) ImportBulkTuples(stream grpc.ClientStreamingServer[v1beta1.ImportBulkTuplesRequest, v1beta1.ImportBulkTuplesResponse]) error {
+	_, deadline := stream.Context().Deadline()
+	f.deadlines = append(f.deadlines, deadline)
+	var total uint64
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return stream.SendAndClose(&v1beta1.ImportBulkTuplesResponse{NumImported: total})
+		}
+		f.imported = append(f.imported, req.GetTuples())
+		total += uint64(len(req.GetTuples()))
+	}
+}
+
+func (f *fakeServer) DiffSchema(ctx context.Context, req *v1beta1.DiffSchemaRequest) (*v1beta1.DiffSchemaResponse, error) {
+	f.diffs = append(f.diffs, req)
+	affected := uint64(7)
+	return &v1beta1.DiffSchemaResponse{
+		Changes: []*v1beta1.SchemaChange{
+			{Kind: "removed_relation", Definition: "rbac/group", Name: "t_owner", Breaking: true, AffectedTuples: &affected},
+			{Kind: "added_type", Definition: "rbac/team"},
+		},
+		Breaking: true,
+		Blocked:  true,
+	}, nil
+}
+
+func (f *fakeServer) StartMigration(ctx context.Context, req *v1beta1.StartMigrationRequest) (*v1beta1.StartMigrationResponse, error) {
+	f.migrations = append(f.migrations, req)
+	return &v1beta1.StartMigrationResponse{Migration: &v1beta1.Migration{
+		Id:     "m1",
+		Spec:   req.GetSpec(),
+		DryRun: req.GetDryRun(),
+		State:  v1beta1.Migration_STATE_RUNNING,
+	}}, nil
+}
+
+func startFakeServer(t *testing.T) (*fakeServer, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	fake := &fakeServer{}
+	srv := grpc.NewServer()
+	v1beta1.RegisterKesselCheckServiceServer(srv, fake)
+	v1beta1.RegisterKesselTupleServiceServer(srv, fake)
+	v1beta1.RegisterKesselSchemaServiceServer(srv, fake)
+	v1beta1.RegisterKesselMigrationServiceServer(srv, fake)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return fake, lis.Addr().String()
+}
+
+func TestRun_Check(t *testing.T) {
+	t.Parallel()
+
+	fake, addr := startFakeServer(t)
+	var out bytes.Buffer
+	err := run([]string{"--addr", addr, "--token", "jwt", "check", "--consistency-token", "previous",
+		"rbac/workspace:ws1", "view", "rbac/principal:alice"}, nil, &out)
+	require.NoError(t, err)
+
+	require.Len(t, fake.checks, 1)
+	assert.Equal(t, "ws1", fake.checks[0].GetResource().GetId())
+	assert.Equal(t, "view", fake.checks[0].GetRelation())
+	assert.Equal(t, "previous", fake.checks[0].GetConsistency().GetAtLeastAsFresh().GetToken())
+	assert.Equal(t, []string{"Bearer jwt"}, fake.authorization)
+	assert.Equal(t, "ALLOWED  CONSISTENCY TOKEN\nTRUE     zed-token\n", out.String())
+}
+
+func TestRun_CheckAsJSON(t *testing.T) {
+	t.Parallel()
+
+	_, addr := startFakeServer(t)
+	var out bytes.Buffer
+	err := run([]string{"--addr", addr, "--output", "json", "check",
+		"rbac/workspace:ws1", "view", "rbac/principal:alice"}, nil, &out)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"allowed":"ALLOWED_TRUE","consistencyToken":{"token":"zed-token"}}`, out.String())
+}
+
+func TestRun_ImportSendsBatches(t *testing.T) {
+	t.Parallel()
+
+	fake, addr := startFakeServer(t)
+	tuple := `{"resource":{"type":{"namespace":"rbac","name":"group"},"id":"admins"},"relation":"member",` +
+		`"subject":{"subject":{"type":{"namespace":"rbac","name":"principal"},"id":"%s"}}}`
+	in := strings.Join([]string{
+		strings.Replace(tuple, "%s", "alice", 1),
+		"",
+		strings.Replace(tuple, "%s", "bob", 1),
+		strings.Replace(tuple, "%s", "carol", 1),
+	}, "\n")
+
+	var out bytes.Buffer
+	err := run([]string{"--addr", addr, "import", "--batch-size", "2", "-"}, strings.NewReader(in), &out)
+	require.NoError(t, err)
+
+	require.Len(t, fake.imported, 2)
+	assert.Len(t, fake.imported[0], 2)
+	assert.Equal(t, "carol", fake.imported[1][0].GetSubject().GetSubject().GetId())
+	assert.Equal(t, "IMPORTED\n3\n", out.String())
+}
+
+func TestRun_StreamingCommandsHaveNoDeadlineUnlessSet(t *testing.T) {
+	t.Parallel()
+
+	fake, addr := startFakeServer(t)
+	tuple := `{"resource":{"type":{"namespace":"rbac","name":"group"},"id":"admins"},"relation":"member",` +
+		`"subject":{"subject":{"type":{"namespace":"rbac","name":"principal"},"id":"alice"}}}`
+
+	require.NoError(t, run([]string{"--addr", addr, "import", "-"}, strings.NewReader(tuple), &bytes.Buffer{}))
+	require.NoError(t, run([]string{"--addr", addr, "--timeout", "1h", "import", "-"}, strings.NewReader(tuple), &bytes.Buffer{}))
+	require.NoError(t, run([]string{"--addr", addr, "check", "rbac/workspace:ws1", "view", "rbac/principal:alice"}, nil, &bytes.Buffer{}))
+
+	assert.Equal(t, []bool{false, true, true}, fake.deadlines)
+}
+
+func TestRun_SchemaDiffFailsWhenBlocked(t *testing.T) {
+	t.Parallel()
+	fake, addr := startFakeServer(t)
+	schemaFile := filepath.Join(t.TempDir(), "schema.zed")
+	require.NoError(t, os.WriteFile(schemaFile, []byte("definition rbac/principal {}"), 0o600))
+
+	var out bytes.Buffer
+	err := run([]string{"--addr", addr, "schema", "diff", "--count-limit", "10", schemaFile}, nil, &out)
+
+	assert.ErrorIs(t, err, errBlocked)
+	require.Len(t, fake.diffs, 1)
+	assert.Equal(t, "definition rbac/principal {}", fake.diffs[0].GetSchema())
+	assert.Nil(t, fake.diffs[0].BaseSchema)
+	assert.Equal(t, uint32(10), fake.diffs[0].GetCountLimit())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"removed_relation", "rbac/group#t_owner", "true", "7"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"added_type", "rbac/team", "false", "-"}, strings.Fields(lines[2]))
+}
+
+func TestRun_MigrationStartReadsYAMLSpec(t *testing.T) {
+	t.Parallel()
+	fake, addr := startFakeServer(t)
+	specFile := filepath.Join(t.TempDir(), "spec.yaml")
+	spec := `
+lock_id: rename-member
+batch_size: 50
+rename_relation:
+  resource_type: {namespace: rbac, name: group}
+  from: member
+  to: members
+`
+	require.NoError(t, os.WriteFile(specFile, []byte(spec), 0o600))
+
+	var out bytes.Buffer
+	err := run([]string{"--addr", addr, "migration", "start", "--dry-run", specFile}, nil, &out)
+
+	require.NoError(t, err)
+	require.Len(t, fake.migrations, 1)
+	assert.True(t, fake.migrations[0].GetDryRun())
+	got := fake.migrations[0].GetSpec()
+	assert.Equal(t, "rename-member", got.GetLockId())
+	assert.Equal(t, uint32(50), got.GetBatchSize())
+	assert.Equal(t, "group", got.GetRenameRelation().GetResourceType().GetName())
+	assert.Equal(t, "members", got.GetRenameRelation().GetTo())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"m1", "RUNNING", "(dry", "run)", "0", "0", "0"}, strings.Fields(lines[1]))
+}
+
+func TestRun_RejectsUnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	err := run([]string{"frobnicate"}, nil, &bytes.Buffer{})
+	assert.EqualError(t, err, `unknown command "frobnicate"`)
+}
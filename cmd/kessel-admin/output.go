@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// printer writes results either as an aligned table or as one JSON object per line.
+type printer struct {
+	w       io.Writer
+	json    bool
+	table   *tabwriter.Writer
+	printed bool
+	notes   []string
+}
+
+func newPrinter(w io.Writer, format string) *printer {
+	return &printer{w: w, json: format == "json", table: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)}
+}
+
+// row prints msg as a JSON line, or columns as a table row below header.
+func (p *printer) row(msg proto.Message, header []string, columns ...string) error {
+	if p.json {
+		return p.jsonLine(msg)
+	}
+	if !p.printed {
+		p.printed = true
+		if _, err := fmt.Fprintln(p.table, strings.Join(header, "\t")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(p.table, strings.Join(columns, "\t"))
+	return err
+}
+
+func (p *printer) jsonLine(msg proto.Message) error {
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(p.w, string(b))
+	return err
+}
+
+// note records a line printed below the table, such as a consistency or continuation token. JSON output carries
+// these in the messages themselves.
+func (p *printer) note(format string, args ...any) {
+	if !p.json {
+		p.notes = append(p.notes, fmt.Sprintf(format, args...))
+	}
+}
+
+// flush prints the table and notes.
+func (p *printer) flush() error {
+	if err := p.table.Flush(); err != nil {
+		return err
+	}
+	for _, n := range p.notes {
+		if _, err := fmt.Fprintln(p.w, n); err != nil {
+			return err
+		}
+	}
+	p.notes = nil
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+// Tuples are written as namespace/type:id#relation@namespace/type:id, optionally followed by #relation for a
+// subject set, e.g. rbac/group:admins#member@rbac/principal:alice.
+
+// parseType parses namespace/type.
+func parseType(s string) (*v1beta1.ObjectType, error) {
+	namespace, name, ok := strings.Cut(s, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid type %q, expected namespace/type", s)
+	}
+	return &v1beta1.ObjectType{Namespace: namespace, Name: name}, nil
+}
+
+// parseObject parses namespace/type:id.
+func parseObject(s string) (*v1beta1.ObjectReference, error) {
+	typ, id, ok := strings.Cut(s, ":")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("invalid object %q, expected namespace/type:id", s)
+	}
+	objectType, err := parseType(typ)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object %q: %w", s, err)
+	}
+	return &v1beta1.ObjectReference{Type: objectType, Id: id}, nil
+}
+
+// parseSubject parses namespace/type:id with an optional #relation.
+func parseSubject(s string) (*v1beta1.SubjectReference, error) {
+	object, relation, hasRelation := strings.Cut(s, "#")
+	subject, err := parseObject(object)
+	if err != nil {
+		return nil, err
+	}
+	ref := &v1beta1.SubjectReference{Subject: subject}
+	if hasRelation {
+		if relation == "" {
+			return nil, fmt.Errorf("invalid subject %q, empty relation", s)
+		}
+		ref.Relation = &relation
+	}
+	return ref, nil
+}
+
+// parseTuple parses namespace/type:id#relation@namespace/type:id[#relation].
+func parseTuple(s string) (*v1beta1.Relationship, error) {
+	resource, subject, ok := strings.Cut(s, "@")
+	if !ok {
+		return nil, fmt.Errorf("invalid tuple %q, expected resource#relation@subject", s)
+	}
+	object, relation, ok := strings.Cut(resource, "#")
+	if !ok || relation == "" {
+		return nil, fmt.Errorf("invalid tuple %q, missing relation", s)
+	}
+	resourceRef, err := parseObject(object)
+	if err != nil {
+		return nil, err
+	}
+	subjectRef, err := parseSubject(subject)
+	if err != nil {
+		return nil, err
+	}
+	return &v1beta1.Relationship{Resource: resourceRef, Relation: relation, Subject: subjectRef}, nil
+}
+
+func formatType(t *v1beta1.ObjectType) string {
+	return t.GetNamespace() + "/" + t.GetName()
+}
+
+func formatObject(o *v1beta1.ObjectReference) string {
+	return formatType(o.GetType()) + ":" + o.GetId()
+}
+
+func formatSubject(s *v1beta1.SubjectReference) string {
+	if s.Relation != nil {
+		return formatObject(s.GetSubject()) + "#" + s.GetRelation()
+	}
+	return formatObject(s.GetSubject())
+}
+
+func formatTuple(r *v1beta1.Relationship) string {
+	return formatObject(r.GetResource()) + "#" + r.GetRelation() + "@" + formatSubject(r.GetSubject())
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTuple_RoundTrips(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{
+		"rbac/group:admins#member@rbac/principal:alice",
+		"rbac/workspace:ws1#user_grant@rbac/role_binding:rb1",
+		"rbac/workspace:ws1#viewer@rbac/group:admins#member",
+	} {
+		tuple, err := parseTuple(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, s, formatTuple(tuple))
+	}
+}
+
+func TestParseTuple_ParsesSubjectSet(t *testing.T) {
+	t.Parallel()
+
+	tuple, err := parseTuple("rbac/workspace:ws1#viewer@rbac/group:admins#member")
+	require.NoError(t, err)
+
+	assert.Equal(t, "rbac", tuple.GetResource().GetType().GetNamespace())
+	assert.Equal(t, "workspace", tuple.GetResource().GetType().GetName())
+	assert.Equal(t, "ws1", tuple.GetResource().GetId())
+	assert.Equal(t, "viewer", tuple.GetRelation())
+	assert.Equal(t, "admins", tuple.GetSubject().GetSubject().GetId())
+	assert.Equal(t, "member", tuple.GetSubject().GetRelation())
+}
+
+func TestParseTuple_RejectsMalformedTuples(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{
+		"",
+		"rbac/group:admins#member",
+		"rbac/group:admins@rbac/principal:alice",
+		"group:admins#member@rbac/principal:alice",
+		"rbac/group#member@rbac/principal:alice",
+		"rbac/group:admins#member@rbac/principal",
+		"rbac/group:admins#member@rbac/principal:alice#",
+		"rbac/:admins#member@rbac/principal:alice",
+	} {
+		_, err := parseTuple(s)
+		assert.Error(t, err, s)
+	}
+}
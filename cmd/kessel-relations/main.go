@@ -1,20 +1,29 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/config/env"
 
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/biz"
 	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/data"
+	"github.com/project-kessel/relations-api/internal/filewatch"
+	"github.com/project-kessel/relations-api/internal/server"
+	"github.com/project-kessel/relations-api/internal/server/kafka"
 
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/config"
 	"github.com/go-kratos/kratos/v2/config/file"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 	"github.com/go-kratos/kratos/v2/transport/http"
 
@@ -37,28 +46,100 @@ func init() {
 	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
 }
 
-func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server) *kratos.App {
+// defaultPreflightTimeout bounds the startup checks of SpiceDB unless data.spiceDb.preflight.timeout is set.
+const defaultPreflightTimeout = 30 * time.Second
+
+func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, consumer *kafka.Consumer, relay *data.TupleEventRelay, reloader *server.ConfigReloader, sc *conf.Server, dc *conf.Data, backend *biz.IsBackendAvaliableUsecase, auditor *audit.Auditor) *kratos.App {
+	var watcher *filewatch.Watcher
+	servers := []transport.Server{gs, hs}
+	if consumer != nil {
+		servers = append(servers, consumer)
+	}
+	if relay != nil {
+		servers = append(servers, relay)
+	}
 	return kratos.New(
 		kratos.ID(id),
 		kratos.Name(Name),
 		kratos.Version(Version),
 		kratos.Metadata(map[string]string{}),
 		kratos.Logger(logger),
-		kratos.Server(
-			gs,
-			hs,
-		),
+		kratos.Server(servers...),
+		kratos.BeforeStart(func(ctx context.Context) (err error) {
+			// Service startup - SEC-MON-REQ-1 compliance (EOI-5 process_status)
+			auditor.Record(ctx, audit.Event{
+				Message:      "Service starting",
+				Action:       "STARTUP",
+				ResourceType: "service",
+				ResourceID:   Name,
+				Outcome:      audit.OutcomeSuccess,
+				Details: map[string]string{
+					"service_version": Version,
+					"auth_enabled":    strconv.FormatBool(sc.GetAuth().GetEnableAuth()),
+					"grpc_addr":       sc.GetGrpc().GetAddr(),
+					"http_addr":       sc.GetHttp().GetAddr(),
+				},
+			})
+			if err := preflight(ctx, dc.GetSpiceDb().GetPreflight(), backend, auditor); err != nil {
+				return err
+			}
+			watcher, err = watchConfig(flagconf, reloader, auditor, logger)
+			return err
+		}),
+		kratos.AfterStop(func(context.Context) error {
+			// the watcher is not started if preflight failed or the servers stopped before BeforeStart ran
+			if watcher == nil {
+				return nil
+			}
+			return watcher.Close()
+		}),
 	)
 }
 
-func main() {
-	flag.Parse()
+// preflight checks SpiceDB before the servers start, if enabled, so a bad token or schema stops the service from
+// starting rather than failing the first requests.
+func preflight(ctx context.Context, c *conf.Data_SpiceDb_Preflight, backend *biz.IsBackendAvaliableUsecase, auditor *audit.Auditor) error {
+	if !c.GetEnabled() {
+		return nil
+	}
+	timeout := c.GetTimeout().AsDuration()
+	if timeout <= 0 {
+		timeout = defaultPreflightTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, check := range backend.Preflight(ctx) {
+		// Startup preflight - SEC-MON-REQ-1 compliance (EOI-5 process_status, EOI-11 warnings_or_errors)
+		if check.Err != nil {
+			auditor.Record(ctx, audit.Event{
+				Message:      "Preflight check failed, refusing to start",
+				Action:       "STARTUP",
+				ResourceType: "preflight",
+				ResourceID:   check.Name,
+				Outcome:      audit.OutcomeFailure,
+				Reason:       check.Err.Error(),
+			})
+			return fmt.Errorf("preflight check %s failed: %w", check.Name, check.Err)
+		}
+		auditor.Record(ctx, audit.Event{
+			Message:      "Preflight check passed",
+			Action:       "STARTUP",
+			ResourceType: "preflight",
+			ResourceID:   check.Name,
+			Outcome:      audit.OutcomeSuccess,
+		})
+	}
+	return nil
+}
 
+// loadConfig reads the configuration from the environment and the files at path.
+func loadConfig(path string) (*conf.Bootstrap, error) {
 	c := config.New(
 		config.WithResolveActualTypes(true),
 		config.WithSource(
 			env.NewSource("SPICEDB_"),
-			file.NewSource(flagconf),
+			file.NewSource(path),
 		),
 	)
 	defer func() {
@@ -68,12 +149,43 @@ func main() {
 	}()
 
 	if err := c.Load(); err != nil {
-		panic(fmt.Errorf("error loading configuration: %w", err))
+		return nil, fmt.Errorf("error loading configuration: %w", err)
 	}
 
 	var bc conf.Bootstrap
 	if err := c.Scan(&bc); err != nil {
-		panic(fmt.Errorf("error reading bootstrap config from configuration: %w", err))
+		return nil, fmt.Errorf("error reading bootstrap config from configuration: %w", err)
+	}
+	return &bc, nil
+}
+
+// watchConfig reloads the server configuration when the files at path change. Changes of the data configuration
+// require a restart.
+func watchConfig(path string, reloader *server.ConfigReloader, auditor *audit.Auditor, logger log.Logger) (*filewatch.Watcher, error) {
+	return filewatch.New([]string{path}, func() {
+		bc, err := loadConfig(path)
+		if err != nil {
+			// Configuration reload - SEC-MON-REQ-1 compliance (EOI-5 process_status, EOI-11 warnings_or_errors)
+			auditor.Record(context.Background(), audit.Event{
+				Message:      "Configuration reload failed, previous configuration remains in use",
+				Action:       "RELOAD",
+				ResourceType: "config",
+				ResourceID:   path,
+				Outcome:      audit.OutcomeFailure,
+				Reason:       err.Error(),
+			})
+			return
+		}
+		_ = reloader.Reload(bc.Server) // failures are logged by the reloader
+	}, logger)
+}
+
+func main() {
+	flag.Parse()
+
+	bc, err := loadConfig(flagconf)
+	if err != nil {
+		panic(err)
 	}
 
 	//preshared, err := c.Value("PRESHARED").String()
@@ -84,34 +196,22 @@ func main() {
 	//	bc.Data.SpiceDb.Token = preshared
 	//}
 
-	logger := createLogger(bc.Server)
+	level := server.NewLogLevel(bc.Server)
+	logger := createLogger(level)
 
-	app, cleanup, err := wireApp(bc.Server, bc.Data, logger)
+	app, cleanup, err := wireApp(bc.Server, bc.Data, level, logger)
 	if err != nil {
 		panic(fmt.Errorf("error initializing application (via wire): %w", err))
 	}
 	defer cleanup()
 
-	// Service startup - SEC-MON-REQ-1 compliance (EOI-5 process_status)
-	log.NewHelper(logger).Infow(
-		"msg", "Service starting",
-		"action", "STARTUP",
-		"resource_type", "service",
-		"resource_id", Name,
-		"outcome", "success",
-		"service_version", Version,
-		"auth_enabled", bc.Server.Auth.EnableAuth,
-		"grpc_addr", bc.Server.Grpc.Addr,
-		"http_addr", bc.Server.Http.Addr,
-	)
-
 	// start and wait for stop signal
 	if err := app.Run(); err != nil {
 		panic(fmt.Errorf("fatal error during application execution: %w", err))
 	}
 }
 
-func createLogger(c *conf.Server) log.Logger {
+func createLogger(level *server.LogLevel) log.Logger {
 	logger := log.With(log.NewStdLogger(os.Stdout),
 		"ts", log.DefaultTimestamp,
 		"caller", log.DefaultCaller,
@@ -122,24 +222,6 @@ func createLogger(c *conf.Server) log.Logger {
 		"span.id", tracing.SpanID(),
 	)
 
-	var level log.Level
-	if c.MinLogLevel == nil {
-		level = log.LevelInfo
-	} else {
-		switch strings.ToUpper(*c.MinLogLevel) {
-		case "DEBUG":
-			level = log.LevelDebug
-		case "INFO":
-			level = log.LevelInfo
-		case "WARN":
-			level = log.LevelWarn
-		case "ERROR":
-			level = log.LevelError
-		case "FATAL":
-			level = log.LevelFatal
-		}
-	}
-	logger = log.NewFilter(logger, log.FilterLevel(level))
-
-	return logger
+	// the level follows server.minLogLevel when the configuration is reloaded
+	return log.NewFilter(logger, log.FilterLevel(log.LevelDebug), level.Filter())
 }
@@ -23,49 +23,163 @@ import (
 // Injectors from wire.go:
 
 // wireApp init kratos application.
-func wireApp(confServer *conf.Server, confData *conf.Data, logger log.Logger) (*kratos.App, func(), error) {
-	spiceDbRepository, cleanup, err := data.NewSpiceDbRepository(confData, logger)
+func wireApp(confServer *conf.Server, confData *conf.Data, logLevel *server.LogLevel, logger log.Logger) (*kratos.App, func(), error) {
+	meterProvider, cleanup, err := server.NewMeterProvider(confServer, logger)
 	if err != nil {
 		return nil, nil, err
 	}
+	meter, err := server.NewMeter(confServer, meterProvider)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	auditor, cleanup2, err := server.NewAuditor(confServer, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	spiceDbRepository, cleanup3, err := data.NewSpiceDbRepository(confData, meter, auditor, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
 	createRelationshipsUsecase := biz.NewCreateRelationshipsUsecase(spiceDbRepository, logger)
 	readRelationshipsUsecase := biz.NewReadRelationshipsUsecase(spiceDbRepository, logger)
 	deleteRelationshipsUsecase := biz.NewDeleteRelationshipsUsecase(spiceDbRepository, logger)
 	importBulkTuplesUsecase := biz.NewImportBulkTuplesUsecase(spiceDbRepository, logger)
 	acquireLockUsecase := biz.NewAcquireLockUsecase(spiceDbRepository, logger)
-	relationshipsService := service.NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, importBulkTuplesUsecase, acquireLockUsecase)
+	relationshipsService := service.NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, importBulkTuplesUsecase, acquireLockUsecase, auditor)
 	isBackendAvaliableUsecase := biz.NewIsBackendAvailableUsecase(spiceDbRepository)
-	healthService := service.NewHealthService(isBackendAvaliableUsecase)
+	verifier, cleanup4, err := server.NewTokenVerifier(confServer, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	healthProber, cleanup5 := server.NewHealthProber(confServer, isBackendAvaliableUsecase, verifier, auditor, logger)
+	healthService := service.NewHealthService(isBackendAvaliableUsecase, healthProber)
 	checkUsecase := biz.NewCheckUsecase(spiceDbRepository, logger)
 	checkForUpdateUsecase := biz.NewCheckForUpdateUsecase(spiceDbRepository, logger)
 	checkBulkUsecase := biz.NewCheckBulkUsecase(spiceDbRepository, logger)
 	checkForUpdateBulkUsecase := biz.NewCheckForUpdateBulkUsecase(spiceDbRepository, logger)
-	checkService := service.NewCheckService(logger, checkUsecase, checkForUpdateUsecase, checkBulkUsecase, checkForUpdateBulkUsecase)
+	metrics, err := service.NewMetrics(meter)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	checkService := service.NewCheckService(logger, checkUsecase, checkForUpdateUsecase, checkBulkUsecase, checkForUpdateBulkUsecase, auditor, metrics)
 	getSubjectsUsecase := biz.NewGetSubjectsUseCase(spiceDbRepository)
 	getResourcesUsecase := biz.NewGetResourcesUseCase(spiceDbRepository)
-	lookupService := service.NewLookupService(logger, getSubjectsUsecase, getResourcesUsecase)
-	meterProvider, err := server.NewMeterProvider(confServer)
+	lookupService := service.NewLookupService(logger, getSubjectsUsecase, getResourcesUsecase, auditor, metrics)
+	diffSchemaUsecase := biz.NewDiffSchemaUsecase(spiceDbRepository, logger)
+	schemaService := service.NewSchemaService(logger, diffSchemaUsecase)
+	migrationUsecase, cleanup6 := biz.NewMigrationUsecase(spiceDbRepository, auditor, logger)
+	migrationService := service.NewMigrationService(logger, migrationUsecase, auditor)
+	tracerProvider, cleanup7, err := server.NewTracerProvider(confServer, logger)
 	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	meter, err := server.NewMeter(confServer, meterProvider)
+	authorizer, err := server.NewAuthorizer(confServer, auditor)
+	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	limiter, err := server.NewRateLimiter(confServer, meter, auditor)
+	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	slowlogLogger := server.NewSlowRequestLogger(confServer, logger)
+	redactor, err := server.NewLogRedactor(confServer)
+	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	grpcServer, err := server.NewGRPCServer(confServer, relationshipsService, healthService, checkService, lookupService, schemaService, migrationService, meter, tracerProvider, verifier, authorizer, limiter, slowlogLogger, redactor, healthProber, auditor, logger)
+	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	httpServer, err := server.NewHTTPServer(confServer, relationshipsService, healthService, checkService, lookupService, schemaService, migrationService, meter, tracerProvider, verifier, authorizer, limiter, slowlogLogger, redactor, auditor, logger)
 	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	grpcServer, err := server.NewGRPCServer(confServer, relationshipsService, healthService, checkService, lookupService, meter, logger)
+	applyTupleChangesUsecase := biz.NewApplyTupleChangesUsecase(spiceDbRepository, logger)
+	consumer, err := server.NewTupleConsumer(confServer, applyTupleChangesUsecase, acquireLockUsecase, auditor, meter, logger)
 	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	httpServer, err := server.NewHTTPServer(confServer, relationshipsService, healthService, checkService, lookupService, meter, logger)
+	tupleEventRelay, cleanup8, err := data.NewTupleEventRelay(confData, spiceDbRepository, logger)
 	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	app := newApp(logger, grpcServer, httpServer)
+	configReloader := server.NewConfigReloader(confServer, logLevel, verifier, authorizer, auditor)
+	app := newApp(logger, grpcServer, httpServer, consumer, tupleEventRelay, configReloader, confServer, confData, isBackendAvaliableUsecase, auditor)
 	return app, func() {
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -0,0 +1,144 @@
+// Command kessel-schema tests SpiceDB schemas offline, without a SpiceDB instance.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/project-kessel/relations-api/internal/schema"
+)
+
+const usage = `Usage: kessel-schema <command> [flags] [args]
+
+Commands:
+  validate  run the assertions and lookups of fixture files against their schema
+  diff      list the changes between two schemas, failing on breaking ones
+
+Run kessel-schema <command> -h for the flags of a command.
+`
+
+// errFailed reports that the schema did not meet expectations, after the details were printed.
+var errFailed = errors.New("failed")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if !errors.Is(err, errFailed) {
+			fmt.Fprintln(os.Stderr, "kessel-schema:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errors.New("no command given")
+	}
+	switch args[0] {
+	case "validate":
+		return validate(args[1:], out)
+	case "diff":
+		return diff(args[1:], out)
+	case "-h", "-help", "--help":
+		fmt.Fprint(out, usage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func validate(args []string, out io.Writer) error {
+	var schemaFile string
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: kessel-schema validate [flags] <fixture.yaml>...")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&schemaFile, "schema", "", "schema to test instead of the schemaFile of each fixture")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no fixture given")
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		failures, err := validateFixture(path, schemaFile)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if len(failures) == 0 {
+			fmt.Fprintf(out, "PASS %s\n", path)
+			continue
+		}
+		failed = true
+		fmt.Fprintf(out, "FAIL %s\n", path)
+		for _, f := range failures {
+			fmt.Fprintf(out, "  %s\n", strings.ReplaceAll(f, "\n", "\n  "))
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+func validateFixture(path, schemaFile string) ([]string, error) {
+	f, err := schema.LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := f.LoadSchema(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	return f.Run(s)
+}
+
+func diff(args []string, out io.Writer) error {
+	var allowBreaking bool
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: kessel-schema diff [flags] <old schema> <new schema>")
+		fmt.Fprintln(fs.Output(), "Breaking changes are marked with !. Use kessel-admin schema diff to count the tuples they affect.")
+		fs.PrintDefaults()
+	}
+	fs.BoolVar(&allowBreaking, "allow-breaking", false, "exit successfully even if there are breaking changes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected two schema files")
+	}
+
+	var schemas [2]*schema.Schema
+	for i, path := range fs.Args() {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if schemas[i], err = schema.Parse(string(b)); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	changes := schema.Diff(schemas[0], schemas[1])
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "no changes")
+		return nil
+	}
+	fmt.Fprint(out, schema.FormatChanges(changes))
+	if schema.Breaking(changes) && !allowBreaking {
+		return errFailed
+	}
+	return nil
+}
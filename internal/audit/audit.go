@@ -0,0 +1,510 @@
+// Package audit records SEC-MON-REQ-1 audit entries and delivers them to the configured sinks, separately from the
+// application log.
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/outbox"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+
+	// RequestIDHeader is the request header entries are correlated by.
+	RequestIDHeader = "x-request-id"
+
+	redacted = "REDACTED"
+
+	defaultMaxTupleDetails = 1000
+)
+
+// Event is an audit entry: who did what to which resource, and with what outcome.
+type Event struct {
+	Time         time.Time `json:"time"`
+	Message      string    `json:"msg"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	Outcome      string    `json:"outcome"`
+	Principal    string    `json:"principal,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	// full name of the RPC the entry was recorded for
+	Operation string `json:"operation,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// tuples affected or checked, as "ns/type:id#relation ns/type:id[#relation]"
+	Tuples []string `json:"tuples,omitempty"`
+	// set when only some of the tuples affected are listed
+	TuplesTruncated bool `json:"tuples_truncated,omitempty"`
+	// consistency token of the write, correlating the entry with the tuple changes it made
+	ConsistencyToken string            `json:"consistency_token,omitempty"`
+	Details          map[string]string `json:"details,omitempty"`
+}
+
+// Tuple formats a relationship, or a check of a relation or permission, as
+// "ns/type:id#relation ns/type:id[#relation]".
+func Tuple(resource *v1beta1.ObjectReference, relation string, subject *v1beta1.SubjectReference) string {
+	tuple := fmt.Sprintf("%s#%s %s", object(resource), relation, object(subject.GetSubject()))
+	if subject.GetRelation() != "" {
+		tuple += "#" + subject.GetRelation()
+	}
+	return tuple
+}
+
+// Tuples formats up to max of the tuples, reporting whether some were left out.
+func Tuples(tuples []*v1beta1.Relationship, max int) ([]string, bool) {
+	formatted := make([]string, 0, min(len(tuples), max))
+	for _, t := range tuples[:min(len(tuples), max)] {
+		formatted = append(formatted, Tuple(t.GetResource(), t.GetRelation(), t.GetSubject()))
+	}
+	return formatted, len(tuples) > max
+}
+
+func object(o *v1beta1.ObjectReference) string {
+	return fmt.Sprintf("%s/%s:%s", o.GetType().GetNamespace(), o.GetType().GetName(), o.GetId())
+}
+
+// Sink delivers audit entries. A batch whose Write fails is written again in full, so sinks need not handle partial
+// failures.
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// Auditor records audit entries, redacts them and delivers them to every sink.
+type Auditor struct {
+	includeReads    bool
+	maxTupleDetails int
+	redactions      []redaction
+	// the application log is written to synchronously, the other sinks in the background
+	logSink    *logSink
+	deliveries []delivery
+	log        *log.Helper
+}
+
+// delivery buffers entries for a sink until it accepts them.
+type delivery interface {
+	enqueue(ctx context.Context, e Event) error
+	close() error
+}
+
+// NewLogAuditor returns an Auditor writing entries to the application log only.
+func NewLogAuditor(logger log.Logger) *Auditor {
+	return &Auditor{logSink: newLogSink(logger), log: log.NewHelper(logger)}
+}
+
+// New creates an Auditor delivering entries to the sinks configured in c, the application log if none are.
+func New(c *conf.Server_Audit, logger log.Logger) (*Auditor, func(), error) {
+	a := &Auditor{includeReads: c.GetIncludeReads(), log: log.NewHelper(logger)}
+	if c.GetTupleDetails() {
+		a.maxTupleDetails = defaultMaxTupleDetails
+		if c.GetMaxTupleDetails() > 0 {
+			a.maxTupleDetails = int(c.GetMaxTupleDetails())
+		}
+	}
+	var err error
+	if a.redactions, err = newRedactions(c); err != nil {
+		return nil, nil, err
+	}
+
+	sinks := c.GetSinks()
+	if len(sinks) == 0 {
+		sinks = []*conf.Server_Audit_Sink{{Type: "log"}}
+	}
+	for i, sc := range sinks {
+		if sc.GetType() == "" || sc.GetType() == "log" {
+			a.logSink = newLogSink(logger)
+			continue
+		}
+		sink, err := newSink(sc)
+		if err == nil {
+			var d delivery
+			d, err = newDelivery(c, i, sink, logger)
+			if err == nil {
+				a.deliveries = append(a.deliveries, d)
+				continue
+			}
+			_ = sink.Close()
+		}
+		a.Close()
+		return nil, nil, fmt.Errorf("audit sink %d: %w", i, err)
+	}
+	return a, a.Close, nil
+}
+
+func newDelivery(c *conf.Server_Audit, i int, sink Sink, logger log.Logger) (delivery, error) {
+	batchSize := int(c.GetBatchSize())
+	retryBackoff := c.GetRetryBackoff().AsDuration()
+	if c.GetBufferDir() == "" {
+		return newMemoryDelivery(sink, int(c.GetBufferSize()), batchSize, retryBackoff, logger), nil
+	}
+	dir := filepath.Join(c.GetBufferDir(), strconv.Itoa(i)+"-"+c.GetSinks()[i].GetType())
+	return newOutboxDelivery(dir, sink, outbox.Options{BatchSize: batchSize, RetryBackoff: retryBackoff}, logger)
+}
+
+// Close delivers the entries buffered in memory, as far as the sinks accept them, and closes the sinks.
+func (a *Auditor) Close() {
+	for _, d := range a.deliveries {
+		if err := d.close(); err != nil {
+			a.log.Errorf("error closing audit sink: %v", err)
+		}
+	}
+}
+
+// Record records the entry, filling in the time and the operation and request id of the request in ctx. It returns
+// once every sink has accepted or buffered the entry, waiting for space while a sink's memory buffer is full so no
+// entry is dropped.
+func (a *Auditor) Record(ctx context.Context, e Event) {
+	if a == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	operation, requestID := requestInfo(ctx)
+	if e.Operation == "" {
+		e.Operation = operation
+	}
+	if e.RequestID == "" {
+		e.RequestID = requestID
+	}
+	for _, r := range a.redactions {
+		r.apply(&e)
+	}
+
+	if a.logSink != nil {
+		_ = a.logSink.Write(ctx, []Event{e})
+	}
+	for _, d := range a.deliveries {
+		if err := d.enqueue(ctx, e); err != nil {
+			// kept in the application log rather than lost
+			b, _ := json.Marshal(e)
+			a.log.WithContext(ctx).Errorw("msg", "Audit entry not delivered", "error", err.Error(), "entry", string(b))
+		}
+	}
+}
+
+// MaxTupleDetails returns how many of the tuples a write changes its entry lists, zero if entries list none.
+func (a *Auditor) MaxTupleDetails() int {
+	if a == nil {
+		return 0
+	}
+	return a.maxTupleDetails
+}
+
+// RecordRead records an entry for a read-only call, if reads are audited.
+func (a *Auditor) RecordRead(ctx context.Context, e Event) {
+	if a == nil || !a.includeReads {
+		return
+	}
+	a.Record(ctx, e)
+}
+
+// requestInfo returns the operation and request id of the unary or streaming request in ctx.
+func requestInfo(ctx context.Context) (operation, requestID string) {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		operation = tr.Operation()
+		if h := tr.RequestHeader(); h != nil {
+			requestID = h.Get(RequestIDHeader)
+		}
+	}
+	if operation == "" {
+		operation, _ = grpc.Method(ctx)
+	}
+	if requestID == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ids := md.Get(RequestIDHeader); len(ids) > 0 {
+				requestID = ids[0]
+			}
+		}
+	}
+	return operation, requestID
+}
+
+type redaction struct {
+	field  string
+	action string
+	key    []byte // of the HMAC hashed values are replaced with
+}
+
+// newRedactions returns the redactions configured in c. Hashing requires a key, so the digests of guessable values
+// such as user ids cannot be reversed by hashing guesses.
+func newRedactions(c *conf.Server_Audit) ([]redaction, error) {
+	key, err := redactionHashKey(c)
+	if err != nil {
+		return nil, err
+	}
+	var redactions []redaction
+	for _, r := range c.GetRedactions() {
+		switch r.GetField() {
+		case "principal", "resource_id", "request_id", "reason", "tuples":
+		default:
+			return nil, fmt.Errorf("unknown audit redaction field %q", r.GetField())
+		}
+		switch r.GetAction() {
+		case "mask", "hash", "drop":
+		default:
+			return nil, fmt.Errorf("unknown audit redaction action %q, expected mask, hash or drop", r.GetAction())
+		}
+		if r.GetAction() == "hash" && len(key) == 0 {
+			return nil, errors.New("an audit redaction hash key is required to hash fields, set redactionHashKey or redactionHashKeyFile")
+		}
+		redactions = append(redactions, redaction{field: r.GetField(), action: r.GetAction(), key: key})
+	}
+	return redactions, nil
+}
+
+// redactionHashKey returns the configured hash key, redactionHashKey taking precedence over redactionHashKeyFile.
+func redactionHashKey(c *conf.Server_Audit) ([]byte, error) {
+	if c.GetRedactionHashKey() != "" {
+		return []byte(c.GetRedactionHashKey()), nil
+	}
+	if c.GetRedactionHashKeyFile() == "" {
+		return nil, nil
+	}
+	key, err := os.ReadFile(c.GetRedactionHashKeyFile())
+	if err != nil {
+		return nil, fmt.Errorf("error loading audit redaction hash key file: %w", err)
+	}
+	return []byte(strings.TrimSpace(string(key))), nil
+}
+
+func (r redaction) apply(e *Event) {
+	switch r.field {
+	case "principal":
+		e.Principal = r.value(e.Principal)
+	case "resource_id":
+		e.ResourceID = r.value(e.ResourceID)
+	case "request_id":
+		e.RequestID = r.value(e.RequestID)
+	case "reason":
+		e.Reason = r.value(e.Reason)
+	case "tuples":
+		if r.action == "drop" {
+			e.Tuples = nil
+			return
+		}
+		tuples := make([]string, len(e.Tuples))
+		for i, t := range e.Tuples {
+			tuples[i] = r.value(t)
+		}
+		e.Tuples = tuples
+	}
+}
+
+// value returns v redacted, leaving empty values empty.
+func (r redaction) value(v string) string {
+	if v == "" {
+		return ""
+	}
+	switch r.action {
+	case "mask":
+		return redacted
+	case "hash":
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(v))
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return ""
+}
+
+// memoryDelivery buffers entries in memory, delivering them in batches in the background and retrying failed
+// batches with backoff.
+type memoryDelivery struct {
+	sink         Sink
+	entries      chan Event
+	batchSize    int
+	retryBackoff time.Duration
+	log          *log.Helper
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+const (
+	defaultBufferSize = 10000
+	maxRetryBackoff   = time.Minute
+)
+
+func newMemoryDelivery(sink Sink, bufferSize, batchSize int, retryBackoff time.Duration, logger log.Logger) *memoryDelivery {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	if batchSize <= 0 {
+		batchSize = outbox.DefaultBatchSize
+	}
+	if retryBackoff <= 0 {
+		retryBackoff = outbox.DefaultRetryBackoff
+	}
+	d := &memoryDelivery{
+		sink:         sink,
+		entries:      make(chan Event, bufferSize),
+		batchSize:    batchSize,
+		retryBackoff: retryBackoff,
+		log:          log.NewHelper(logger),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+var errClosed = errors.New("audit sink closed")
+
+func (d *memoryDelivery) enqueue(ctx context.Context, e Event) error {
+	select {
+	case <-d.stop:
+		return errClosed
+	default:
+	}
+	select {
+	case d.entries <- e:
+		return nil
+	case <-d.stop:
+		return errClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *memoryDelivery) run() {
+	defer close(d.done)
+	for {
+		var batch []Event
+		select {
+		case e := <-d.entries:
+			batch = append(batch, e)
+		case <-d.stop:
+			d.drain()
+			return
+		}
+	fill:
+		for len(batch) < d.batchSize {
+			select {
+			case e := <-d.entries:
+				batch = append(batch, e)
+			default:
+				break fill
+			}
+		}
+		if !d.deliver(batch) {
+			d.drain()
+			return
+		}
+	}
+}
+
+// deliver writes the batch, retrying until it is accepted. It returns false if stopped before then, after a last
+// attempt.
+func (d *memoryDelivery) deliver(batch []Event) bool {
+	backoff := d.retryBackoff
+	for {
+		err := d.sink.Write(context.Background(), batch)
+		if err == nil {
+			return true
+		}
+		select {
+		case <-d.stop:
+			d.log.Errorf("error delivering %d audit entries while closing, they are lost: %v", len(batch), err)
+			return false
+		default:
+		}
+		d.log.Warnf("error delivering audit entries, retrying in %s: %v", backoff, err)
+		select {
+		case <-d.stop:
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// drain makes one attempt at delivering the entries still buffered.
+func (d *memoryDelivery) drain() {
+	var batch []Event
+	for {
+		select {
+		case e := <-d.entries:
+			batch = append(batch, e)
+			continue
+		default:
+		}
+		break
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := d.sink.Write(context.Background(), batch); err != nil {
+		d.log.Errorf("error delivering %d audit entries while closing, they are lost: %v", len(batch), err)
+	}
+}
+
+func (d *memoryDelivery) close() error {
+	close(d.stop)
+	<-d.done
+	return d.sink.Close()
+}
+
+// outboxDelivery keeps entries in an outbox on disk until the sink accepts them.
+type outboxDelivery struct {
+	outbox *outbox.Outbox
+	sink   Sink
+	log    *log.Helper
+}
+
+func newOutboxDelivery(dir string, sink Sink, opts outbox.Options, logger log.Logger) (*outboxDelivery, error) {
+	d := &outboxDelivery{sink: sink, log: log.NewHelper(logger)}
+	opts.Name = "audit entries"
+	var err error
+	if d.outbox, err = outbox.Open(dir, d.publish, opts, logger); err != nil {
+		return nil, err
+	}
+	d.outbox.Start()
+	return d, nil
+}
+
+func (d *outboxDelivery) enqueue(_ context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return d.outbox.Append(b)
+}
+
+func (d *outboxDelivery) publish(ctx context.Context, entries [][]byte) error {
+	events := make([]Event, 0, len(entries))
+	for _, entry := range entries {
+		var e Event
+		if err := json.Unmarshal(entry, &e); err != nil {
+			d.log.Errorf("skipping unreadable audit buffer entry: %v", err)
+			continue
+		}
+		events = append(events, e)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return d.sink.Write(ctx, events)
+}
+
+func (d *outboxDelivery) close() error {
+	return errors.Join(d.outbox.Close(), d.sink.Close())
+}
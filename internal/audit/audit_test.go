@@ -0,0 +1,299 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const createTuples = "/kessel.relations.v1beta1.KesselTupleService/CreateTuples"
+
+// captureLogger records the keyvals of every log entry.
+type captureLogger struct {
+	mu      sync.Mutex
+	entries []map[string]any
+}
+
+func (l *captureLogger) Log(level log.Level, keyvals ...any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := map[string]any{"level": level}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		entry[keyvals[i].(string)] = keyvals[i+1]
+	}
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+type testTransport struct {
+	header transport.Header
+}
+
+func (t *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (t *testTransport) Endpoint() string                { return "" }
+func (t *testTransport) Operation() string               { return createTuples }
+func (t *testTransport) RequestHeader() transport.Header { return t.header }
+func (t *testTransport) ReplyHeader() transport.Header   { return nil }
+
+type header map[string]string
+
+func (h header) Get(key string) string      { return h[key] }
+func (h header) Set(key, value string)      { h[key] = value }
+func (h header) Add(key, value string)      { h[key] = value }
+func (h header) Keys() []string             { return nil }
+func (h header) Values(key string) []string { return []string{h[key]} }
+
+func requestContext() context.Context {
+	return transport.NewServerContext(context.Background(), &testTransport{header: header{RequestIDHeader: "req-1"}})
+}
+
+func tupleCreated() Event {
+	return Event{
+		Message:      "Tuples created",
+		Action:       "CREATE",
+		ResourceType: "relationship_tuple",
+		ResourceID:   "count:1",
+		Outcome:      OutcomeSuccess,
+		Principal:    "alice",
+		Tuples:       []string{"rbac/group:g1#member rbac/principal:bob"},
+	}
+}
+
+// webhook is an audit webhook failing while failures remain.
+type webhook struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failures int
+	received []Event
+}
+
+func newWebhook(t *testing.T, failures int) *webhook {
+	w := &webhook{failures: failures}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.failures > 0 {
+			w.failures--
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var events []Event
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.received = append(w.received, events...)
+	}))
+	t.Cleanup(w.Close)
+	return w
+}
+
+func (w *webhook) events() []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Event(nil), w.received...)
+}
+
+func TestAuditor_WritesToTheApplicationLogByDefault(t *testing.T) {
+	t.Parallel()
+	logger := &captureLogger{}
+	a, cleanup, err := New(nil, logger)
+	require.NoError(t, err)
+	defer cleanup()
+
+	a.Record(requestContext(), tupleCreated())
+	failed := tupleCreated()
+	failed.Outcome, failed.Reason = OutcomeFailure, "spicedb_error"
+	a.Record(requestContext(), failed)
+
+	require.Len(t, logger.entries, 2)
+	entry := logger.entries[0]
+	assert.Equal(t, log.LevelInfo, entry["level"])
+	assert.Equal(t, "Tuples created", entry["msg"])
+	assert.Equal(t, "CREATE", entry["action"])
+	assert.Equal(t, "relationship_tuple", entry["resource_type"])
+	assert.Equal(t, "count:1", entry["resource_id"])
+	assert.Equal(t, "success", entry["outcome"])
+	assert.Equal(t, "alice", entry["principal"])
+	assert.Equal(t, createTuples, entry["operation"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, log.LevelWarn, logger.entries[1]["level"])
+	assert.Equal(t, "spicedb_error", logger.entries[1]["reason"])
+}
+
+func TestAuditor_RecordsReadsOnlyWhenEnabled(t *testing.T) {
+	t.Parallel()
+	logger := &captureLogger{}
+	a := NewLogAuditor(logger)
+	a.RecordRead(context.Background(), tupleCreated())
+	assert.Empty(t, logger.entries)
+
+	a, cleanup, err := New(&conf.Server_Audit{IncludeReads: true}, logger)
+	require.NoError(t, err)
+	defer cleanup()
+	a.RecordRead(context.Background(), tupleCreated())
+	assert.Len(t, logger.entries, 1)
+}
+
+func TestAuditor_Redacts(t *testing.T) {
+	t.Parallel()
+	logger := &captureLogger{}
+	a, cleanup, err := New(&conf.Server_Audit{RedactionHashKey: "test-key", Redactions: []*conf.Server_Audit_Redaction{
+		{Field: "principal", Action: "hash"},
+		{Field: "request_id", Action: "drop"},
+		{Field: "tuples", Action: "mask"},
+	}}, logger)
+	require.NoError(t, err)
+	defer cleanup()
+
+	a.Record(requestContext(), tupleCreated())
+
+	entry := logger.entries[0]
+	assert.Equal(t, "hmac-sha256:ff7a3cd2cfcd73da2f3d9350cdbdfd2b8b6546ffd2b7296de6c86d373ebb71a6", entry["principal"])
+	assert.NotContains(t, entry, "request_id")
+	assert.Equal(t, []string{"REDACTED"}, entry["tuples"])
+	assert.Equal(t, "count:1", entry["resource_id"], "fields without rules are kept")
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := New(&conf.Server_Audit{Redactions: []*conf.Server_Audit_Redaction{{Field: "time", Action: "mask"}}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "time")
+	_, _, err = New(&conf.Server_Audit{Redactions: []*conf.Server_Audit_Redaction{{Field: "principal", Action: "encrypt"}}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "encrypt")
+	_, _, err = New(&conf.Server_Audit{Redactions: []*conf.Server_Audit_Redaction{{Field: "principal", Action: "hash"}}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "hash key", "hashing without a key is refused")
+	_, _, err = New(&conf.Server_Audit{Sinks: []*conf.Server_Audit_Sink{{Type: "kafka"}}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "kafka")
+	_, _, err = New(&conf.Server_Audit{Sinks: []*conf.Server_Audit_Sink{{Type: "file"}}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "path")
+}
+
+func TestAuditor_WritesJSONLinesToAFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger := &captureLogger{}
+	a, cleanup, err := New(&conf.Server_Audit{Sinks: []*conf.Server_Audit_Sink{{Type: "file", Path: path}}}, logger)
+	require.NoError(t, err)
+
+	a.Record(requestContext(), tupleCreated())
+	a.Record(requestContext(), tupleCreated())
+	cleanup()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "CREATE", events[0].Action)
+	assert.Equal(t, "req-1", events[0].RequestID)
+	assert.Equal(t, []string{"rbac/group:g1#member rbac/principal:bob"}, events[0].Tuples)
+	assert.False(t, events[0].Time.IsZero())
+	assert.Empty(t, logger.entries, "entries go to the configured sinks only")
+}
+
+func TestAuditor_RetriesWebhookUntilAccepted(t *testing.T) {
+	t.Parallel()
+	hook := newWebhook(t, 3)
+	a, cleanup, err := New(&conf.Server_Audit{
+		Sinks:        []*conf.Server_Audit_Sink{{Type: "webhook", Url: hook.URL}, {Type: "log"}},
+		RetryBackoff: durationpb.New(time.Millisecond),
+	}, &captureLogger{})
+	require.NoError(t, err)
+	defer cleanup()
+
+	a.Record(requestContext(), tupleCreated())
+
+	assert.Eventually(t, func() bool { return len(hook.events()) == 1 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, "alice", hook.events()[0].Principal)
+}
+
+func TestAuditor_KeepsBufferedEntriesAcrossRestarts(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	down := newWebhook(t, 1<<30)
+	c := &conf.Server_Audit{
+		Sinks:        []*conf.Server_Audit_Sink{{Type: "webhook", Url: down.URL}},
+		BufferDir:    dir,
+		RetryBackoff: durationpb.New(time.Millisecond),
+	}
+	a, cleanup, err := New(c, log.DefaultLogger)
+	require.NoError(t, err)
+	a.Record(requestContext(), tupleCreated())
+	cleanup()
+	assert.Empty(t, down.events())
+
+	up := newWebhook(t, 0)
+	c.Sinks[0].Url = up.URL
+	_, cleanup, err = New(c, log.DefaultLogger)
+	require.NoError(t, err)
+	defer cleanup()
+
+	assert.Eventually(t, func() bool { return len(up.events()) == 1 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, "CREATE", up.events()[0].Action)
+}
+
+func TestAuditor_DeliversBufferedEntriesOnClose(t *testing.T) {
+	t.Parallel()
+	hook := newWebhook(t, 0)
+	a, cleanup, err := New(&conf.Server_Audit{Sinks: []*conf.Server_Audit_Sink{{Type: "webhook", Url: hook.URL}}, BatchSize: 1}, log.DefaultLogger)
+	require.NoError(t, err)
+
+	for range 10 {
+		a.Record(requestContext(), tupleCreated())
+	}
+	cleanup()
+
+	assert.Len(t, hook.events(), 10)
+}
+
+func TestAuditor_MaxTupleDetails(t *testing.T) {
+	t.Parallel()
+
+	assert.Zero(t, NewLogAuditor(log.DefaultLogger).MaxTupleDetails(), "tuples are not listed by default")
+	a, cleanup, err := New(&conf.Server_Audit{TupleDetails: true}, log.DefaultLogger)
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Equal(t, defaultMaxTupleDetails, a.MaxTupleDetails())
+	a, cleanup, err = New(&conf.Server_Audit{TupleDetails: true, MaxTupleDetails: 5}, log.DefaultLogger)
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Equal(t, 5, a.MaxTupleDetails())
+}
+
+func TestAuditor_LogsTupleDetails(t *testing.T) {
+	t.Parallel()
+	logger := &captureLogger{}
+	a := NewLogAuditor(logger)
+
+	e := tupleCreated()
+	e.ConsistencyToken, e.TuplesTruncated = "GhUKEzE3", true
+	a.Record(requestContext(), e)
+
+	entry := logger.entries[0]
+	assert.Equal(t, "GhUKEzE3", entry["consistency_token"])
+	assert.Equal(t, true, entry["tuples_truncated"])
+	assert.Equal(t, []string{"rbac/group:g1#member rbac/principal:bob"}, entry["tuples"])
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const (
+	defaultSyslogTag      = "kessel-relations"
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+func newSink(c *conf.Server_Audit_Sink) (Sink, error) {
+	switch c.GetType() {
+	case "file":
+		if c.GetPath() == "" {
+			return nil, fmt.Errorf("path must be set for the file sink")
+		}
+		f, err := os.OpenFile(c.GetPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("error opening audit file: %w", err)
+		}
+		return &fileSink{f: f}, nil
+	case "syslog":
+		tag := c.GetTag()
+		if tag == "" {
+			tag = defaultSyslogTag
+		}
+		w, err := syslog.Dial(c.GetNetwork(), c.GetAddress(), syslog.LOG_AUTH|syslog.LOG_INFO, tag)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to syslog: %w", err)
+		}
+		return &syslogSink{w: w}, nil
+	case "webhook":
+		if c.GetUrl() == "" {
+			return nil, fmt.Errorf("url must be set for the webhook sink")
+		}
+		timeout := c.GetTimeout().AsDuration()
+		if timeout <= 0 {
+			timeout = defaultWebhookTimeout
+		}
+		return &webhookSink{url: c.GetUrl(), headers: c.GetHeaders(), client: &http.Client{Timeout: timeout}}, nil
+	}
+	return nil, fmt.Errorf("unknown audit sink type %q, expected log, file, syslog or webhook", c.GetType())
+}
+
+// logSink writes entries to the application log, failures at warning level.
+type logSink struct {
+	logger log.Logger
+}
+
+func newLogSink(logger log.Logger) *logSink {
+	return &logSink{logger: logger}
+}
+
+func (s *logSink) Write(ctx context.Context, events []Event) error {
+	helper := log.NewHelper(log.WithContext(ctx, s.logger))
+	for _, e := range events {
+		keyvals := []any{
+			"msg", e.Message,
+			"action", e.Action,
+			"resource_type", e.ResourceType,
+			"resource_id", e.ResourceID,
+			"outcome", e.Outcome,
+		}
+		for _, kv := range [][2]string{
+			{"principal", e.Principal},
+			{"reason", e.Reason},
+			{"operation", e.Operation},
+			{"request_id", e.RequestID},
+			{"consistency_token", e.ConsistencyToken},
+		} {
+			if kv[1] != "" {
+				keyvals = append(keyvals, kv[0], kv[1])
+			}
+		}
+		if len(e.Tuples) > 0 {
+			keyvals = append(keyvals, "tuples", e.Tuples)
+		}
+		if e.TuplesTruncated {
+			keyvals = append(keyvals, "tuples_truncated", true)
+		}
+		for _, k := range slices.Sorted(maps.Keys(e.Details)) {
+			keyvals = append(keyvals, k, e.Details[k])
+		}
+		if e.Outcome == OutcomeFailure {
+			helper.Warnw(keyvals...)
+		} else {
+			helper.Infow(keyvals...)
+		}
+	}
+	return nil
+}
+
+func (s *logSink) Close() error { return nil }
+
+// fileSink appends entries to a file as JSON lines, separate from the application log.
+type fileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func (s *fileSink) Write(_ context.Context, events []Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
+
+// syslogSink sends each entry as a JSON message with the auth facility, failures at warning severity.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func (s *syslogSink) Write(_ context.Context, events []Event) error {
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if e.Outcome == OutcomeFailure {
+			err = s.w.Warning(string(b))
+		} else {
+			err = s.w.Info(string(b))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
+
+// webhookSink POSTs each batch of entries to a URL as a JSON array, any 2xx response accepts the batch.
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (s *webhookSink) Write(ctx context.Context, events []Event) error {
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("audit webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
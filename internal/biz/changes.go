@@ -0,0 +1,75 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+// DefaultChangeBatchSize is the number of tuple changes written per transaction unless configured otherwise.
+const DefaultChangeBatchSize = 100
+
+// ApplyTupleChangesUsecase applies tuple changes received outside of the API, such as from a Kafka topic.
+type ApplyTupleChangesUsecase struct {
+	repo ZanzibarRepository
+	log  *log.Helper
+}
+
+func NewApplyTupleChangesUsecase(repo ZanzibarRepository, logger log.Logger) *ApplyTupleChangesUsecase {
+	return &ApplyTupleChangesUsecase{repo: repo, log: log.NewHelper(logger)}
+}
+
+// Apply writes the changes in order, in transactions of at most batchSize changes. A transaction ends early before a
+// change to a tuple it already changes, as a tuple can only be changed once per transaction. Creates touch and
+// deletes do nothing for missing tuples, so changes applied again after a failure have no further effect.
+func (uc *ApplyTupleChangesUsecase) Apply(ctx context.Context, changes []*v1beta1.TupleChange, batchSize int, fencing *v1beta1.FencingCheck) error {
+	if batchSize <= 0 {
+		batchSize = DefaultChangeBatchSize
+	}
+	var deletes, creates []*v1beta1.Relationship
+	inBatch := map[string]bool{}
+	flush := func() error {
+		if len(inBatch) == 0 {
+			return nil
+		}
+		if _, err := uc.repo.RewriteRelationships(ctx, deletes, creates, fencing); err != nil {
+			return err
+		}
+		deletes, creates = nil, nil
+		clear(inBatch)
+		return nil
+	}
+
+	for _, change := range changes {
+		key := tupleKey(change.GetTuple())
+		if inBatch[key] || len(inBatch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		switch change.GetOperation() {
+		case v1beta1.TupleChange_OPERATION_TOUCH:
+			creates = append(creates, change.GetTuple())
+		case v1beta1.TupleChange_OPERATION_DELETE:
+			deletes = append(deletes, change.GetTuple())
+		default:
+			return fmt.Errorf("unknown tuple change operation %v", change.GetOperation())
+		}
+		inBatch[key] = true
+	}
+	return flush()
+}
+
+// tupleKey identifies a tuple as "ns/type:id#relation ns/type:id[#relation]".
+func tupleKey(r *v1beta1.Relationship) string {
+	key := fmt.Sprintf("%s/%s:%s#%s %s/%s:%s", r.GetResource().GetType().GetNamespace(), r.GetResource().GetType().GetName(),
+		r.GetResource().GetId(), r.GetRelation(), r.GetSubject().GetSubject().GetType().GetNamespace(),
+		r.GetSubject().GetSubject().GetType().GetName(), r.GetSubject().GetSubject().GetId())
+	if relation := r.GetSubject().GetRelation(); relation != "" {
+		key += "#" + relation
+	}
+	return key
+}
@@ -0,0 +1,88 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+func touch(tuple string) *v1beta1.TupleChange {
+	return &v1beta1.TupleChange{Operation: v1beta1.TupleChange_OPERATION_TOUCH, Tuple: parseTestTuple(tuple)}
+}
+
+func remove(tuple string) *v1beta1.TupleChange {
+	return &v1beta1.TupleChange{Operation: v1beta1.TupleChange_OPERATION_DELETE, Tuple: parseTestTuple(tuple)}
+}
+
+func TestApplyTupleChanges_AppliesInBatches(t *testing.T) {
+	t.Parallel()
+	repo := newMemoryTuples("rbac/group:g1#member rbac/principal:alice")
+	uc := NewApplyTupleChangesUsecase(repo, log.DefaultLogger)
+
+	err := uc.Apply(context.Background(), []*v1beta1.TupleChange{
+		touch("rbac/group:g1#member rbac/principal:bob"),
+		remove("rbac/group:g1#member rbac/principal:alice"),
+		touch("rbac/group:g2#member rbac/principal:carol"),
+	}, 2, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.rewrites)
+	assert.Equal(t, []string{
+		"rbac/group:g1#member rbac/principal:bob",
+		"rbac/group:g2#member rbac/principal:carol",
+	}, repo.keys())
+}
+
+func TestApplyTupleChanges_ChangesATupleOncePerTransaction(t *testing.T) {
+	t.Parallel()
+	repo := newMemoryTuples()
+	uc := NewApplyTupleChangesUsecase(repo, log.DefaultLogger)
+
+	err := uc.Apply(context.Background(), []*v1beta1.TupleChange{
+		touch("rbac/group:g1#member rbac/principal:bob"),
+		touch("rbac/group:g2#member rbac/principal:bob"),
+		remove("rbac/group:g1#member rbac/principal:bob"),
+		touch("rbac/group:g1#member rbac/principal:bob"),
+	}, 100, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, repo.rewrites, "a transaction ends before each repeated change to g1")
+	assert.Equal(t, []string{
+		"rbac/group:g1#member rbac/principal:bob",
+		"rbac/group:g2#member rbac/principal:bob",
+	}, repo.keys())
+}
+
+func TestApplyTupleChanges_IsIdempotent(t *testing.T) {
+	t.Parallel()
+	repo := newMemoryTuples()
+	uc := NewApplyTupleChangesUsecase(repo, log.DefaultLogger)
+	changes := []*v1beta1.TupleChange{
+		touch("rbac/group:g1#member rbac/principal:bob"),
+		remove("rbac/group:g1#member rbac/principal:alice"),
+	}
+
+	require.NoError(t, uc.Apply(context.Background(), changes, 0, nil))
+	require.NoError(t, uc.Apply(context.Background(), changes, 0, nil))
+
+	assert.Equal(t, []string{"rbac/group:g1#member rbac/principal:bob"}, repo.keys())
+}
+
+func TestApplyTupleChanges_UsesFencing(t *testing.T) {
+	t.Parallel()
+	repo := newMemoryTuples()
+	lock, err := repo.AcquireLock(context.Background(), "consumer")
+	require.NoError(t, err)
+	uc := NewApplyTupleChangesUsecase(repo, log.DefaultLogger)
+	changes := []*v1beta1.TupleChange{touch("rbac/group:g1#member rbac/principal:bob")}
+
+	err = uc.Apply(context.Background(), changes, 0, &v1beta1.FencingCheck{LockId: "consumer", LockToken: "stale"})
+	assert.Error(t, err)
+	err = uc.Apply(context.Background(), changes, 0, &v1beta1.FencingCheck{LockId: "consumer", LockToken: lock.GetLockToken()})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,20 @@
+package biz
+
+import "context"
+
+type principalKey struct{}
+
+// NewPrincipalContext returns a context attributing the tuple changes made with it to principal. The repository
+// records the principal with the changes in SpiceDB, and tuple change events report it.
+func NewPrincipalContext(ctx context.Context, principal string) context.Context {
+	if principal == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal the tuple changes made with ctx are attributed to, if any.
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
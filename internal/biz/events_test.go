@@ -0,0 +1,16 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalContext(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "alice", PrincipalFromContext(NewPrincipalContext(context.Background(), "alice")))
+	assert.Empty(t, PrincipalFromContext(context.Background()))
+	assert.Empty(t, PrincipalFromContext(NewPrincipalContext(context.Background(), "")))
+}
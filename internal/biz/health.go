@@ -1,5 +1,16 @@
 package biz
 
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+)
+
 type IsBackendAvaliableUsecase struct {
 	repo ZanzibarRepository
 }
@@ -8,6 +19,194 @@ func NewIsBackendAvailableUsecase(repo ZanzibarRepository) *IsBackendAvaliableUs
 	return &IsBackendAvaliableUsecase{repo: repo}
 }
 
-func (rc *IsBackendAvaliableUsecase) IsBackendAvailable() error {
-	return rc.repo.IsBackendAvailable()
+// IsBackendAvailable checks the health of the backend within the deadline of ctx.
+func (rc *IsBackendAvaliableUsecase) IsBackendAvailable(ctx context.Context) error {
+	return rc.repo.IsBackendAvailable(ctx)
+}
+
+// IsCircuitOpen reports whether calls to the backend are failing fast after repeated failures.
+func (rc *IsBackendAvaliableUsecase) IsCircuitOpen() bool {
+	return rc.repo.IsCircuitOpen()
+}
+
+// InitializeSchema writes the schema to the backend unless it was already written.
+func (rc *IsBackendAvaliableUsecase) InitializeSchema(ctx context.Context) error {
+	return rc.repo.InitializeSchema(ctx)
+}
+
+// Preflight runs the startup checks of the backend.
+func (rc *IsBackendAvaliableUsecase) Preflight(ctx context.Context) []PreflightCheck {
+	return rc.repo.Preflight(ctx)
+}
+
+// PreflightCheck is the outcome of a startup check of the backend, Err is nil if it passed.
+type PreflightCheck struct {
+	Name string
+	Err  error
+}
+
+// ComponentHealth is the state of a component readiness depends on.
+type ComponentHealth struct {
+	Name    string
+	Healthy bool
+	// Message is the error of the last failed probe.
+	Message string
+}
+
+type component struct {
+	ComponentHealth
+	check     func(ctx context.Context) error
+	successes uint32
+	failures  uint32
+}
+
+// HealthProber periodically probes the components the service depends on. A component becomes unhealthy after
+// failureThreshold consecutive failed probes and healthy again after successThreshold consecutive successful ones;
+// components start unhealthy until their first successful probes. The service is ready while every component is
+// healthy.
+type HealthProber struct {
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold uint32
+	successThreshold uint32
+	auditor          *audit.Auditor
+	log              *log.Helper
+
+	mu         sync.RWMutex
+	components []*component
+	ready      bool
+	listeners  []func(ready bool)
+}
+
+// NewHealthProber creates a prober without components, which is not ready until components are registered and
+// probed.
+func NewHealthProber(interval, timeout time.Duration, failureThreshold, successThreshold uint32, auditor *audit.Auditor, logger log.Logger) *HealthProber {
+	return &HealthProber{
+		interval:         interval,
+		timeout:          timeout,
+		failureThreshold: max(failureThreshold, 1),
+		successThreshold: max(successThreshold, 1),
+		auditor:          auditor,
+		log:              log.NewHelper(logger),
+	}
+}
+
+// Register adds a component probed by check. Components must be registered before probing starts.
+func (p *HealthProber) Register(name string, check func(ctx context.Context) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.components = append(p.components, &component{ComponentHealth: ComponentHealth{Name: name, Message: "not probed yet"}, check: check})
+}
+
+// Subscribe calls f with the current readiness and again whenever it changes.
+func (p *HealthProber) Subscribe(f func(ready bool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, f)
+	f(p.ready)
+}
+
+// Ready reports whether every component is healthy.
+func (p *HealthProber) Ready() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.ready
+}
+
+// Components returns the state of every component.
+func (p *HealthProber) Components() []ComponentHealth {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	components := make([]ComponentHealth, 0, len(p.components))
+	for _, c := range p.components {
+		components = append(components, c.ComponentHealth)
+	}
+	return components
+}
+
+// Run probes the components every interval until ctx is done, starting immediately.
+func (p *HealthProber) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.Probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe checks every component once, in registration order.
+func (p *HealthProber) Probe(ctx context.Context) {
+	p.mu.RLock()
+	components := p.components
+	p.mu.RUnlock()
+
+	errs := make([]error, len(components))
+	for i, c := range components {
+		probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		errs[i] = c.check(probeCtx)
+		cancel()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ready := len(components) > 0
+	for i, c := range components {
+		p.record(c, errs[i])
+		ready = ready && c.Healthy
+	}
+	if ready == p.ready {
+		return
+	}
+	p.ready = ready
+	p.logReadiness()
+	for _, f := range p.listeners {
+		f(ready)
+	}
+}
+
+func (p *HealthProber) record(c *component, err error) {
+	if err == nil {
+		c.failures = 0
+		c.successes++
+		if c.Healthy || c.successes >= p.successThreshold {
+			c.Healthy, c.Message = true, ""
+		}
+		return
+	}
+	c.successes = 0
+	c.failures++
+	c.Message = err.Error()
+	if c.Healthy && c.failures >= p.failureThreshold {
+		c.Healthy = false
+	}
+}
+
+// Readiness change - SEC-MON-REQ-1 compliance (EOI-5 process_status)
+func (p *HealthProber) logReadiness() {
+	var unhealthy []string
+	for _, c := range p.components {
+		if !c.Healthy {
+			unhealthy = append(unhealthy, c.Name)
+		}
+	}
+	if p.ready {
+		p.auditor.Record(context.Background(), audit.Event{
+			Message:      "Service ready",
+			Action:       "READINESS",
+			ResourceType: "service",
+			Outcome:      audit.OutcomeSuccess,
+		})
+		return
+	}
+	p.auditor.Record(context.Background(), audit.Event{
+		Message:      "Service not ready",
+		Action:       "READINESS",
+		ResourceType: "service",
+		Outcome:      audit.OutcomeFailure,
+		Reason:       strings.Join(unhealthy, ","),
+	})
 }
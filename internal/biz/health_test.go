@@ -0,0 +1,84 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProber(failureThreshold, successThreshold uint32) (*HealthProber, *error) {
+	var checkErr error
+	p := NewHealthProber(time.Second, time.Second, failureThreshold, successThreshold, nil, log.DefaultLogger)
+	p.Register("backend", func(context.Context) error { return checkErr })
+	return p, &checkErr
+}
+
+func TestHealthProber_NotReadyWithoutComponents(t *testing.T) {
+	t.Parallel()
+
+	p := NewHealthProber(time.Second, time.Second, 1, 1, nil, log.DefaultLogger)
+	p.Probe(context.Background())
+
+	assert.False(t, p.Ready())
+}
+
+func TestHealthProber_AppliesThresholds(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p, checkErr := newTestProber(2, 2)
+
+	p.Probe(ctx)
+	assert.False(t, p.Ready(), "a component needs successThreshold successful probes")
+	p.Probe(ctx)
+	assert.True(t, p.Ready())
+
+	*checkErr = errors.New("connection refused")
+	p.Probe(ctx)
+	assert.True(t, p.Ready(), "a single failure is tolerated")
+	assert.Equal(t, []ComponentHealth{{Name: "backend", Healthy: true, Message: "connection refused"}}, p.Components())
+	p.Probe(ctx)
+	assert.False(t, p.Ready())
+
+	*checkErr = nil
+	p.Probe(ctx)
+	assert.False(t, p.Ready())
+	p.Probe(ctx)
+	assert.True(t, p.Ready())
+	assert.Equal(t, []ComponentHealth{{Name: "backend", Healthy: true}}, p.Components())
+}
+
+func TestHealthProber_NotifiesSubscribersOfChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p, checkErr := newTestProber(1, 1)
+
+	var notified []bool
+	p.Subscribe(func(ready bool) { notified = append(notified, ready) })
+
+	p.Probe(ctx)
+	p.Probe(ctx)
+	*checkErr = errors.New("connection refused")
+	p.Probe(ctx)
+
+	assert.Equal(t, []bool{false, true, false}, notified)
+}
+
+func TestHealthProber_TimesOutChecks(t *testing.T) {
+	t.Parallel()
+
+	p := NewHealthProber(time.Second, 10*time.Millisecond, 1, 1, nil, log.DefaultLogger)
+	p.Register("backend", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	p.Probe(context.Background())
+
+	assert.False(t, p.Ready())
+	assert.Equal(t, context.DeadlineExceeded.Error(), p.Components()[0].Message)
+}
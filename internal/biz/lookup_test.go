@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/schema"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 )
@@ -16,6 +17,8 @@ type DummyZanzibar struct {
 	subjectsError  error
 	resourcesError error
 	capturedLimit  uint32
+	liveSchema     string
+	tupleCounts    map[schema.TupleFilter]uint64
 }
 
 func (dz *DummyZanzibar) Check(ctx context.Context, request *v1beta1.CheckRequest) (*v1beta1.CheckResponse, error) {
@@ -42,7 +45,15 @@ func (dz *DummyZanzibar) ReadRelationships(ctx context.Context, filter *v1beta1.
 	return nil, nil, nil
 }
 
-func (dz *DummyZanzibar) DeleteRelationships(ctx context.Context, filter *v1beta1.RelationTupleFilter, fencing *v1beta1.FencingCheck) (*v1beta1.DeleteTuplesResponse, error) {
+func (dz *DummyZanzibar) RewriteRelationships(ctx context.Context, deletes, creates []*v1beta1.Relationship, fencing *v1beta1.FencingCheck) (*v1beta1.ConsistencyToken, error) {
+	return nil, nil
+}
+
+func (dz *DummyZanzibar) LatestConsistencyToken(ctx context.Context) (*v1beta1.ConsistencyToken, error) {
+	return nil, nil
+}
+
+func (dz *DummyZanzibar) DeleteRelationships(ctx context.Context, filter *v1beta1.RelationTupleFilter, fencing *v1beta1.FencingCheck, opts DeleteOptions) (*DeleteResult, error) {
 	return nil, nil
 }
 
@@ -108,10 +119,34 @@ func (dz *DummyZanzibar) LookupResources(ctx context.Context, resource_type *v1b
 	return resourcesChan, errsChan, nil
 }
 
-func (dz *DummyZanzibar) IsBackendAvailable() error {
+func (dz *DummyZanzibar) IsBackendAvailable(ctx context.Context) error {
 	return nil
 }
 
+func (dz *DummyZanzibar) IsCircuitOpen() bool {
+	return false
+}
+
+func (dz *DummyZanzibar) InitializeSchema(ctx context.Context) error {
+	return nil
+}
+
+func (dz *DummyZanzibar) Preflight(ctx context.Context) []PreflightCheck {
+	return nil
+}
+
+func (dz *DummyZanzibar) ReadSchema(ctx context.Context) (string, error) {
+	return dz.liveSchema, nil
+}
+
+func (dz *DummyZanzibar) CountRelationships(ctx context.Context, filter schema.TupleFilter, limit uint64) (uint64, bool, error) {
+	count := dz.tupleCounts[filter]
+	if count > limit {
+		return limit, true, nil
+	}
+	return count, false, nil
+}
+
 func (dz *DummyZanzibar) ImportBulkTuples(stream grpc.ClientStreamingServer[v1beta1.ImportBulkTuplesRequest, v1beta1.ImportBulkTuplesResponse]) error {
 	return nil
 }
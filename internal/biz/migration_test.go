@@ -0,0 +1,434 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+// memoryTuples is an in-memory tuple store with the read, rewrite and lock semantics migrations rely on.
+type memoryTuples struct {
+	ZanzibarRepository
+	mu        sync.Mutex
+	tuples    map[string]*v1beta1.Relationship
+	lockToken string
+	locks     int
+	rewrites  int
+	// reads records the consistency of each read, principals the principal of each rewrite
+	reads      []*v1beta1.Consistency
+	principals []string
+	// failRewrite fails the rewrite with this number, counting from 1
+	failRewrite int
+}
+
+func newMemoryTuples(tuples ...string) *memoryTuples {
+	m := &memoryTuples{tuples: map[string]*v1beta1.Relationship{}}
+	for _, t := range tuples {
+		rel := parseTestTuple(t)
+		m.tuples[tupleKey(rel)] = rel
+	}
+	return m
+}
+
+// parseTestTuple parses "ns/type:id#relation ns/type:id[#relation]".
+func parseTestTuple(s string) *v1beta1.Relationship {
+	resource, subject, _ := strings.Cut(s, " ")
+	parseObject := func(o string) (*v1beta1.ObjectReference, string) {
+		o, relation, _ := strings.Cut(o, "#")
+		typ, id, _ := strings.Cut(o, ":")
+		ns, name, _ := strings.Cut(typ, "/")
+		return &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: ns, Name: name}, Id: id}, relation
+	}
+	res, relation := parseObject(resource)
+	sub, subjectRelation := parseObject(subject)
+	return &v1beta1.Relationship{
+		Resource: res,
+		Relation: relation,
+		Subject:  &v1beta1.SubjectReference{Subject: sub, Relation: optional(subjectRelation)},
+	}
+}
+
+func (m *memoryTuples) keys() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.tuples {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func matches(f *v1beta1.RelationTupleFilter, r *v1beta1.Relationship) bool {
+	match := func(want *string, got string) bool { return want == nil || *want == got }
+	sf := f.GetSubjectFilter()
+	return match(f.ResourceNamespace, r.GetResource().GetType().GetNamespace()) &&
+		match(f.ResourceType, r.GetResource().GetType().GetName()) &&
+		match(f.ResourceId, r.GetResource().GetId()) &&
+		match(f.Relation, r.GetRelation()) &&
+		(sf == nil || match(sf.SubjectNamespace, r.GetSubject().GetSubject().GetType().GetNamespace()) &&
+			match(sf.SubjectType, r.GetSubject().GetSubject().GetType().GetName()) &&
+			match(sf.Relation, r.GetSubject().GetRelation()))
+}
+
+func (m *memoryTuples) ReadRelationships(ctx context.Context, filter *v1beta1.RelationTupleFilter, limit uint32, continuation ContinuationToken, consistency *v1beta1.Consistency) (chan *RelationshipResult, chan error, error) {
+	m.mu.Lock()
+	m.reads = append(m.reads, consistency)
+	m.mu.Unlock()
+	results := make(chan *RelationshipResult, limit)
+	errs := make(chan error, 1)
+	for _, key := range m.keys() {
+		m.mu.Lock()
+		rel := m.tuples[key]
+		m.mu.Unlock()
+		if key <= string(continuation) || !matches(filter, rel) {
+			continue
+		}
+		if len(results) == int(limit) {
+			break
+		}
+		results <- &RelationshipResult{Relationship: proto.Clone(rel).(*v1beta1.Relationship), Continuation: ContinuationToken(key)}
+	}
+	close(results)
+	close(errs)
+	return results, errs, nil
+}
+
+func (m *memoryTuples) RewriteRelationships(ctx context.Context, deletes, creates []*v1beta1.Relationship, fencing *v1beta1.FencingCheck) (*v1beta1.ConsistencyToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rewrites++
+	if m.rewrites == m.failRewrite {
+		return nil, errors.New("spicedb unavailable")
+	}
+	if fencing.GetLockToken() != m.lockToken {
+		return nil, errors.New("lock lost")
+	}
+	m.principals = append(m.principals, PrincipalFromContext(ctx))
+	// like SpiceDB, a transaction may change each tuple once
+	changed := map[string]bool{}
+	for _, rel := range append(slices.Clone(deletes), creates...) {
+		if changed[tupleKey(rel)] {
+			return nil, fmt.Errorf("tuple %s is changed twice", tupleKey(rel))
+		}
+		changed[tupleKey(rel)] = true
+	}
+	for _, rel := range deletes {
+		delete(m.tuples, tupleKey(rel))
+	}
+	for _, rel := range creates {
+		m.tuples[tupleKey(rel)] = rel
+	}
+	return &v1beta1.ConsistencyToken{Token: fmt.Sprintf("rev-%d", m.rewrites)}, nil
+}
+
+func (m *memoryTuples) LatestConsistencyToken(ctx context.Context) (*v1beta1.ConsistencyToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &v1beta1.ConsistencyToken{Token: fmt.Sprintf("rev-%d", m.rewrites)}, nil
+}
+
+func (m *memoryTuples) AcquireLock(ctx context.Context, lockId string) (*v1beta1.AcquireLockResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locks++
+	m.lockToken = fmt.Sprintf("token-%d", m.locks)
+	return &v1beta1.AcquireLockResponse{LockToken: m.lockToken}, nil
+}
+
+func waitForMigration(t *testing.T, uc *MigrationUsecase, id string) *v1beta1.Migration {
+	t.Helper()
+	var status *v1beta1.Migration
+	require.Eventually(t, func() bool {
+		var err error
+		status, err = uc.Get(id)
+		require.NoError(t, err)
+		return status.GetState() != v1beta1.Migration_STATE_RUNNING
+	}, 5*time.Second, time.Millisecond)
+	return status
+}
+
+func renameMembers(batchSize uint32) *v1beta1.MigrationSpec {
+	return &v1beta1.MigrationSpec{
+		Operation: &v1beta1.MigrationSpec_RenameRelation{RenameRelation: &v1beta1.RenameRelation{
+			ResourceType: &v1beta1.ObjectType{Namespace: "rbac", Name: "group"},
+			From:         "member",
+			To:           "members",
+		}},
+		LockId:    "migrate-members",
+		BatchSize: &batchSize,
+	}
+}
+
+var groupTuples = []string{
+	"rbac/group:a#member rbac/principal:alice",
+	"rbac/group:a#member rbac/principal:bob",
+	"rbac/group:b#member rbac/principal:carol",
+	"rbac/group:b#member rbac/group:a#member",
+	"rbac/group:c#member rbac/principal:dave",
+	"rbac/group:c#owner rbac/principal:erin",
+}
+
+func TestMigration_RenamesRelationInBatches(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(groupTuples...)
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+
+	started, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: renameMembers(2)})
+	require.NoError(t, err)
+	status := waitForMigration(t, uc, started.GetId())
+
+	assert.Equal(t, v1beta1.Migration_STATE_SUCCEEDED, status.GetState(), status.GetError())
+	assert.Equal(t, uint64(5), status.GetTuplesRead())
+	assert.Equal(t, uint64(5), status.GetTuplesRewritten())
+	assert.Equal(t, uint64(3), status.GetBatches())
+	assert.Empty(t, status.GetPreview())
+	assert.Equal(t, []string{
+		"rbac/group:a#members rbac/principal:alice",
+		"rbac/group:a#members rbac/principal:bob",
+		"rbac/group:b#members rbac/group:a#member",
+		"rbac/group:b#members rbac/principal:carol",
+		"rbac/group:c#members rbac/principal:dave",
+		"rbac/group:c#owner rbac/principal:erin",
+	}, repo.keys())
+}
+
+func TestMigration_DryRunPreviewsWithoutWriting(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(groupTuples...)
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+
+	started, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: renameMembers(2), DryRun: true})
+	require.NoError(t, err)
+	status := waitForMigration(t, uc, started.GetId())
+
+	assert.Equal(t, v1beta1.Migration_STATE_SUCCEEDED, status.GetState())
+	assert.Equal(t, uint64(5), status.GetTuplesRewritten())
+	require.Len(t, status.GetPreview(), 5)
+	assert.Equal(t, "member", status.GetPreview()[0].GetFrom().GetRelation())
+	assert.Equal(t, "members", status.GetPreview()[0].GetTo().GetRelation())
+	assert.Zero(t, repo.locks)
+	assert.Zero(t, repo.rewrites)
+}
+
+func TestMigration_ResumesFromContinuationTokenAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(groupTuples...)
+	repo.failRewrite = 2
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+
+	started, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: renameMembers(2)})
+	require.NoError(t, err)
+	failed := waitForMigration(t, uc, started.GetId())
+
+	assert.Equal(t, v1beta1.Migration_STATE_FAILED, failed.GetState())
+	assert.Equal(t, "spicedb unavailable", failed.GetError())
+	assert.Equal(t, uint64(2), failed.GetTuplesRewritten())
+	assert.NotEmpty(t, failed.GetContinuationToken())
+
+	spec := renameMembers(2)
+	spec.ContinuationToken = proto.String(failed.GetContinuationToken())
+	resumed, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: spec})
+	require.NoError(t, err)
+	status := waitForMigration(t, uc, resumed.GetId())
+
+	assert.Equal(t, v1beta1.Migration_STATE_SUCCEEDED, status.GetState(), status.GetError())
+	assert.Equal(t, uint64(3), status.GetTuplesRewritten())
+	assert.NotContains(t, repo.keys(), "rbac/group:c#member rbac/principal:dave")
+}
+
+func TestMigration_StopsWhenLockIsLost(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(groupTuples...)
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+	_, err := repo.AcquireLock(context.Background(), "migrate-members")
+	require.NoError(t, err)
+
+	plan, err := planMigration(renameMembers(2))
+	require.NoError(t, err)
+	m := &migration{status: &v1beta1.Migration{Id: "fenced", Spec: renameMembers(2), State: v1beta1.Migration_STATE_RUNNING}}
+	uc.migrations[m.status.Id] = m
+	uc.run(context.Background(), m, plan, &v1beta1.FencingCheck{LockId: "migrate-members", LockToken: "stale"})
+
+	status, err := uc.Get("fenced")
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.Migration_STATE_FAILED, status.GetState())
+	assert.Equal(t, "lock lost", status.GetError())
+	assert.Len(t, repo.keys(), len(groupTuples))
+}
+
+func TestMigration_ReadsAtLatestRevisionAndWritesAsCaller(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(groupTuples...)
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(NewPrincipalContext(context.Background(), "alice"))
+	started, err := uc.Start(ctx, &v1beta1.StartMigrationRequest{Spec: renameMembers(2)})
+	require.NoError(t, err)
+	cancel() // the migration outlives the request
+	status := waitForMigration(t, uc, started.GetId())
+
+	assert.Equal(t, v1beta1.Migration_STATE_SUCCEEDED, status.GetState(), status.GetError())
+	require.NotEmpty(t, repo.reads)
+	assert.Equal(t, "rev-0", repo.reads[0].GetAtLeastAsFresh().GetToken(), "the first batch is read at the latest revision")
+	assert.Equal(t, []string{"alice", "alice", "alice"}, repo.principals)
+}
+
+func TestMigration_CreatesSharedRewriteTargetOnce(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(
+		"rbac/group:d#admin rbac/principal:frank",
+		"rbac/group:d#owner rbac/principal:frank",
+	)
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+
+	started, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: &v1beta1.MigrationSpec{
+		Operation: &v1beta1.MigrationSpec_RewriteTuples{RewriteTuples: &v1beta1.RewriteTuples{
+			Filter:   &v1beta1.RelationTupleFilter{ResourceNamespace: proto.String("rbac"), ResourceType: proto.String("group")},
+			Relation: proto.String("member"),
+		}},
+		LockId: "merge-roles",
+	}})
+	require.NoError(t, err)
+	status := waitForMigration(t, uc, started.GetId())
+
+	assert.Equal(t, v1beta1.Migration_STATE_SUCCEEDED, status.GetState(), status.GetError())
+	assert.Equal(t, uint64(2), status.GetTuplesRewritten())
+	assert.Equal(t, []string{"rbac/group:d#member rbac/principal:frank"}, repo.keys())
+}
+
+func TestMigration_RetypesSubjects(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(groupTuples...)
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+
+	started, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: &v1beta1.MigrationSpec{
+		Operation: &v1beta1.MigrationSpec_RetypeSubject{RetypeSubject: &v1beta1.RetypeSubject{
+			ResourceType: &v1beta1.ObjectType{Namespace: "rbac", Name: "group"},
+			Relation:     "member",
+			From:         &v1beta1.ObjectType{Namespace: "rbac", Name: "principal"},
+			To:           &v1beta1.ObjectType{Namespace: "rbac", Name: "user"},
+		}},
+		LockId: "retype",
+	}})
+	require.NoError(t, err)
+	status := waitForMigration(t, uc, started.GetId())
+
+	assert.Equal(t, uint64(4), status.GetTuplesRewritten())
+	assert.Contains(t, repo.keys(), "rbac/group:a#member rbac/user:alice")
+	assert.Contains(t, repo.keys(), "rbac/group:b#member rbac/group:a#member")
+	assert.Contains(t, repo.keys(), "rbac/group:c#owner rbac/principal:erin")
+}
+
+func TestMigration_RewriteCanKeepSource(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemoryTuples(groupTuples...)
+	uc, stop := NewMigrationUsecase(repo, nil, log.DefaultLogger)
+	defer stop()
+
+	started, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: &v1beta1.MigrationSpec{
+		Operation: &v1beta1.MigrationSpec_RewriteTuples{RewriteTuples: &v1beta1.RewriteTuples{
+			Filter: &v1beta1.RelationTupleFilter{
+				ResourceNamespace: proto.String("rbac"),
+				ResourceType:      proto.String("group"),
+				Relation:          proto.String("owner"),
+			},
+			Relation:   proto.String("member"),
+			KeepSource: true,
+		}},
+		LockId: "copy-owners",
+	}})
+	require.NoError(t, err)
+	status := waitForMigration(t, uc, started.GetId())
+
+	assert.Equal(t, uint64(1), status.GetTuplesRewritten())
+	assert.Contains(t, repo.keys(), "rbac/group:c#owner rbac/principal:erin")
+	assert.Contains(t, repo.keys(), "rbac/group:c#member rbac/principal:erin")
+}
+
+func TestMigration_RejectsNoOpSpecs(t *testing.T) {
+	t.Parallel()
+
+	uc, stop := NewMigrationUsecase(newMemoryTuples(), nil, log.DefaultLogger)
+	defer stop()
+	spec := renameMembers(2)
+	spec.GetRenameRelation().To = "member"
+
+	_, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: spec})
+	assert.Equal(t, InvalidMigrationReason, kerrors.Reason(err))
+
+	_, err = uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: &v1beta1.MigrationSpec{
+		Operation: &v1beta1.MigrationSpec_RewriteTuples{RewriteTuples: &v1beta1.RewriteTuples{Filter: &v1beta1.RelationTupleFilter{}}},
+		LockId:    "nothing",
+	}})
+	assert.Equal(t, InvalidMigrationReason, kerrors.Reason(err))
+}
+
+func TestMigration_GetUnknownMigration(t *testing.T) {
+	t.Parallel()
+
+	uc, stop := NewMigrationUsecase(newMemoryTuples(), nil, log.DefaultLogger)
+	defer stop()
+
+	_, err := uc.Get("missing")
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestMigration_ForgetsFinishedMigrationsAfterRetention(t *testing.T) {
+	t.Parallel()
+
+	uc, stop := NewMigrationUsecase(newMemoryTuples(groupTuples...), nil, log.DefaultLogger)
+	defer stop()
+	var mu sync.Mutex
+	now := time.Now()
+	uc.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	started, err := uc.Start(context.Background(), &v1beta1.StartMigrationRequest{Spec: renameMembers(2), DryRun: true})
+	require.NoError(t, err)
+	waitForMigration(t, uc, started.GetId())
+
+	advance(finishedMigrationRetention)
+	_, err = uc.Get(started.GetId())
+	assert.NoError(t, err, "kept for the retention period")
+
+	advance(time.Second)
+	_, err = uc.Get(started.GetId())
+	assert.True(t, kerrors.IsNotFound(err))
+}
@@ -6,6 +6,7 @@ import (
 	"google.golang.org/grpc"
 
 	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/schema"
 
 	"github.com/go-kratos/kratos/v2/log"
 )
@@ -14,6 +15,34 @@ import (
 type TouchSemantics bool
 
 type ContinuationToken string
+
+// DeleteThresholdExceededReason is the error reason returned when a deletion would exceed the configured maximum.
+const DeleteThresholdExceededReason = "DELETE_THRESHOLD_EXCEEDED"
+
+// LockLostReason is the error reason returned when a fenced write fails because its lock was acquired by another
+// writer since.
+const LockLostReason = "LOCK_LOST"
+
+// DeleteOptions bound the effect of a single DeleteRelationships call.
+type DeleteOptions struct {
+	// DryRun reports the matching tuples without deleting them.
+	DryRun bool
+	// Limit deletes at most this many tuples, leaving the rest in place. Zero means no limit.
+	Limit uint32
+	// OverrideThreshold allows deleting more tuples than the configured maximum.
+	OverrideThreshold bool
+	// ListDeleted lists up to this many of the tuples deleted in the result, as SpiceDB does not report which tuples
+	// it deletes. They are read back from SpiceDB's Watch API, none are listed if the datastore does not support
+	// watching. The deletion itself is the same either way.
+	ListDeleted uint32
+}
+
+// DeleteResult is the outcome of a DeleteRelationships call: the response to the caller and, with
+// DeleteOptions.ListDeleted, the tuples deleted.
+type DeleteResult struct {
+	*v1beta1.DeleteTuplesResponse
+	Deleted []*v1beta1.Relationship
+}
 type SubjectResult struct {
 	Subject          *v1beta1.SubjectReference
 	Continuation     ContinuationToken
@@ -38,10 +67,17 @@ type ZanzibarRepository interface {
 	CheckForUpdateBulk(ctx context.Context, request *v1beta1.CheckForUpdateBulkRequest) (*v1beta1.CheckForUpdateBulkResponse, error)
 	CreateRelationships(context.Context, []*v1beta1.Relationship, TouchSemantics, *v1beta1.FencingCheck) (*v1beta1.CreateTuplesResponse, error)
 	ReadRelationships(ctx context.Context, filter *v1beta1.RelationTupleFilter, limit uint32, continuation ContinuationToken, consistency *v1beta1.Consistency) (chan *RelationshipResult, chan error, error)
-	DeleteRelationships(context.Context, *v1beta1.RelationTupleFilter, *v1beta1.FencingCheck) (*v1beta1.DeleteTuplesResponse, error)
+	DeleteRelationships(context.Context, *v1beta1.RelationTupleFilter, *v1beta1.FencingCheck, DeleteOptions) (*DeleteResult, error)
+	RewriteRelationships(ctx context.Context, deletes, creates []*v1beta1.Relationship, fencing *v1beta1.FencingCheck) (*v1beta1.ConsistencyToken, error)
+	LatestConsistencyToken(ctx context.Context) (*v1beta1.ConsistencyToken, error)
 	LookupSubjects(ctx context.Context, subjectType *v1beta1.ObjectType, subject_relation, relation string, resource *v1beta1.ObjectReference, limit uint32, continuation ContinuationToken, consistency *v1beta1.Consistency) (chan *SubjectResult, chan error, error)
 	LookupResources(ctx context.Context, resouce_type *v1beta1.ObjectType, relation string, subject *v1beta1.SubjectReference, limit uint32, continuation ContinuationToken, consistency *v1beta1.Consistency) (chan *ResourceResult, chan error, error)
-	IsBackendAvailable() error
+	IsBackendAvailable(ctx context.Context) error
+	IsCircuitOpen() bool
+	InitializeSchema(ctx context.Context) error
+	Preflight(ctx context.Context) []PreflightCheck
+	ReadSchema(ctx context.Context) (string, error)
+	CountRelationships(ctx context.Context, filter schema.TupleFilter, limit uint64) (uint64, bool, error)
 	ImportBulkTuples(stream grpc.ClientStreamingServer[v1beta1.ImportBulkTuplesRequest, v1beta1.ImportBulkTuplesResponse]) error
 	AcquireLock(ctx context.Context, lockId string) (*v1beta1.AcquireLockResponse, error)
 }
@@ -152,8 +188,8 @@ func NewDeleteRelationshipsUsecase(repo ZanzibarRepository, logger log.Logger) *
 	return &DeleteRelationshipsUsecase{repo: repo, log: log.NewHelper(logger)}
 }
 
-func (rc *DeleteRelationshipsUsecase) DeleteRelationships(ctx context.Context, r *v1beta1.RelationTupleFilter, fencing *v1beta1.FencingCheck) (*v1beta1.DeleteTuplesResponse, error) {
-	return rc.repo.DeleteRelationships(ctx, r, fencing)
+func (rc *DeleteRelationshipsUsecase) DeleteRelationships(ctx context.Context, r *v1beta1.RelationTupleFilter, fencing *v1beta1.FencingCheck, opts DeleteOptions) (*DeleteResult, error) {
+	return rc.repo.DeleteRelationships(ctx, r, fencing, opts)
 }
 
 type ImportBulkTuplesUsecase struct {
@@ -0,0 +1,99 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/schema"
+)
+
+// InvalidSchemaReason is the error reason returned when a schema to diff does not parse.
+const InvalidSchemaReason = "INVALID_SCHEMA"
+
+// DefaultAffectedTupleCountLimit bounds the tuples counted per breaking change unless the request sets a limit.
+const DefaultAffectedTupleCountLimit uint32 = 1000
+
+// MaxAffectedTupleCountLimit bounds the tuples counted per breaking change whatever limit the request sets, as
+// counting reads every tuple counted.
+const MaxAffectedTupleCountLimit uint32 = 10000
+
+type DiffSchemaUsecase struct {
+	repo ZanzibarRepository
+	log  *log.Helper
+}
+
+func NewDiffSchemaUsecase(repo ZanzibarRepository, logger log.Logger) *DiffSchemaUsecase {
+	return &DiffSchemaUsecase{repo: repo, log: log.NewHelper(logger)}
+}
+
+// DiffSchema diffs the requested schema against the base schema, by default the one in the backend, and counts the
+// tuples in the backend each breaking change would orphan. The diff is blocked if any breaking change orphans
+// tuples, or if tuples were not counted and there is any breaking change.
+func (uc *DiffSchemaUsecase) DiffSchema(ctx context.Context, req *v1beta1.DiffSchemaRequest) (*v1beta1.DiffSchemaResponse, error) {
+	base := req.GetBaseSchema()
+	if req.BaseSchema == nil {
+		live, err := uc.repo.ReadSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		base = live
+	}
+	old, err := schema.Parse(base)
+	if err != nil {
+		if req.BaseSchema == nil {
+			return nil, fmt.Errorf("error parsing the schema in the backend: %w", err)
+		}
+		return nil, kerrors.BadRequest(InvalidSchemaReason, fmt.Sprintf("invalid base schema: %v", err))
+	}
+	comparison, err := schema.Parse(req.GetSchema())
+	if err != nil {
+		return nil, kerrors.BadRequest(InvalidSchemaReason, fmt.Sprintf("invalid schema: %v", err))
+	}
+
+	limit := DefaultAffectedTupleCountLimit
+	if req.CountLimit != nil {
+		limit = min(req.GetCountLimit(), MaxAffectedTupleCountLimit)
+	}
+
+	resp := &v1beta1.DiffSchemaResponse{}
+	for _, c := range schema.Diff(old, comparison) {
+		change := &v1beta1.SchemaChange{
+			Kind:       string(c.Kind),
+			Definition: c.Definition,
+			Name:       c.Name,
+			Detail:     c.Detail,
+			Breaking:   c.Breaking,
+		}
+		resp.Changes = append(resp.Changes, change)
+		if !c.Breaking {
+			continue
+		}
+		resp.Breaking = true
+		if req.GetSkipTupleCounts() {
+			resp.Blocked = true
+			continue
+		}
+
+		var affected uint64
+		for _, filter := range c.Affected {
+			count, truncated, err := uc.repo.CountRelationships(ctx, filter, uint64(limit)-affected)
+			if err != nil {
+				return nil, err
+			}
+			affected += count
+			if truncated {
+				change.AffectedTuplesTruncated = true
+				break
+			}
+		}
+		change.AffectedTuples = &affected
+		if affected > 0 || change.AffectedTuplesTruncated {
+			resp.Blocked = true
+		}
+	}
+	return resp, nil
+}
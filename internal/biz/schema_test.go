@@ -0,0 +1,146 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/schema"
+)
+
+const liveSchema = `
+	definition rbac/principal {}
+	definition rbac/group {
+		relation t_member: rbac/principal | rbac/principal:*
+		relation t_owner: rbac/principal
+		permission member = t_member
+	}`
+
+func TestDiffSchema_CountsTuplesOrphanedByBreakingChanges(t *testing.T) {
+	t.Parallel()
+
+	repo := &DummyZanzibar{
+		liveSchema: liveSchema,
+		tupleCounts: map[schema.TupleFilter]uint64{
+			{ResourceType: "rbac/group", Relation: "t_owner"}: 3,
+		},
+	}
+	uc := NewDiffSchemaUsecase(repo, log.DefaultLogger)
+
+	resp, err := uc.DiffSchema(context.Background(), &v1beta1.DiffSchemaRequest{Schema: `
+		definition rbac/principal {}
+		definition rbac/group {
+			relation t_member: rbac/principal
+			permission member = t_member
+		}`})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetChanges(), 2)
+	narrowed, removed := resp.GetChanges()[0], resp.GetChanges()[1]
+	assert.Equal(t, string(schema.NarrowedSubjectTypes), narrowed.GetKind())
+	assert.Equal(t, uint64(0), narrowed.GetAffectedTuples())
+	assert.NotNil(t, narrowed.AffectedTuples)
+	assert.Equal(t, string(schema.RemovedRelation), removed.GetKind())
+	assert.Equal(t, "t_owner", removed.GetName())
+	assert.Equal(t, uint64(3), removed.GetAffectedTuples())
+	assert.True(t, resp.GetBreaking())
+	assert.True(t, resp.GetBlocked())
+}
+
+func TestDiffSchema_NotBlockedWhenBreakingChangesOrphanNothing(t *testing.T) {
+	t.Parallel()
+
+	uc := NewDiffSchemaUsecase(&DummyZanzibar{liveSchema: liveSchema}, log.DefaultLogger)
+
+	resp, err := uc.DiffSchema(context.Background(), &v1beta1.DiffSchemaRequest{Schema: `
+		definition rbac/principal {}
+		definition rbac/group {
+			relation t_member: rbac/principal | rbac/principal:*
+			permission member = t_member
+		}`})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetChanges(), 1)
+	assert.True(t, resp.GetBreaking())
+	assert.False(t, resp.GetBlocked())
+}
+
+func TestDiffSchema_BlocksOnBreakingChangesWithoutCounts(t *testing.T) {
+	t.Parallel()
+
+	uc := NewDiffSchemaUsecase(&DummyZanzibar{}, log.DefaultLogger)
+	base := liveSchema
+
+	resp, err := uc.DiffSchema(context.Background(), &v1beta1.DiffSchemaRequest{
+		Schema:          `definition rbac/principal {}`,
+		BaseSchema:      &base,
+		SkipTupleCounts: true,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetChanges(), 1)
+	assert.Equal(t, string(schema.RemovedType), resp.GetChanges()[0].GetKind())
+	assert.Nil(t, resp.GetChanges()[0].AffectedTuples)
+	assert.True(t, resp.GetBlocked())
+}
+
+func TestDiffSchema_StopsCountingAtLimit(t *testing.T) {
+	t.Parallel()
+
+	repo := &DummyZanzibar{
+		liveSchema: liveSchema,
+		tupleCounts: map[schema.TupleFilter]uint64{
+			{ResourceType: "rbac/group", Relation: "t_member"}: 4,
+			{ResourceType: "rbac/group", Relation: "t_owner"}:  4,
+		},
+	}
+	uc := NewDiffSchemaUsecase(repo, log.DefaultLogger)
+	limit := uint32(6)
+
+	resp, err := uc.DiffSchema(context.Background(), &v1beta1.DiffSchemaRequest{
+		Schema:     `definition rbac/principal {}`,
+		CountLimit: &limit,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetChanges(), 1)
+	assert.Equal(t, uint64(6), resp.GetChanges()[0].GetAffectedTuples())
+	assert.True(t, resp.GetChanges()[0].GetAffectedTuplesTruncated())
+}
+
+func TestDiffSchema_ClampsCountLimit(t *testing.T) {
+	t.Parallel()
+
+	repo := &DummyZanzibar{
+		liveSchema: liveSchema,
+		tupleCounts: map[schema.TupleFilter]uint64{
+			{ResourceType: "rbac/group", Relation: "t_member"}: uint64(MaxAffectedTupleCountLimit) + 1,
+		},
+	}
+	uc := NewDiffSchemaUsecase(repo, log.DefaultLogger)
+	limit := MaxAffectedTupleCountLimit * 10
+
+	resp, err := uc.DiffSchema(context.Background(), &v1beta1.DiffSchemaRequest{
+		Schema:     `definition rbac/principal {}`,
+		CountLimit: &limit,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetChanges(), 1)
+	assert.Equal(t, uint64(MaxAffectedTupleCountLimit), resp.GetChanges()[0].GetAffectedTuples())
+	assert.True(t, resp.GetChanges()[0].GetAffectedTuplesTruncated())
+}
+
+func TestDiffSchema_RejectsInvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	uc := NewDiffSchemaUsecase(&DummyZanzibar{liveSchema: liveSchema}, log.DefaultLogger)
+
+	_, err := uc.DiffSchema(context.Background(), &v1beta1.DiffSchemaRequest{Schema: `definition rbac/group { relation t_member: rbac/missing }`})
+	assert.Equal(t, InvalidSchemaReason, kerrors.Reason(err))
+}
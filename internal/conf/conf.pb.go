@@ -75,13 +75,26 @@ func (x *Bootstrap) GetData() *Data {
 }
 
 type Server struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Http          *Server_HTTP           `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
-	Grpc          *Server_GRPC           `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
-	MinLogLevel   *string                `protobuf:"bytes,3,opt,name=minLogLevel,proto3,oneof" json:"minLogLevel,omitempty"`
-	Auth          *Server_Auth           `protobuf:"bytes,4,opt,name=auth,proto3" json:"auth,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Http        *Server_HTTP           `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
+	Grpc        *Server_GRPC           `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
+	MinLogLevel *string                `protobuf:"bytes,3,opt,name=minLogLevel,proto3,oneof" json:"minLogLevel,omitempty"`
+	Auth        *Server_Auth           `protobuf:"bytes,4,opt,name=auth,proto3" json:"auth,omitempty"`
+	RateLimit   *Server_RateLimit      `protobuf:"bytes,5,opt,name=rateLimit,proto3" json:"rateLimit,omitempty"`
+	// serves both the gRPC and HTTP listeners over TLS when certFile is set
+	Tls      *Server_TLS      `protobuf:"bytes,6,opt,name=tls,proto3" json:"tls,omitempty"`
+	Health   *Server_Health   `protobuf:"bytes,7,opt,name=health,proto3" json:"health,omitempty"`
+	Consumer *Server_Consumer `protobuf:"bytes,8,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	Audit    *Server_Audit    `protobuf:"bytes,9,opt,name=audit,proto3" json:"audit,omitempty"`
+	Tracing  *Server_Tracing  `protobuf:"bytes,10,opt,name=tracing,proto3" json:"tracing,omitempty"`
+	Metrics  *Server_Metrics  `protobuf:"bytes,11,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	// attributes of the resource exported metrics and spans describe, e.g. deployment.environment.name, alongside
+	// service.name
+	ResourceAttributes map[string]string    `protobuf:"bytes,12,rep,name=resourceAttributes,proto3" json:"resourceAttributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	SlowRequests       *Server_SlowRequests `protobuf:"bytes,13,opt,name=slowRequests,proto3" json:"slowRequests,omitempty"`
+	LogRedaction       *Server_LogRedaction `protobuf:"bytes,14,opt,name=logRedaction,proto3" json:"logRedaction,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *Server) Reset() {
@@ -142,9 +155,80 @@ func (x *Server) GetAuth() *Server_Auth {
 	return nil
 }
 
+func (x *Server) GetRateLimit() *Server_RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *Server) GetTls() *Server_TLS {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *Server) GetHealth() *Server_Health {
+	if x != nil {
+		return x.Health
+	}
+	return nil
+}
+
+func (x *Server) GetConsumer() *Server_Consumer {
+	if x != nil {
+		return x.Consumer
+	}
+	return nil
+}
+
+func (x *Server) GetAudit() *Server_Audit {
+	if x != nil {
+		return x.Audit
+	}
+	return nil
+}
+
+func (x *Server) GetTracing() *Server_Tracing {
+	if x != nil {
+		return x.Tracing
+	}
+	return nil
+}
+
+func (x *Server) GetMetrics() *Server_Metrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *Server) GetResourceAttributes() map[string]string {
+	if x != nil {
+		return x.ResourceAttributes
+	}
+	return nil
+}
+
+func (x *Server) GetSlowRequests() *Server_SlowRequests {
+	if x != nil {
+		return x.SlowRequests
+	}
+	return nil
+}
+
+func (x *Server) GetLogRedaction() *Server_LogRedaction {
+	if x != nil {
+		return x.LogRedaction
+	}
+	return nil
+}
+
 type Data struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SpiceDb       *Data_SpiceDb          `protobuf:"bytes,1,opt,name=spiceDb,proto3" json:"spiceDb,omitempty"`
+	Events        *Data_Events           `protobuf:"bytes,2,opt,name=events,proto3" json:"events,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -186,6 +270,13 @@ func (x *Data) GetSpiceDb() *Data_SpiceDb {
 	return nil
 }
 
+func (x *Data) GetEvents() *Data_Events {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 type Server_HTTP struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
@@ -315,11 +406,27 @@ func (x *Server_GRPC) GetTimeout() *durationpb.Duration {
 }
 
 type Server_Auth struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	EnableAuth    bool                   `protobuf:"varint,1,opt,name=enableAuth,proto3" json:"enableAuth,omitempty"`
-	JwksUrl       string                 `protobuf:"bytes,2,opt,name=jwksUrl,proto3" json:"jwksUrl,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	EnableAuth bool                   `protobuf:"varint,1,opt,name=enableAuth,proto3" json:"enableAuth,omitempty"`
+	// JWKS of a single issuer, ignored when issuers are configured. Unless jwksIssuer is set the iss and aud claims of
+	// its tokens are not checked, which is deprecated.
+	JwksUrl string `protobuf:"bytes,2,opt,name=jwksUrl,proto3" json:"jwksUrl,omitempty"`
+	// expected iss claim of tokens verified with jwksUrl, requires jwksAudiences
+	JwksIssuer string `protobuf:"bytes,8,opt,name=jwksIssuer,proto3" json:"jwksIssuer,omitempty"`
+	// the aud claim of tokens verified with jwksUrl must contain one of these
+	JwksAudiences []string `protobuf:"bytes,9,rep,name=jwksAudiences,proto3" json:"jwksAudiences,omitempty"`
+	// enforce policies after authentication, callers matching no policy are denied
+	EnableAuthz bool `protobuf:"varint,3,opt,name=enableAuthz,proto3" json:"enableAuthz,omitempty"`
+	// a request is allowed when the policies matching the caller grant the operation on every namespace it touches
+	Policies []*Server_Auth_Policy `protobuf:"bytes,4,rep,name=policies,proto3" json:"policies,omitempty"`
+	Issuers  []*Server_Auth_Issuer `protobuf:"bytes,5,rep,name=issuers,proto3" json:"issuers,omitempty"`
+	// how often JWKS are re-fetched in the background, defaults to 1h. Keys from the last successful fetch stay in
+	// use while an issuer is unreachable.
+	JwksRefreshInterval *durationpb.Duration `protobuf:"bytes,6,opt,name=jwksRefreshInterval,proto3" json:"jwksRefreshInterval,omitempty"`
+	// callers presenting a client certificate verified by tls.clientCaFile are authenticated by it and need no JWT
+	AllowClientCertAuth bool `protobuf:"varint,7,opt,name=allowClientCertAuth,proto3" json:"allowClientCertAuth,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *Server_Auth) Reset() {
@@ -366,32 +473,83 @@ func (x *Server_Auth) GetJwksUrl() string {
 	return ""
 }
 
-type Data_SpiceDb struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	UseTLS          bool                   `protobuf:"varint,1,opt,name=useTLS,proto3" json:"useTLS,omitempty"`
-	Endpoint        string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
-	Token           string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
-	TokenFile       string                 `protobuf:"bytes,4,opt,name=tokenFile,proto3" json:"tokenFile,omitempty"`
-	SchemaFile      string                 `protobuf:"bytes,5,opt,name=schemaFile,proto3" json:"schemaFile,omitempty"`
-	FullyConsistent bool                   `protobuf:"varint,6,opt,name=fullyConsistent,proto3" json:"fullyConsistent,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *Server_Auth) GetJwksIssuer() string {
+	if x != nil {
+		return x.JwksIssuer
+	}
+	return ""
 }
 
-func (x *Data_SpiceDb) Reset() {
-	*x = Data_SpiceDb{}
+func (x *Server_Auth) GetJwksAudiences() []string {
+	if x != nil {
+		return x.JwksAudiences
+	}
+	return nil
+}
+
+func (x *Server_Auth) GetEnableAuthz() bool {
+	if x != nil {
+		return x.EnableAuthz
+	}
+	return false
+}
+
+func (x *Server_Auth) GetPolicies() []*Server_Auth_Policy {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+func (x *Server_Auth) GetIssuers() []*Server_Auth_Issuer {
+	if x != nil {
+		return x.Issuers
+	}
+	return nil
+}
+
+func (x *Server_Auth) GetJwksRefreshInterval() *durationpb.Duration {
+	if x != nil {
+		return x.JwksRefreshInterval
+	}
+	return nil
+}
+
+func (x *Server_Auth) GetAllowClientCertAuth() bool {
+	if x != nil {
+		return x.AllowClientCertAuth
+	}
+	return false
+}
+
+type Server_RateLimit struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// default token bucket applied to every principal and operation pair, unauthenticated callers are keyed by peer
+	// address, 0 is unlimited
+	RequestsPerSecond float64 `protobuf:"fixed64,2,opt,name=requestsPerSecond,proto3" json:"requestsPerSecond,omitempty"`
+	// requests allowed at once above the rate, defaults to one second of requests
+	Burst uint32 `protobuf:"varint,3,opt,name=burst,proto3" json:"burst,omitempty"`
+	// the first matching rule overrides the default bucket
+	Rules         []*Server_RateLimit_Rule `protobuf:"bytes,4,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_RateLimit) Reset() {
+	*x = Server_RateLimit{}
 	mi := &file_conf_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Data_SpiceDb) String() string {
+func (x *Server_RateLimit) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Data_SpiceDb) ProtoMessage() {}
+func (*Server_RateLimit) ProtoMessage() {}
 
-func (x *Data_SpiceDb) ProtoReflect() protoreflect.Message {
+func (x *Server_RateLimit) ProtoReflect() protoreflect.Message {
 	mi := &file_conf_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -403,87 +561,2349 @@ func (x *Data_SpiceDb) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Data_SpiceDb.ProtoReflect.Descriptor instead.
-func (*Data_SpiceDb) Descriptor() ([]byte, []int) {
-	return file_conf_proto_rawDescGZIP(), []int{2, 0}
+// Deprecated: Use Server_RateLimit.ProtoReflect.Descriptor instead.
+func (*Server_RateLimit) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 3}
 }
 
-func (x *Data_SpiceDb) GetUseTLS() bool {
+func (x *Server_RateLimit) GetEnabled() bool {
 	if x != nil {
-		return x.UseTLS
+		return x.Enabled
 	}
 	return false
 }
 
-func (x *Data_SpiceDb) GetEndpoint() string {
+func (x *Server_RateLimit) GetRequestsPerSecond() float64 {
 	if x != nil {
-		return x.Endpoint
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *Server_RateLimit) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+func (x *Server_RateLimit) GetRules() []*Server_RateLimit_Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type Server_TLS struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	CertFile string                 `protobuf:"bytes,1,opt,name=certFile,proto3" json:"certFile,omitempty"`
+	KeyFile  string                 `protobuf:"bytes,2,opt,name=keyFile,proto3" json:"keyFile,omitempty"`
+	// CA bundle client certificates are verified against
+	ClientCaFile string `protobuf:"bytes,3,opt,name=clientCaFile,proto3" json:"clientCaFile,omitempty"`
+	// "none" (default), "request" to verify client certificates when presented, or "require"
+	ClientAuth string `protobuf:"bytes,4,opt,name=clientAuth,proto3" json:"clientAuth,omitempty"`
+	// certificate field used as the caller principal: "subject" (CN, default), or the first "uri", "dns" or "email" SAN
+	ClientPrincipal string `protobuf:"bytes,5,opt,name=clientPrincipal,proto3" json:"clientPrincipal,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Server_TLS) Reset() {
+	*x = Server_TLS{}
+	mi := &file_conf_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_TLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_TLS) ProtoMessage() {}
+
+func (x *Server_TLS) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_TLS.ProtoReflect.Descriptor instead.
+func (*Server_TLS) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 4}
+}
+
+func (x *Server_TLS) GetCertFile() string {
+	if x != nil {
+		return x.CertFile
 	}
 	return ""
 }
 
-func (x *Data_SpiceDb) GetToken() string {
+func (x *Server_TLS) GetKeyFile() string {
 	if x != nil {
-		return x.Token
+		return x.KeyFile
 	}
 	return ""
 }
 
-func (x *Data_SpiceDb) GetTokenFile() string {
+func (x *Server_TLS) GetClientCaFile() string {
 	if x != nil {
-		return x.TokenFile
+		return x.ClientCaFile
 	}
 	return ""
 }
 
-func (x *Data_SpiceDb) GetSchemaFile() string {
+func (x *Server_TLS) GetClientAuth() string {
 	if x != nil {
-		return x.SchemaFile
+		return x.ClientAuth
 	}
 	return ""
 }
 
-func (x *Data_SpiceDb) GetFullyConsistent() bool {
+func (x *Server_TLS) GetClientPrincipal() string {
 	if x != nil {
-		return x.FullyConsistent
+		return x.ClientPrincipal
+	}
+	return ""
+}
+
+type Server_Health struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// interval between probes of the components readiness depends on, defaults to 5s
+	ProbeInterval *durationpb.Duration `protobuf:"bytes,1,opt,name=probeInterval,proto3" json:"probeInterval,omitempty"`
+	// deadline of each probe, defaults to 5s
+	ProbeTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=probeTimeout,proto3" json:"probeTimeout,omitempty"`
+	// consecutive failed probes before a component is reported unavailable, defaults to 3
+	FailureThreshold uint32 `protobuf:"varint,3,opt,name=failureThreshold,proto3" json:"failureThreshold,omitempty"`
+	// consecutive successful probes before an unavailable component is reported available, defaults to 1
+	SuccessThreshold uint32 `protobuf:"varint,4,opt,name=successThreshold,proto3" json:"successThreshold,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Server_Health) Reset() {
+	*x = Server_Health{}
+	mi := &file_conf_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Health) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Health) ProtoMessage() {}
+
+func (x *Server_Health) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Health.ProtoReflect.Descriptor instead.
+func (*Server_Health) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 5}
+}
+
+func (x *Server_Health) GetProbeInterval() *durationpb.Duration {
+	if x != nil {
+		return x.ProbeInterval
+	}
+	return nil
+}
+
+func (x *Server_Health) GetProbeTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.ProbeTimeout
+	}
+	return nil
+}
+
+func (x *Server_Health) GetFailureThreshold() uint32 {
+	if x != nil {
+		return x.FailureThreshold
+	}
+	return 0
+}
+
+func (x *Server_Health) GetSuccessThreshold() uint32 {
+	if x != nil {
+		return x.SuccessThreshold
+	}
+	return 0
+}
+
+type Server_Consumer struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// apply tuple changes read from a Kafka topic, alongside serving the API
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// base URL of a Kafka REST proxy (v2 API), e.g. Confluent REST Proxy or Redpanda's HTTP proxy
+	RestProxyUrl string `protobuf:"bytes,2,opt,name=restProxyUrl,proto3" json:"restProxyUrl,omitempty"`
+	Topic        string `protobuf:"bytes,3,opt,name=topic,proto3" json:"topic,omitempty"`
+	// consumer group offsets are committed for, defaults to kessel-relations
+	Group string `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"`
+	// encoding of message values: "json" (default) for kessel.relations.v1beta1.TupleChange in protobuf JSON, or
+	// "protobuf" for the binary encoding
+	Format string `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
+	// lock acquired at start and checked by every write, a consumer whose lock is acquired elsewhere pauses and
+	// acquires it again after retryBackoff
+	LockId string `protobuf:"bytes,6,opt,name=lockId,proto3" json:"lockId,omitempty"`
+	// changes written per SpiceDB transaction, defaults to 100
+	BatchSize uint32 `protobuf:"varint,7,opt,name=batchSize,proto3" json:"batchSize,omitempty"`
+	// how long each poll for records waits, defaults to 1s
+	PollTimeout *durationpb.Duration `protobuf:"bytes,8,opt,name=pollTimeout,proto3" json:"pollTimeout,omitempty"`
+	// delay before retrying a failed write, doubling up to 1m, defaults to 1s
+	RetryBackoff  *durationpb.Duration `protobuf:"bytes,9,opt,name=retryBackoff,proto3" json:"retryBackoff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Consumer) Reset() {
+	*x = Server_Consumer{}
+	mi := &file_conf_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Consumer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Consumer) ProtoMessage() {}
+
+func (x *Server_Consumer) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Consumer.ProtoReflect.Descriptor instead.
+func (*Server_Consumer) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 6}
+}
+
+func (x *Server_Consumer) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
 	}
 	return false
 }
 
-var File_conf_proto protoreflect.FileDescriptor
+func (x *Server_Consumer) GetRestProxyUrl() string {
+	if x != nil {
+		return x.RestProxyUrl
+	}
+	return ""
+}
 
-const file_conf_proto_rawDesc = "" +
-	"\n" +
-	"\n" +
-	"conf.proto\x12\n" +
-	"kratos.api\x1a\x1egoogle/protobuf/duration.proto\"]\n" +
-	"\tBootstrap\x12*\n" +
-	"\x06server\x18\x01 \x01(\v2\x12.kratos.api.ServerR\x06server\x12$\n" +
-	"\x04data\x18\x02 \x01(\v2\x10.kratos.api.DataR\x04data\"\xff\x03\n" +
-	"\x06Server\x12+\n" +
-	"\x04http\x18\x01 \x01(\v2\x17.kratos.api.Server.HTTPR\x04http\x12+\n" +
-	"\x04grpc\x18\x02 \x01(\v2\x17.kratos.api.Server.GRPCR\x04grpc\x12%\n" +
-	"\vminLogLevel\x18\x03 \x01(\tH\x00R\vminLogLevel\x88\x01\x01\x12+\n" +
-	"\x04auth\x18\x04 \x01(\v2\x17.kratos.api.Server.AuthR\x04auth\x1a\x89\x01\n" +
-	"\x04HTTP\x12\x18\n" +
-	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
-	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x1e\n" +
-	"\n" +
-	"pathprefix\x18\x04 \x01(\tR\n" +
-	"pathprefix\x1ai\n" +
-	"\x04GRPC\x12\x18\n" +
-	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
-	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1a@\n" +
-	"\x04Auth\x12\x1e\n" +
-	"\n" +
-	"enableAuth\x18\x01 \x01(\bR\n" +
+func (x *Server_Consumer) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Server_Consumer) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *Server_Consumer) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *Server_Consumer) GetLockId() string {
+	if x != nil {
+		return x.LockId
+	}
+	return ""
+}
+
+func (x *Server_Consumer) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Server_Consumer) GetPollTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.PollTimeout
+	}
+	return nil
+}
+
+func (x *Server_Consumer) GetRetryBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.RetryBackoff
+	}
+	return nil
+}
+
+type Server_Audit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// also audit Check, CheckForUpdate, their bulk variants, LookupSubjects and LookupResources calls
+	IncludeReads bool `protobuf:"varint,1,opt,name=includeReads,proto3" json:"includeReads,omitempty"`
+	// destinations every entry is delivered to, defaults to the application log
+	Sinks []*Server_Audit_Sink `protobuf:"bytes,2,rep,name=sinks,proto3" json:"sinks,omitempty"`
+	// directory entries are kept in until each sink accepts them, so they survive restarts. Entries are buffered in
+	// memory when unset.
+	BufferDir string `protobuf:"bytes,3,opt,name=bufferDir,proto3" json:"bufferDir,omitempty"`
+	// entries buffered in memory per sink before requests wait for the sink to catch up, defaults to 10000
+	BufferSize uint32 `protobuf:"varint,4,opt,name=bufferSize,proto3" json:"bufferSize,omitempty"`
+	// entries delivered to a sink at once, defaults to 100
+	BatchSize uint32 `protobuf:"varint,5,opt,name=batchSize,proto3" json:"batchSize,omitempty"`
+	// delay before retrying a failed delivery, doubling up to 1m, defaults to 1s
+	RetryBackoff *durationpb.Duration `protobuf:"bytes,6,opt,name=retryBackoff,proto3" json:"retryBackoff,omitempty"`
+	// applied to every entry before it reaches any sink
+	Redactions []*Server_Audit_Redaction `protobuf:"bytes,7,rep,name=redactions,proto3" json:"redactions,omitempty"`
+	// list every tuple created by CreateTuples and deleted by DeleteTuples in their entries. The tuples a deletion
+	// removed are listed from SpiceDB's Watch API.
+	TupleDetails bool `protobuf:"varint,8,opt,name=tupleDetails,proto3" json:"tupleDetails,omitempty"`
+	// tuples listed per entry, entries listing fewer tuples than were changed are marked truncated. Defaults to 1000.
+	MaxTupleDetails uint32 `protobuf:"varint,9,opt,name=maxTupleDetails,proto3" json:"maxTupleDetails,omitempty"`
+	// HMAC key redacted values are hashed with, redactionHashKey takes precedence over redactionHashKeyFile. One is
+	// required if a redaction hashes.
+	RedactionHashKey     string `protobuf:"bytes,10,opt,name=redactionHashKey,proto3" json:"redactionHashKey,omitempty"`
+	RedactionHashKeyFile string `protobuf:"bytes,11,opt,name=redactionHashKeyFile,proto3" json:"redactionHashKeyFile,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Server_Audit) Reset() {
+	*x = Server_Audit{}
+	mi := &file_conf_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Audit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Audit) ProtoMessage() {}
+
+func (x *Server_Audit) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Audit.ProtoReflect.Descriptor instead.
+func (*Server_Audit) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 7}
+}
+
+func (x *Server_Audit) GetIncludeReads() bool {
+	if x != nil {
+		return x.IncludeReads
+	}
+	return false
+}
+
+func (x *Server_Audit) GetSinks() []*Server_Audit_Sink {
+	if x != nil {
+		return x.Sinks
+	}
+	return nil
+}
+
+func (x *Server_Audit) GetBufferDir() string {
+	if x != nil {
+		return x.BufferDir
+	}
+	return ""
+}
+
+func (x *Server_Audit) GetBufferSize() uint32 {
+	if x != nil {
+		return x.BufferSize
+	}
+	return 0
+}
+
+func (x *Server_Audit) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Server_Audit) GetRetryBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.RetryBackoff
+	}
+	return nil
+}
+
+func (x *Server_Audit) GetRedactions() []*Server_Audit_Redaction {
+	if x != nil {
+		return x.Redactions
+	}
+	return nil
+}
+
+func (x *Server_Audit) GetTupleDetails() bool {
+	if x != nil {
+		return x.TupleDetails
+	}
+	return false
+}
+
+func (x *Server_Audit) GetMaxTupleDetails() uint32 {
+	if x != nil {
+		return x.MaxTupleDetails
+	}
+	return 0
+}
+
+func (x *Server_Audit) GetRedactionHashKey() string {
+	if x != nil {
+		return x.RedactionHashKey
+	}
+	return ""
+}
+
+func (x *Server_Audit) GetRedactionHashKeyFile() string {
+	if x != nil {
+		return x.RedactionHashKeyFile
+	}
+	return ""
+}
+
+type Server_Tracing struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// export spans of every RPC and SpiceDB call over OTLP. Trace context is propagated regardless.
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// "grpc" (default) or "http"
+	Protocol string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// collector host:port, defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable or localhost
+	Endpoint string `protobuf:"bytes,3,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	// export without TLS
+	Insecure bool `protobuf:"varint,4,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	// headers sent with every export, e.g. for collector authentication
+	Headers map[string]string `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// fraction of traces started by this service that are sampled, defaults to 1. Traces started by callers follow
+	// their sampling decision.
+	SampleRatio   *float64 `protobuf:"fixed64,6,opt,name=sampleRatio,proto3,oneof" json:"sampleRatio,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Tracing) Reset() {
+	*x = Server_Tracing{}
+	mi := &file_conf_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Tracing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Tracing) ProtoMessage() {}
+
+func (x *Server_Tracing) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Tracing.ProtoReflect.Descriptor instead.
+func (*Server_Tracing) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 8}
+}
+
+func (x *Server_Tracing) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Server_Tracing) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *Server_Tracing) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Server_Tracing) GetInsecure() bool {
+	if x != nil {
+		return x.Insecure
+	}
+	return false
+}
+
+func (x *Server_Tracing) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Server_Tracing) GetSampleRatio() float64 {
+	if x != nil && x.SampleRatio != nil {
+		return *x.SampleRatio
+	}
+	return 0
+}
+
+type Server_Metrics struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "prometheus" serves them at /metrics, "otlp" pushes them to a collector. Defaults to prometheus only.
+	Exporters []string `protobuf:"bytes,1,rep,name=exporters,proto3" json:"exporters,omitempty"`
+	// OTLP protocol, "grpc" (default) or "http"
+	Protocol string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// collector host:port, defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable or localhost
+	Endpoint string `protobuf:"bytes,3,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	// export without TLS
+	Insecure bool `protobuf:"varint,4,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	// headers sent with every export, e.g. for collector authentication
+	Headers map[string]string `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// interval between OTLP pushes, defaults to 60s
+	Interval *durationpb.Duration `protobuf:"bytes,6,opt,name=interval,proto3" json:"interval,omitempty"`
+	// attach the trace and span IDs of sampled requests to the measurements taken while serving them, e.g. latencies
+	Exemplars     bool `protobuf:"varint,7,opt,name=exemplars,proto3" json:"exemplars,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Metrics) Reset() {
+	*x = Server_Metrics{}
+	mi := &file_conf_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Metrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Metrics) ProtoMessage() {}
+
+func (x *Server_Metrics) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Metrics.ProtoReflect.Descriptor instead.
+func (*Server_Metrics) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 9}
+}
+
+func (x *Server_Metrics) GetExporters() []string {
+	if x != nil {
+		return x.Exporters
+	}
+	return nil
+}
+
+func (x *Server_Metrics) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *Server_Metrics) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Server_Metrics) GetInsecure() bool {
+	if x != nil {
+		return x.Insecure
+	}
+	return false
+}
+
+func (x *Server_Metrics) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Server_Metrics) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *Server_Metrics) GetExemplars() bool {
+	if x != nil {
+		return x.Exemplars
+	}
+	return false
+}
+
+type Server_SlowRequests struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// log requests slower than their threshold along with the SpiceDB work they caused
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// threshold of operations without a rule, defaults to 1s
+	Threshold *durationpb.Duration `protobuf:"bytes,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	// fraction of the slow requests of operations without a rule that are logged, defaults to 1
+	SampleRatio *float64                    `protobuf:"fixed64,3,opt,name=sampleRatio,proto3,oneof" json:"sampleRatio,omitempty"`
+	Rules       []*Server_SlowRequests_Rule `protobuf:"bytes,4,rep,name=rules,proto3" json:"rules,omitempty"`
+	// slow requests logged per second and operation at most, unlimited when 0. Those not logged are counted in the
+	// next entry of the operation.
+	MaxPerSecond  float64 `protobuf:"fixed64,5,opt,name=maxPerSecond,proto3" json:"maxPerSecond,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_SlowRequests) Reset() {
+	*x = Server_SlowRequests{}
+	mi := &file_conf_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_SlowRequests) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_SlowRequests) ProtoMessage() {}
+
+func (x *Server_SlowRequests) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_SlowRequests.ProtoReflect.Descriptor instead.
+func (*Server_SlowRequests) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 11}
+}
+
+func (x *Server_SlowRequests) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Server_SlowRequests) GetThreshold() *durationpb.Duration {
+	if x != nil {
+		return x.Threshold
+	}
+	return nil
+}
+
+func (x *Server_SlowRequests) GetSampleRatio() float64 {
+	if x != nil && x.SampleRatio != nil {
+		return *x.SampleRatio
+	}
+	return 0
+}
+
+func (x *Server_SlowRequests) GetRules() []*Server_SlowRequests_Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Server_SlowRequests) GetMaxPerSecond() float64 {
+	if x != nil {
+		return x.MaxPerSecond
+	}
+	return 0
+}
+
+type Server_LogRedaction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// applied to the requests written to the application log, wherever the message appears in them
+	Rules []*Server_LogRedaction_Rule `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	// HMAC key values are hashed with, hashKey takes precedence over hashKeyFile. One is required if a rule hashes.
+	HashKey       string `protobuf:"bytes,2,opt,name=hashKey,proto3" json:"hashKey,omitempty"`
+	HashKeyFile   string `protobuf:"bytes,3,opt,name=hashKeyFile,proto3" json:"hashKeyFile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_LogRedaction) Reset() {
+	*x = Server_LogRedaction{}
+	mi := &file_conf_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_LogRedaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_LogRedaction) ProtoMessage() {}
+
+func (x *Server_LogRedaction) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_LogRedaction.ProtoReflect.Descriptor instead.
+func (*Server_LogRedaction) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 12}
+}
+
+func (x *Server_LogRedaction) GetRules() []*Server_LogRedaction_Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Server_LogRedaction) GetHashKey() string {
+	if x != nil {
+		return x.HashKey
+	}
+	return ""
+}
+
+func (x *Server_LogRedaction) GetHashKeyFile() string {
+	if x != nil {
+		return x.HashKeyFile
+	}
+	return ""
+}
+
+type Server_Auth_Policy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// caller selectors, every non-empty selector must match the token claims
+	Subject  string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	ClientId string `protobuf:"bytes,2,opt,name=clientId,proto3" json:"clientId,omitempty"`
+	// the token must carry at least one of the listed scopes and at least one of the listed roles
+	Scopes []string `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	Roles  []string `protobuf:"bytes,4,rep,name=roles,proto3" json:"roles,omitempty"`
+	// full operation names, "/<service>/*" or "*", and "debug" to allow debug traces of checks
+	Operations []string `protobuf:"bytes,5,rep,name=operations,proto3" json:"operations,omitempty"`
+	// resource namespaces the caller may address, "*" also allows requests not scoped to a namespace
+	Namespaces    []string `protobuf:"bytes,6,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Auth_Policy) Reset() {
+	*x = Server_Auth_Policy{}
+	mi := &file_conf_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Auth_Policy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Auth_Policy) ProtoMessage() {}
+
+func (x *Server_Auth_Policy) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Auth_Policy.ProtoReflect.Descriptor instead.
+func (*Server_Auth_Policy) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 2, 0}
+}
+
+func (x *Server_Auth_Policy) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Server_Auth_Policy) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *Server_Auth_Policy) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *Server_Auth_Policy) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *Server_Auth_Policy) GetOperations() []string {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+func (x *Server_Auth_Policy) GetNamespaces() []string {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type Server_Auth_Issuer struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// expected iss claim, tokens are verified with the keys of the issuer they name
+	Issuer  string `protobuf:"bytes,1,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	JwksUrl string `protobuf:"bytes,2,opt,name=jwksUrl,proto3" json:"jwksUrl,omitempty"`
+	// the aud claim must contain one of these, empty disables the audience check
+	Audiences []string `protobuf:"bytes,3,rep,name=audiences,proto3" json:"audiences,omitempty"`
+	// allowed alg header values, e.g. RS256 or ES256, defaults to RS256
+	Algorithms    []string `protobuf:"bytes,4,rep,name=algorithms,proto3" json:"algorithms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Auth_Issuer) Reset() {
+	*x = Server_Auth_Issuer{}
+	mi := &file_conf_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Auth_Issuer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Auth_Issuer) ProtoMessage() {}
+
+func (x *Server_Auth_Issuer) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Auth_Issuer.ProtoReflect.Descriptor instead.
+func (*Server_Auth_Issuer) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 2, 1}
+}
+
+func (x *Server_Auth_Issuer) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *Server_Auth_Issuer) GetJwksUrl() string {
+	if x != nil {
+		return x.JwksUrl
+	}
+	return ""
+}
+
+func (x *Server_Auth_Issuer) GetAudiences() []string {
+	if x != nil {
+		return x.Audiences
+	}
+	return nil
+}
+
+func (x *Server_Auth_Issuer) GetAlgorithms() []string {
+	if x != nil {
+		return x.Algorithms
+	}
+	return nil
+}
+
+type Server_RateLimit_Rule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// full operation name, e.g. /kessel.relations.v1beta1.KesselCheckService/CheckBulk, empty matches any
+	Operation string `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	// JWT sub of the caller, empty matches any
+	Principal string `protobuf:"bytes,2,opt,name=principal,proto3" json:"principal,omitempty"`
+	// 0 is unlimited
+	RequestsPerSecond float64 `protobuf:"fixed64,3,opt,name=requestsPerSecond,proto3" json:"requestsPerSecond,omitempty"`
+	// defaults to one second of requests
+	Burst         uint32 `protobuf:"varint,4,opt,name=burst,proto3" json:"burst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_RateLimit_Rule) Reset() {
+	*x = Server_RateLimit_Rule{}
+	mi := &file_conf_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_RateLimit_Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_RateLimit_Rule) ProtoMessage() {}
+
+func (x *Server_RateLimit_Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_RateLimit_Rule.ProtoReflect.Descriptor instead.
+func (*Server_RateLimit_Rule) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 3, 0}
+}
+
+func (x *Server_RateLimit_Rule) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Server_RateLimit_Rule) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *Server_RateLimit_Rule) GetRequestsPerSecond() float64 {
+	if x != nil {
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *Server_RateLimit_Rule) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+type Server_Audit_Sink struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "log" (the application log), "file", "syslog" or "webhook"
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// file entries are appended to as JSON lines
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// syslog network ("udp", "tcp" or "unix") and address, the local syslog daemon when both are empty
+	Network string `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	Address string `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	// syslog tag, defaults to kessel-relations
+	Tag string `protobuf:"bytes,5,opt,name=tag,proto3" json:"tag,omitempty"`
+	// URL batches of entries are POSTed to as a JSON array
+	Url string `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
+	// headers sent with every webhook request, e.g. Authorization
+	Headers map[string]string `protobuf:"bytes,7,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// deadline of each webhook request, defaults to 10s
+	Timeout       *durationpb.Duration `protobuf:"bytes,8,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Audit_Sink) Reset() {
+	*x = Server_Audit_Sink{}
+	mi := &file_conf_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Audit_Sink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Audit_Sink) ProtoMessage() {}
+
+func (x *Server_Audit_Sink) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Audit_Sink.ProtoReflect.Descriptor instead.
+func (*Server_Audit_Sink) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 7, 0}
+}
+
+func (x *Server_Audit_Sink) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Server_Audit_Sink) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Server_Audit_Sink) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Server_Audit_Sink) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Server_Audit_Sink) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *Server_Audit_Sink) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Server_Audit_Sink) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Server_Audit_Sink) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type Server_Audit_Redaction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "principal", "resource_id", "request_id", "reason" or "tuples"
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// "mask" replaces the value with REDACTED, "hash" with an HMAC-SHA256 digest of it and "drop" removes it
+	Action        string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Audit_Redaction) Reset() {
+	*x = Server_Audit_Redaction{}
+	mi := &file_conf_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Audit_Redaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Audit_Redaction) ProtoMessage() {}
+
+func (x *Server_Audit_Redaction) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Audit_Redaction.ProtoReflect.Descriptor instead.
+func (*Server_Audit_Redaction) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 7, 1}
+}
+
+func (x *Server_Audit_Redaction) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Server_Audit_Redaction) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type Server_SlowRequests_Rule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// full operation name, e.g. /kessel.relations.v1beta1.KesselCheckService/Check
+	Operation string `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	// defaults to the threshold of operations without a rule
+	Threshold *durationpb.Duration `protobuf:"bytes,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	// fraction of slow requests logged, defaults to 1. 0 disables slow request logging for the operation.
+	SampleRatio   *float64 `protobuf:"fixed64,3,opt,name=sampleRatio,proto3,oneof" json:"sampleRatio,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_SlowRequests_Rule) Reset() {
+	*x = Server_SlowRequests_Rule{}
+	mi := &file_conf_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_SlowRequests_Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_SlowRequests_Rule) ProtoMessage() {}
+
+func (x *Server_SlowRequests_Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_SlowRequests_Rule.ProtoReflect.Descriptor instead.
+func (*Server_SlowRequests_Rule) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 11, 0}
+}
+
+func (x *Server_SlowRequests_Rule) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Server_SlowRequests_Rule) GetThreshold() *durationpb.Duration {
+	if x != nil {
+		return x.Threshold
+	}
+	return nil
+}
+
+func (x *Server_SlowRequests_Rule) GetSampleRatio() float64 {
+	if x != nil && x.SampleRatio != nil {
+		return *x.SampleRatio
+	}
+	return 0
+}
+
+type Server_LogRedaction_Rule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// full name of the message the field belongs to, e.g. kessel.relations.v1beta1.SubjectReference
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// dot separated path of the field from that message, e.g. subject.id
+	Field string `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	// "mask" replaces the value with REDACTED, "hash" with an HMAC-SHA256 digest of it and "drop" removes it. Only
+	// string fields can be masked or hashed.
+	Action        string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_LogRedaction_Rule) Reset() {
+	*x = Server_LogRedaction_Rule{}
+	mi := &file_conf_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_LogRedaction_Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_LogRedaction_Rule) ProtoMessage() {}
+
+func (x *Server_LogRedaction_Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_LogRedaction_Rule.ProtoReflect.Descriptor instead.
+func (*Server_LogRedaction_Rule) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 12, 0}
+}
+
+func (x *Server_LogRedaction_Rule) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Server_LogRedaction_Rule) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Server_LogRedaction_Rule) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type Data_SpiceDb struct {
+	state            protoimpl.MessageState         `protogen:"open.v1"`
+	UseTLS           bool                           `protobuf:"varint,1,opt,name=useTLS,proto3" json:"useTLS,omitempty"`
+	Endpoint         string                         `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Token            string                         `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	TokenFile        string                         `protobuf:"bytes,4,opt,name=tokenFile,proto3" json:"tokenFile,omitempty"`
+	SchemaFile       string                         `protobuf:"bytes,5,opt,name=schemaFile,proto3" json:"schemaFile,omitempty"`
+	FullyConsistent  bool                           `protobuf:"varint,6,opt,name=fullyConsistent,proto3" json:"fullyConsistent,omitempty"`
+	ConsistencyToken *Data_SpiceDb_ConsistencyToken `protobuf:"bytes,7,opt,name=consistencyToken,proto3" json:"consistencyToken,omitempty"`
+	DeleteGuardrails *Data_SpiceDb_DeleteGuardrails `protobuf:"bytes,8,opt,name=deleteGuardrails,proto3" json:"deleteGuardrails,omitempty"`
+	// applies when useTLS is set, files are re-read when they change on disk
+	Tls *Data_SpiceDb_TLS `protobuf:"bytes,9,opt,name=tls,proto3" json:"tls,omitempty"`
+	// re-read tokenFile and schemaFile when they change on disk, rotating the token and re-applying the schema
+	WatchFiles bool                     `protobuf:"varint,10,opt,name=watchFiles,proto3" json:"watchFiles,omitempty"`
+	Connection *Data_SpiceDb_Connection `protobuf:"bytes,11,opt,name=connection,proto3" json:"connection,omitempty"`
+	// how schemaFile is used: "apply" (default) writes it to SpiceDB, "verify" never writes it and fails if the
+	// schema in SpiceDB differs from it
+	SchemaMode    string                  `protobuf:"bytes,12,opt,name=schemaMode,proto3" json:"schemaMode,omitempty"`
+	Preflight     *Data_SpiceDb_Preflight `protobuf:"bytes,13,opt,name=preflight,proto3" json:"preflight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb) Reset() {
+	*x = Data_SpiceDb{}
+	mi := &file_conf_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb) ProtoMessage() {}
+
+func (x *Data_SpiceDb) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *Data_SpiceDb) GetUseTLS() bool {
+	if x != nil {
+		return x.UseTLS
+	}
+	return false
+}
+
+func (x *Data_SpiceDb) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb) GetTokenFile() string {
+	if x != nil {
+		return x.TokenFile
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb) GetSchemaFile() string {
+	if x != nil {
+		return x.SchemaFile
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb) GetFullyConsistent() bool {
+	if x != nil {
+		return x.FullyConsistent
+	}
+	return false
+}
+
+func (x *Data_SpiceDb) GetConsistencyToken() *Data_SpiceDb_ConsistencyToken {
+	if x != nil {
+		return x.ConsistencyToken
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb) GetDeleteGuardrails() *Data_SpiceDb_DeleteGuardrails {
+	if x != nil {
+		return x.DeleteGuardrails
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb) GetTls() *Data_SpiceDb_TLS {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb) GetWatchFiles() bool {
+	if x != nil {
+		return x.WatchFiles
+	}
+	return false
+}
+
+func (x *Data_SpiceDb) GetConnection() *Data_SpiceDb_Connection {
+	if x != nil {
+		return x.Connection
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb) GetSchemaMode() string {
+	if x != nil {
+		return x.SchemaMode
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb) GetPreflight() *Data_SpiceDb_Preflight {
+	if x != nil {
+		return x.Preflight
+	}
+	return nil
+}
+
+type Data_Events struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// publish an event for every tuple change read from SpiceDB's Watch API, which the datastore must support
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// directory the watch cursor and the events not yet delivered are kept in, it must survive restarts for changes
+	// made while the service is down or the sink is unavailable to be published
+	OutboxDir string `protobuf:"bytes,2,opt,name=outboxDir,proto3" json:"outboxDir,omitempty"`
+	// where events are delivered: "stdout" (default), "file" or "kafka"
+	Sink string `protobuf:"bytes,3,opt,name=sink,proto3" json:"sink,omitempty"`
+	// file events are appended to as JSON lines by the file sink
+	FilePath string             `protobuf:"bytes,4,opt,name=filePath,proto3" json:"filePath,omitempty"`
+	Kafka    *Data_Events_Kafka `protobuf:"bytes,5,opt,name=kafka,proto3" json:"kafka,omitempty"`
+	// events delivered per publish, defaults to 100
+	BatchSize uint32 `protobuf:"varint,6,opt,name=batchSize,proto3" json:"batchSize,omitempty"`
+	// delay before retrying a failed publish, doubling up to 1m, defaults to 1s
+	RetryBackoff *durationpb.Duration `protobuf:"bytes,7,opt,name=retryBackoff,proto3" json:"retryBackoff,omitempty"`
+	// lock electing the one replica that relays changes, defaults to "kessel-relations-events"
+	LockId string `protobuf:"bytes,8,opt,name=lockId,proto3" json:"lockId,omitempty"`
+	// how long the replica relaying changes may fail to renew its lease before another takes over, defaults to 30s
+	LeaseDuration *durationpb.Duration `protobuf:"bytes,9,opt,name=leaseDuration,proto3" json:"leaseDuration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Events) Reset() {
+	*x = Data_Events{}
+	mi := &file_conf_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Events) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Events) ProtoMessage() {}
+
+func (x *Data_Events) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Events.ProtoReflect.Descriptor instead.
+func (*Data_Events) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 1}
+}
+
+func (x *Data_Events) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_Events) GetOutboxDir() string {
+	if x != nil {
+		return x.OutboxDir
+	}
+	return ""
+}
+
+func (x *Data_Events) GetSink() string {
+	if x != nil {
+		return x.Sink
+	}
+	return ""
+}
+
+func (x *Data_Events) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *Data_Events) GetKafka() *Data_Events_Kafka {
+	if x != nil {
+		return x.Kafka
+	}
+	return nil
+}
+
+func (x *Data_Events) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Data_Events) GetRetryBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.RetryBackoff
+	}
+	return nil
+}
+
+func (x *Data_Events) GetLockId() string {
+	if x != nil {
+		return x.LockId
+	}
+	return ""
+}
+
+func (x *Data_Events) GetLeaseDuration() *durationpb.Duration {
+	if x != nil {
+		return x.LeaseDuration
+	}
+	return nil
+}
+
+type Data_SpiceDb_ConsistencyToken struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// identifies the SpiceDB cluster tokens are issued for, defaults to the endpoint
+	BackendId string `protobuf:"bytes,1,opt,name=backendId,proto3" json:"backendId,omitempty"`
+	Shard     string `protobuf:"bytes,2,opt,name=shard,proto3" json:"shard,omitempty"`
+	// HMAC key used to sign tokens, signingKey takes precedence over signingKeyFile. One is required unless
+	// unsigned is set.
+	SigningKey     string `protobuf:"bytes,3,opt,name=signingKey,proto3" json:"signingKey,omitempty"`
+	SigningKeyFile string `protobuf:"bytes,4,opt,name=signingKeyFile,proto3" json:"signingKeyFile,omitempty"`
+	// tokens older than maxAge are rejected, zero disables expiry
+	MaxAge *durationpb.Duration `protobuf:"bytes,5,opt,name=maxAge,proto3" json:"maxAge,omitempty"`
+	// accept raw ZedTokens issued before envelopes were introduced, for clients that have not migrated yet. Raw
+	// tokens are neither signed nor bound to the backend.
+	AcceptLegacyTokens bool `protobuf:"varint,6,opt,name=acceptLegacyTokens,proto3" json:"acceptLegacyTokens,omitempty"`
+	// issue tokens without a signature, which clients can forge; only for local development and tests
+	Unsigned      bool `protobuf:"varint,7,opt,name=unsigned,proto3" json:"unsigned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) Reset() {
+	*x = Data_SpiceDb_ConsistencyToken{}
+	mi := &file_conf_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_ConsistencyToken) ProtoMessage() {}
+
+func (x *Data_SpiceDb_ConsistencyToken) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_ConsistencyToken.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_ConsistencyToken) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 0}
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) GetBackendId() string {
+	if x != nil {
+		return x.BackendId
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) GetShard() string {
+	if x != nil {
+		return x.Shard
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) GetSigningKey() string {
+	if x != nil {
+		return x.SigningKey
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) GetSigningKeyFile() string {
+	if x != nil {
+		return x.SigningKeyFile
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) GetMaxAge() *durationpb.Duration {
+	if x != nil {
+		return x.MaxAge
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) GetAcceptLegacyTokens() bool {
+	if x != nil {
+		return x.AcceptLegacyTokens
+	}
+	return false
+}
+
+func (x *Data_SpiceDb_ConsistencyToken) GetUnsigned() bool {
+	if x != nil {
+		return x.Unsigned
+	}
+	return false
+}
+
+type Data_SpiceDb_DeleteGuardrails struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// DeleteTuples calls matching more tuples fail unless override_threshold is set, zero disables the check.
+	// Must not exceed SpiceDB's --max-delete-relationships-limit.
+	MaxDeletions uint32 `protobuf:"varint,1,opt,name=maxDeletions,proto3" json:"maxDeletions,omitempty"`
+	// number of tuples returned by a dry run, defaults to 10
+	DryRunSampleSize uint32 `protobuf:"varint,2,opt,name=dryRunSampleSize,proto3" json:"dryRunSampleSize,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_DeleteGuardrails) Reset() {
+	*x = Data_SpiceDb_DeleteGuardrails{}
+	mi := &file_conf_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_DeleteGuardrails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_DeleteGuardrails) ProtoMessage() {}
+
+func (x *Data_SpiceDb_DeleteGuardrails) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_DeleteGuardrails.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_DeleteGuardrails) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 1}
+}
+
+func (x *Data_SpiceDb_DeleteGuardrails) GetMaxDeletions() uint32 {
+	if x != nil {
+		return x.MaxDeletions
+	}
+	return 0
+}
+
+func (x *Data_SpiceDb_DeleteGuardrails) GetDryRunSampleSize() uint32 {
+	if x != nil {
+		return x.DryRunSampleSize
+	}
+	return 0
+}
+
+type Data_SpiceDb_TLS struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CA bundle SpiceDB's certificate is verified against, defaults to the system roots
+	CaFile string `protobuf:"bytes,1,opt,name=caFile,proto3" json:"caFile,omitempty"`
+	// client certificate presented to SpiceDB for mTLS
+	CertFile string `protobuf:"bytes,2,opt,name=certFile,proto3" json:"certFile,omitempty"`
+	KeyFile  string `protobuf:"bytes,3,opt,name=keyFile,proto3" json:"keyFile,omitempty"`
+	// overrides the server name used for SNI and certificate verification
+	ServerName    string `protobuf:"bytes,4,opt,name=serverName,proto3" json:"serverName,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_TLS) Reset() {
+	*x = Data_SpiceDb_TLS{}
+	mi := &file_conf_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_TLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_TLS) ProtoMessage() {}
+
+func (x *Data_SpiceDb_TLS) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_TLS.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_TLS) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 2}
+}
+
+func (x *Data_SpiceDb_TLS) GetCaFile() string {
+	if x != nil {
+		return x.CaFile
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb_TLS) GetCertFile() string {
+	if x != nil {
+		return x.CertFile
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb_TLS) GetKeyFile() string {
+	if x != nil {
+		return x.KeyFile
+	}
+	return ""
+}
+
+func (x *Data_SpiceDb_TLS) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+type Data_SpiceDb_Connection struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// deadline of unary calls whose caller set none or a later one, zero disables it
+	Timeout *durationpb.Duration `protobuf:"bytes,1,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// queue calls while SpiceDB is unreachable instead of failing them immediately, bounded by the call deadline
+	WaitForReady   bool                                    `protobuf:"varint,2,opt,name=waitForReady,proto3" json:"waitForReady,omitempty"`
+	Retry          *Data_SpiceDb_Connection_Retry          `protobuf:"bytes,3,opt,name=retry,proto3" json:"retry,omitempty"`
+	CircuitBreaker *Data_SpiceDb_Connection_CircuitBreaker `protobuf:"bytes,4,opt,name=circuitBreaker,proto3" json:"circuitBreaker,omitempty"`
+	Keepalive      *Data_SpiceDb_Connection_Keepalive      `protobuf:"bytes,5,opt,name=keepalive,proto3" json:"keepalive,omitempty"`
+	// number of connections calls are spread over, defaults to 1
+	PoolSize      uint32 `protobuf:"varint,6,opt,name=poolSize,proto3" json:"poolSize,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_Connection) Reset() {
+	*x = Data_SpiceDb_Connection{}
+	mi := &file_conf_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_Connection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_Connection) ProtoMessage() {}
+
+func (x *Data_SpiceDb_Connection) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_Connection.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_Connection) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 3}
+}
+
+func (x *Data_SpiceDb_Connection) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_Connection) GetWaitForReady() bool {
+	if x != nil {
+		return x.WaitForReady
+	}
+	return false
+}
+
+func (x *Data_SpiceDb_Connection) GetRetry() *Data_SpiceDb_Connection_Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_Connection) GetCircuitBreaker() *Data_SpiceDb_Connection_CircuitBreaker {
+	if x != nil {
+		return x.CircuitBreaker
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_Connection) GetKeepalive() *Data_SpiceDb_Connection_Keepalive {
+	if x != nil {
+		return x.Keepalive
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_Connection) GetPoolSize() uint32 {
+	if x != nil {
+		return x.PoolSize
+	}
+	return 0
+}
+
+type Data_SpiceDb_Preflight struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// check at startup that SpiceDB is reachable, accepts the token and has the schema, refusing to start otherwise
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// deadline of all preflight checks together, defaults to 30s
+	Timeout       *durationpb.Duration `protobuf:"bytes,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_Preflight) Reset() {
+	*x = Data_SpiceDb_Preflight{}
+	mi := &file_conf_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_Preflight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_Preflight) ProtoMessage() {}
+
+func (x *Data_SpiceDb_Preflight) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_Preflight.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_Preflight) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 4}
+}
+
+func (x *Data_SpiceDb_Preflight) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Data_SpiceDb_Preflight) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type Data_SpiceDb_Connection_Retry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// attempts of idempotent calls (checks, lookups and reads) including the first, at most 5, 0 or 1 disables
+	// retries. Only calls failing with UNAVAILABLE are retried.
+	MaxAttempts uint32 `protobuf:"varint,1,opt,name=maxAttempts,proto3" json:"maxAttempts,omitempty"`
+	// the backoff before each retry is randomized up to initialBackoff, growing 2x per attempt up to maxBackoff
+	InitialBackoff *durationpb.Duration `protobuf:"bytes,2,opt,name=initialBackoff,proto3" json:"initialBackoff,omitempty"`
+	MaxBackoff     *durationpb.Duration `protobuf:"bytes,3,opt,name=maxBackoff,proto3" json:"maxBackoff,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_Connection_Retry) Reset() {
+	*x = Data_SpiceDb_Connection_Retry{}
+	mi := &file_conf_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_Connection_Retry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_Connection_Retry) ProtoMessage() {}
+
+func (x *Data_SpiceDb_Connection_Retry) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_Connection_Retry.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_Connection_Retry) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 3, 0}
+}
+
+func (x *Data_SpiceDb_Connection_Retry) GetMaxAttempts() uint32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *Data_SpiceDb_Connection_Retry) GetInitialBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.InitialBackoff
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_Connection_Retry) GetMaxBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.MaxBackoff
+	}
+	return nil
+}
+
+type Data_SpiceDb_Connection_CircuitBreaker struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// consecutive calls failing with UNAVAILABLE or DEADLINE_EXCEEDED that open the breaker, zero disables it.
+	// While open calls fail fast and readiness reports unavailable.
+	FailureThreshold uint32 `protobuf:"varint,1,opt,name=failureThreshold,proto3" json:"failureThreshold,omitempty"`
+	// how long the breaker stays open before a trial call is let through, defaults to 10s
+	OpenDuration  *durationpb.Duration `protobuf:"bytes,2,opt,name=openDuration,proto3" json:"openDuration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_Connection_CircuitBreaker) Reset() {
+	*x = Data_SpiceDb_Connection_CircuitBreaker{}
+	mi := &file_conf_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_Connection_CircuitBreaker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_Connection_CircuitBreaker) ProtoMessage() {}
+
+func (x *Data_SpiceDb_Connection_CircuitBreaker) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_Connection_CircuitBreaker.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_Connection_CircuitBreaker) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 3, 1}
+}
+
+func (x *Data_SpiceDb_Connection_CircuitBreaker) GetFailureThreshold() uint32 {
+	if x != nil {
+		return x.FailureThreshold
+	}
+	return 0
+}
+
+func (x *Data_SpiceDb_Connection_CircuitBreaker) GetOpenDuration() *durationpb.Duration {
+	if x != nil {
+		return x.OpenDuration
+	}
+	return nil
+}
+
+type Data_SpiceDb_Connection_Keepalive struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// interval of pings on an idle connection, zero disables keepalive
+	Time *durationpb.Duration `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// how long to wait for a ping acknowledgement before closing the connection
+	Timeout             *durationpb.Duration `protobuf:"bytes,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	PermitWithoutStream bool                 `protobuf:"varint,3,opt,name=permitWithoutStream,proto3" json:"permitWithoutStream,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Data_SpiceDb_Connection_Keepalive) Reset() {
+	*x = Data_SpiceDb_Connection_Keepalive{}
+	mi := &file_conf_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_SpiceDb_Connection_Keepalive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_SpiceDb_Connection_Keepalive) ProtoMessage() {}
+
+func (x *Data_SpiceDb_Connection_Keepalive) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_SpiceDb_Connection_Keepalive.ProtoReflect.Descriptor instead.
+func (*Data_SpiceDb_Connection_Keepalive) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 0, 3, 2}
+}
+
+func (x *Data_SpiceDb_Connection_Keepalive) GetTime() *durationpb.Duration {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_Connection_Keepalive) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Data_SpiceDb_Connection_Keepalive) GetPermitWithoutStream() bool {
+	if x != nil {
+		return x.PermitWithoutStream
+	}
+	return false
+}
+
+type Data_Events_Kafka struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// base URL of a Kafka REST proxy (v2 API), e.g. Confluent REST Proxy or Redpanda's HTTP proxy
+	RestProxyUrl string `protobuf:"bytes,1,opt,name=restProxyUrl,proto3" json:"restProxyUrl,omitempty"`
+	Topic        string `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	// deadline of each produce request, defaults to 10s
+	Timeout       *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data_Events_Kafka) Reset() {
+	*x = Data_Events_Kafka{}
+	mi := &file_conf_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data_Events_Kafka) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data_Events_Kafka) ProtoMessage() {}
+
+func (x *Data_Events_Kafka) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data_Events_Kafka.ProtoReflect.Descriptor instead.
+func (*Data_Events_Kafka) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{2, 1, 0}
+}
+
+func (x *Data_Events_Kafka) GetRestProxyUrl() string {
+	if x != nil {
+		return x.RestProxyUrl
+	}
+	return ""
+}
+
+func (x *Data_Events_Kafka) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Data_Events_Kafka) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+var File_conf_proto protoreflect.FileDescriptor
+
+const file_conf_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"conf.proto\x12\n" +
+	"kratos.api\x1a\x1egoogle/protobuf/duration.proto\"]\n" +
+	"\tBootstrap\x12*\n" +
+	"\x06server\x18\x01 \x01(\v2\x12.kratos.api.ServerR\x06server\x12$\n" +
+	"\x04data\x18\x02 \x01(\v2\x10.kratos.api.DataR\x04data\"\xf7&\n" +
+	"\x06Server\x12+\n" +
+	"\x04http\x18\x01 \x01(\v2\x17.kratos.api.Server.HTTPR\x04http\x12+\n" +
+	"\x04grpc\x18\x02 \x01(\v2\x17.kratos.api.Server.GRPCR\x04grpc\x12%\n" +
+	"\vminLogLevel\x18\x03 \x01(\tH\x00R\vminLogLevel\x88\x01\x01\x12+\n" +
+	"\x04auth\x18\x04 \x01(\v2\x17.kratos.api.Server.AuthR\x04auth\x12:\n" +
+	"\trateLimit\x18\x05 \x01(\v2\x1c.kratos.api.Server.RateLimitR\trateLimit\x12(\n" +
+	"\x03tls\x18\x06 \x01(\v2\x16.kratos.api.Server.TLSR\x03tls\x121\n" +
+	"\x06health\x18\a \x01(\v2\x19.kratos.api.Server.HealthR\x06health\x127\n" +
+	"\bconsumer\x18\b \x01(\v2\x1b.kratos.api.Server.ConsumerR\bconsumer\x12.\n" +
+	"\x05audit\x18\t \x01(\v2\x18.kratos.api.Server.AuditR\x05audit\x124\n" +
+	"\atracing\x18\n" +
+	" \x01(\v2\x1a.kratos.api.Server.TracingR\atracing\x124\n" +
+	"\ametrics\x18\v \x01(\v2\x1a.kratos.api.Server.MetricsR\ametrics\x12Z\n" +
+	"\x12resourceAttributes\x18\f \x03(\v2*.kratos.api.Server.ResourceAttributesEntryR\x12resourceAttributes\x12C\n" +
+	"\fslowRequests\x18\r \x01(\v2\x1f.kratos.api.Server.SlowRequestsR\fslowRequests\x12C\n" +
+	"\flogRedaction\x18\x0e \x01(\v2\x1f.kratos.api.Server.LogRedactionR\flogRedaction\x1a\x89\x01\n" +
+	"\x04HTTP\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x1e\n" +
+	"\n" +
+	"pathprefix\x18\x04 \x01(\tR\n" +
+	"pathprefix\x1ai\n" +
+	"\x04GRPC\x12\x18\n" +
+	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1a\xc6\x05\n" +
+	"\x04Auth\x12\x1e\n" +
+	"\n" +
+	"enableAuth\x18\x01 \x01(\bR\n" +
 	"enableAuth\x12\x18\n" +
-	"\ajwksUrl\x18\x02 \x01(\tR\ajwksUrlB\x0e\n" +
-	"\f_minLogLevel\"\xf8\x01\n" +
+	"\ajwksUrl\x18\x02 \x01(\tR\ajwksUrl\x12\x1e\n" +
+	"\n" +
+	"jwksIssuer\x18\b \x01(\tR\n" +
+	"jwksIssuer\x12$\n" +
+	"\rjwksAudiences\x18\t \x03(\tR\rjwksAudiences\x12 \n" +
+	"\venableAuthz\x18\x03 \x01(\bR\venableAuthz\x12:\n" +
+	"\bpolicies\x18\x04 \x03(\v2\x1e.kratos.api.Server.Auth.PolicyR\bpolicies\x128\n" +
+	"\aissuers\x18\x05 \x03(\v2\x1e.kratos.api.Server.Auth.IssuerR\aissuers\x12K\n" +
+	"\x13jwksRefreshInterval\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x13jwksRefreshInterval\x120\n" +
+	"\x13allowClientCertAuth\x18\a \x01(\bR\x13allowClientCertAuth\x1a\xac\x01\n" +
+	"\x06Policy\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x1a\n" +
+	"\bclientId\x18\x02 \x01(\tR\bclientId\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\x12\x14\n" +
+	"\x05roles\x18\x04 \x03(\tR\x05roles\x12\x1e\n" +
+	"\n" +
+	"operations\x18\x05 \x03(\tR\n" +
+	"operations\x12\x1e\n" +
+	"\n" +
+	"namespaces\x18\x06 \x03(\tR\n" +
+	"namespaces\x1ax\n" +
+	"\x06Issuer\x12\x16\n" +
+	"\x06issuer\x18\x01 \x01(\tR\x06issuer\x12\x18\n" +
+	"\ajwksUrl\x18\x02 \x01(\tR\ajwksUrl\x12\x1c\n" +
+	"\taudiences\x18\x03 \x03(\tR\taudiences\x12\x1e\n" +
+	"\n" +
+	"algorithms\x18\x04 \x03(\tR\n" +
+	"algorithms\x1a\xab\x02\n" +
+	"\tRateLimit\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12,\n" +
+	"\x11requestsPerSecond\x18\x02 \x01(\x01R\x11requestsPerSecond\x12\x14\n" +
+	"\x05burst\x18\x03 \x01(\rR\x05burst\x127\n" +
+	"\x05rules\x18\x04 \x03(\v2!.kratos.api.Server.RateLimit.RuleR\x05rules\x1a\x86\x01\n" +
+	"\x04Rule\x12\x1c\n" +
+	"\toperation\x18\x01 \x01(\tR\toperation\x12\x1c\n" +
+	"\tprincipal\x18\x02 \x01(\tR\tprincipal\x12,\n" +
+	"\x11requestsPerSecond\x18\x03 \x01(\x01R\x11requestsPerSecond\x12\x14\n" +
+	"\x05burst\x18\x04 \x01(\rR\x05burst\x1a\xa9\x01\n" +
+	"\x03TLS\x12\x1a\n" +
+	"\bcertFile\x18\x01 \x01(\tR\bcertFile\x12\x18\n" +
+	"\akeyFile\x18\x02 \x01(\tR\akeyFile\x12\"\n" +
+	"\fclientCaFile\x18\x03 \x01(\tR\fclientCaFile\x12\x1e\n" +
+	"\n" +
+	"clientAuth\x18\x04 \x01(\tR\n" +
+	"clientAuth\x12(\n" +
+	"\x0fclientPrincipal\x18\x05 \x01(\tR\x0fclientPrincipal\x1a\xe0\x01\n" +
+	"\x06Health\x12?\n" +
+	"\rprobeInterval\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\rprobeInterval\x12=\n" +
+	"\fprobeTimeout\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\fprobeTimeout\x12*\n" +
+	"\x10failureThreshold\x18\x03 \x01(\rR\x10failureThreshold\x12*\n" +
+	"\x10successThreshold\x18\x04 \x01(\rR\x10successThreshold\x1a\xbe\x02\n" +
+	"\bConsumer\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\"\n" +
+	"\frestProxyUrl\x18\x02 \x01(\tR\frestProxyUrl\x12\x14\n" +
+	"\x05topic\x18\x03 \x01(\tR\x05topic\x12\x14\n" +
+	"\x05group\x18\x04 \x01(\tR\x05group\x12\x16\n" +
+	"\x06format\x18\x05 \x01(\tR\x06format\x12\x16\n" +
+	"\x06lockId\x18\x06 \x01(\tR\x06lockId\x12\x1c\n" +
+	"\tbatchSize\x18\a \x01(\rR\tbatchSize\x12;\n" +
+	"\vpollTimeout\x18\b \x01(\v2\x19.google.protobuf.DurationR\vpollTimeout\x12=\n" +
+	"\fretryBackoff\x18\t \x01(\v2\x19.google.protobuf.DurationR\fretryBackoff\x1a\xe8\x06\n" +
+	"\x05Audit\x12\"\n" +
+	"\fincludeReads\x18\x01 \x01(\bR\fincludeReads\x123\n" +
+	"\x05sinks\x18\x02 \x03(\v2\x1d.kratos.api.Server.Audit.SinkR\x05sinks\x12\x1c\n" +
+	"\tbufferDir\x18\x03 \x01(\tR\tbufferDir\x12\x1e\n" +
+	"\n" +
+	"bufferSize\x18\x04 \x01(\rR\n" +
+	"bufferSize\x12\x1c\n" +
+	"\tbatchSize\x18\x05 \x01(\rR\tbatchSize\x12=\n" +
+	"\fretryBackoff\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\fretryBackoff\x12B\n" +
+	"\n" +
+	"redactions\x18\a \x03(\v2\".kratos.api.Server.Audit.RedactionR\n" +
+	"redactions\x12\"\n" +
+	"\ftupleDetails\x18\b \x01(\bR\ftupleDetails\x12(\n" +
+	"\x0fmaxTupleDetails\x18\t \x01(\rR\x0fmaxTupleDetails\x12*\n" +
+	"\x10redactionHashKey\x18\n" +
+	" \x01(\tR\x10redactionHashKey\x122\n" +
+	"\x14redactionHashKeyFile\x18\v \x01(\tR\x14redactionHashKeyFile\x1a\xbd\x02\n" +
+	"\x04Sink\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12\x10\n" +
+	"\x03tag\x18\x05 \x01(\tR\x03tag\x12\x10\n" +
+	"\x03url\x18\x06 \x01(\tR\x03url\x12D\n" +
+	"\aheaders\x18\a \x03(\v2*.kratos.api.Server.Audit.Sink.HeadersEntryR\aheaders\x123\n" +
+	"\atimeout\x18\b \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\tRedaction\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x1a\xad\x02\n" +
+	"\aTracing\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x1a\n" +
+	"\bendpoint\x18\x03 \x01(\tR\bendpoint\x12\x1a\n" +
+	"\binsecure\x18\x04 \x01(\bR\binsecure\x12A\n" +
+	"\aheaders\x18\x05 \x03(\v2'.kratos.api.Server.Tracing.HeadersEntryR\aheaders\x12%\n" +
+	"\vsampleRatio\x18\x06 \x01(\x01H\x00R\vsampleRatio\x88\x01\x01\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0e\n" +
+	"\f_sampleRatio\x1a\xcf\x02\n" +
+	"\aMetrics\x12\x1c\n" +
+	"\texporters\x18\x01 \x03(\tR\texporters\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x1a\n" +
+	"\bendpoint\x18\x03 \x01(\tR\bendpoint\x12\x1a\n" +
+	"\binsecure\x18\x04 \x01(\bR\binsecure\x12A\n" +
+	"\aheaders\x18\x05 \x03(\v2'.kratos.api.Server.Metrics.HeadersEntryR\aheaders\x125\n" +
+	"\binterval\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\binterval\x12\x1c\n" +
+	"\texemplars\x18\a \x01(\bR\texemplars\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aE\n" +
+	"\x17ResourceAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a\x8f\x03\n" +
+	"\fSlowRequests\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x127\n" +
+	"\tthreshold\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\tthreshold\x12%\n" +
+	"\vsampleRatio\x18\x03 \x01(\x01H\x00R\vsampleRatio\x88\x01\x01\x12:\n" +
+	"\x05rules\x18\x04 \x03(\v2$.kratos.api.Server.SlowRequests.RuleR\x05rules\x12\"\n" +
+	"\fmaxPerSecond\x18\x05 \x01(\x01R\fmaxPerSecond\x1a\x94\x01\n" +
+	"\x04Rule\x12\x1c\n" +
+	"\toperation\x18\x01 \x01(\tR\toperation\x127\n" +
+	"\tthreshold\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\tthreshold\x12%\n" +
+	"\vsampleRatio\x18\x03 \x01(\x01H\x00R\vsampleRatio\x88\x01\x01B\x0e\n" +
+	"\f_sampleRatioB\x0e\n" +
+	"\f_sampleRatio\x1a\xd6\x01\n" +
+	"\fLogRedaction\x12:\n" +
+	"\x05rules\x18\x01 \x03(\v2$.kratos.api.Server.LogRedaction.RuleR\x05rules\x12\x18\n" +
+	"\ahashKey\x18\x02 \x01(\tR\ahashKey\x12 \n" +
+	"\vhashKeyFile\x18\x03 \x01(\tR\vhashKeyFile\x1aN\n" +
+	"\x04Rule\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06actionB\x0e\n" +
+	"\f_minLogLevel\"\xa2\x14\n" +
 	"\x04Data\x122\n" +
-	"\aspiceDb\x18\x01 \x01(\v2\x18.kratos.api.Data.SpiceDbR\aspiceDb\x1a\xbb\x01\n" +
+	"\aspiceDb\x18\x01 \x01(\v2\x18.kratos.api.Data.SpiceDbR\aspiceDb\x12/\n" +
+	"\x06events\x18\x02 \x01(\v2\x17.kratos.api.Data.EventsR\x06events\x1a\xde\x0f\n" +
 	"\aSpiceDb\x12\x16\n" +
 	"\x06useTLS\x18\x01 \x01(\bR\x06useTLS\x12\x1a\n" +
 	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12\x14\n" +
@@ -492,7 +2912,79 @@ const file_conf_proto_rawDesc = "" +
 	"\n" +
 	"schemaFile\x18\x05 \x01(\tR\n" +
 	"schemaFile\x12(\n" +
-	"\x0ffullyConsistent\x18\x06 \x01(\bR\x0ffullyConsistentB<Z:github.com/project-kessel/relations-api/internal/conf;confb\x06proto3"
+	"\x0ffullyConsistent\x18\x06 \x01(\bR\x0ffullyConsistent\x12U\n" +
+	"\x10consistencyToken\x18\a \x01(\v2).kratos.api.Data.SpiceDb.ConsistencyTokenR\x10consistencyToken\x12U\n" +
+	"\x10deleteGuardrails\x18\b \x01(\v2).kratos.api.Data.SpiceDb.DeleteGuardrailsR\x10deleteGuardrails\x12.\n" +
+	"\x03tls\x18\t \x01(\v2\x1c.kratos.api.Data.SpiceDb.TLSR\x03tls\x12\x1e\n" +
+	"\n" +
+	"watchFiles\x18\n" +
+	" \x01(\bR\n" +
+	"watchFiles\x12C\n" +
+	"\n" +
+	"connection\x18\v \x01(\v2#.kratos.api.Data.SpiceDb.ConnectionR\n" +
+	"connection\x12\x1e\n" +
+	"\n" +
+	"schemaMode\x18\f \x01(\tR\n" +
+	"schemaMode\x12@\n" +
+	"\tpreflight\x18\r \x01(\v2\".kratos.api.Data.SpiceDb.PreflightR\tpreflight\x1a\x8d\x02\n" +
+	"\x10ConsistencyToken\x12\x1c\n" +
+	"\tbackendId\x18\x01 \x01(\tR\tbackendId\x12\x14\n" +
+	"\x05shard\x18\x02 \x01(\tR\x05shard\x12\x1e\n" +
+	"\n" +
+	"signingKey\x18\x03 \x01(\tR\n" +
+	"signingKey\x12&\n" +
+	"\x0esigningKeyFile\x18\x04 \x01(\tR\x0esigningKeyFile\x121\n" +
+	"\x06maxAge\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x06maxAge\x12.\n" +
+	"\x12acceptLegacyTokens\x18\x06 \x01(\bR\x12acceptLegacyTokens\x12\x1a\n" +
+	"\bunsigned\x18\a \x01(\bR\bunsigned\x1ab\n" +
+	"\x10DeleteGuardrails\x12\"\n" +
+	"\fmaxDeletions\x18\x01 \x01(\rR\fmaxDeletions\x12*\n" +
+	"\x10dryRunSampleSize\x18\x02 \x01(\rR\x10dryRunSampleSize\x1as\n" +
+	"\x03TLS\x12\x16\n" +
+	"\x06caFile\x18\x01 \x01(\tR\x06caFile\x12\x1a\n" +
+	"\bcertFile\x18\x02 \x01(\tR\bcertFile\x12\x18\n" +
+	"\akeyFile\x18\x03 \x01(\tR\akeyFile\x12\x1e\n" +
+	"\n" +
+	"serverName\x18\x04 \x01(\tR\n" +
+	"serverName\x1a\xb6\x06\n" +
+	"\n" +
+	"Connection\x123\n" +
+	"\atimeout\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\"\n" +
+	"\fwaitForReady\x18\x02 \x01(\bR\fwaitForReady\x12?\n" +
+	"\x05retry\x18\x03 \x01(\v2).kratos.api.Data.SpiceDb.Connection.RetryR\x05retry\x12Z\n" +
+	"\x0ecircuitBreaker\x18\x04 \x01(\v22.kratos.api.Data.SpiceDb.Connection.CircuitBreakerR\x0ecircuitBreaker\x12K\n" +
+	"\tkeepalive\x18\x05 \x01(\v2-.kratos.api.Data.SpiceDb.Connection.KeepaliveR\tkeepalive\x12\x1a\n" +
+	"\bpoolSize\x18\x06 \x01(\rR\bpoolSize\x1a\xa7\x01\n" +
+	"\x05Retry\x12 \n" +
+	"\vmaxAttempts\x18\x01 \x01(\rR\vmaxAttempts\x12A\n" +
+	"\x0einitialBackoff\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x0einitialBackoff\x129\n" +
+	"\n" +
+	"maxBackoff\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"maxBackoff\x1a{\n" +
+	"\x0eCircuitBreaker\x12*\n" +
+	"\x10failureThreshold\x18\x01 \x01(\rR\x10failureThreshold\x12=\n" +
+	"\fopenDuration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\fopenDuration\x1a\xa1\x01\n" +
+	"\tKeepalive\x12-\n" +
+	"\x04time\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x04time\x123\n" +
+	"\atimeout\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x120\n" +
+	"\x13permitWithoutStream\x18\x03 \x01(\bR\x13permitWithoutStream\x1aZ\n" +
+	"\tPreflight\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x123\n" +
+	"\atimeout\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1a\xd3\x03\n" +
+	"\x06Events\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1c\n" +
+	"\toutboxDir\x18\x02 \x01(\tR\toutboxDir\x12\x12\n" +
+	"\x04sink\x18\x03 \x01(\tR\x04sink\x12\x1a\n" +
+	"\bfilePath\x18\x04 \x01(\tR\bfilePath\x123\n" +
+	"\x05kafka\x18\x05 \x01(\v2\x1d.kratos.api.Data.Events.KafkaR\x05kafka\x12\x1c\n" +
+	"\tbatchSize\x18\x06 \x01(\rR\tbatchSize\x12=\n" +
+	"\fretryBackoff\x18\a \x01(\v2\x19.google.protobuf.DurationR\fretryBackoff\x12\x16\n" +
+	"\x06lockId\x18\b \x01(\tR\x06lockId\x12?\n" +
+	"\rleaseDuration\x18\t \x01(\v2\x19.google.protobuf.DurationR\rleaseDuration\x1av\n" +
+	"\x05Kafka\x12\"\n" +
+	"\frestProxyUrl\x18\x01 \x01(\tR\frestProxyUrl\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeoutB<Z:github.com/project-kessel/relations-api/internal/conf;confb\x06proto3"
 
 var (
 	file_conf_proto_rawDescOnce sync.Once
@@ -506,31 +2998,112 @@ func file_conf_proto_rawDescGZIP() []byte {
 	return file_conf_proto_rawDescData
 }
 
-var file_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_conf_proto_goTypes = []any{
-	(*Bootstrap)(nil),           // 0: kratos.api.Bootstrap
-	(*Server)(nil),              // 1: kratos.api.Server
-	(*Data)(nil),                // 2: kratos.api.Data
-	(*Server_HTTP)(nil),         // 3: kratos.api.Server.HTTP
-	(*Server_GRPC)(nil),         // 4: kratos.api.Server.GRPC
-	(*Server_Auth)(nil),         // 5: kratos.api.Server.Auth
-	(*Data_SpiceDb)(nil),        // 6: kratos.api.Data.SpiceDb
-	(*durationpb.Duration)(nil), // 7: google.protobuf.Duration
+	(*Bootstrap)(nil),                              // 0: kratos.api.Bootstrap
+	(*Server)(nil),                                 // 1: kratos.api.Server
+	(*Data)(nil),                                   // 2: kratos.api.Data
+	(*Server_HTTP)(nil),                            // 3: kratos.api.Server.HTTP
+	(*Server_GRPC)(nil),                            // 4: kratos.api.Server.GRPC
+	(*Server_Auth)(nil),                            // 5: kratos.api.Server.Auth
+	(*Server_RateLimit)(nil),                       // 6: kratos.api.Server.RateLimit
+	(*Server_TLS)(nil),                             // 7: kratos.api.Server.TLS
+	(*Server_Health)(nil),                          // 8: kratos.api.Server.Health
+	(*Server_Consumer)(nil),                        // 9: kratos.api.Server.Consumer
+	(*Server_Audit)(nil),                           // 10: kratos.api.Server.Audit
+	(*Server_Tracing)(nil),                         // 11: kratos.api.Server.Tracing
+	(*Server_Metrics)(nil),                         // 12: kratos.api.Server.Metrics
+	nil,                                            // 13: kratos.api.Server.ResourceAttributesEntry
+	(*Server_SlowRequests)(nil),                    // 14: kratos.api.Server.SlowRequests
+	(*Server_LogRedaction)(nil),                    // 15: kratos.api.Server.LogRedaction
+	(*Server_Auth_Policy)(nil),                     // 16: kratos.api.Server.Auth.Policy
+	(*Server_Auth_Issuer)(nil),                     // 17: kratos.api.Server.Auth.Issuer
+	(*Server_RateLimit_Rule)(nil),                  // 18: kratos.api.Server.RateLimit.Rule
+	(*Server_Audit_Sink)(nil),                      // 19: kratos.api.Server.Audit.Sink
+	(*Server_Audit_Redaction)(nil),                 // 20: kratos.api.Server.Audit.Redaction
+	nil,                                            // 21: kratos.api.Server.Audit.Sink.HeadersEntry
+	nil,                                            // 22: kratos.api.Server.Tracing.HeadersEntry
+	nil,                                            // 23: kratos.api.Server.Metrics.HeadersEntry
+	(*Server_SlowRequests_Rule)(nil),               // 24: kratos.api.Server.SlowRequests.Rule
+	(*Server_LogRedaction_Rule)(nil),               // 25: kratos.api.Server.LogRedaction.Rule
+	(*Data_SpiceDb)(nil),                           // 26: kratos.api.Data.SpiceDb
+	(*Data_Events)(nil),                            // 27: kratos.api.Data.Events
+	(*Data_SpiceDb_ConsistencyToken)(nil),          // 28: kratos.api.Data.SpiceDb.ConsistencyToken
+	(*Data_SpiceDb_DeleteGuardrails)(nil),          // 29: kratos.api.Data.SpiceDb.DeleteGuardrails
+	(*Data_SpiceDb_TLS)(nil),                       // 30: kratos.api.Data.SpiceDb.TLS
+	(*Data_SpiceDb_Connection)(nil),                // 31: kratos.api.Data.SpiceDb.Connection
+	(*Data_SpiceDb_Preflight)(nil),                 // 32: kratos.api.Data.SpiceDb.Preflight
+	(*Data_SpiceDb_Connection_Retry)(nil),          // 33: kratos.api.Data.SpiceDb.Connection.Retry
+	(*Data_SpiceDb_Connection_CircuitBreaker)(nil), // 34: kratos.api.Data.SpiceDb.Connection.CircuitBreaker
+	(*Data_SpiceDb_Connection_Keepalive)(nil),      // 35: kratos.api.Data.SpiceDb.Connection.Keepalive
+	(*Data_Events_Kafka)(nil),                      // 36: kratos.api.Data.Events.Kafka
+	(*durationpb.Duration)(nil),                    // 37: google.protobuf.Duration
 }
 var file_conf_proto_depIdxs = []int32{
-	1, // 0: kratos.api.Bootstrap.server:type_name -> kratos.api.Server
-	2, // 1: kratos.api.Bootstrap.data:type_name -> kratos.api.Data
-	3, // 2: kratos.api.Server.http:type_name -> kratos.api.Server.HTTP
-	4, // 3: kratos.api.Server.grpc:type_name -> kratos.api.Server.GRPC
-	5, // 4: kratos.api.Server.auth:type_name -> kratos.api.Server.Auth
-	6, // 5: kratos.api.Data.spiceDb:type_name -> kratos.api.Data.SpiceDb
-	7, // 6: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
-	7, // 7: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	1,  // 0: kratos.api.Bootstrap.server:type_name -> kratos.api.Server
+	2,  // 1: kratos.api.Bootstrap.data:type_name -> kratos.api.Data
+	3,  // 2: kratos.api.Server.http:type_name -> kratos.api.Server.HTTP
+	4,  // 3: kratos.api.Server.grpc:type_name -> kratos.api.Server.GRPC
+	5,  // 4: kratos.api.Server.auth:type_name -> kratos.api.Server.Auth
+	6,  // 5: kratos.api.Server.rateLimit:type_name -> kratos.api.Server.RateLimit
+	7,  // 6: kratos.api.Server.tls:type_name -> kratos.api.Server.TLS
+	8,  // 7: kratos.api.Server.health:type_name -> kratos.api.Server.Health
+	9,  // 8: kratos.api.Server.consumer:type_name -> kratos.api.Server.Consumer
+	10, // 9: kratos.api.Server.audit:type_name -> kratos.api.Server.Audit
+	11, // 10: kratos.api.Server.tracing:type_name -> kratos.api.Server.Tracing
+	12, // 11: kratos.api.Server.metrics:type_name -> kratos.api.Server.Metrics
+	13, // 12: kratos.api.Server.resourceAttributes:type_name -> kratos.api.Server.ResourceAttributesEntry
+	14, // 13: kratos.api.Server.slowRequests:type_name -> kratos.api.Server.SlowRequests
+	15, // 14: kratos.api.Server.logRedaction:type_name -> kratos.api.Server.LogRedaction
+	26, // 15: kratos.api.Data.spiceDb:type_name -> kratos.api.Data.SpiceDb
+	27, // 16: kratos.api.Data.events:type_name -> kratos.api.Data.Events
+	37, // 17: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
+	37, // 18: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
+	16, // 19: kratos.api.Server.Auth.policies:type_name -> kratos.api.Server.Auth.Policy
+	17, // 20: kratos.api.Server.Auth.issuers:type_name -> kratos.api.Server.Auth.Issuer
+	37, // 21: kratos.api.Server.Auth.jwksRefreshInterval:type_name -> google.protobuf.Duration
+	18, // 22: kratos.api.Server.RateLimit.rules:type_name -> kratos.api.Server.RateLimit.Rule
+	37, // 23: kratos.api.Server.Health.probeInterval:type_name -> google.protobuf.Duration
+	37, // 24: kratos.api.Server.Health.probeTimeout:type_name -> google.protobuf.Duration
+	37, // 25: kratos.api.Server.Consumer.pollTimeout:type_name -> google.protobuf.Duration
+	37, // 26: kratos.api.Server.Consumer.retryBackoff:type_name -> google.protobuf.Duration
+	19, // 27: kratos.api.Server.Audit.sinks:type_name -> kratos.api.Server.Audit.Sink
+	37, // 28: kratos.api.Server.Audit.retryBackoff:type_name -> google.protobuf.Duration
+	20, // 29: kratos.api.Server.Audit.redactions:type_name -> kratos.api.Server.Audit.Redaction
+	22, // 30: kratos.api.Server.Tracing.headers:type_name -> kratos.api.Server.Tracing.HeadersEntry
+	23, // 31: kratos.api.Server.Metrics.headers:type_name -> kratos.api.Server.Metrics.HeadersEntry
+	37, // 32: kratos.api.Server.Metrics.interval:type_name -> google.protobuf.Duration
+	37, // 33: kratos.api.Server.SlowRequests.threshold:type_name -> google.protobuf.Duration
+	24, // 34: kratos.api.Server.SlowRequests.rules:type_name -> kratos.api.Server.SlowRequests.Rule
+	25, // 35: kratos.api.Server.LogRedaction.rules:type_name -> kratos.api.Server.LogRedaction.Rule
+	21, // 36: kratos.api.Server.Audit.Sink.headers:type_name -> kratos.api.Server.Audit.Sink.HeadersEntry
+	37, // 37: kratos.api.Server.Audit.Sink.timeout:type_name -> google.protobuf.Duration
+	37, // 38: kratos.api.Server.SlowRequests.Rule.threshold:type_name -> google.protobuf.Duration
+	28, // 39: kratos.api.Data.SpiceDb.consistencyToken:type_name -> kratos.api.Data.SpiceDb.ConsistencyToken
+	29, // 40: kratos.api.Data.SpiceDb.deleteGuardrails:type_name -> kratos.api.Data.SpiceDb.DeleteGuardrails
+	30, // 41: kratos.api.Data.SpiceDb.tls:type_name -> kratos.api.Data.SpiceDb.TLS
+	31, // 42: kratos.api.Data.SpiceDb.connection:type_name -> kratos.api.Data.SpiceDb.Connection
+	32, // 43: kratos.api.Data.SpiceDb.preflight:type_name -> kratos.api.Data.SpiceDb.Preflight
+	36, // 44: kratos.api.Data.Events.kafka:type_name -> kratos.api.Data.Events.Kafka
+	37, // 45: kratos.api.Data.Events.retryBackoff:type_name -> google.protobuf.Duration
+	37, // 46: kratos.api.Data.Events.leaseDuration:type_name -> google.protobuf.Duration
+	37, // 47: kratos.api.Data.SpiceDb.ConsistencyToken.maxAge:type_name -> google.protobuf.Duration
+	37, // 48: kratos.api.Data.SpiceDb.Connection.timeout:type_name -> google.protobuf.Duration
+	33, // 49: kratos.api.Data.SpiceDb.Connection.retry:type_name -> kratos.api.Data.SpiceDb.Connection.Retry
+	34, // 50: kratos.api.Data.SpiceDb.Connection.circuitBreaker:type_name -> kratos.api.Data.SpiceDb.Connection.CircuitBreaker
+	35, // 51: kratos.api.Data.SpiceDb.Connection.keepalive:type_name -> kratos.api.Data.SpiceDb.Connection.Keepalive
+	37, // 52: kratos.api.Data.SpiceDb.Preflight.timeout:type_name -> google.protobuf.Duration
+	37, // 53: kratos.api.Data.SpiceDb.Connection.Retry.initialBackoff:type_name -> google.protobuf.Duration
+	37, // 54: kratos.api.Data.SpiceDb.Connection.Retry.maxBackoff:type_name -> google.protobuf.Duration
+	37, // 55: kratos.api.Data.SpiceDb.Connection.CircuitBreaker.openDuration:type_name -> google.protobuf.Duration
+	37, // 56: kratos.api.Data.SpiceDb.Connection.Keepalive.time:type_name -> google.protobuf.Duration
+	37, // 57: kratos.api.Data.SpiceDb.Connection.Keepalive.timeout:type_name -> google.protobuf.Duration
+	37, // 58: kratos.api.Data.Events.Kafka.timeout:type_name -> google.protobuf.Duration
+	59, // [59:59] is the sub-list for method output_type
+	59, // [59:59] is the sub-list for method input_type
+	59, // [59:59] is the sub-list for extension type_name
+	59, // [59:59] is the sub-list for extension extendee
+	0,  // [0:59] is the sub-list for field type_name
 }
 
 func init() { file_conf_proto_init() }
@@ -539,13 +3112,16 @@ func file_conf_proto_init() {
 		return
 	}
 	file_conf_proto_msgTypes[1].OneofWrappers = []any{}
+	file_conf_proto_msgTypes[11].OneofWrappers = []any{}
+	file_conf_proto_msgTypes[14].OneofWrappers = []any{}
+	file_conf_proto_msgTypes[24].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_conf_proto_rawDesc), len(file_conf_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
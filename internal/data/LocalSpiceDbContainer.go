@@ -18,6 +18,7 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel/metric/noop"
 )
 
 const (
@@ -178,13 +179,14 @@ func (l *LocalSpiceDbContainer) CreateSpiceDbRepository() (*SpiceDbRepository, e
 	}()
 
 	spiceDbConf := &conf.Data_SpiceDb{
-		UseTLS:          false,
-		Endpoint:        "localhost:" + l.port,
-		Token:           tmpFile.Name(),
-		SchemaFile:      l.schemaLocation,
-		FullyConsistent: FullyConsistent, // Should be inline with our config file
-	}
-	repo, _, err := NewSpiceDbRepository(&conf.Data{SpiceDb: spiceDbConf}, l.logger)
+		UseTLS:           false,
+		Endpoint:         "localhost:" + l.port,
+		Token:            tmpFile.Name(),
+		SchemaFile:       l.schemaLocation,
+		FullyConsistent:  FullyConsistent, // Should be inline with our config file
+		ConsistencyToken: &conf.Data_SpiceDb_ConsistencyToken{SigningKey: randomKey},
+	}
+	repo, _, err := NewSpiceDbRepository(&conf.Data{SpiceDb: spiceDbConf}, noop.NewMeterProvider().Meter(""), nil, l.logger)
 	if err != nil {
 		return nil, err
 	}
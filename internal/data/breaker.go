@@ -0,0 +1,168 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultOpenDuration = 10 * time.Second
+
+var errCircuitOpen = status.Error(codes.Unavailable, "spicedb circuit breaker is open, failing fast")
+
+// circuitBreaker stops calling SpiceDB after a run of consecutive failures indicating it is down or overloaded.
+// Calls fail fast while the breaker is open; once openDuration has passed a single trial call is let through,
+// closing the breaker if it succeeds and reopening it otherwise. A nil breaker lets every call through.
+type circuitBreaker struct {
+	threshold    uint32
+	openDuration time.Duration
+	now          func() time.Time
+	log          *log.Helper
+
+	mu       sync.Mutex
+	failures uint32
+	openedAt time.Time // zero while closed
+	probing  bool
+}
+
+func newCircuitBreaker(threshold uint32, openDuration time.Duration, logger log.Logger) *circuitBreaker {
+	if threshold == 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold:    threshold,
+		openDuration: durationOr(openDuration, defaultOpenDuration),
+		now:          time.Now,
+		log:          log.NewHelper(logger),
+	}
+}
+
+// allow returns errCircuitOpen if the call must fail fast.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.openDuration {
+		return errCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record counts the outcome of a call let through by allow.
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if isCanceled(err) {
+		// the caller gave up, which says nothing about SpiceDB; a canceled trial call lets the next one through
+		b.probing = false
+		return
+	}
+	if !isBackendFailure(err) {
+		if !b.openedAt.IsZero() {
+			b.log.Info("spicedb is reachable again, closing circuit breaker")
+		}
+		b.failures, b.openedAt, b.probing = 0, time.Time{}, false
+		return
+	}
+
+	b.failures++
+	if b.probing || (b.openedAt.IsZero() && b.failures >= b.threshold) {
+		if b.openedAt.IsZero() {
+			b.log.Warnf("spicedb failed %d consecutive calls, opening circuit breaker for %s: %v", b.failures, b.openDuration, err)
+		}
+		b.openedAt, b.probing = b.now(), false
+	}
+}
+
+// isOpen reports whether calls are currently failing fast or waiting on a trial call.
+func (b *circuitBreaker) isOpen() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openedAt.IsZero()
+}
+
+// isBackendFailure reports whether err indicates SpiceDB is unavailable, as opposed to rejecting the request.
+func isBackendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// isCanceled reports whether the call ended because its caller canceled it.
+func isCanceled(err error) bool {
+	return status.Code(err) == codes.Canceled || errors.Is(err, context.Canceled)
+}
+
+func (b *circuitBreaker) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.allow(); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(err)
+		return err
+	}
+}
+
+func (b *circuitBreaker) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if method == v1.WatchService_Watch_FullMethodName {
+			// a watch stream only receives a message once a tuple changes, which may take arbitrarily long, so it
+			// could hold the trial call and keep the breaker open on a quiet system
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		if err := b.allow(); err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			b.record(err)
+			return nil, err
+		}
+		return &breakerClientStream{ClientStream: stream, breaker: b}, nil
+	}
+}
+
+// breakerClientStream records the outcome of a stream when its first message arrives or it ends.
+type breakerClientStream struct {
+	grpc.ClientStream
+	breaker  *circuitBreaker
+	recorded sync.Once
+}
+
+func (s *breakerClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.recorded.Do(func() {
+		// an empty stream ends with io.EOF, which is a success for the breaker but must still reach the caller
+		recordErr := err
+		if errors.Is(recordErr, io.EOF) {
+			recordErr = nil
+		}
+		s.breaker.record(recordErr)
+	})
+	return err
+}
@@ -0,0 +1,167 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestBreaker(threshold uint32) (*circuitBreaker, *time.Time) {
+	now := time.Now()
+	b := newCircuitBreaker(threshold, time.Minute, log.NewStdLogger(io.Discard))
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	b, _ := newTestBreaker(3)
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	b.record(unavailable)
+	b.record(unavailable)
+	b.record(nil) // a success resets the count
+	b.record(unavailable)
+	b.record(unavailable)
+	assert.False(t, b.isOpen())
+	assert.NoError(t, b.allow())
+
+	b.record(status.Error(codes.DeadlineExceeded, "timeout"))
+	assert.True(t, b.isOpen())
+	assert.ErrorIs(t, b.allow(), errCircuitOpen)
+}
+
+func TestCircuitBreaker_RejectedRequestsDoNotCount(t *testing.T) {
+	t.Parallel()
+
+	b, _ := newTestBreaker(1)
+	b.record(status.Error(codes.InvalidArgument, "bad request"))
+	b.record(status.Error(codes.NotFound, "not found"))
+	assert.False(t, b.isOpen())
+}
+
+func TestCircuitBreaker_CanceledCallsAreNeutral(t *testing.T) {
+	t.Parallel()
+
+	b, now := newTestBreaker(2)
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	canceled := status.Error(codes.Canceled, "context canceled")
+
+	b.record(unavailable)
+	b.record(canceled)
+	b.record(unavailable)
+	assert.True(t, b.isOpen(), "a canceled call does not reset the count")
+
+	*now = now.Add(time.Minute)
+	assert.NoError(t, b.allow())
+	b.record(context.Canceled)
+	assert.True(t, b.isOpen(), "a canceled trial call does not close the breaker")
+	assert.NoError(t, b.allow(), "another trial call is let through")
+}
+
+func TestCircuitBreaker_TrialCallClosesOrReopens(t *testing.T) {
+	t.Parallel()
+
+	b, now := newTestBreaker(1)
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	b.record(unavailable)
+	assert.ErrorIs(t, b.allow(), errCircuitOpen)
+
+	*now = now.Add(time.Minute)
+	assert.NoError(t, b.allow(), "a trial call is let through after openDuration")
+	assert.ErrorIs(t, b.allow(), errCircuitOpen, "only one trial call at a time")
+	b.record(unavailable)
+	assert.True(t, b.isOpen())
+	assert.ErrorIs(t, b.allow(), errCircuitOpen, "a failed trial call reopens the breaker")
+
+	*now = now.Add(time.Minute)
+	assert.NoError(t, b.allow())
+	b.record(nil)
+	assert.False(t, b.isOpen())
+	assert.NoError(t, b.allow())
+}
+
+func TestCircuitBreaker_DisabledWithoutThreshold(t *testing.T) {
+	t.Parallel()
+
+	b := newCircuitBreaker(0, 0, log.NewStdLogger(io.Discard))
+	b.record(status.Error(codes.Unavailable, "connection refused"))
+	assert.NoError(t, b.allow())
+	assert.False(t, b.isOpen())
+}
+
+// recvStream is a client stream whose receives return errs in turn.
+type recvStream struct {
+	grpc.ClientStream
+	errs []error
+}
+
+func (s *recvStream) RecvMsg(any) error {
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func openStream(t *testing.T, b *circuitBreaker, errs ...error) grpc.ClientStream {
+	t.Helper()
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &recvStream{errs: errs}, nil
+	}
+	stream, err := b.streamInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, "/test", streamer)
+	require.NoError(t, err)
+	return stream
+}
+
+func TestCircuitBreaker_EmptyStreamEndsWithEOF(t *testing.T) {
+	t.Parallel()
+
+	b, now := newTestBreaker(1)
+	b.record(status.Error(codes.Unavailable, "connection refused"))
+	*now = now.Add(time.Minute)
+
+	stream := openStream(t, b, io.EOF, io.EOF)
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF, "the caller still sees the end of the stream")
+	assert.False(t, b.isOpen(), "an empty stream is a successful trial call")
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF)
+}
+
+func TestCircuitBreaker_StreamRecordsFirstReceiveOnly(t *testing.T) {
+	t.Parallel()
+
+	b, _ := newTestBreaker(1)
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	stream := openStream(t, b, nil, unavailable)
+	assert.NoError(t, stream.RecvMsg(nil))
+	assert.ErrorIs(t, stream.RecvMsg(nil), unavailable)
+	assert.False(t, b.isOpen(), "only the first receive is recorded")
+
+	stream = openStream(t, b, unavailable)
+	assert.ErrorIs(t, stream.RecvMsg(nil), unavailable)
+	assert.True(t, b.isOpen())
+}
+
+func TestCircuitBreaker_WatchStreamsBypassTheBreaker(t *testing.T) {
+	t.Parallel()
+
+	b, _ := newTestBreaker(1)
+	b.record(status.Error(codes.Unavailable, "connection refused"))
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &recvStream{}, nil
+	}
+
+	_, err := b.streamInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, v1.WatchService_Watch_FullMethodName, streamer)
+	assert.NoError(t, err, "a watch can neither be failed fast nor serve as the trial call")
+	_, err = b.streamInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, "/test", streamer)
+	assert.ErrorIs(t, err, errCircuitOpen)
+}
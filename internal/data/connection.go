@@ -0,0 +1,205 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 2 * time.Second
+	// gRPC caps retry attempts at 5
+	maxRetryAttempts = 5
+)
+
+// idempotentMethods are the SpiceDB calls safe to retry, by service. Streaming calls are only retried until the
+// first response is received.
+var idempotentMethods = map[string][]string{
+	"authzed.api.v1.PermissionsService": {
+		"CheckPermission", "CheckBulkPermissions", "ExpandPermissionTree",
+		"LookupResources", "LookupSubjects", "ReadRelationships",
+	},
+	"authzed.api.v1.SchemaService": {"ReadSchema", "DiffSchema"},
+	"grpc.health.v1.Health":        {"Check"},
+}
+
+// unaryMethods are the SpiceDB calls the connection timeout applies to. Streams are not bounded, as lookups over
+// large result sets legitimately run long.
+var unaryMethods = map[string][]string{
+	"authzed.api.v1.PermissionsService": {
+		"CheckPermission", "CheckBulkPermissions", "ExpandPermissionTree",
+		"WriteRelationships", "DeleteRelationships",
+	},
+	"authzed.api.v1.SchemaService": {"ReadSchema", "WriteSchema", "DiffSchema"},
+	"grpc.health.v1.Health":        {"Check"},
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          uint32   `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	Timeout     string       `json:"timeout,omitempty"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// serviceConfig returns the gRPC service config implementing the timeout and retry settings of c, or an empty
+// string if neither is configured.
+func serviceConfig(c *conf.Data_SpiceDb_Connection) (string, error) {
+	var retry *retryPolicy
+	if attempts := c.GetRetry().GetMaxAttempts(); attempts > 1 {
+		if attempts > maxRetryAttempts {
+			return "", fmt.Errorf("spicedb connection retry maxAttempts must not exceed %d", maxRetryAttempts)
+		}
+		retry = &retryPolicy{
+			MaxAttempts:          attempts,
+			InitialBackoff:       grpcDuration(durationOr(c.GetRetry().GetInitialBackoff().AsDuration(), defaultInitialBackoff)),
+			MaxBackoff:           grpcDuration(durationOr(c.GetRetry().GetMaxBackoff().AsDuration(), defaultMaxBackoff)),
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+	}
+	var timeout string
+	if d := c.GetTimeout().AsDuration(); d > 0 {
+		timeout = grpcDuration(d)
+	}
+	if retry == nil && timeout == "" {
+		return "", nil
+	}
+
+	// a method takes the settings of the most specific entry naming it, so every method gets exactly one entry
+	var configs []methodConfig
+	for service, methods := range unaryMethods {
+		for _, method := range methods {
+			mc := methodConfig{Name: []methodName{{service, method}}, Timeout: timeout}
+			if isIdempotent(service, method) {
+				mc.RetryPolicy = retry
+			}
+			configs = append(configs, mc)
+		}
+	}
+	if retry != nil {
+		for service, methods := range idempotentMethods {
+			for _, method := range methods {
+				if !isUnary(service, method) {
+					configs = append(configs, methodConfig{Name: []methodName{{service, method}}, RetryPolicy: retry})
+				}
+			}
+		}
+	}
+
+	sc, err := json.Marshal(map[string]any{"methodConfig": configs})
+	if err != nil {
+		return "", err
+	}
+	return string(sc), nil
+}
+
+func isIdempotent(service, method string) bool {
+	return slices.Contains(idempotentMethods[service], method)
+}
+
+func isUnary(service, method string) bool {
+	return slices.Contains(unaryMethods[service], method)
+}
+
+// grpcDuration formats d the way the JSON service config expects.
+func grpcDuration(d time.Duration) string {
+	return fmt.Sprintf("%.9fs", d.Seconds())
+}
+
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// connectionOptions returns the dial options implementing the connection settings of c.
+func connectionOptions(c *conf.Data_SpiceDb_Connection) ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+
+	sc, err := serviceConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	if sc != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(sc))
+	}
+	if c.GetWaitForReady() {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	}
+	if ka := c.GetKeepalive(); ka.GetTime().AsDuration() > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                ka.GetTime().AsDuration(),
+			Timeout:             ka.GetTimeout().AsDuration(),
+			PermitWithoutStream: ka.GetPermitWithoutStream(),
+		}))
+	}
+	return opts, nil
+}
+
+// connPool spreads calls over several connections to SpiceDB, so a single HTTP/2 connection's stream limit does not
+// cap concurrency.
+type connPool struct {
+	conns []*grpc.ClientConn
+	next  atomic.Uint32
+}
+
+var _ grpc.ClientConnInterface = (*connPool)(nil)
+
+func newConnPool(endpoint string, size uint32, opts ...grpc.DialOption) (*connPool, error) {
+	if size == 0 {
+		size = 1
+	}
+	p := &connPool{}
+	for i := uint32(0); i < size; i++ {
+		conn, err := grpc.NewClient(endpoint, opts...)
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+		p.conns = append(p.conns, conn)
+	}
+	return p, nil
+}
+
+func (p *connPool) pick() *grpc.ClientConn {
+	return p.conns[p.next.Add(1)%uint32(len(p.conns))]
+}
+
+func (p *connPool) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (p *connPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
+
+func (p *connPool) Close() error {
+	var errs []error
+	for _, conn := range p.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
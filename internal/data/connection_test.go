@@ -0,0 +1,88 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+func parseServiceConfig(t *testing.T, c *conf.Data_SpiceDb_Connection) map[string]methodConfig {
+	t.Helper()
+
+	sc, err := serviceConfig(c)
+	require.NoError(t, err)
+	var parsed struct {
+		MethodConfig []methodConfig `json:"methodConfig"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(sc), &parsed))
+
+	byMethod := make(map[string]methodConfig)
+	for _, mc := range parsed.MethodConfig {
+		for _, name := range mc.Name {
+			_, duplicate := byMethod[name.Method]
+			require.False(t, duplicate, "%s configured twice", name.Method)
+			byMethod[name.Method] = mc
+		}
+	}
+	return byMethod
+}
+
+func TestServiceConfig_RetriesOnlyIdempotentCalls(t *testing.T) {
+	t.Parallel()
+
+	methods := parseServiceConfig(t, &conf.Data_SpiceDb_Connection{
+		Timeout: durationpb.New(2 * time.Second),
+		Retry:   &conf.Data_SpiceDb_Connection_Retry{MaxAttempts: 3},
+	})
+
+	check := methods["CheckPermission"]
+	require.NotNil(t, check.RetryPolicy)
+	assert.Equal(t, uint32(3), check.RetryPolicy.MaxAttempts)
+	assert.Equal(t, "0.100000000s", check.RetryPolicy.InitialBackoff)
+	assert.Equal(t, "2.000000000s", check.Timeout)
+
+	lookup := methods["LookupResources"]
+	assert.NotNil(t, lookup.RetryPolicy)
+	assert.Empty(t, lookup.Timeout, "streams are not bounded by the timeout")
+
+	write := methods["WriteRelationships"]
+	assert.Nil(t, write.RetryPolicy)
+	assert.Equal(t, "2.000000000s", write.Timeout)
+}
+
+func TestServiceConfig_EmptyWithoutSettings(t *testing.T) {
+	t.Parallel()
+
+	sc, err := serviceConfig(&conf.Data_SpiceDb_Connection{Retry: &conf.Data_SpiceDb_Connection_Retry{MaxAttempts: 1}})
+	require.NoError(t, err)
+	assert.Empty(t, sc)
+
+	_, err = serviceConfig(&conf.Data_SpiceDb_Connection{Retry: &conf.Data_SpiceDb_Connection_Retry{MaxAttempts: 6}})
+	assert.Error(t, err)
+}
+
+func TestConnectionOptions_AcceptedByGrpc(t *testing.T) {
+	t.Parallel()
+
+	opts, err := connectionOptions(&conf.Data_SpiceDb_Connection{
+		Timeout:      durationpb.New(time.Second),
+		WaitForReady: true,
+		Retry:        &conf.Data_SpiceDb_Connection_Retry{MaxAttempts: 3, MaxBackoff: durationpb.New(time.Second)},
+		Keepalive:    &conf.Data_SpiceDb_Connection_Keepalive{Time: durationpb.New(30 * time.Second), Timeout: durationpb.New(5 * time.Second)},
+	})
+	require.NoError(t, err)
+
+	pool, err := newConnPool("localhost:50051", 3, append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pool.Close() })
+	assert.Len(t, pool.conns, 3)
+	assert.NotSame(t, pool.pick(), pool.pick())
+}
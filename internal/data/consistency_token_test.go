@@ -0,0 +1,111 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+func newTestConsistencyTokenCodec(t *testing.T, tc *conf.Data_SpiceDb_ConsistencyToken) *consistencyTokenCodec {
+	codec, err := newConsistencyTokenCodec(&conf.Data_SpiceDb{Endpoint: "localhost:50051", ConsistencyToken: tc})
+	require.NoError(t, err)
+	return codec
+}
+
+func TestConsistencyTokenCodec_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	codec := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{SigningKey: "secret", Shard: "a"})
+
+	token := codec.encode("GhUKEzE3MjQ5MjE4MzQwMDAwMDAwMDA=")
+	assert.True(t, strings.HasPrefix(token.Token, consistencyTokenVersion+"."))
+
+	zedToken, err := codec.decode(token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "GhUKEzE3MjQ5MjE4MzQwMDAwMDAwMDA=", zedToken)
+
+	claims, err := ParseConsistencyToken(token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:50051", claims.BackendID)
+	assert.Equal(t, "a", claims.Shard)
+}
+
+func TestConsistencyTokenCodec_EmptyZedTokenPassesThrough(t *testing.T) {
+	t.Parallel()
+
+	codec := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true})
+	assert.Equal(t, "", codec.encode("").Token)
+}
+
+func TestConsistencyTokenCodec_RejectsTamperedToken(t *testing.T) {
+	t.Parallel()
+
+	codec := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{SigningKey: "secret"})
+	other := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{SigningKey: "other-secret"})
+
+	_, err := codec.decode(other.encode("zed").Token)
+	assert.ErrorIs(t, err, errInvalidTokenSignature)
+}
+
+func TestConsistencyTokenCodec_RejectsTokenFromOtherBackend(t *testing.T) {
+	t.Parallel()
+
+	codec := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{SigningKey: "secret", BackendId: "cluster-a"})
+	other := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{SigningKey: "secret", BackendId: "cluster-b"})
+
+	_, err := codec.decode(other.encode("zed").Token)
+	assert.ErrorIs(t, err, errForeignConsistencyToken)
+}
+
+func TestConsistencyTokenCodec_RejectsExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	codec := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true, MaxAge: durationpb.New(time.Minute)})
+	issued := time.Now()
+	codec.now = func() time.Time { return issued }
+	token := codec.encode("zed")
+
+	codec.now = func() time.Time { return issued.Add(2 * time.Minute) }
+	_, err := codec.decode(token.Token)
+	assert.ErrorIs(t, err, errExpiredConsistencyToken)
+}
+
+func TestConsistencyTokenCodec_MalformedTokens(t *testing.T) {
+	t.Parallel()
+
+	codec := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true})
+
+	for _, token := range []string{"", "GhUKEzE3MjQ5", "kt1.not-base64!.", "kt1.e30.", "kt1.a.b.c"} {
+		_, err := codec.decode(token)
+		assert.ErrorIs(t, err, errMalformedConsistencyToken, token)
+	}
+}
+
+func TestConsistencyTokenCodec_AcceptsLegacyTokensOnlyIfEnabled(t *testing.T) {
+	t.Parallel()
+
+	codec := newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{SigningKey: "secret"})
+	_, err := codec.decode("GhUKEzE3MjQ5")
+	assert.ErrorIs(t, err, errMalformedConsistencyToken, "legacy tokens are rejected by default")
+
+	codec = newTestConsistencyTokenCodec(t, &conf.Data_SpiceDb_ConsistencyToken{SigningKey: "secret", AcceptLegacyTokens: true})
+	zedToken, err := codec.decode("GhUKEzE3MjQ5")
+	assert.NoError(t, err)
+	assert.Equal(t, "GhUKEzE3MjQ5", zedToken)
+}
+
+func TestNewConsistencyTokenCodec_RequiresSigningKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := newConsistencyTokenCodec(&conf.Data_SpiceDb{Endpoint: "localhost:50051"})
+	assert.ErrorContains(t, err, "signing key is required")
+
+	_, err = newConsistencyTokenCodec(&conf.Data_SpiceDb{Endpoint: "localhost:50051", ConsistencyToken: &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true}})
+	assert.NoError(t, err)
+}
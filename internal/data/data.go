@@ -6,4 +6,4 @@ import (
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewSpiceDbRepository, wire.Bind(new(biz.ZanzibarRepository), new(*SpiceDbRepository)))
+var ProviderSet = wire.NewSet(NewSpiceDbRepository, NewTupleEventRelay, wire.Bind(new(biz.ZanzibarRepository), new(*SpiceDbRepository)))
@@ -0,0 +1,177 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+// debugTrace converts the trace SpiceDB returned for a check into Kessel types and relation names, with the number
+// of operations dispatched taken from the trailer of the call. direct returns the tuple that granted a relation step
+// without sub-steps, or nil if it is not known.
+func debugTrace(trace *v1.CheckDebugTrace, trailer metadata.MD, direct func(step *v1.CheckDebugTrace) *apiV1beta1.Relationship) *apiV1beta1.CheckDebugTrace {
+	debug := &apiV1beta1.CheckDebugTrace{DispatchCount: dispatchCount(trailer)}
+	if trace == nil {
+		return debug
+	}
+	debug.Root = debugStep(trace, nil)
+	debug.MatchedTuples = matchedTuples(trace, direct, nil)
+	return debug
+}
+
+// directTuples returns a function reading the tuple that granted a relation step without sub-steps at the revision
+// checkedAt the check was evaluated at. The trace does not say whether the tuple names the subject itself or, for
+// subjects without a relation, the wildcard of its type, so the tuple naming the subject is read first. Tuples that
+// cannot be read are not listed, the check itself has succeeded regardless.
+func (s *SpiceDbRepository) directTuples(ctx context.Context, checkedAt *v1.ZedToken) func(step *v1.CheckDebugTrace) *apiV1beta1.Relationship {
+	return func(step *v1.CheckDebugTrace) *apiV1beta1.Relationship {
+		ids := []string{step.GetSubject().GetObject().GetObjectId()}
+		if step.GetSubject().GetOptionalRelation() == "" {
+			ids = append(ids, "*")
+		}
+		for _, id := range ids {
+			rel, err := s.readTuple(ctx, checkedAt, relationshipMatching(&v1.Relationship{
+				Resource: step.GetResource(),
+				Relation: step.GetPermission(),
+				Subject: &v1.SubjectReference{
+					Object:           &v1.ObjectReference{ObjectType: step.GetSubject().GetObject().GetObjectType(), ObjectId: id},
+					OptionalRelation: step.GetSubject().GetOptionalRelation(),
+				},
+			}))
+			if err != nil {
+				s.log.WithContext(ctx).Warnf("error reading the tuple matched by a debug trace, it is not listed: %v", err)
+				return nil
+			}
+			if rel != nil {
+				return spiceDbRelationshipToKessel(rel)
+			}
+		}
+		return nil
+	}
+}
+
+// readTuple reads the first relationship matching filter at the revision at, or nil if none does.
+func (s *SpiceDbRepository) readTuple(ctx context.Context, at *v1.ZedToken, filter *v1.RelationshipFilter) (*v1.Relationship, error) {
+	client, err := s.client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: at}},
+		RelationshipFilter: filter,
+		OptionalLimit:      1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
+	}
+	msg, err := client.Recv()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
+	}
+	return msg.GetRelationship(), nil
+}
+
+func dispatchCount(trailer metadata.MD) uint32 {
+	value, err := responsemeta.GetResponseTrailerMetadata(trailer, responsemeta.DispatchedOperationsCount)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(value, 10, 32)
+	return uint32(n)
+}
+
+func debugStep(trace, parent *v1.CheckDebugTrace) *apiV1beta1.CheckDebugStep {
+	step := &apiV1beta1.CheckDebugStep{
+		Resource: debugObject(trace.GetResource()),
+		Relation: strings.TrimPrefix(trace.GetPermission(), relationPrefix),
+		Subject:  debugSubject(trace.GetSubject()),
+		Cached:   trace.GetWasCachedResult(),
+		Duration: trace.GetDuration(),
+		// steps of a permission on another resource can only be reached by following an arrow, those of a relation on
+		// another resource expand a subject set
+		Arrow: parent.GetPermissionType() == v1.CheckDebugTrace_PERMISSION_TYPE_PERMISSION &&
+			!proto.Equal(parent.GetResource(), trace.GetResource()),
+	}
+	switch trace.GetPermissionType() {
+	case v1.CheckDebugTrace_PERMISSION_TYPE_RELATION:
+		step.Kind = apiV1beta1.CheckDebugStep_KIND_RELATION
+	case v1.CheckDebugTrace_PERMISSION_TYPE_PERMISSION:
+		step.Kind = apiV1beta1.CheckDebugStep_KIND_PERMISSION
+	}
+	switch trace.GetResult() {
+	case v1.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION:
+		step.Result = apiV1beta1.CheckDebugStep_RESULT_ALLOWED
+	case v1.CheckDebugTrace_PERMISSIONSHIP_NO_PERMISSION:
+		step.Result = apiV1beta1.CheckDebugStep_RESULT_DENIED
+	case v1.CheckDebugTrace_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		step.Result = apiV1beta1.CheckDebugStep_RESULT_CONDITIONAL
+	}
+	for _, sub := range trace.GetSubProblems().GetTraces() {
+		step.Steps = append(step.Steps, debugStep(sub, trace))
+	}
+	return step
+}
+
+// matchedTuples returns the tuples of the relations in trace that granted the subject. A relation without sub-steps
+// was granted by a tuple naming the subject or the wildcard of its type, which direct looks up, one with sub-steps by
+// tuples naming the subject sets granted below it. Relations of several resources evaluated at once and cached
+// results do not say which tuple matched.
+func matchedTuples(trace *v1.CheckDebugTrace, direct func(step *v1.CheckDebugTrace) *apiV1beta1.Relationship, tuples []*apiV1beta1.Relationship) []*apiV1beta1.Relationship {
+	subs := trace.GetSubProblems().GetTraces()
+	if trace.GetPermissionType() == v1.CheckDebugTrace_PERMISSION_TYPE_RELATION &&
+		trace.GetResult() == v1.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION &&
+		!trace.GetWasCachedResult() && singleObject(trace.GetResource()) {
+		if len(subs) == 0 {
+			if tuple := direct(trace); tuple != nil {
+				tuples = append(tuples, tuple)
+			}
+		}
+		for _, sub := range subs {
+			if sub.GetResult() == v1.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION && singleObject(sub.GetResource()) {
+				tuples = append(tuples, debugTuple(trace, &apiV1beta1.SubjectReference{
+					Subject:  debugObject(sub.GetResource()),
+					Relation: optionalStringToStringPointer(strings.TrimPrefix(sub.GetPermission(), relationPrefix)),
+				}))
+			}
+		}
+	}
+	for _, sub := range subs {
+		tuples = matchedTuples(sub, direct, tuples)
+	}
+	return tuples
+}
+
+func singleObject(object *v1.ObjectReference) bool {
+	return !strings.Contains(object.GetObjectId(), ",")
+}
+
+func debugTuple(trace *v1.CheckDebugTrace, subject *apiV1beta1.SubjectReference) *apiV1beta1.Relationship {
+	return &apiV1beta1.Relationship{
+		Resource: debugObject(trace.GetResource()),
+		Relation: strings.TrimPrefix(trace.GetPermission(), relationPrefix),
+		Subject:  subject,
+	}
+}
+
+func debugObject(object *v1.ObjectReference) *apiV1beta1.ObjectReference {
+	return &apiV1beta1.ObjectReference{
+		Type: spicedbTypeToKesselType(object.GetObjectType()),
+		Id:   object.GetObjectId(),
+	}
+}
+
+func debugSubject(subject *v1.SubjectReference) *apiV1beta1.SubjectReference {
+	return &apiV1beta1.SubjectReference{
+		Subject:  debugObject(subject.GetObject()),
+		Relation: optionalStringToStringPointer(strings.TrimPrefix(subject.GetOptionalRelation(), relationPrefix)),
+	}
+}
@@ -0,0 +1,172 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+var traceSubject = &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "rbac/principal", ObjectId: "alice"}}
+
+func traceStep(resource, id, permission string, kind v1.CheckDebugTrace_PermissionType, result v1.CheckDebugTrace_Permissionship, subs ...*v1.CheckDebugTrace) *v1.CheckDebugTrace {
+	trace := &v1.CheckDebugTrace{
+		Resource:       &v1.ObjectReference{ObjectType: resource, ObjectId: id},
+		Permission:     permission,
+		PermissionType: kind,
+		Subject:        traceSubject,
+		Result:         result,
+		Duration:       durationpb.New(0),
+	}
+	if len(subs) > 0 {
+		trace.Resolution = &v1.CheckDebugTrace_SubProblems_{SubProblems: &v1.CheckDebugTrace_SubProblems{Traces: subs}}
+	}
+	return trace
+}
+
+const (
+	traceRelation   = v1.CheckDebugTrace_PERMISSION_TYPE_RELATION
+	tracePermission = v1.CheckDebugTrace_PERMISSION_TYPE_PERMISSION
+	traceHas        = v1.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION
+	traceHasNot     = v1.CheckDebugTrace_PERMISSIONSHIP_NO_PERMISSION
+)
+
+func dispatched(count string) metadata.MD {
+	return metadata.Pairs(string(responsemeta.DispatchedOperationsCount), count)
+}
+
+func relationship(resourceType, resourceID, relation, subjectType, subjectID string, subjectRelation *string) *apiV1beta1.Relationship {
+	return &apiV1beta1.Relationship{
+		Resource: &apiV1beta1.ObjectReference{Type: spicedbTypeToKesselType(resourceType), Id: resourceID},
+		Relation: relation,
+		Subject: &apiV1beta1.SubjectReference{
+			Subject:  &apiV1beta1.ObjectReference{Type: spicedbTypeToKesselType(subjectType), Id: subjectID},
+			Relation: subjectRelation,
+		},
+	}
+}
+
+// directTuple looks up the tuples granting relation steps without sub-steps in tuples, as directTuples reads them.
+func directTuple(tuples ...*apiV1beta1.Relationship) func(step *v1.CheckDebugTrace) *apiV1beta1.Relationship {
+	return func(step *v1.CheckDebugTrace) *apiV1beta1.Relationship {
+		for _, tuple := range tuples {
+			if tuple.GetResource().GetId() == step.GetResource().GetObjectId() &&
+				tuple.GetRelation() == strings.TrimPrefix(step.GetPermission(), relationPrefix) {
+				return tuple
+			}
+		}
+		return nil
+	}
+}
+
+func TestDebugTrace_DescribesTraversalInKesselTerms(t *testing.T) {
+	t.Parallel()
+
+	// view = t_owner + t_parent->view, with alice a member of a group owning the parent workspace
+	trace := traceStep("rbac/workspace", "ws1", "view", tracePermission, traceHas,
+		traceStep("rbac/workspace", "ws1", "t_owner", traceRelation, traceHasNot),
+		traceStep("rbac/workspace", "root", "view", tracePermission, traceHas,
+			traceStep("rbac/workspace", "root", "t_owner", traceRelation, traceHas,
+				traceStep("rbac/group", "g1", "member", tracePermission, traceHas,
+					traceStep("rbac/group", "g1", "t_member", traceRelation, traceHas),
+				),
+			),
+		),
+	)
+
+	member := "member"
+	direct := relationship("rbac/group", "g1", "member", "rbac/principal", "alice", nil)
+	debug := debugTrace(trace, dispatched("7"), directTuple(direct))
+
+	assert.Equal(t, uint32(7), debug.GetDispatchCount())
+	root := debug.GetRoot()
+	assert.Equal(t, "rbac", root.GetResource().GetType().GetNamespace())
+	assert.Equal(t, "workspace", root.GetResource().GetType().GetName())
+	assert.Equal(t, "view", root.GetRelation())
+	assert.Equal(t, apiV1beta1.CheckDebugStep_KIND_PERMISSION, root.GetKind())
+	assert.Equal(t, apiV1beta1.CheckDebugStep_RESULT_ALLOWED, root.GetResult())
+	assert.Equal(t, "principal", root.GetSubject().GetSubject().GetType().GetName())
+	assert.False(t, root.GetArrow())
+	require.Len(t, root.GetSteps(), 2)
+
+	owner, parent := root.GetSteps()[0], root.GetSteps()[1]
+	assert.Equal(t, "owner", owner.GetRelation())
+	assert.Equal(t, apiV1beta1.CheckDebugStep_KIND_RELATION, owner.GetKind())
+	assert.Equal(t, apiV1beta1.CheckDebugStep_RESULT_DENIED, owner.GetResult())
+	assert.False(t, owner.GetArrow())
+	assert.Equal(t, "root", parent.GetResource().GetId())
+	assert.True(t, parent.GetArrow(), "the view of the parent workspace is reached through t_parent")
+
+	group := parent.GetSteps()[0].GetSteps()[0]
+	assert.Equal(t, "g1", group.GetResource().GetId())
+	assert.False(t, group.GetArrow(), "expanding a subject set is not an arrow")
+
+	assert.Equal(t, []*apiV1beta1.Relationship{
+		relationship("rbac/workspace", "root", "owner", "rbac/group", "g1", &member),
+		direct,
+	}, debug.GetMatchedTuples())
+}
+
+func TestDebugTrace_ListsTheTupleReadForDirectGrants(t *testing.T) {
+	t.Parallel()
+
+	// alice is granted through the wildcard, not a tuple naming her
+	wildcard := relationship("rbac/role", "rl1", "view_widget", "rbac/principal", "*", nil)
+	trace := traceStep("rbac/role", "rl1", "view_widget", tracePermission, traceHas,
+		traceStep("rbac/role", "rl1", "t_view_widget", traceRelation, traceHas),
+		traceStep("rbac/role", "rl1", "t_edit_widget", traceRelation, traceHas),
+	)
+
+	debug := debugTrace(trace, nil, directTuple(wildcard))
+
+	assert.Equal(t, []*apiV1beta1.Relationship{wildcard}, debug.GetMatchedTuples(),
+		"steps whose tuple could not be read are not listed")
+}
+
+func TestDebugTrace_ReportsCachedStepsWithoutGuessingTuples(t *testing.T) {
+	t.Parallel()
+
+	cached := traceStep("rbac/workspace", "ws1", "t_owner", traceRelation, traceHas)
+	cached.Resolution = &v1.CheckDebugTrace_WasCachedResult{WasCachedResult: true}
+	trace := traceStep("rbac/workspace", "ws1", "view", tracePermission, traceHas,
+		cached,
+		traceStep("rbac/workspace", "ws2,ws3", "t_owner", traceRelation, traceHas),
+	)
+
+	debug := debugTrace(trace, nil, func(*v1.CheckDebugTrace) *apiV1beta1.Relationship {
+		t.Fatal("cached steps and steps of several resources are not looked up")
+		return nil
+	})
+
+	assert.Zero(t, debug.GetDispatchCount())
+	assert.True(t, debug.GetRoot().GetSteps()[0].GetCached())
+	assert.Equal(t, "ws2,ws3", debug.GetRoot().GetSteps()[1].GetResource().GetId())
+	assert.Empty(t, debug.GetMatchedTuples())
+}
+
+func TestAddBulkDebugTraces_OnlyTracesItemsRequestingIt(t *testing.T) {
+	t.Parallel()
+
+	items := []*apiV1beta1.CheckBulkRequestItem{{Debug: true}, {}}
+	spicePairs := make([]*v1.CheckBulkPermissionsPair, len(items))
+	pairs := make([]*apiV1beta1.CheckBulkResponsePair, len(items))
+	for i := range items {
+		spicePairs[i] = &v1.CheckBulkPermissionsPair{Response: &v1.CheckBulkPermissionsPair_Item{Item: &v1.CheckBulkPermissionsResponseItem{
+			DebugTrace: &v1.DebugInformation{Check: traceStep("rbac/workspace", "ws1", "t_owner", traceRelation, traceHas)},
+		}}}
+		pairs[i] = &apiV1beta1.CheckBulkResponsePair{Response: &apiV1beta1.CheckBulkResponsePair_Item{Item: &apiV1beta1.CheckBulkResponseItem{}}}
+	}
+
+	addBulkDebugTraces(items, spicePairs, pairs, dispatched("3"), directTuple())
+
+	assert.Equal(t, "owner", pairs[0].GetItem().GetDebugTrace().GetRoot().GetRelation())
+	assert.Equal(t, uint32(3), pairs[0].GetItem().GetDebugTrace().GetDispatchCount())
+	assert.Nil(t, pairs[1].GetItem().GetDebugTrace())
+}
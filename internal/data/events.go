@@ -0,0 +1,422 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/biz"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/outbox"
+)
+
+const (
+	// cursorFileName is the file in the outbox directory holding the revision changes were relayed through
+	cursorFileName = "watch.cursor"
+	// maxTuplesPerEvent bounds the size of events, a change of more tuples is published as several events
+	maxTuplesPerEvent = 1000
+
+	defaultWatchRetryBackoff = time.Second
+	maxWatchRetryBackoff     = time.Minute
+
+	// keys of the transaction metadata writes are recorded with in SpiceDB
+	metadataPrincipal = "principal"
+	metadataFilter    = "filter"
+	metadataDeletion  = "deletion"
+)
+
+// TupleEventSink delivers tuple change events to consumers. A batch whose Publish fails is published again in full,
+// so sinks need not handle partial failures.
+type TupleEventSink interface {
+	Publish(ctx context.Context, events []*apiV1beta1.TupleEvent) error
+	Close() error
+}
+
+// NewTupleEventRelay returns the relay publishing tuple change events, nil unless events are enabled. Events recorded
+// in its outbox are delivered to the configured sink until the cleanup function is called.
+func NewTupleEventRelay(c *conf.Data, repo *SpiceDbRepository, logger log.Logger) (*TupleEventRelay, func(), error) {
+	ec := c.GetEvents()
+	if !ec.GetEnabled() {
+		return nil, func() {}, nil
+	}
+	if ec.GetOutboxDir() == "" {
+		return nil, nil, fmt.Errorf("events.outboxDir must be set when events are enabled")
+	}
+	sink, err := newTupleEventSink(ec)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, err := openTupleEventOutbox(ec.GetOutboxDir(), sink, outbox.Options{
+		BatchSize:    int(ec.GetBatchSize()),
+		RetryBackoff: ec.GetRetryBackoff().AsDuration(),
+	}, logger)
+	if err != nil {
+		_ = sink.Close()
+		return nil, nil, err
+	}
+	o.outbox.Start()
+	lease := newRelayLease(repo, ec.GetLockId(), ec.GetLeaseDuration().AsDuration(), logger)
+	relay := newTupleEventRelay(repo.client, repo.tokens, o, lease, ec.GetOutboxDir(), ec.GetRetryBackoff().AsDuration(), logger)
+	return relay, func() {
+		if err := o.Close(); err != nil {
+			o.log.Errorf("error closing tuple event outbox: %v", err)
+		}
+	}, nil
+}
+
+func newTupleEventSink(ec *conf.Data_Events) (TupleEventSink, error) {
+	switch ec.GetSink() {
+	case "", "stdout":
+		return newWriterSink(nopCloser{os.Stdout}), nil
+	case "file":
+		if ec.GetFilePath() == "" {
+			return nil, fmt.Errorf("events.filePath must be set for the file sink")
+		}
+		f, err := os.OpenFile(ec.GetFilePath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("error opening event file: %w", err)
+		}
+		return newWriterSink(f), nil
+	case "kafka":
+		return newKafkaRestSink(ec.GetKafka())
+	}
+	return nil, fmt.Errorf("unknown events.sink %q, expected stdout, file or kafka", ec.GetSink())
+}
+
+// TupleEventRelay is a transport.Server following SpiceDB's Watch API and recording an event for every tuple change
+// in the outbox. The watch cursor is only advanced once the events of a change are recorded, so after a crash changes
+// are relayed again rather than lost; their events keep their ids, which are derived from the revision of the change.
+// Only the replica holding the lease relays changes.
+type TupleEventRelay struct {
+	watch        v1.WatchServiceClient
+	tokens       *consistencyTokenCodec
+	outbox       *tupleEventOutbox
+	lease        *relayLease
+	cursorPath   string
+	retryBackoff time.Duration
+	log          *log.Helper
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started atomic.Bool
+	done    chan struct{}
+}
+
+func newTupleEventRelay(watch v1.WatchServiceClient, tokens *consistencyTokenCodec, o *tupleEventOutbox, lease *relayLease, dir string, retryBackoff time.Duration, logger log.Logger) *TupleEventRelay {
+	if retryBackoff <= 0 {
+		retryBackoff = defaultWatchRetryBackoff
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TupleEventRelay{
+		watch:        watch,
+		tokens:       tokens,
+		outbox:       o,
+		lease:        lease,
+		cursorPath:   filepath.Join(dir, cursorFileName),
+		retryBackoff: retryBackoff,
+		log:          log.NewHelper(logger),
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+}
+
+// Start relays tuple changes whenever this replica holds the lease, until Stop is called.
+func (r *TupleEventRelay) Start(ctx context.Context) error {
+	r.started.Store(true)
+	defer close(r.done)
+	stop := context.AfterFunc(ctx, r.cancel)
+	defer stop()
+
+	for r.ctx.Err() == nil {
+		cursor, err := r.lease.acquire(r.ctx)
+		if err != nil {
+			break
+		}
+		// the cursor of the previous holder, which may be this replica, is where its relaying stopped
+		if err := r.writeCursor(cursor); err != nil {
+			r.log.Errorf("error resuming from the watch cursor of the previous lease holder: %v", err)
+		}
+		r.lead()
+	}
+	return nil
+}
+
+// lead relays tuple changes until the lease is lost or Stop is called, renewing the lease meanwhile.
+func (r *TupleEventRelay) lead() {
+	ctx, cancel := context.WithCancel(r.ctx)
+	held := make(chan struct{})
+	go func() {
+		defer close(held)
+		defer cancel()
+		r.lease.hold(ctx, func() string {
+			cursor, _ := r.readCursor()
+			return cursor
+		})
+	}()
+	r.relayUntilDone(ctx)
+	cancel()
+	<-held
+}
+
+// relayUntilDone relays tuple changes until ctx is done, watching again after a backoff whenever the stream fails.
+func (r *TupleEventRelay) relayUntilDone(ctx context.Context) {
+	backoff := r.retryBackoff
+	for ctx.Err() == nil {
+		progressed, err := r.relay(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		if progressed {
+			backoff = r.retryBackoff
+		}
+		// an expired cursor fails every retry, it is left to the operator to delete rather than skipping changes
+		r.log.Errorf("error watching tuple changes, retrying in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxWatchRetryBackoff)
+	}
+}
+
+func (r *TupleEventRelay) Stop(ctx context.Context) error {
+	r.cancel()
+	if !r.started.Load() {
+		return nil
+	}
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// relay watches from the cursor until the stream fails, reporting whether it advanced the cursor.
+func (r *TupleEventRelay) relay(ctx context.Context) (bool, error) {
+	cursor, err := r.readCursor()
+	if err != nil {
+		return false, err
+	}
+	req := &v1.WatchRequest{}
+	if cursor != "" {
+		req.OptionalStartCursor = &v1.ZedToken{Token: cursor}
+	} else {
+		r.log.Info("no watch cursor, relaying tuple changes made from now on")
+	}
+	stream, err := r.watch.Watch(ctx, req)
+	if err != nil {
+		return false, err
+	}
+
+	progressed := false
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return progressed, errors.New("watch stream ended")
+		}
+		if err != nil {
+			return progressed, err
+		}
+		for _, event := range tupleEvents(resp, r.tokens, time.Now()) {
+			if err := r.outbox.record(event); err != nil {
+				return progressed, err
+			}
+		}
+		if err := r.writeCursor(resp.GetChangesThrough().GetToken()); err != nil {
+			return progressed, err
+		}
+		progressed = true
+	}
+}
+
+func (r *TupleEventRelay) readCursor() (string, error) {
+	b, err := os.ReadFile(r.cursorPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading watch cursor: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// writeCursor replaces the cursor file atomically, so a crash leaves either the old or the new cursor.
+func (r *TupleEventRelay) writeCursor(cursor string) error {
+	if cursor == "" {
+		return nil
+	}
+	tmp := r.cursorPath + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("error writing watch cursor: %w", err)
+	}
+	if _, err := f.WriteString(cursor); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing watch cursor: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("error syncing watch cursor: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing watch cursor: %w", err)
+	}
+	return os.Rename(tmp, r.cursorPath)
+}
+
+// tupleEvents converts the updates of a watch response into events, one per operation, or several if the change has
+// more than maxTuplesPerEvent tuples of an operation. The tuples of locks are internal and have no events.
+func tupleEvents(resp *v1.WatchResponse, tokens *consistencyTokenCodec, now time.Time) []*apiV1beta1.TupleEvent {
+	tuples := map[apiV1beta1.TupleEvent_Operation][]*apiV1beta1.Relationship{}
+	for _, update := range resp.GetUpdates() {
+		rel := update.GetRelationship()
+		switch rel.GetResource().GetObjectType() {
+		case lockType, lockVersionType:
+			continue
+		}
+		operation := eventOperation(update.GetOperation())
+		tuples[operation] = append(tuples[operation], spiceDbRelationshipToKessel(rel))
+	}
+
+	revision := resp.GetChangesThrough().GetToken()
+	principal, filter := changeOrigin(resp.GetOptionalTransactionMetadata())
+	var events []*apiV1beta1.TupleEvent
+	for _, operation := range []apiV1beta1.TupleEvent_Operation{
+		apiV1beta1.TupleEvent_OPERATION_CREATE,
+		apiV1beta1.TupleEvent_OPERATION_TOUCH,
+		apiV1beta1.TupleEvent_OPERATION_DELETE,
+	} {
+		remaining := tuples[operation]
+		for chunk := 0; len(remaining) > 0; chunk++ {
+			n := min(len(remaining), maxTuplesPerEvent)
+			event := &apiV1beta1.TupleEvent{
+				Id:               uuid.NewSHA1(uuid.NameSpaceOID, fmt.Appendf(nil, "%s/%s/%d", revision, operation, chunk)).String(),
+				Operation:        operation,
+				Tuples:           remaining[:n],
+				Principal:        principal,
+				ConsistencyToken: tokens.encode(revision),
+				Time:             timestamppb.New(now),
+			}
+			if operation == apiV1beta1.TupleEvent_OPERATION_DELETE {
+				event.Filter = filter
+				event.DeletedCount = uint64(n)
+			}
+			events = append(events, event)
+			remaining = remaining[n:]
+		}
+	}
+	return events
+}
+
+func eventOperation(operation v1.RelationshipUpdate_Operation) apiV1beta1.TupleEvent_Operation {
+	switch operation {
+	case v1.RelationshipUpdate_OPERATION_CREATE:
+		return apiV1beta1.TupleEvent_OPERATION_CREATE
+	case v1.RelationshipUpdate_OPERATION_DELETE:
+		return apiV1beta1.TupleEvent_OPERATION_DELETE
+	}
+	return apiV1beta1.TupleEvent_OPERATION_TOUCH
+}
+
+// transactionMetadata is recorded with a write in SpiceDB for the change events of its tuples: the principal of ctx,
+// and for deletes the filter as the caller gave it. It is nil if there is neither.
+func transactionMetadata(ctx context.Context, filter *apiV1beta1.RelationTupleFilter) *structpb.Struct {
+	fields := map[string]*structpb.Value{}
+	if principal := biz.PrincipalFromContext(ctx); principal != "" {
+		fields[metadataPrincipal] = structpb.NewStringValue(principal)
+	}
+	if filter != nil {
+		if b, err := protojson.Marshal(filter); err == nil {
+			value := &structpb.Struct{}
+			if err := protojson.Unmarshal(b, value); err == nil {
+				fields[metadataFilter] = structpb.NewStructValue(value)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &structpb.Struct{Fields: fields}
+}
+
+// changeOrigin reads the principal and filter transactionMetadata recorded.
+func changeOrigin(metadata *structpb.Struct) (string, *apiV1beta1.RelationTupleFilter) {
+	principal := metadata.GetFields()[metadataPrincipal].GetStringValue()
+	value := metadata.GetFields()[metadataFilter].GetStructValue()
+	if value == nil {
+		return principal, nil
+	}
+	b, err := protojson.Marshal(value)
+	if err != nil {
+		return principal, nil
+	}
+	filter := &apiV1beta1.RelationTupleFilter{}
+	if err := protojson.Unmarshal(b, filter); err != nil {
+		return principal, nil
+	}
+	return principal, filter
+}
+
+// tupleEventOutbox records events in an outbox before they are published to the sink, so events survive the sink
+// being unavailable and the service restarting.
+type tupleEventOutbox struct {
+	outbox *outbox.Outbox
+	sink   TupleEventSink
+	log    *log.Helper
+}
+
+func openTupleEventOutbox(dir string, sink TupleEventSink, opts outbox.Options, logger log.Logger) (*tupleEventOutbox, error) {
+	o := &tupleEventOutbox{sink: sink, log: log.NewHelper(logger)}
+	opts.Name = "tuple events"
+	var err error
+	if o.outbox, err = outbox.Open(dir, o.publish, opts, logger); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// record appends the event to the outbox, returning once it is synced to disk.
+func (o *tupleEventOutbox) record(event *apiV1beta1.TupleEvent) error {
+	line, err := protojson.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return o.outbox.Append(line)
+}
+
+func (o *tupleEventOutbox) publish(ctx context.Context, entries [][]byte) error {
+	events := make([]*apiV1beta1.TupleEvent, 0, len(entries))
+	for _, entry := range entries {
+		event := &apiV1beta1.TupleEvent{}
+		if err := protojson.Unmarshal(entry, event); err != nil {
+			o.log.Errorf("skipping unreadable outbox entry: %v", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return o.sink.Publish(ctx, events)
+}
+
+// Close stops relaying, leaving undelivered events to be delivered on the next start.
+func (o *tupleEventOutbox) Close() error {
+	return errors.Join(o.outbox.Close(), o.sink.Close())
+}
@@ -0,0 +1,270 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/biz"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/outbox"
+)
+
+// recordingSink collects published events, failing while failures remain.
+type recordingSink struct {
+	mu        sync.Mutex
+	failures  int
+	published []*apiV1beta1.TupleEvent
+	closed    bool
+}
+
+func (s *recordingSink) Publish(_ context.Context, events []*apiV1beta1.TupleEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, events...)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordingSink) events() []*apiV1beta1.TupleEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*apiV1beta1.TupleEvent(nil), s.published...)
+}
+
+func (s *recordingSink) ids() []string {
+	var ids []string
+	for _, e := range s.events() {
+		ids = append(ids, e.GetId())
+	}
+	return ids
+}
+
+func TestTupleEventOutbox_PublishesRecordedEventsToTheSink(t *testing.T) {
+	t.Parallel()
+	sink := &recordingSink{failures: 1}
+	o, err := openTupleEventOutbox(t.TempDir(), sink, outbox.Options{RetryBackoff: time.Millisecond}, log.DefaultLogger)
+	require.NoError(t, err)
+	o.outbox.Start()
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, o.record(&apiV1beta1.TupleEvent{Id: id, Operation: apiV1beta1.TupleEvent_OPERATION_CREATE}))
+	}
+
+	assert.Eventually(t, func() bool { return len(sink.ids()) == 3 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b", "c"}, sink.ids())
+	require.NoError(t, o.Close())
+	assert.True(t, sink.closed)
+}
+
+func TestNewTupleEventRelay_RequiresOutboxDir(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := NewTupleEventRelay(&conf.Data{Events: &conf.Data_Events{Enabled: true}}, nil, log.DefaultLogger)
+	assert.ErrorContains(t, err, "outboxDir")
+
+	relay, cleanup, err := NewTupleEventRelay(&conf.Data{}, nil, log.DefaultLogger)
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Nil(t, relay, "no relay runs while events are disabled")
+}
+
+func watchUpdate(operation v1.RelationshipUpdate_Operation, resourceType, id string) *v1.RelationshipUpdate {
+	return &v1.RelationshipUpdate{
+		Operation: operation,
+		Relationship: &v1.Relationship{
+			Resource: &v1.ObjectReference{ObjectType: resourceType, ObjectId: id},
+			Relation: relationPrefix + "member",
+			Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "rbac/principal", ObjectId: "bob"}},
+		},
+	}
+}
+
+func unsignedTokens(t *testing.T) *consistencyTokenCodec {
+	tokens, err := newConsistencyTokenCodec(&conf.Data_SpiceDb{Endpoint: "localhost:50051", ConsistencyToken: &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true}})
+	require.NoError(t, err)
+	return tokens
+}
+
+func TestTupleEvents_GroupsUpdatesByOperation(t *testing.T) {
+	t.Parallel()
+	filter := &apiV1beta1.RelationTupleFilter{ResourceNamespace: pointerize("rbac"), ResourceType: pointerize("group"), Relation: pointerize("member")}
+	ctx := biz.NewPrincipalContext(context.Background(), "alice")
+	resp := &v1.WatchResponse{
+		ChangesThrough: &v1.ZedToken{Token: "rev1"},
+		Updates: []*v1.RelationshipUpdate{
+			watchUpdate(v1.RelationshipUpdate_OPERATION_DELETE, "rbac/group", "g1"),
+			watchUpdate(v1.RelationshipUpdate_OPERATION_TOUCH, "rbac/group", "g2"),
+			watchUpdate(v1.RelationshipUpdate_OPERATION_DELETE, "rbac/group", "g3"),
+			watchUpdate(v1.RelationshipUpdate_OPERATION_TOUCH, lockType, "lock"),
+		},
+		OptionalTransactionMetadata: transactionMetadata(ctx, filter),
+	}
+
+	tokens := unsignedTokens(t)
+	events := tupleEvents(resp, tokens, time.Now())
+
+	require.Len(t, events, 2)
+	assert.Equal(t, apiV1beta1.TupleEvent_OPERATION_TOUCH, events[0].GetOperation())
+	require.Len(t, events[0].GetTuples(), 1, "lock tuples have no events")
+	assert.Equal(t, "g2", events[0].GetTuples()[0].GetResource().GetId())
+	assert.Equal(t, "member", events[0].GetTuples()[0].GetRelation())
+	assert.Nil(t, events[0].GetFilter())
+
+	assert.Equal(t, apiV1beta1.TupleEvent_OPERATION_DELETE, events[1].GetOperation())
+	require.Len(t, events[1].GetTuples(), 2)
+	assert.Equal(t, "g1", events[1].GetTuples()[0].GetResource().GetId())
+	assert.Equal(t, uint64(2), events[1].GetDeletedCount())
+	assert.True(t, proto.Equal(filter, events[1].GetFilter()), "the filter is read back from the transaction metadata")
+	for _, event := range events {
+		assert.Equal(t, "alice", event.GetPrincipal())
+		revision, err := tokens.decode(event.GetConsistencyToken().GetToken())
+		assert.NoError(t, err)
+		assert.Equal(t, "rev1", revision)
+	}
+	assert.NotEqual(t, events[0].GetId(), events[1].GetId())
+	assert.Equal(t, events[1].GetId(), tupleEvents(resp, tokens, time.Now())[1].GetId(), "ids are stable across replays")
+}
+
+func TestTupleEvents_SplitsLargeChanges(t *testing.T) {
+	t.Parallel()
+	resp := &v1.WatchResponse{ChangesThrough: &v1.ZedToken{Token: "rev1"}}
+	for i := 0; i < maxTuplesPerEvent+1; i++ {
+		resp.Updates = append(resp.Updates, watchUpdate(v1.RelationshipUpdate_OPERATION_CREATE, "rbac/group", strconv.Itoa(i)))
+	}
+
+	events := tupleEvents(resp, unsignedTokens(t), time.Now())
+
+	require.Len(t, events, 2)
+	assert.Len(t, events[0].GetTuples(), maxTuplesPerEvent)
+	assert.Len(t, events[1].GetTuples(), 1)
+	assert.NotEqual(t, events[0].GetId(), events[1].GetId())
+	assert.Empty(t, events[0].GetPrincipal(), "changes without transaction metadata have no principal")
+}
+
+// fakeWatch serves one batch of responses per Watch call, then fails the stream.
+type fakeWatch struct {
+	mu        sync.Mutex
+	responses [][]*v1.WatchResponse
+	cursors   []string
+}
+
+func (w *fakeWatch) Watch(_ context.Context, in *v1.WatchRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[v1.WatchResponse], error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cursors = append(w.cursors, in.GetOptionalStartCursor().GetToken())
+	var batch []*v1.WatchResponse
+	if len(w.responses) > 0 {
+		batch, w.responses = w.responses[0], w.responses[1:]
+	}
+	return &fakeWatchStream{responses: batch}, nil
+}
+
+func (w *fakeWatch) startCursors() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.cursors...)
+}
+
+type fakeWatchStream struct {
+	grpc.ClientStream
+	responses []*v1.WatchResponse
+}
+
+func (s *fakeWatchStream) Recv() (*v1.WatchResponse, error) {
+	if len(s.responses) == 0 {
+		return nil, status.Error(codes.Unavailable, "stream broken")
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	return resp, nil
+}
+
+func TestTupleEventRelay_ResumesFromTheCursor(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	sink := &recordingSink{}
+	o, err := openTupleEventOutbox(dir, sink, outbox.Options{RetryBackoff: time.Millisecond}, log.DefaultLogger)
+	require.NoError(t, err)
+	o.outbox.Start()
+	defer o.Close()
+
+	watch := &fakeWatch{responses: [][]*v1.WatchResponse{
+		{{ChangesThrough: &v1.ZedToken{Token: "rev1"}, Updates: []*v1.RelationshipUpdate{watchUpdate(v1.RelationshipUpdate_OPERATION_TOUCH, "rbac/group", "g1")}}},
+		{{ChangesThrough: &v1.ZedToken{Token: "rev2"}, Updates: []*v1.RelationshipUpdate{watchUpdate(v1.RelationshipUpdate_OPERATION_DELETE, "rbac/group", "g1")}}},
+	}}
+	lease := newRelayLease(&memLocks{}, "", time.Hour, log.DefaultLogger)
+	relay := newTupleEventRelay(watch, unsignedTokens(t), o, lease, dir, time.Millisecond, log.DefaultLogger)
+	go func() { _ = relay.Start(context.Background()) }()
+	defer func() { _ = relay.Stop(context.Background()) }()
+
+	assert.Eventually(t, func() bool { return len(sink.ids()) == 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"", "rev1"}, watch.startCursors()[:2], "the stream is resumed after the change relayed last")
+	cursor, err := os.ReadFile(filepath.Join(dir, cursorFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "rev2", string(cursor))
+}
+
+func TestKafkaRestSink_ProducesRecordsKeyedByEventId(t *testing.T) {
+	t.Parallel()
+	var got kafkaProduceRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/kessel.tuples", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer srv.Close()
+
+	sink, err := newKafkaRestSink(&conf.Data_Events_Kafka{RestProxyUrl: srv.URL + "/", Topic: "kessel.tuples", Timeout: durationpb.New(time.Second)})
+	require.NoError(t, err)
+	err = sink.Publish(context.Background(), []*apiV1beta1.TupleEvent{{Id: "a", Principal: "alice"}})
+
+	require.NoError(t, err)
+	require.Len(t, got.Records, 1)
+	assert.Equal(t, "a", got.Records[0].Key)
+	assert.JSONEq(t, `{"id":"a","principal":"alice"}`, string(got.Records[0].Value))
+}
+
+func TestKafkaRestSink_FailsOnRecordErrors(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50003,"error":"leader not available"}]}`))
+	}))
+	defer srv.Close()
+
+	sink, err := newKafkaRestSink(&conf.Data_Events_Kafka{RestProxyUrl: srv.URL, Topic: "kessel.tuples"})
+	require.NoError(t, err)
+	err = sink.Publish(context.Background(), []*apiV1beta1.TupleEvent{{Id: "a"}})
+
+	assert.ErrorContains(t, err, "leader not available")
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const defaultKafkaProduceTimeout = 10 * time.Second
+
+// writerSink writes events as JSON lines, for local use.
+type writerSink struct {
+	w io.WriteCloser
+}
+
+func newWriterSink(w io.WriteCloser) *writerSink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Publish(_ context.Context, events []*apiV1beta1.TupleEvent) error {
+	var buf bytes.Buffer
+	for _, event := range events {
+		b, err := protojson.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if f, ok := s.w.(*os.File); ok {
+		return f.Sync()
+	}
+	return nil
+}
+
+func (s *writerSink) Close() error {
+	return s.w.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// kafkaRestSink produces events to a Kafka topic through a REST proxy speaking the Confluent v2 API, keyed by event id.
+type kafkaRestSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func newKafkaRestSink(c *conf.Data_Events_Kafka) (*kafkaRestSink, error) {
+	if c.GetRestProxyUrl() == "" || c.GetTopic() == "" {
+		return nil, fmt.Errorf("events.kafka.restProxyUrl and events.kafka.topic must be set for the kafka sink")
+	}
+	return &kafkaRestSink{
+		endpoint: strings.TrimSuffix(c.GetRestProxyUrl(), "/") + "/topics/" + url.PathEscape(c.GetTopic()),
+		client:   &http.Client{Timeout: durationOr(c.GetTimeout().AsDuration(), defaultKafkaProduceTimeout)},
+	}, nil
+}
+
+func (s *kafkaRestSink) Publish(ctx context.Context, events []*apiV1beta1.TupleEvent) error {
+	produce := kafkaProduceRequest{Records: make([]kafkaRecord, 0, len(events))}
+	for _, event := range events {
+		b, err := protojson.Marshal(event)
+		if err != nil {
+			return err
+		}
+		produce.Records = append(produce.Records, kafkaRecord{Key: event.GetId(), Value: b})
+	}
+	body, err := json.Marshal(produce)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error producing to kafka: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("error producing to kafka: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("error decoding kafka produce response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("error producing to kafka: %s (code %d)", offset.Error, *offset.ErrorCode)
+		}
+	}
+	return nil
+}
+
+func (s *kafkaRestSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,199 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultEventsLockId   = "kessel-relations-events"
+	defaultLeaseDuration  = 30 * time.Second
+	leaseVersionSeparator = "|"
+)
+
+var errLockTaken = errors.New("lock was acquired elsewhere")
+
+// lockVersions reads and replaces the versions of locks.
+type lockVersions interface {
+	// lockVersion returns the version of the lock, "" if it was never acquired.
+	lockVersion(ctx context.Context, lockId string) (string, error)
+	// replaceLockVersion replaces the version from, "" for a lock never acquired, with to. It fails with
+	// errLockTaken if the lock no longer has the version from.
+	replaceLockVersion(ctx context.Context, lockId, from, to string) error
+}
+
+// relayLease elects the one replica relaying tuple changes, so that each change is published once rather than by
+// every replica. The holder renews the lease every third of duration by replacing the version of its lock, and another
+// replica takes the lease over once the version has not changed for duration. Versions carry the watch cursor of the
+// holder, so the replica taking over resumes watching where the previous holder stopped.
+type relayLease struct {
+	locks    lockVersions
+	lockId   string
+	duration time.Duration
+	now      func() time.Time
+	log      *log.Helper
+
+	held string // the version written by this replica, only used by the relay goroutine
+}
+
+func newRelayLease(locks lockVersions, lockId string, duration time.Duration, logger log.Logger) *relayLease {
+	if lockId == "" {
+		lockId = defaultEventsLockId
+	}
+	return &relayLease{
+		locks:    locks,
+		lockId:   lockId,
+		duration: durationOr(duration, defaultLeaseDuration),
+		now:      time.Now,
+		log:      log.NewHelper(logger),
+	}
+}
+
+func (l *relayLease) interval() time.Duration {
+	return l.duration / 3
+}
+
+// acquire waits until this replica holds the lease, returning the watch cursor of the previous holder, if any.
+func (l *relayLease) acquire(ctx context.Context) (string, error) {
+	observed, observedAt := "", time.Time{}
+	for {
+		version, err := l.locks.lockVersion(ctx, l.lockId)
+		if err != nil {
+			l.log.Warnf("error reading lease %s: %v", l.lockId, err)
+		} else {
+			if observedAt.IsZero() || version != observed {
+				observed, observedAt = version, l.now()
+			}
+			if version == "" || l.now().Sub(observedAt) >= l.duration {
+				cursor := leaseCursor(version)
+				mine := newLeaseVersion(cursor)
+				err := l.locks.replaceLockVersion(ctx, l.lockId, version, mine)
+				if err == nil {
+					l.held = mine
+					l.log.Infof("holding lease %s, relaying tuple changes", l.lockId)
+					return cursor, nil
+				}
+				// a replica taking the lease over first is expected, it is renewed by the new holder
+				if !errors.Is(err, errLockTaken) {
+					l.log.Warnf("error acquiring lease %s: %v", l.lockId, err)
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(l.interval()):
+		}
+	}
+}
+
+// hold renews the lease with the current watch cursor until ctx is done, returning early once the lease is lost:
+// when another replica took it over, or when it could not be renewed for duration and may have been.
+func (l *relayLease) hold(ctx context.Context, cursor func() string) {
+	renewed := l.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.interval()):
+		}
+		mine := newLeaseVersion(cursor())
+		err := l.locks.replaceLockVersion(ctx, l.lockId, l.held, mine)
+		switch {
+		case err == nil:
+			l.held, renewed = mine, l.now()
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, errLockTaken):
+			l.log.Warnf("lease %s was taken over, no longer relaying tuple changes", l.lockId)
+			return
+		case l.now().Sub(renewed) >= l.duration:
+			l.log.Errorf("lease %s was not renewed for %s, no longer relaying tuple changes: %v", l.lockId, l.duration, err)
+			return
+		default:
+			l.log.Warnf("error renewing lease %s: %v", l.lockId, err)
+		}
+	}
+}
+
+// newLeaseVersion returns a version unique to the holder renewing the lease, carrying its watch cursor.
+func newLeaseVersion(cursor string) string {
+	if cursor == "" {
+		return uuid.New().String()
+	}
+	return uuid.New().String() + leaseVersionSeparator + cursor
+}
+
+func leaseCursor(version string) string {
+	_, cursor, _ := strings.Cut(version, leaseVersionSeparator)
+	return cursor
+}
+
+func lockRelationship(lockId, version string) *v1.Relationship {
+	return &v1.Relationship{
+		Resource: &v1.ObjectReference{ObjectType: lockType, ObjectId: lockId},
+		Relation: addRelationPrefix(lockVersionRelation, relationPrefix),
+		Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: lockVersionType, ObjectId: version}},
+	}
+}
+
+func lockFilter(lockId string) *v1.RelationshipFilter {
+	return &v1.RelationshipFilter{
+		ResourceType:       lockType,
+		OptionalResourceId: lockId,
+		OptionalRelation:   addRelationPrefix(lockVersionRelation, relationPrefix),
+	}
+}
+
+func (s *SpiceDbRepository) lockVersion(ctx context.Context, lockId string) (string, error) {
+	if err := s.InitializeSchema(ctx); err != nil {
+		return "", err
+	}
+	rels, _, err := s.readMatching(ctx, lockFilter(lockId), 1)
+	if err != nil || len(rels) == 0 {
+		return "", err
+	}
+	return rels[0].GetSubject().GetObject().GetObjectId(), nil
+}
+
+func (s *SpiceDbRepository) replaceLockVersion(ctx context.Context, lockId, from, to string) error {
+	if err := s.InitializeSchema(ctx); err != nil {
+		return err
+	}
+	req := &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+			Relationship: lockRelationship(lockId, to),
+		}},
+	}
+	if from == "" {
+		req.OptionalPreconditions = []*v1.Precondition{{
+			Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH,
+			Filter:    lockFilter(lockId),
+		}}
+	} else {
+		previous := lockRelationship(lockId, from)
+		req.Updates = append(req.Updates, &v1.RelationshipUpdate{
+			Operation:    v1.RelationshipUpdate_OPERATION_DELETE,
+			Relationship: previous,
+		})
+		req.OptionalPreconditions = []*v1.Precondition{{
+			Operation: v1.Precondition_OPERATION_MUST_MATCH,
+			Filter:    relationshipMatching(previous),
+		}}
+	}
+	if _, err := s.client.WriteRelationships(ctx, req); err != nil {
+		if isPreconditionFailure(err) {
+			return errLockTaken
+		}
+		return fmt.Errorf("error writing relationships to SpiceDB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memLocks keeps lock versions in memory.
+type memLocks struct {
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+func (l *memLocks) lockVersion(_ context.Context, lockId string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.versions[lockId], nil
+}
+
+func (l *memLocks) replaceLockVersion(_ context.Context, lockId, from, to string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.versions[lockId] != from {
+		return errLockTaken
+	}
+	if l.versions == nil {
+		l.versions = map[string]string{}
+	}
+	l.versions[lockId] = to
+	return nil
+}
+
+func (l *memLocks) set(lockId, version string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.versions == nil {
+		l.versions = map[string]string{}
+	}
+	l.versions[lockId] = version
+}
+
+func TestRelayLease_AcquiresAFreeLockAtOnce(t *testing.T) {
+	t.Parallel()
+
+	locks := &memLocks{}
+	lease := newRelayLease(locks, "", time.Hour, log.DefaultLogger)
+
+	cursor, err := lease.acquire(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, cursor)
+	version, _ := locks.lockVersion(context.Background(), defaultEventsLockId)
+	assert.Equal(t, lease.held, version)
+}
+
+func TestRelayLease_TakesOverAStaleLeaseWithItsCursor(t *testing.T) {
+	t.Parallel()
+
+	locks := &memLocks{}
+	locks.set("events", newLeaseVersion("rev7"))
+	lease := newRelayLease(locks, "events", 30*time.Millisecond, log.DefaultLogger)
+
+	start := time.Now()
+	cursor, err := lease.acquire(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond, "the lease is only taken over once it was not renewed")
+	assert.Equal(t, "rev7", cursor)
+	assert.Equal(t, "rev7", leaseCursor(lease.held))
+}
+
+func TestRelayLease_WaitsWhileTheHolderRenews(t *testing.T) {
+	t.Parallel()
+
+	locks := &memLocks{}
+	holder := newRelayLease(locks, "events", 30*time.Millisecond, log.DefaultLogger)
+	_, err := holder.acquire(context.Background())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	held := make(chan struct{})
+	go func() {
+		defer close(held)
+		holder.hold(ctx, func() string { return "rev3" })
+	}()
+
+	waiter := newRelayLease(locks, "events", 30*time.Millisecond, log.DefaultLogger)
+	waitCtx, stopWaiting := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer stopWaiting()
+	_, err = waiter.acquire(waitCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "a renewed lease is not taken over")
+
+	cancel()
+	<-held
+	cursor, err := waiter.acquire(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "rev3", cursor, "renewals carry the cursor of the holder")
+}
+
+func TestRelayLease_HoldReturnsOnceTakenOver(t *testing.T) {
+	t.Parallel()
+
+	locks := &memLocks{}
+	lease := newRelayLease(locks, "events", 30*time.Millisecond, log.DefaultLogger)
+	_, err := lease.acquire(context.Background())
+	require.NoError(t, err)
+
+	locks.set("events", newLeaseVersion(""))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lease.hold(context.Background(), func() string { return "" })
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("hold did not return after the lease was taken over")
+	}
+}
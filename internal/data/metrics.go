@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc/status"
+
+	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+const (
+	// SpiceDbDurationHistogramName is the latency of SpiceDB calls by method and gRPC status code, streams until
+	// they end.
+	SpiceDbDurationHistogramName = "kessel_relations_spicedb_request_duration_seconds"
+	// TuplesWrittenCounterName counts the tuples created or touched, by resource type and relation.
+	TuplesWrittenCounterName = "kessel_relations_tuples_written"
+	// TuplesDeletedCounterName counts the tuples deleted, by resource type and relation. Deletions by filter are
+	// counted under the type and relation of the filter, empty if the filter does not restrict them.
+	TuplesDeletedCounterName = "kessel_relations_tuples_deleted"
+	// TuplesImportedCounterName counts the tuples loaded by bulk imports.
+	TuplesImportedCounterName = "kessel_relations_tuples_imported"
+	// LockAcquisitionsCounterName counts lock acquisitions by outcome: acquired, conflict if another writer acquired
+	// the lock concurrently, or error.
+	LockAcquisitionsCounterName = "kessel_relations_lock_acquisitions"
+	// FenceFailuresCounterName counts fenced writes refused because their lock was acquired by another writer, by
+	// operation.
+	FenceFailuresCounterName = "kessel_relations_fence_failures"
+)
+
+// Attributes of the repository metrics. Resource types and relations are only recorded once SpiceDB accepted them,
+// so their values are bounded by the schema.
+const (
+	methodAttr       = attribute.Key("method")
+	codeAttr         = attribute.Key("code")
+	resourceTypeAttr = attribute.Key("resource_type")
+	relationAttr     = attribute.Key("relation")
+	outcomeAttr      = attribute.Key("outcome")
+	operationAttr    = attribute.Key("operation")
+)
+
+type repositoryMetrics struct {
+	spiceDbDuration metric.Float64Histogram
+	written         metric.Int64Counter
+	deleted         metric.Int64Counter
+	imported        metric.Int64Counter
+	locks           metric.Int64Counter
+	fenceFailures   metric.Int64Counter
+}
+
+func newRepositoryMetrics(meter metric.Meter) (*repositoryMetrics, error) {
+	var (
+		m   repositoryMetrics
+		err error
+	)
+	if m.spiceDbDuration, err = meter.Float64Histogram(
+		SpiceDbDurationHistogramName,
+		metric.WithUnit("s"),
+		metric.WithDescription("The duration of SpiceDB calls, by method and status code"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	); err != nil {
+		return nil, err
+	}
+	counters := []struct {
+		counter     *metric.Int64Counter
+		name        string
+		unit        string
+		description string
+	}{
+		{&m.written, TuplesWrittenCounterName, "{tuple}", "The total number of tuples created or touched, by resource type and relation"},
+		{&m.deleted, TuplesDeletedCounterName, "{tuple}", "The total number of tuples deleted, by resource type and relation"},
+		{&m.imported, TuplesImportedCounterName, "{tuple}", "The total number of tuples loaded by bulk imports"},
+		{&m.locks, LockAcquisitionsCounterName, "{acquisition}", "The total number of lock acquisitions, by outcome"},
+		{&m.fenceFailures, FenceFailuresCounterName, "{write}", "The total number of fenced writes refused because their lock was lost, by operation"},
+	}
+	for _, c := range counters {
+		if *c.counter, err = meter.Int64Counter(c.name, metric.WithUnit(c.unit), metric.WithDescription(c.description)); err != nil {
+			return nil, err
+		}
+	}
+	return &m, nil
+}
+
+// spiceDbCallFinished records the duration of a SpiceDB call.
+func (m *repositoryMetrics) spiceDbCallFinished(ctx context.Context, method string, start time.Time, err error) {
+	m.spiceDbDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		methodAttr.String(method),
+		codeAttr.String(status.Code(err).String()),
+	))
+}
+
+// tupleKind is the resource type, as "namespace/name", and relation tuples are counted by.
+type tupleKind struct {
+	resourceType string
+	relation     string
+}
+
+func kindOf(rel *apiV1beta1.Relationship) tupleKind {
+	t := rel.GetResource().GetType()
+	return tupleKind{resourceType: t.GetNamespace() + "/" + t.GetName(), relation: rel.GetRelation()}
+}
+
+// countByKind counts tuples by kind. Relations must not be prefixed yet.
+func countByKind(rels []*apiV1beta1.Relationship) map[tupleKind]int64 {
+	counts := make(map[tupleKind]int64)
+	for _, rel := range rels {
+		counts[kindOf(rel)]++
+	}
+	return counts
+}
+
+func (m *repositoryMetrics) add(ctx context.Context, counter metric.Int64Counter, counts map[tupleKind]int64) {
+	for kind, n := range counts {
+		counter.Add(ctx, n, metric.WithAttributes(resourceTypeAttr.String(kind.resourceType), relationAttr.String(kind.relation)))
+	}
+}
+
+func (m *repositoryMetrics) tuplesWritten(ctx context.Context, counts map[tupleKind]int64) {
+	m.add(ctx, m.written, counts)
+}
+
+func (m *repositoryMetrics) tuplesDeleted(ctx context.Context, counts map[tupleKind]int64) {
+	m.add(ctx, m.deleted, counts)
+}
+
+// tuplesDeletedByFilter records a deletion by filter under the resource type and relation of the filter.
+func (m *repositoryMetrics) tuplesDeletedByFilter(ctx context.Context, filter *apiV1beta1.RelationTupleFilter, relation string, deleted uint64) {
+	if deleted == 0 {
+		return
+	}
+	resourceType := ""
+	if filter.GetResourceNamespace() != "" || filter.GetResourceType() != "" {
+		resourceType = filter.GetResourceNamespace() + "/" + filter.GetResourceType()
+	}
+	m.deleted.Add(ctx, int64(deleted), metric.WithAttributes(resourceTypeAttr.String(resourceType), relationAttr.String(relation)))
+}
+
+func (m *repositoryMetrics) tuplesImported(ctx context.Context, n uint64) {
+	m.imported.Add(ctx, int64(n))
+}
+
+func (m *repositoryMetrics) lockAcquired(ctx context.Context, outcome string) {
+	m.locks.Add(ctx, 1, metric.WithAttributes(outcomeAttr.String(outcome)))
+}
+
+func (m *repositoryMetrics) fenceFailed(ctx context.Context, operation string) {
+	m.fenceFailures.Add(ctx, 1, metric.WithAttributes(operationAttr.String(operation)))
+}
@@ -0,0 +1,135 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+func newTestMetrics(t *testing.T) (*repositoryMetrics, *sdkmetric.ManualReader) {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	m, err := newRepositoryMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	require.NoError(t, err)
+	return m, reader
+}
+
+// collectMetrics returns the metrics recorded so far by name.
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+// sums returns the value of each data point of a counter, keyed by its encoded attributes.
+func sums(data metricdata.Aggregation) map[string]int64 {
+	values := map[string]int64{}
+	if sum, ok := data.(metricdata.Sum[int64]); ok {
+		for _, dp := range sum.DataPoints {
+			values[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp.Value
+		}
+	}
+	return values
+}
+
+func attrs(kvs ...attribute.KeyValue) string {
+	set := attribute.NewSet(kvs...)
+	return set.Encoded(attribute.DefaultEncoder())
+}
+
+func tuple(namespace, name, relation string) *apiV1beta1.Relationship {
+	return &apiV1beta1.Relationship{
+		Resource: &apiV1beta1.ObjectReference{Type: &apiV1beta1.ObjectType{Namespace: namespace, Name: name}, Id: "1"},
+		Relation: relation,
+	}
+}
+
+func TestRepositoryMetrics_CountsTuplesByResourceTypeAndRelation(t *testing.T) {
+	t.Parallel()
+	m, reader := newTestMetrics(t)
+	ctx := context.Background()
+
+	m.tuplesWritten(ctx, countByKind([]*apiV1beta1.Relationship{
+		tuple("rbac", "group", "member"),
+		tuple("rbac", "group", "member"),
+		tuple("rbac", "workspace", "parent"),
+	}))
+	m.tuplesDeletedByFilter(ctx, &apiV1beta1.RelationTupleFilter{ResourceNamespace: pointerize("rbac"), ResourceType: pointerize("group")}, "", 5)
+	m.tuplesDeletedByFilter(ctx, &apiV1beta1.RelationTupleFilter{ResourceNamespace: pointerize("rbac"), ResourceType: pointerize("group")}, "member", 0)
+
+	metrics := collectMetrics(t, reader)
+	assert.Equal(t, map[string]int64{
+		attrs(resourceTypeAttr.String("rbac/group"), relationAttr.String("member")):     2,
+		attrs(resourceTypeAttr.String("rbac/workspace"), relationAttr.String("parent")): 1,
+	}, sums(metrics[TuplesWrittenCounterName]))
+	assert.Equal(t, map[string]int64{
+		attrs(resourceTypeAttr.String("rbac/group"), relationAttr.String("")): 5,
+	}, sums(metrics[TuplesDeletedCounterName]), "deletions of nothing are not recorded")
+}
+
+func TestRepositoryMetrics_CountsLocksAndFenceFailures(t *testing.T) {
+	t.Parallel()
+	m, reader := newTestMetrics(t)
+	ctx := context.Background()
+
+	m.lockAcquired(ctx, "acquired")
+	m.lockAcquired(ctx, "acquired")
+	m.lockAcquired(ctx, "conflict")
+	m.fenceFailed(ctx, "delete")
+
+	metrics := collectMetrics(t, reader)
+	assert.Equal(t, map[string]int64{
+		attrs(outcomeAttr.String("acquired")): 2,
+		attrs(outcomeAttr.String("conflict")): 1,
+	}, sums(metrics[LockAcquisitionsCounterName]))
+	assert.Equal(t, map[string]int64{
+		attrs(operationAttr.String("delete")): 1,
+	}, sums(metrics[FenceFailuresCounterName]))
+}
+
+func TestSpiceDbTracer_RecordsCallDurationsByMethodAndCode(t *testing.T) {
+	t.Parallel()
+	m, reader := newTestMetrics(t)
+	tracer := newSpiceDbTracer(noop.NewTracerProvider(), propagation.TraceContext{}, m)
+
+	invoke := func(err error) {
+		_ = tracer.unaryInterceptor()(context.Background(), checkPermission, &v1.CheckPermissionRequest{}, nil, nil,
+			func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return err
+			})
+	}
+	invoke(nil)
+	invoke(nil)
+	invoke(status.Error(codes.Unavailable, "connection refused"))
+
+	histogram, ok := collectMetrics(t, reader)[SpiceDbDurationHistogramName].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	counts := map[string]uint64{}
+	for _, dp := range histogram.DataPoints {
+		counts[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp.Count
+	}
+	assert.Equal(t, map[string]uint64{
+		attrs(methodAttr.String("CheckPermission"), codeAttr.String("OK")):          2,
+		attrs(methodAttr.String("CheckPermission"), codeAttr.String("Unavailable")): 1,
+	}, counts)
+}
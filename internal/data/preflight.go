@@ -0,0 +1,61 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/project-kessel/relations-api/internal/biz"
+)
+
+// Preflight checks that SpiceDB is reachable, accepts the token and has the schema, applying or verifying it
+// according to the schema mode. Checks after a failed one are skipped.
+func (s *SpiceDbRepository) Preflight(ctx context.Context) []biz.PreflightCheck {
+	steps := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{"connection", s.checkConnection},
+		{"token", s.checkToken},
+		{"schema", s.InitializeSchema},
+	}
+
+	var checks []biz.PreflightCheck
+	for _, step := range steps {
+		err := step.check(ctx)
+		checks = append(checks, biz.PreflightCheck{Name: step.name, Err: err})
+		if err != nil {
+			break
+		}
+	}
+	return checks
+}
+
+func (s *SpiceDbRepository) checkConnection(ctx context.Context) error {
+	resp, err := s.healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("error connecting to spicedb: %w", err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("spicedb is not serving: %v", resp.GetStatus())
+	}
+	return nil
+}
+
+// checkToken makes an authenticated call. SpiceDB without a schema yet answers NotFound, which still proves the
+// token was accepted.
+func (s *SpiceDbRepository) checkToken(ctx context.Context) error {
+	_, err := s.client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	switch status.Code(err) {
+	case codes.OK, codes.NotFound:
+		return nil
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return fmt.Errorf("spicedb rejected the token: %w", err)
+	default:
+		return fmt.Errorf("error reading schema from spicedb: %w", err)
+	}
+}
@@ -0,0 +1,118 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+)
+
+// tokenCredentials attaches the SpiceDB preshared key to every call. The key can be replaced while calls are in
+// flight, so a rotated secret takes effect without reconnecting.
+type tokenCredentials struct {
+	token  atomic.Pointer[string]
+	secure bool
+}
+
+func newTokenCredentials(token string, secure bool) *tokenCredentials {
+	t := &tokenCredentials{secure: secure}
+	t.token.Store(&token)
+	return t
+}
+
+func (t *tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + *t.token.Load()}, nil
+}
+
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return t.secure
+}
+
+// set replaces the token, reporting whether it changed.
+func (t *tokenCredentials) set(token string) bool {
+	return *t.token.Swap(&token) != token
+}
+
+// reloadTimeout bounds writing a reloaded schema to SpiceDB.
+const reloadTimeout = 30 * time.Second
+
+// reloadFiles re-reads the watched token and schema files after a change on disk. A reload in progress is cancelled
+// when the repository is cleaned up.
+func (s *SpiceDbRepository) reloadFiles() {
+	ctx, cancel := context.WithTimeout(s.ctx, reloadTimeout)
+	defer cancel()
+	if s.tokenFile != "" {
+		s.logReload(ctx, "spicedb_token", s.tokenFile, s.reloadToken())
+	}
+	if s.schemaFilePath != "" {
+		s.logReload(ctx, "schema", s.schemaFilePath, s.reloadSchema(ctx))
+	}
+}
+
+var errUnchanged = errors.New("unchanged")
+
+func (s *SpiceDbRepository) reloadToken() error {
+	token, err := readFile(s.tokenFile)
+	if err != nil {
+		return fmt.Errorf("error loading token file: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("token is empty")
+	}
+	if !s.credentials.set(token) {
+		return errUnchanged
+	}
+	return nil
+}
+
+// reloadSchema writes the schema file to SpiceDB if it differs from the schema last written. SpiceDB validates the
+// schema and rejects changes that would orphan existing relationships, in which case the current schema stays in
+// effect. In verify mode the changed file is only compared with the schema in SpiceDB.
+func (s *SpiceDbRepository) reloadSchema(ctx context.Context) error {
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+
+	schema, err := readFile(s.schemaFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load schema file: %w", err)
+	}
+	if schema == s.appliedSchema {
+		return errUnchanged
+	}
+	if schema == "" {
+		return fmt.Errorf("schema file is empty")
+	}
+	if err := s.applySchema(ctx, schema); err != nil {
+		return err
+	}
+	s.appliedSchema = schema
+	s.isInitialized.Store(true)
+	return nil
+}
+
+// Configuration reload - SEC-MON-REQ-1 compliance (EOI-5 process_status, EOI-11 warnings_or_errors)
+func (s *SpiceDbRepository) logReload(ctx context.Context, resourceType, file string, err error) {
+	switch {
+	case errors.Is(err, errUnchanged):
+	case err != nil:
+		s.auditor.Record(ctx, audit.Event{
+			Message:      "Reload failed, previous value remains in use",
+			Action:       "RELOAD",
+			ResourceType: resourceType,
+			ResourceID:   file,
+			Outcome:      audit.OutcomeFailure,
+			Reason:       err.Error(),
+		})
+	default:
+		s.auditor.Record(ctx, audit.Event{
+			Message:      "Reloaded from file",
+			Action:       "RELOAD",
+			ResourceType: resourceType,
+			ResourceID:   file,
+			Outcome:      audit.OutcomeSuccess,
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package data
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/authzed/authzed-go/v1"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestTokenCredentials_RotatesToken(t *testing.T) {
+	t.Parallel()
+
+	creds := newTokenCredentials("first", true)
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer first", md["authorization"])
+	assert.True(t, creds.RequireTransportSecurity())
+
+	assert.False(t, creds.set("first"))
+	assert.True(t, creds.set("second"))
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer second", md["authorization"])
+}
+
+func TestReloadToken_KeepsTokenWhenFileIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	repo := &SpiceDbRepository{
+		tokenFile:   tokenFile,
+		credentials: newTokenCredentials("first", false),
+		log:         log.NewHelper(log.NewStdLogger(io.Discard)),
+	}
+
+	require.NoError(t, os.WriteFile(tokenFile, nil, 0600))
+	assert.Error(t, repo.reloadToken())
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first"), 0600))
+	assert.ErrorIs(t, repo.reloadToken(), errUnchanged)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second"), 0600))
+	assert.NoError(t, repo.reloadToken())
+	assert.Equal(t, "second", *repo.credentials.token.Load())
+}
+
+// schemaWriter accepts every schema written while the context of the write is live.
+type schemaWriter struct {
+	v1.SchemaServiceClient
+	deadline bool
+}
+
+func (w *schemaWriter) WriteSchema(ctx context.Context, _ *v1.WriteSchemaRequest, _ ...grpc.CallOption) (*v1.WriteSchemaResponse, error) {
+	_, w.deadline = ctx.Deadline()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &v1.WriteSchemaResponse{}, nil
+}
+
+func TestReloadFiles_StopsWithRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	writer := &schemaWriter{}
+	repo := &SpiceDbRepository{
+		ctx:            ctx,
+		client:         &authzed.Client{SchemaServiceClient: writer},
+		schemaFilePath: filepath.Join(t.TempDir(), "schema.zed"),
+		log:            log.NewHelper(log.NewStdLogger(io.Discard)),
+	}
+
+	require.NoError(t, os.WriteFile(repo.schemaFilePath, []byte("definition rbac/principal {}"), 0600))
+	repo.reloadFiles()
+	assert.True(t, writer.deadline, "schema writes are bounded")
+	assert.Equal(t, "definition rbac/principal {}", repo.appliedSchema)
+
+	cancel()
+	require.NoError(t, os.WriteFile(repo.schemaFilePath, []byte("definition rbac/group {}"), 0600))
+	repo.reloadFiles()
+	assert.Equal(t, "definition rbac/principal {}", repo.appliedSchema, "reloads stop once the repository is cleaned up")
+}
@@ -0,0 +1,73 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/project-kessel/relations-api/internal/schema"
+)
+
+// ReadSchema returns the schema written to SpiceDB, or "" if none has been written yet.
+func (s *SpiceDbRepository) ReadSchema(ctx context.Context) (string, error) {
+	resp, err := s.client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if status.Code(err) == codes.NotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading schema from spicedb: %w", err)
+	}
+	return resp.GetSchemaText(), nil
+}
+
+// CountRelationships counts the tuples matching filter, which uses SpiceDB's type and relation names, with a fully
+// consistent read. Counting stops once limit is exceeded, reported by the second result.
+func (s *SpiceDbRepository) CountRelationships(ctx context.Context, filter schema.TupleFilter, limit uint64) (uint64, bool, error) {
+	relationshipFilter := &v1.RelationshipFilter{
+		ResourceType:     filter.ResourceType,
+		OptionalRelation: filter.Relation,
+	}
+	if filter.SubjectType != "" {
+		relationshipFilter.OptionalSubjectFilter = &v1.SubjectFilter{
+			SubjectType:      filter.SubjectType,
+			OptionalRelation: &v1.SubjectFilter_RelationFilter{Relation: filter.SubjectRelation},
+		}
+		if filter.Wildcard {
+			relationshipFilter.OptionalSubjectFilter.OptionalSubjectId = "*"
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client, err := s.client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: relationshipFilter,
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
+	}
+
+	var count uint64
+	for {
+		msg, err := client.Recv()
+		if errors.Is(err, io.EOF) {
+			return count, false, nil
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("error counting relationships %s: %w", filter, err)
+		}
+		// SpiceDB cannot exclude wildcards, so subject types without one are filtered here
+		if filter.SubjectType != "" && !filter.Wildcard && msg.GetRelationship().GetSubject().GetObject().GetObjectId() == "*" {
+			continue
+		}
+		if count == limit {
+			return count, true, nil
+		}
+		count++
+	}
+}
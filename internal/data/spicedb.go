@@ -6,33 +6,58 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
 	"github.com/project-kessel/relations-api/internal/biz"
 	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/filewatch"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/authzed-go/v1"
-	"github.com/authzed/grpcutil"
 	kerrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // SpiceDbRepository .
 type SpiceDbRepository struct {
 	client          *authzed.Client
 	healthClient    grpc_health_v1.HealthClient
+	breaker         *circuitBreaker
 	schemaFilePath  string
-	isInitialized   bool
+	schemaMode      string
+	tokenFile       string
+	credentials     *tokenCredentials
+	schemaMu        sync.Mutex
+	appliedSchema   string
+	isInitialized   atomic.Bool
 	fullyConsistent bool //TODO: rename flag to smth like fullyConsistentAsDefault
+	tokens          *consistencyTokenCodec
+	maxDeletions    uint32
+	dryRunSamples   int
+	metrics         *repositoryMetrics
+	auditor         *audit.Auditor
 	log             *log.Helper
+	// ctx is cancelled when the repository is cleaned up, stopping background work such as reloads
+	ctx context.Context
 }
 
 const (
@@ -40,18 +65,61 @@ const (
 	lockType            = "kessel/lock"
 	lockVersionType     = "kessel/lockversion"
 	lockVersionRelation = "version"
+
+	defaultDryRunSampleSize = 10
+
+	// maxListedDeletions is the most tuples deleted one by one, SpiceDB accepts up to 1000 updates and preconditions
+	// per write by default and one precondition is left for fencing
+	maxListedDeletions = 999
+	// watchDeletedTimeout bounds how long the tuples a deletion by filter removed are looked for in the Watch API
+	watchDeletedTimeout = 5 * time.Second
+
+	// schemaModeApply writes the schema file to SpiceDB, schemaModeVerify only checks that SpiceDB has it
+	schemaModeApply  = "apply"
+	schemaModeVerify = "verify"
 )
 
 // NewSpiceDbRepository .
-func NewSpiceDbRepository(c *conf.Data, logger log.Logger) (*SpiceDbRepository, func(), error) {
+func NewSpiceDbRepository(c *conf.Data, meter metric.Meter, auditor *audit.Auditor, logger log.Logger) (*SpiceDbRepository, func(), error) {
 	log.NewHelper(logger).Info("creating spicedb connection")
 
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.EmptyDialOption{})
-	//TODO: add a flag to enable/disable grpc.WithBlock
+	schemaMode := c.SpiceDb.GetSchemaMode()
+	switch schemaMode {
+	case "":
+		schemaMode = schemaModeApply
+	case schemaModeApply, schemaModeVerify:
+	default:
+		return nil, nil, fmt.Errorf("error creating spicedb client: unknown schemaMode %q", schemaMode)
+	}
+
+	// connection.waitForReady queues calls while SpiceDB is unreachable, bounded by the call deadline
+	opts, err := connectionOptions(c.SpiceDb.GetConnection())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating spicedb client: %w", err)
+	}
+	metrics, err := newRepositoryMetrics(meter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating spicedb client: %w", err)
+	}
+	// spans start before the breaker so calls it rejects are traced too
+	tracer := newSpiceDbTracer(otel.GetTracerProvider(), otel.GetTextMapPropagator(), metrics)
+	opts = append(opts,
+		grpc.WithChainUnaryInterceptor(tracer.unaryInterceptor()),
+		grpc.WithChainStreamInterceptor(tracer.streamInterceptor()),
+	)
+	breaker := newCircuitBreaker(
+		c.SpiceDb.GetConnection().GetCircuitBreaker().GetFailureThreshold(),
+		c.SpiceDb.GetConnection().GetCircuitBreaker().GetOpenDuration().AsDuration(),
+		logger,
+	)
+	if breaker != nil {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(breaker.unaryInterceptor()),
+			grpc.WithChainStreamInterceptor(breaker.streamInterceptor()),
+		)
+	}
 
 	var token string
-	var err error
 	if c.SpiceDb.Token != "" {
 		token = c.SpiceDb.Token
 	} else if c.SpiceDb.TokenFile != "" {
@@ -65,44 +133,110 @@ func NewSpiceDbRepository(c *conf.Data, logger log.Logger) (*SpiceDbRepository,
 		return nil, nil, fmt.Errorf("error creating spicedb client: token is empty")
 	}
 
+	tokenCredentials := newTokenCredentials(token, c.SpiceDb.UseTLS)
+	opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials))
 	if !c.SpiceDb.UseTLS {
-		opts = append(opts, grpcutil.WithInsecureBearerToken(token))
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
-		tlsConfig, _ := grpcutil.WithSystemCerts(grpcutil.VerifyCA)
-		opts = append(opts, grpcutil.WithBearerToken(token))
-		opts = append(opts, tlsConfig)
+		tlsConfig, err := newSpiceDbTLSConfig(c.SpiceDb.GetTls())
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating spicedb client: %w", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	}
 
-	client, err := authzed.NewClient(
-		c.SpiceDb.Endpoint,
-		opts...,
-	)
-
+	pool, err := newConnPool(c.SpiceDb.Endpoint, c.SpiceDb.GetConnection().GetPoolSize(), opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error creating spicedb client: %w", err)
 	}
+	client := &authzed.Client{
+		SchemaServiceClient:      v1.NewSchemaServiceClient(pool),
+		PermissionsServiceClient: v1.NewPermissionsServiceClient(pool),
+		WatchServiceClient:       v1.NewWatchServiceClient(pool),
+	}
 
-	// Create health client for readyz
-	conn, err := grpc.NewClient(
-		c.SpiceDb.Endpoint,
-		opts...,
-	)
+	// Create health client for readyz. Health checks pass through the circuit breaker, so readiness doubles as the
+	// trial call closing it once SpiceDB is back.
+	healthClient := grpc_health_v1.NewHealthClient(pool)
+
+	tokens, err := newConsistencyTokenCodec(c.SpiceDb)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error creating grpc health client: %w", err)
+		_ = pool.Close()
+		return nil, nil, fmt.Errorf("error creating spicedb client: %w", err)
+	}
+	if !tokens.signed() {
+		log.NewHelper(logger).Warn("no consistency token signing key configured, consistency tokens will not be signed")
 	}
-	healthClient := grpc_health_v1.NewHealthClient(conn)
 
-	cleanup := func() {
-		log.NewHelper(logger).Info("spicedb connection cleanup requested (nothing to clean up)")
+	dryRunSamples := int(c.SpiceDb.GetDeleteGuardrails().GetDryRunSampleSize())
+	if dryRunSamples == 0 {
+		dryRunSamples = defaultDryRunSampleSize
 	}
 
 	log := log.NewHelper(logger)
-	return &SpiceDbRepository{client, healthClient, c.SpiceDb.SchemaFile, false, c.SpiceDb.FullyConsistent, log}, cleanup, nil
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := &SpiceDbRepository{
+		ctx:             ctx,
+		client:          client,
+		healthClient:    healthClient,
+		breaker:         breaker,
+		schemaFilePath:  c.SpiceDb.SchemaFile,
+		schemaMode:      schemaMode,
+		credentials:     tokenCredentials,
+		fullyConsistent: c.SpiceDb.FullyConsistent,
+		tokens:          tokens,
+		maxDeletions:    c.SpiceDb.GetDeleteGuardrails().GetMaxDeletions(),
+		dryRunSamples:   dryRunSamples,
+		metrics:         metrics,
+		auditor:         auditor,
+		log:             log,
+	}
+	if c.SpiceDb.Token == "" {
+		repo.tokenFile = c.SpiceDb.TokenFile
+	}
+
+	var watcher *filewatch.Watcher
+	if c.SpiceDb.WatchFiles {
+		var paths []string
+		for _, path := range []string{repo.tokenFile, repo.schemaFilePath} {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+		watcher, err = filewatch.New(paths, repo.reloadFiles, logger)
+		if err != nil {
+			cancel()
+			_ = pool.Close()
+			return nil, nil, fmt.Errorf("error creating spicedb client: %w", err)
+		}
+	}
+
+	cleanup := func() {
+		log.Info("spicedb connection cleanup requested, closing connections")
+		cancel()
+		if watcher != nil {
+			if err := watcher.Close(); err != nil {
+				log.Warnf("error stopping file watcher: %v", err)
+			}
+		}
+		if err := pool.Close(); err != nil {
+			log.Warnf("error closing spicedb connections: %v", err)
+		}
+	}
+
+	return repo, cleanup, nil
 }
 
-func (s *SpiceDbRepository) initialize() error {
-	if s.isInitialized {
+// InitializeSchema writes the schema file to SpiceDB unless it was already written. In verify mode it checks that
+// SpiceDB has the schema instead.
+func (s *SpiceDbRepository) InitializeSchema(ctx context.Context) error {
+	if s.isInitialized.Load() {
+		return nil
+	}
+
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+	if s.isInitialized.Load() {
 		return nil
 	}
 
@@ -111,20 +245,43 @@ func (s *SpiceDbRepository) initialize() error {
 		return fmt.Errorf("failed to load schema file: %w", err)
 	}
 
-	_, err = s.client.WriteSchema(context.TODO(), &v1.WriteSchemaRequest{
-		Schema: schema,
-	})
-
-	if err != nil {
+	if err := s.applySchema(ctx, schema); err != nil {
 		return err
 	}
 
-	s.isInitialized = true
+	s.appliedSchema = schema
+	s.isInitialized.Store(true)
+	return nil
+}
+
+// applySchema writes schema to SpiceDB or, in verify mode, checks that SpiceDB already has it.
+func (s *SpiceDbRepository) applySchema(ctx context.Context, schema string) error {
+	if s.schemaMode == schemaModeVerify {
+		return s.verifySchema(ctx, schema)
+	}
+	if _, err := s.client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: schema}); err != nil {
+		return fmt.Errorf("schema rejected by spicedb: %w", err)
+	}
+	return nil
+}
+
+// verifySchema compares schema with the schema in SpiceDB semantically, so formatting and comments do not matter.
+func (s *SpiceDbRepository) verifySchema(ctx context.Context, schema string) error {
+	resp, err := s.client.DiffSchema(ctx, &v1.DiffSchemaRequest{
+		Consistency:      &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		ComparisonSchema: schema,
+	})
+	if err != nil {
+		return fmt.Errorf("error comparing schema with spicedb: %w", err)
+	}
+	if diffs := len(resp.GetDiffs()); diffs > 0 {
+		return fmt.Errorf("schema in spicedb differs from %s in %d places", s.schemaFilePath, diffs)
+	}
 	return nil
 }
 
 func (s *SpiceDbRepository) LookupSubjects(ctx context.Context, subject_type *apiV1beta1.ObjectType, subject_relation, relation string, object *apiV1beta1.ObjectReference, limit uint32, continuation biz.ContinuationToken, consistency *apiV1beta1.Consistency) (chan *biz.SubjectResult, chan error, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, nil, err
 	}
 
@@ -135,8 +292,13 @@ func (s *SpiceDbRepository) LookupSubjects(ctx context.Context, subject_type *ap
 		}
 	}
 
+	spiceDbConsistency, err := s.determineConsistency(consistency)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req := &v1.LookupSubjectsRequest{
-		Consistency: s.determineConsistency(consistency),
+		Consistency: spiceDbConsistency,
 		Resource: &v1.ObjectReference{
 			ObjectType: kesselTypeToSpiceDBType(object.Type),
 			ObjectId:   object.Id,
@@ -184,7 +346,7 @@ func (s *SpiceDbRepository) LookupSubjects(ctx context.Context, subject_type *ap
 					},
 				},
 				Continuation:     continuation,
-				ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: msg.GetLookedUpAt().GetToken()},
+				ConsistencyToken: s.tokens.encode(msg.GetLookedUpAt().GetToken()),
 			}
 		}
 	}()
@@ -193,7 +355,7 @@ func (s *SpiceDbRepository) LookupSubjects(ctx context.Context, subject_type *ap
 }
 
 func (s *SpiceDbRepository) LookupResources(ctx context.Context, resouce_type *apiV1beta1.ObjectType, relation string, subject *apiV1beta1.SubjectReference, limit uint32, continuation biz.ContinuationToken, consistency *apiV1beta1.Consistency) (chan *biz.ResourceResult, chan error, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, nil, err
 	}
 
@@ -203,8 +365,13 @@ func (s *SpiceDbRepository) LookupResources(ctx context.Context, resouce_type *a
 			Token: string(continuation),
 		}
 	}
+	spiceDbConsistency, err := s.determineConsistency(consistency)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	client, err := s.client.LookupResources(ctx, &v1.LookupResourcesRequest{
-		Consistency:        s.determineConsistency(consistency),
+		Consistency:        spiceDbConsistency,
 		ResourceObjectType: kesselTypeToSpiceDBType(resouce_type),
 		Permission:         relation,
 		Subject: &v1.SubjectReference{
@@ -248,7 +415,7 @@ func (s *SpiceDbRepository) LookupResources(ctx context.Context, resouce_type *a
 					Id:   resId,
 				},
 				Continuation:     continuation,
-				ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: msg.GetLookedUpAt().GetToken()},
+				ConsistencyToken: s.tokens.encode(msg.GetLookedUpAt().GetToken()),
 			}
 		}
 	}()
@@ -256,7 +423,7 @@ func (s *SpiceDbRepository) LookupResources(ctx context.Context, resouce_type *a
 }
 
 func (s *SpiceDbRepository) ImportBulkTuples(stream grpc.ClientStreamingServer[apiV1beta1.ImportBulkTuplesRequest, apiV1beta1.ImportBulkTuplesResponse]) error {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(stream.Context()); err != nil {
 		return err
 	}
 
@@ -275,6 +442,7 @@ func (s *SpiceDbRepository) ImportBulkTuples(stream grpc.ClientStreamingServer[a
 				} else {
 					s.log.Infof("total number of relationships loaded: %d", res.NumLoaded)
 					totalImported = res.NumLoaded
+					s.metrics.tuplesImported(stream.Context(), totalImported)
 					return stream.SendAndClose(&apiV1beta1.ImportBulkTuplesResponse{NumImported: totalImported})
 				}
 			}
@@ -299,11 +467,12 @@ func (s *SpiceDbRepository) ImportBulkTuples(stream grpc.ClientStreamingServer[a
 }
 
 func (s *SpiceDbRepository) CreateRelationships(ctx context.Context, rels []*apiV1beta1.Relationship, touch biz.TouchSemantics, fencing *apiV1beta1.FencingCheck) (*apiV1beta1.CreateTuplesResponse, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, err
 	}
 
 	var relationshipUpdates []*v1.RelationshipUpdate
+	written := countByKind(rels)
 
 	var operation v1.RelationshipUpdate_Operation
 	if touch {
@@ -324,37 +493,101 @@ func (s *SpiceDbRepository) CreateRelationships(ctx context.Context, rels []*api
 	}
 
 	req := &v1.WriteRelationshipsRequest{
-		Updates: relationshipUpdates,
+		Updates:                     relationshipUpdates,
+		OptionalTransactionMetadata: transactionMetadata(ctx, nil),
 	}
 
 	if fencing != nil {
-		req.OptionalPreconditions = []*v1.Precondition{
-			{
-				Operation: v1.Precondition_OPERATION_MUST_MATCH,
-				Filter: &v1.RelationshipFilter{
-					ResourceType:       lockType,
-					OptionalResourceId: fencing.GetLockId(),
-					OptionalRelation:   addRelationPrefix(lockVersionRelation, relationPrefix),
-					OptionalSubjectFilter: &v1.SubjectFilter{
-						SubjectType:       lockVersionType,
-						OptionalSubjectId: fencing.GetLockToken(),
-					},
-				},
-			},
-		}
+		req.OptionalPreconditions = []*v1.Precondition{fencingPrecondition(fencing)}
 	}
 
 	resp, err := s.client.WriteRelationships(ctx, req)
 
 	if err != nil {
+		if fencing != nil && isPreconditionFailure(err) {
+			s.metrics.fenceFailed(ctx, "create")
+		}
 		return nil, fmt.Errorf("error writing relationships to SpiceDB: %w", err)
 	}
+	s.metrics.tuplesWritten(ctx, written)
+
+	return &apiV1beta1.CreateTuplesResponse{ConsistencyToken: s.tokens.encode(resp.GetWrittenAt().GetToken())}, nil
+}
+
+// RewriteRelationships deletes and creates tuples in one transaction, creating with touch semantics so a rewrite
+// repeated after a failure succeeds.
+func (s *SpiceDbRepository) RewriteRelationships(ctx context.Context, deletes, creates []*apiV1beta1.Relationship, fencing *apiV1beta1.FencingCheck) (*apiV1beta1.ConsistencyToken, error) {
+	if err := s.InitializeSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	req := &v1.WriteRelationshipsRequest{OptionalTransactionMetadata: transactionMetadata(ctx, nil)}
+	for _, rels := range []struct {
+		operation v1.RelationshipUpdate_Operation
+		tuples    []*apiV1beta1.Relationship
+	}{
+		{v1.RelationshipUpdate_OPERATION_DELETE, deletes},
+		{v1.RelationshipUpdate_OPERATION_TOUCH, creates},
+	} {
+		for _, rel := range rels.tuples {
+			rel = proto.Clone(rel).(*apiV1beta1.Relationship)
+			rel.Relation = addRelationPrefix(rel.Relation, relationPrefix)
+			req.Updates = append(req.Updates, &v1.RelationshipUpdate{
+				Operation:    rels.operation,
+				Relationship: createSpiceDbRelationship(rel),
+			})
+		}
+	}
+	if fencing != nil {
+		req.OptionalPreconditions = []*v1.Precondition{fencingPrecondition(fencing)}
+	}
+
+	resp, err := s.client.WriteRelationships(ctx, req)
+	if err != nil {
+		if fencing != nil && isPreconditionFailure(err) {
+			s.metrics.fenceFailed(ctx, "rewrite")
+			return nil, kerrors.Conflict(biz.LockLostReason, fmt.Sprintf("lock %s was acquired by another writer", fencing.GetLockId()))
+		}
+		return nil, fmt.Errorf("error writing relationships to SpiceDB: %w", err)
+	}
+	s.metrics.tuplesDeleted(ctx, countByKind(deletes))
+	s.metrics.tuplesWritten(ctx, countByKind(creates))
+	return s.tokens.encode(resp.GetWrittenAt().GetToken()), nil
+}
+
+// isPreconditionFailure reports whether SpiceDB refused a write because a precondition did not hold.
+func isPreconditionFailure(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok &&
+			info.GetReason() == v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE.String() {
+			return true
+		}
+	}
+	return false
+}
 
-	return &apiV1beta1.CreateTuplesResponse{ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: resp.GetWrittenAt().GetToken()}}, nil
+// fencingPrecondition requires the lock to still be held with the token of the fencing check.
+func fencingPrecondition(fencing *apiV1beta1.FencingCheck) *v1.Precondition {
+	return &v1.Precondition{
+		Operation: v1.Precondition_OPERATION_MUST_MATCH,
+		Filter: &v1.RelationshipFilter{
+			ResourceType:       lockType,
+			OptionalResourceId: fencing.GetLockId(),
+			OptionalRelation:   addRelationPrefix(lockVersionRelation, relationPrefix),
+			OptionalSubjectFilter: &v1.SubjectFilter{
+				SubjectType:       lockVersionType,
+				OptionalSubjectId: fencing.GetLockToken(),
+			},
+		},
+	}
 }
 
 func (s *SpiceDbRepository) ReadRelationships(ctx context.Context, filter *apiV1beta1.RelationTupleFilter, limit uint32, continuation biz.ContinuationToken, consistency *apiV1beta1.Consistency) (chan *biz.RelationshipResult, chan error, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, nil, err
 	}
 
@@ -378,8 +611,13 @@ func (s *SpiceDbRepository) ReadRelationships(ctx context.Context, filter *apiV1
 		return nil, nil, kerrors.BadRequest("SpiceDb request validation", err.Error()).WithCause(err)
 	}
 
+	spiceDbConsistency, err := s.determineConsistency(consistency)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req := &v1.ReadRelationshipsRequest{
-		Consistency:        s.determineConsistency(consistency),
+		Consistency:        spiceDbConsistency,
 		RelationshipFilter: relationshipFilter,
 		OptionalLimit:      limit,
 		OptionalCursor:     cursor,
@@ -411,24 +649,10 @@ func (s *SpiceDbRepository) ReadRelationships(ctx context.Context, filter *apiV1
 				continuation = biz.ContinuationToken(msg.AfterResultCursor.Token)
 			}
 
-			spiceDbRel := msg.GetRelationship()
 			relationshipTuples <- &biz.RelationshipResult{
-				Relationship: &apiV1beta1.Relationship{
-					Resource: &apiV1beta1.ObjectReference{
-						Type: spicedbTypeToKesselType(spiceDbRel.Resource.ObjectType),
-						Id:   spiceDbRel.Resource.ObjectId,
-					},
-					Relation: strings.TrimPrefix(msg.Relationship.Relation, relationPrefix),
-					Subject: &apiV1beta1.SubjectReference{
-						Relation: optionalStringToStringPointer(spiceDbRel.Subject.OptionalRelation),
-						Subject: &apiV1beta1.ObjectReference{
-							Type: spicedbTypeToKesselType(spiceDbRel.Subject.Object.ObjectType),
-							Id:   spiceDbRel.Subject.Object.ObjectId,
-						},
-					},
-				},
+				Relationship:     spiceDbRelationshipToKessel(msg.GetRelationship()),
 				Continuation:     continuation,
-				ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: msg.ReadAt.GetToken()},
+				ConsistencyToken: s.tokens.encode(msg.ReadAt.GetToken()),
 			}
 		}
 	}()
@@ -436,11 +660,13 @@ func (s *SpiceDbRepository) ReadRelationships(ctx context.Context, filter *apiV1
 	return relationshipTuples, errs, nil
 }
 
-func (s *SpiceDbRepository) DeleteRelationships(ctx context.Context, filter *apiV1beta1.RelationTupleFilter, fencing *apiV1beta1.FencingCheck) (*apiV1beta1.DeleteTuplesResponse, error) {
-	if err := s.initialize(); err != nil {
+func (s *SpiceDbRepository) DeleteRelationships(ctx context.Context, filter *apiV1beta1.RelationTupleFilter, fencing *apiV1beta1.FencingCheck, opts biz.DeleteOptions) (*biz.DeleteResult, error) {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, err
 	}
 
+	relation := filter.GetRelation()
+	metadata := transactionMetadata(ctx, filter)
 	if filter.GetRelation() != "" && filter.GetResourceType() != "" {
 		tempRelation := addRelationPrefix(filter.GetRelation(), relationPrefix)
 		filter.Relation = &tempRelation
@@ -452,37 +678,246 @@ func (s *SpiceDbRepository) DeleteRelationships(ctx context.Context, filter *api
 		return nil, kerrors.BadRequest("SpiceDb request validation", err.Error()).WithCause(err)
 	}
 
-	req := &v1.DeleteRelationshipsRequest{RelationshipFilter: relationshipFilter}
+	if opts.DryRun {
+		preview, err := s.previewDeletion(ctx, relationshipFilter, opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+		return &biz.DeleteResult{DeleteTuplesResponse: preview}, nil
+	}
 
-	if fencing != nil {
-		req.OptionalPreconditions = []*v1.Precondition{
-			{
-				Operation: v1.Precondition_OPERATION_MUST_MATCH,
-				Filter: &v1.RelationshipFilter{
-					ResourceType:       lockType,
-					OptionalResourceId: fencing.GetLockId(),
-					OptionalRelation:   addRelationPrefix(lockVersionRelation, relationPrefix),
-					OptionalSubjectFilter: &v1.SubjectFilter{
-						SubjectType:       lockVersionType,
-						OptionalSubjectId: fencing.GetLockToken(),
-					},
-				},
-			},
+	guarded := s.maxDeletions > 0 && !opts.OverrideThreshold
+	if guarded && opts.Limit > s.maxDeletions {
+		return nil, s.deleteThresholdExceeded()
+	}
+
+	var readAt, deletion string
+	if opts.ListDeleted > 0 {
+		// the tuples deleted are listed from the Watch API, watching from the revision before the deletion
+		if readAt, err = s.headRevision(ctx); err != nil {
+			s.log.WithContext(ctx).Warnf("error reading the revision before deleting, the tuples deleted are not listed: %v", err)
+		} else {
+			// recognizes the deletion in the Watch API
+			deletion = uuid.NewString()
+			if metadata == nil {
+				metadata = &structpb.Struct{Fields: map[string]*structpb.Value{}}
+			}
+			metadata.Fields[metadataDeletion] = structpb.NewStringValue(deletion)
 		}
 	}
 
+	req := &v1.DeleteRelationshipsRequest{RelationshipFilter: relationshipFilter, OptionalTransactionMetadata: metadata}
+	if opts.Limit > 0 {
+		req.OptionalLimit = opts.Limit
+		req.OptionalAllowPartialDeletions = true
+	} else if guarded {
+		// without partial deletions SpiceDB deletes nothing if more than OptionalLimit relationships match
+		req.OptionalLimit = s.maxDeletions
+	}
+
+	if fencing != nil {
+		req.OptionalPreconditions = []*v1.Precondition{fencingPrecondition(fencing)}
+	}
+
 	resp, err := s.client.DeleteRelationships(ctx, req)
 
-	// TODO: we have not specified an option in our API to allow partial deletions, so currently it's all or nothing
 	if err != nil {
+		if guarded && opts.Limit == 0 && isTooManyRelationshipsToDelete(err) {
+			return nil, s.deleteThresholdExceeded()
+		}
+		if fencing != nil && isPreconditionFailure(err) {
+			s.metrics.fenceFailed(ctx, "delete")
+		}
 		return nil, fmt.Errorf("error invoking DeleteRelationships in SpiceDB %w", err)
 	}
+	s.metrics.tuplesDeletedByFilter(ctx, filter, relation, resp.GetRelationshipsDeletedCount())
+
+	var listed []*apiV1beta1.Relationship
+	if deletion != "" && resp.GetRelationshipsDeletedCount() > 0 {
+		listed = s.watchDeleted(ctx, relationshipFilter.GetResourceType(), readAt, deletion, resp.GetDeletedAt().GetToken(), opts.ListDeleted)
+	}
+
+	return &biz.DeleteResult{
+		DeleteTuplesResponse: &apiV1beta1.DeleteTuplesResponse{
+			ConsistencyToken: s.tokens.encode(resp.GetDeletedAt().GetToken()),
+			DeletedCount:     resp.GetRelationshipsDeletedCount(),
+			MoreRemaining:    resp.GetDeletionProgress() == v1.DeleteRelationshipsResponse_DELETION_PROGRESS_PARTIAL,
+		},
+		Deleted: listed,
+	}, nil
+}
+
+// LatestConsistencyToken returns a token for the current revision of SpiceDB, reads at least as fresh as it see every
+// write made before.
+func (s *SpiceDbRepository) LatestConsistencyToken(ctx context.Context) (*apiV1beta1.ConsistencyToken, error) {
+	if err := s.InitializeSchema(ctx); err != nil {
+		return nil, err
+	}
+	revision, err := s.headRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokens.encode(revision), nil
+}
+
+// headRevision returns the current revision of SpiceDB, which it reads the schema at.
+func (s *SpiceDbRepository) headRevision(ctx context.Context) (string, error) {
+	resp, err := s.client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if err != nil {
+		return "", fmt.Errorf("error invoking ReadSchema in SpiceDB: %w", err)
+	}
+	return resp.GetReadAt().GetToken(), nil
+}
+
+// relationshipMatching returns the filter matching exactly rel.
+func relationshipMatching(rel *v1.Relationship) *v1.RelationshipFilter {
+	return &v1.RelationshipFilter{
+		ResourceType:       rel.GetResource().GetObjectType(),
+		OptionalResourceId: rel.GetResource().GetObjectId(),
+		OptionalRelation:   rel.GetRelation(),
+		OptionalSubjectFilter: &v1.SubjectFilter{
+			SubjectType:       rel.GetSubject().GetObject().GetObjectType(),
+			OptionalSubjectId: rel.GetSubject().GetObject().GetObjectId(),
+			// an empty relation only matches subjects without one
+			OptionalRelation: &v1.SubjectFilter_RelationFilter{Relation: rel.GetSubject().GetOptionalRelation()},
+		},
+	}
+}
+
+// watchDeleted reads up to limit of the tuples a deletion removed from the Watch API, as SpiceDB does not
+// report them. The deletion is recognized by the id recorded in its transaction metadata or by its revision, watching
+// from the revision before it. No tuples are returned if the datastore does not support watching or the deletion is
+// not seen within watchDeletedTimeout, the deletion itself has succeeded regardless.
+func (s *SpiceDbRepository) watchDeleted(ctx context.Context, resourceType, from, deletion, deletedAt string, limit uint32) []*apiV1beta1.Relationship {
+	if from == "" {
+		s.log.WithContext(ctx).Warnf("the revision before the deletion at %s is unknown, the tuples deleted are not listed", deletedAt)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, watchDeletedTimeout)
+	defer cancel()
+	req := &v1.WatchRequest{OptionalStartCursor: &v1.ZedToken{Token: from}}
+	if resourceType != "" {
+		req.OptionalObjectTypes = []string{resourceType}
+	}
+	stream, err := s.client.Watch(ctx, req)
+	for err == nil {
+		var resp *v1.WatchResponse
+		if resp, err = stream.Recv(); err != nil {
+			break
+		}
+		if resp.GetChangesThrough().GetToken() != deletedAt &&
+			resp.GetOptionalTransactionMetadata().GetFields()[metadataDeletion].GetStringValue() != deletion {
+			continue
+		}
+		var deleted []*apiV1beta1.Relationship
+		for _, update := range resp.GetUpdates() {
+			if update.GetOperation() == v1.RelationshipUpdate_OPERATION_DELETE && len(deleted) < int(limit) {
+				deleted = append(deleted, spiceDbRelationshipToKessel(update.GetRelationship()))
+			}
+		}
+		return deleted
+	}
+	s.log.WithContext(ctx).Warnf("error watching for the tuples deleted at %s, they are not listed: %v", deletedAt, err)
+	return nil
+}
+
+// readMatching reads up to limit of the relationships matching filter fully consistently, returning them with the
+// revision they were read at.
+func (s *SpiceDbRepository) readMatching(ctx context.Context, filter *v1.RelationshipFilter, limit uint32) ([]*v1.Relationship, string, error) {
+	client, err := s.client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: filter,
+		OptionalLimit:      limit,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
+	}
+	var relationships []*v1.Relationship
+	var readAt string
+	for {
+		msg, err := client.Recv()
+		if errors.Is(err, io.EOF) {
+			return relationships, readAt, nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
+		}
+		relationships = append(relationships, msg.GetRelationship())
+		readAt = msg.GetReadAt().GetToken()
+	}
+}
+
+// previewDeletion counts the relationships a deletion with the given filter and limit would remove, returning the
+// first few as a sample. Nothing is deleted. No more than the configured maximum deletions are counted, so a broad
+// filter cannot make the preview read every relationship.
+func (s *SpiceDbRepository) previewDeletion(ctx context.Context, filter *v1.RelationshipFilter, limit uint32) (*apiV1beta1.DeleteTuplesResponse, error) {
+	if s.maxDeletions > 0 && (limit == 0 || limit > s.maxDeletions) {
+		limit = s.maxDeletions
+	}
+	req := &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: filter,
+	}
+	if limit > 0 {
+		// read one more than the limit to find out whether any would remain
+		req.OptionalLimit = limit + 1
+	}
+
+	client, err := s.client.ReadRelationships(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
+	}
+
+	resp := &apiV1beta1.DeleteTuplesResponse{}
+	readAt := ""
+	for {
+		msg, err := client.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
+		}
+		readAt = msg.GetReadAt().GetToken()
+
+		if limit > 0 && resp.DeletedCount == uint64(limit) {
+			resp.MoreRemaining = true
+			break
+		}
+		resp.DeletedCount++
+		if len(resp.Sample) < s.dryRunSamples {
+			resp.Sample = append(resp.Sample, spiceDbRelationshipToKessel(msg.GetRelationship()))
+		}
+	}
+	resp.ConsistencyToken = s.tokens.encode(readAt)
 
-	return &apiV1beta1.DeleteTuplesResponse{ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: resp.GetDeletedAt().GetToken()}}, nil
+	return resp, nil
+}
+
+func (s *SpiceDbRepository) deleteThresholdExceeded() error {
+	return kerrors.BadRequest(biz.DeleteThresholdExceededReason, fmt.Sprintf(
+		"the filter matches more than %d tuples, narrow the filter, set a limit, or set override_threshold",
+		s.maxDeletions))
+}
+
+// isTooManyRelationshipsToDelete reports whether SpiceDB refused a deletion because more relationships matched
+// than the request's limit allowed.
+func isTooManyRelationshipsToDelete(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok &&
+			info.GetReason() == v1.ErrorReason_ERROR_REASON_TOO_MANY_RELATIONSHIPS_FOR_TRANSACTIONAL_DELETE.String() {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *SpiceDbRepository) Check(ctx context.Context, check *apiV1beta1.CheckRequest) (*apiV1beta1.CheckResponse, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, err
 	}
 
@@ -498,32 +933,38 @@ func (s *SpiceDbRepository) Check(ctx context.Context, check *apiV1beta1.CheckRe
 		ObjectType: kesselTypeToSpiceDBType(check.GetResource().GetType()),
 		ObjectId:   check.GetResource().GetId(),
 	}
+	consistency, err := s.determineConsistency(check.Consistency)
+	if err != nil {
+		return &apiV1beta1.CheckResponse{Allowed: apiV1beta1.CheckResponse_ALLOWED_UNSPECIFIED}, err
+	}
 	req := &v1.CheckPermissionRequest{
-		Consistency: s.determineConsistency(check.Consistency),
+		Consistency: consistency,
 		Resource:    resource,
 		Permission:  check.GetRelation(),
 		Subject:     subject,
+		WithTracing: check.GetDebug(),
 	}
-	checkResponse, err := s.client.CheckPermission(ctx, req)
+	var trailer metadata.MD
+	checkResponse, err := s.client.CheckPermission(ctx, req, grpc.Trailer(&trailer))
 	if err != nil {
 		return &apiV1beta1.CheckResponse{Allowed: apiV1beta1.CheckResponse_ALLOWED_UNSPECIFIED}, fmt.Errorf("error invoking CheckPermission in SpiceDB: %w", err)
 	}
 
+	resp := &apiV1beta1.CheckResponse{
+		Allowed:          apiV1beta1.CheckResponse_ALLOWED_FALSE,
+		ConsistencyToken: s.tokens.encode(checkResponse.GetCheckedAt().GetToken()),
+	}
 	if checkResponse.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
-		return &apiV1beta1.CheckResponse{
-			Allowed:          apiV1beta1.CheckResponse_ALLOWED_TRUE,
-			ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: checkResponse.GetCheckedAt().GetToken()},
-		}, nil
+		resp.Allowed = apiV1beta1.CheckResponse_ALLOWED_TRUE
 	}
-
-	return &apiV1beta1.CheckResponse{
-		Allowed:          apiV1beta1.CheckResponse_ALLOWED_FALSE,
-		ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: checkResponse.GetCheckedAt().GetToken()},
-	}, nil
+	if check.GetDebug() {
+		resp.DebugTrace = debugTrace(checkResponse.GetDebugTrace().GetCheck(), trailer, s.directTuples(ctx, checkResponse.GetCheckedAt()))
+	}
+	return resp, nil
 }
 
 func (s *SpiceDbRepository) CheckForUpdate(ctx context.Context, check *apiV1beta1.CheckForUpdateRequest) (*apiV1beta1.CheckForUpdateResponse, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, err
 	}
 
@@ -553,13 +994,13 @@ func (s *SpiceDbRepository) CheckForUpdate(ctx context.Context, check *apiV1beta
 	if checkResponse.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
 		return &apiV1beta1.CheckForUpdateResponse{
 			Allowed:          apiV1beta1.CheckForUpdateResponse_ALLOWED_TRUE,
-			ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: checkResponse.GetCheckedAt().GetToken()},
+			ConsistencyToken: s.tokens.encode(checkResponse.GetCheckedAt().GetToken()),
 		}, nil
 	}
 
 	return &apiV1beta1.CheckForUpdateResponse{
 		Allowed:          apiV1beta1.CheckForUpdateResponse_ALLOWED_FALSE,
-		ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: checkResponse.GetCheckedAt().GetToken()},
+		ConsistencyToken: s.tokens.encode(checkResponse.GetCheckedAt().GetToken()),
 	}, nil
 }
 
@@ -628,7 +1069,7 @@ func fromSpicePair(pair *v1.CheckBulkPermissionsPair, log *log.Helper) *apiV1bet
 
 // CheckForUpdateBulk runs N strongly-consistent checks (FullyConsistent). Reuses CheckBulkRequestItem and CheckBulkResponsePair; returns consistency_token like CheckBulkResponse.
 func (s *SpiceDbRepository) CheckForUpdateBulk(ctx context.Context, check *apiV1beta1.CheckForUpdateBulkRequest) (*apiV1beta1.CheckForUpdateBulkResponse, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, err
 	}
 	items := make([]*v1.CheckBulkPermissionsRequestItem, len(check.Items))
@@ -638,9 +1079,11 @@ func (s *SpiceDbRepository) CheckForUpdateBulk(ctx context.Context, check *apiV1
 	req := &v1.CheckBulkPermissionsRequest{
 		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
 		Items:       items,
+		WithTracing: slices.ContainsFunc(check.Items, (*apiV1beta1.CheckBulkRequestItem).GetDebug),
 	}
 
-	resp, err := s.client.CheckBulkPermissions(ctx, req)
+	var trailer metadata.MD
+	resp, err := s.client.CheckBulkPermissions(ctx, req, grpc.Trailer(&trailer))
 	if err != nil {
 		return nil, fmt.Errorf("error invoking CheckBulkPermissions in SpiceDB (CheckForUpdateBulk): %w", err)
 	}
@@ -649,16 +1092,30 @@ func (s *SpiceDbRepository) CheckForUpdateBulk(ctx context.Context, check *apiV1
 	for i, p := range resp.Pairs {
 		pairs[i] = fromSpicePair(p, s.log)
 	}
+	addBulkDebugTraces(check.Items, resp.Pairs, pairs, trailer, s.directTuples(ctx, resp.GetCheckedAt()))
 	return &apiV1beta1.CheckForUpdateBulkResponse{
 		Pairs:            pairs,
-		ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: resp.GetCheckedAt().GetToken()},
+		ConsistencyToken: s.tokens.encode(resp.GetCheckedAt().GetToken()),
 	}, nil
 }
 
+// addBulkDebugTraces adds the traces SpiceDB returned to the items of a bulk check that requested debugging. SpiceDB
+// answers items in the order they were requested and traces every item once any requests it.
+func addBulkDebugTraces(items []*apiV1beta1.CheckBulkRequestItem, spicePairs []*v1.CheckBulkPermissionsPair, pairs []*apiV1beta1.CheckBulkResponsePair, trailer metadata.MD, direct func(step *v1.CheckDebugTrace) *apiV1beta1.Relationship) {
+	if len(items) != len(pairs) {
+		return
+	}
+	for i, item := range items {
+		if item.GetDebug() && pairs[i].GetItem() != nil {
+			pairs[i].GetItem().DebugTrace = debugTrace(spicePairs[i].GetItem().GetDebugTrace().GetCheck(), trailer, direct)
+		}
+	}
+}
+
 // simplified CheckBulk using the helpers
 func (s *SpiceDbRepository) CheckBulk(ctx context.Context, check *apiV1beta1.CheckBulkRequest) (*apiV1beta1.CheckBulkResponse, error) {
 
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, err
 	}
 	// …initialize, build request…
@@ -666,9 +1123,18 @@ func (s *SpiceDbRepository) CheckBulk(ctx context.Context, check *apiV1beta1.Che
 	for i, it := range check.Items {
 		items[i] = toSpiceItem(it)
 	}
-	req := &v1.CheckBulkPermissionsRequest{Consistency: s.determineConsistency(check.Consistency), Items: items}
+	consistency, err := s.determineConsistency(check.Consistency)
+	if err != nil {
+		return nil, err
+	}
+	req := &v1.CheckBulkPermissionsRequest{
+		Consistency: consistency,
+		Items:       items,
+		WithTracing: slices.ContainsFunc(check.Items, (*apiV1beta1.CheckBulkRequestItem).GetDebug),
+	}
 
-	resp, err := s.client.CheckBulkPermissions(ctx, req)
+	var trailer metadata.MD
+	resp, err := s.client.CheckBulkPermissions(ctx, req, grpc.Trailer(&trailer))
 	if err != nil {
 		return nil, fmt.Errorf("error invoking CheckBulkPermissions in SpiceDB: %w", err)
 	}
@@ -677,15 +1143,25 @@ func (s *SpiceDbRepository) CheckBulk(ctx context.Context, check *apiV1beta1.Che
 	for i, p := range resp.Pairs {
 		pairs[i] = fromSpicePair(p, s.log)
 	}
+	addBulkDebugTraces(check.Items, resp.Pairs, pairs, trailer, s.directTuples(ctx, resp.GetCheckedAt()))
 	return &apiV1beta1.CheckBulkResponse{
 		Pairs:            pairs,
-		ConsistencyToken: &apiV1beta1.ConsistencyToken{Token: resp.GetCheckedAt().GetToken()},
+		ConsistencyToken: s.tokens.encode(resp.GetCheckedAt().GetToken()),
 	}, nil
 }
 
-func (s *SpiceDbRepository) IsBackendAvailable() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// IsCircuitOpen reports whether calls to SpiceDB are failing fast after repeated failures.
+func (s *SpiceDbRepository) IsCircuitOpen() bool {
+	return s.breaker.isOpen()
+}
+
+// IsBackendAvailable checks the health of SpiceDB within the deadline of ctx, or 5s if it has none.
+func (s *SpiceDbRepository) IsBackendAvailable(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+	}
 
 	resp, err := s.healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
 	if err != nil {
@@ -707,7 +1183,7 @@ func (s *SpiceDbRepository) IsBackendAvailable() error {
 }
 
 func (s *SpiceDbRepository) AcquireLock(ctx context.Context, lockId string) (*apiV1beta1.AcquireLockResponse, error) {
-	if err := s.initialize(); err != nil {
+	if err := s.InitializeSchema(ctx); err != nil {
 		return nil, err
 	}
 
@@ -721,11 +1197,13 @@ func (s *SpiceDbRepository) AcquireLock(ctx context.Context, lockId string) (*ap
 		},
 	})
 	if err != nil {
+		s.metrics.lockAcquired(ctx, "error")
 		return nil, fmt.Errorf("error invoking ReadRelationships in SpiceDB: %w", err)
 	}
 
 	existingLock, err := readClient.Recv()
 	if err != nil && !errors.Is(err, io.EOF) {
+		s.metrics.lockAcquired(ctx, "error")
 		return nil, fmt.Errorf("error reading existing lock: %w", err)
 	}
 
@@ -775,12 +1253,36 @@ func (s *SpiceDbRepository) AcquireLock(ctx context.Context, lockId string) (*ap
 		OptionalPreconditions: preconditions,
 	})
 	if err != nil {
+		if isPreconditionFailure(err) {
+			// another writer acquired the lock after it was read
+			s.metrics.lockAcquired(ctx, "conflict")
+		} else {
+			s.metrics.lockAcquired(ctx, "error")
+		}
 		return nil, fmt.Errorf("error writing relationships to SpiceDB: %w", err)
 	}
+	s.metrics.lockAcquired(ctx, "acquired")
 
 	return &apiV1beta1.AcquireLockResponse{LockToken: newFencingToken}, nil
 }
 
+func spiceDbRelationshipToKessel(rel *v1.Relationship) *apiV1beta1.Relationship {
+	return &apiV1beta1.Relationship{
+		Resource: &apiV1beta1.ObjectReference{
+			Type: spicedbTypeToKesselType(rel.Resource.ObjectType),
+			Id:   rel.Resource.ObjectId,
+		},
+		Relation: strings.TrimPrefix(rel.Relation, relationPrefix),
+		Subject: &apiV1beta1.SubjectReference{
+			Relation: optionalStringToStringPointer(rel.Subject.OptionalRelation),
+			Subject: &apiV1beta1.ObjectReference{
+				Type: spicedbTypeToKesselType(rel.Subject.Object.ObjectType),
+				Id:   rel.Subject.Object.ObjectId,
+			},
+		},
+	}
+}
+
 func createSpiceDbRelationshipFilter(filter *apiV1beta1.RelationTupleFilter) (*v1.RelationshipFilter, error) {
 	// spicedb specific internal validation to reflect spicedb limitations whereby namespace and objectType must be both
 	// be set if either of them is set in a filter
@@ -910,13 +1412,17 @@ func readFile(file string) (string, error) {
 	return string(bytes), nil
 }
 
-func (s *SpiceDbRepository) determineConsistency(consistency *apiV1beta1.Consistency) *v1.Consistency {
+func (s *SpiceDbRepository) determineConsistency(consistency *apiV1beta1.Consistency) (*v1.Consistency, error) {
 	if consistency.GetAtLeastAsFresh() != nil {
+		zedToken, err := s.tokens.decode(consistency.GetAtLeastAsFresh().GetToken())
+		if err != nil {
+			return nil, kerrors.BadRequest("CONSISTENCY_TOKEN", err.Error()).WithCause(err)
+		}
 		return &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: &v1.ZedToken{Token: consistency.GetAtLeastAsFresh().GetToken()},
+				AtLeastAsFresh: &v1.ZedToken{Token: zedToken},
 			},
-		}
+		}, nil
 	}
 
 	if consistency.GetMinimizeLatency() {
@@ -924,7 +1430,7 @@ func (s *SpiceDbRepository) determineConsistency(consistency *apiV1beta1.Consist
 			Requirement: &v1.Consistency_MinimizeLatency{
 				MinimizeLatency: true,
 			},
-		}
+		}, nil
 	}
 
 	// This flag will effectively change the default consistency behaviour
@@ -933,7 +1439,7 @@ func (s *SpiceDbRepository) determineConsistency(consistency *apiV1beta1.Consist
 	//TODO: rename fullyConsistent flag to smth like fullyConsistentAsDefault
 	if s.fullyConsistent {
 		// will ensure that all data used is fully consistent with the latest data available within the SpiceDB datastore.
-		return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+		return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}, nil
 	}
 
 	// Default consistency for read APIs is minimize_latency
@@ -941,5 +1447,5 @@ func (s *SpiceDbRepository) determineConsistency(consistency *apiV1beta1.Consist
 		Requirement: &v1.Consistency_MinimizeLatency{
 			MinimizeLatency: true,
 		},
-	}
+	}, nil
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,14 +14,20 @@ import (
 	"github.com/stretchr/testify/mock"
 	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 
 	apiV1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
 	"github.com/project-kessel/relations-api/internal/biz"
 	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/outbox"
+	"github.com/project-kessel/relations-api/internal/schema"
 
+	kerrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -292,13 +300,40 @@ func TestImportBulkTuples(t *testing.T) {
 	assert.True(t, exists)
 }
 
+func TestLatestConsistencyToken_ReadsWrittenTuples(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	createGroupMembers(t, spiceDbRepo, "latest_club", "alice")
+
+	token, err := spiceDbRepo.LatestConsistencyToken(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	results, errs, err := spiceDbRepo.ReadRelationships(ctx, groupMembersFilter("latest_club"), 10, "",
+		&apiV1beta1.Consistency{Requirement: &apiV1beta1.Consistency_AtLeastAsFresh{AtLeastAsFresh: token}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	count := 0
+	for range results {
+		count++
+	}
+	assert.NoError(t, <-errs)
+	assert.Equal(t, 1, count)
+}
+
 func TestIsBackendAvailable(t *testing.T) {
 	t.Parallel()
 
 	spiceDbrepo, err := container.CreateSpiceDbRepository()
 	assert.NoError(t, err)
 
-	err = spiceDbrepo.IsBackendAvailable()
+	err = spiceDbrepo.IsBackendAvailable(context.Background())
 	assert.NoError(t, err)
 }
 
@@ -307,16 +342,84 @@ func TestIsBackendUnavailable(t *testing.T) {
 
 	spiceDBRepo, _, err := NewSpiceDbRepository(&conf.Data{
 		SpiceDb: &conf.Data_SpiceDb{
-			Endpoint: "-1",
-			Token:    "foobar",
-			UseTLS:   true,
-		}}, log.GetLogger())
+			Endpoint:         "-1",
+			Token:            "foobar",
+			ConsistencyToken: &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true},
+			UseTLS:           true,
+		}}, noop.NewMeterProvider().Meter(""), nil, log.GetLogger())
 	assert.NoError(t, err)
 
-	err = spiceDBRepo.IsBackendAvailable()
+	err = spiceDBRepo.IsBackendAvailable(context.Background())
 	assert.Error(t, err)
 }
 
+func TestPreflight_PassesAgainstSpiceDB(t *testing.T) {
+	t.Parallel()
+
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	assert.NoError(t, err)
+
+	checks := spiceDbRepo.Preflight(context.Background())
+	assert.Equal(t, []biz.PreflightCheck{{Name: "connection"}, {Name: "token"}, {Name: "schema"}}, checks)
+}
+
+func TestPreflight_StopsAtUnreachableSpiceDB(t *testing.T) {
+	t.Parallel()
+
+	spiceDBRepo, _, err := NewSpiceDbRepository(&conf.Data{
+		SpiceDb: &conf.Data_SpiceDb{
+			Endpoint:         "-1",
+			Token:            "foobar",
+			ConsistencyToken: &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true},
+			UseTLS:           true,
+		}}, noop.NewMeterProvider().Meter(""), nil, log.GetLogger())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	checks := spiceDBRepo.Preflight(ctx)
+	if assert.Len(t, checks, 1) {
+		assert.Equal(t, "connection", checks[0].Name)
+		assert.Error(t, checks[0].Err)
+	}
+}
+
+func TestPreflight_VerifyModeRejectsDifferentSchema(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	assert.NoError(t, err)
+	assert.NoError(t, spiceDbRepo.InitializeSchema(context.Background()))
+
+	schema, err := readFile(spiceDbRepo.schemaFilePath)
+	assert.NoError(t, err)
+	changed := filepath.Join(t.TempDir(), "schema.zed")
+	assert.NoError(t, os.WriteFile(changed, []byte(schema+"\ndefinition preflight/unknown {}\n"), 0600))
+
+	spiceDbRepo.schemaMode = schemaModeVerify
+	spiceDbRepo.schemaFilePath = changed
+	spiceDbRepo.isInitialized.Store(false)
+
+	checks := spiceDbRepo.Preflight(ctx)
+	if assert.Len(t, checks, 3) {
+		assert.ErrorContains(t, checks[2].Err, "schema in spicedb differs")
+	}
+}
+
+func TestNewSpiceDbRepository_RejectsUnknownSchemaMode(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := NewSpiceDbRepository(&conf.Data{
+		SpiceDb: &conf.Data_SpiceDb{
+			Endpoint:         "-1",
+			Token:            "foobar",
+			SchemaMode:       "overwrite",
+			ConsistencyToken: &conf.Data_SpiceDb_ConsistencyToken{Unsigned: true},
+		}}, noop.NewMeterProvider().Meter(""), nil, log.GetLogger())
+	assert.ErrorContains(t, err, `unknown schemaMode "overwrite"`)
+}
+
 func TestDoesNotCreateRelationshipWithSlashInSubjectType(t *testing.T) {
 	t.Parallel()
 
@@ -614,7 +717,7 @@ func TestWriteReadBackDeleteAndReadBackRelationships(t *testing.T) {
 			SubjectNamespace: pointerize("rbac"),
 			SubjectType:      pointerize("principal"),
 		},
-	}, nil)
+	}, nil, biz.DeleteOptions{})
 
 	if !assert.NoError(t, err) {
 		return
@@ -695,7 +798,7 @@ func TestWriteReadBackDeleteAndReadBackRelationships_WithConsistencyToken(t *tes
 			SubjectNamespace: pointerize("rbac"),
 			SubjectType:      pointerize("principal"),
 		},
-	}, nil)
+	}, nil, biz.DeleteOptions{})
 
 	if !assert.NoError(t, err) {
 		return
@@ -914,7 +1017,7 @@ func TestSpiceDbRepository_CheckPermission(t *testing.T) {
 			SubjectNamespace: pointerize("rbac"),
 			SubjectType:      pointerize("principal"),
 		},
-	}, nil)
+	}, nil, biz.DeleteOptions{})
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -1046,7 +1149,7 @@ func TestSpiceDbRepository_NewEnemyProblem_Success(t *testing.T) {
 			SubjectNamespace: pointerize("rbac"),
 			SubjectType:      pointerize("principal"),
 		},
-	}, nil)
+	}, nil, biz.DeleteOptions{})
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -1211,6 +1314,68 @@ func TestSpiceDbRepository_NewEnemyProblem_Success(t *testing.T) {
 // 	assert.Equal(t, &checkResponse, resp)
 // }
 
+func TestSpiceDbRepository_CheckPermission_DebugTrace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rels := []*apiV1beta1.Relationship{
+		createRelationship("rbac", "workspace", "test", "user_grant", "rbac", "role_binding", "rb_test", ""),
+		createRelationship("rbac", "role_binding", "rb_test", "granted", "rbac", "role", "rl1", ""),
+		createRelationship("rbac", "role_binding", "rb_test", "subject", "rbac", "principal", "bob", ""),
+		createRelationship("rbac", "role", "rl1", "view_widget", "rbac", "principal", "*", ""),
+	}
+
+	_, err = spiceDbRepo.CreateRelationships(ctx, rels, biz.TouchSemantics(true), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	container.WaitForQuantizationInterval()
+
+	resp, err := spiceDbRepo.Check(ctx, &apiV1beta1.CheckRequest{
+		Resource: &apiV1beta1.ObjectReference{Type: &apiV1beta1.ObjectType{Name: "workspace", Namespace: "rbac"}, Id: "test"},
+		Relation: "view_widget",
+		Subject: &apiV1beta1.SubjectReference{
+			Subject: &apiV1beta1.ObjectReference{Type: &apiV1beta1.ObjectType{Name: "principal", Namespace: "rbac"}, Id: "bob"},
+		},
+		Debug: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, apiV1beta1.CheckResponse_ALLOWED_TRUE, resp.GetAllowed())
+	root := resp.GetDebugTrace().GetRoot()
+	assert.Equal(t, "workspace", root.GetResource().GetType().GetName())
+	assert.Equal(t, "view_widget", root.GetRelation())
+	assert.Equal(t, apiV1beta1.CheckDebugStep_RESULT_ALLOWED, root.GetResult())
+	assert.NotEmpty(t, root.GetSteps())
+
+	var relations []string
+	var walk func(step *apiV1beta1.CheckDebugStep)
+	walk = func(step *apiV1beta1.CheckDebugStep) {
+		relations = append(relations, step.GetRelation())
+		for _, sub := range step.GetSteps() {
+			walk(sub)
+		}
+	}
+	walk(root)
+	var wildcard bool
+	for _, tuple := range resp.GetDebugTrace().GetMatchedTuples() {
+		relations = append(relations, tuple.GetRelation())
+		wildcard = wildcard || tuple.GetRelation() == "view_widget" && tuple.GetSubject().GetSubject().GetId() == "*"
+	}
+	assert.True(t, wildcard, "the wildcard tuple granting bob is listed")
+	for _, relation := range relations {
+		assert.False(t, strings.HasPrefix(relation, relationPrefix), "relation %s is named as in SpiceDB", relation)
+	}
+}
+
 func TestSpiceDbRepository_CheckPermission_MinimizeLatency(t *testing.T) {
 	t.Parallel()
 
@@ -1288,7 +1453,7 @@ func TestSpiceDbRepository_CheckPermission_MinimizeLatency(t *testing.T) {
 			SubjectNamespace: pointerize("rbac"),
 			SubjectType:      pointerize("principal"),
 		},
-	}, nil)
+	}, nil, biz.DeleteOptions{})
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -1605,7 +1770,7 @@ func TestSpiceDbRepository_DeleteRelationships_WithFencing(t *testing.T) {
 		LockId:    lockIdentifier,
 		LockToken: fencingToken,
 	}
-	_, err = spiceDbRepo.DeleteRelationships(ctx, filter, fencing)
+	_, err = spiceDbRepo.DeleteRelationships(ctx, filter, fencing, biz.DeleteOptions{})
 	assert.NoError(t, err)
 
 	container.WaitForQuantizationInterval()
@@ -1620,7 +1785,7 @@ func TestSpiceDbRepository_DeleteRelationships_WithFencing(t *testing.T) {
 	_, err = spiceDbRepo.DeleteRelationships(ctx, filter, &apiV1beta1.FencingCheck{
 		LockId:    lockIdentifier,
 		LockToken: "invalid-token",
-	})
+	}, biz.DeleteOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "error invoking DeleteRelationships in SpiceDB")
 
@@ -1628,7 +1793,7 @@ func TestSpiceDbRepository_DeleteRelationships_WithFencing(t *testing.T) {
 	_, err = spiceDbRepo.DeleteRelationships(ctx, filter, &apiV1beta1.FencingCheck{
 		LockId:    "invalid-lock-id",
 		LockToken: fencingToken,
-	})
+	}, biz.DeleteOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "error invoking DeleteRelationships in SpiceDB")
 }
@@ -2093,3 +2258,386 @@ func collectResourceIds(t *testing.T, resources chan *biz.ResourceResult, errs c
 		}
 	}
 }
+
+func groupMembersFilter(groupId string) *apiV1beta1.RelationTupleFilter {
+	return &apiV1beta1.RelationTupleFilter{
+		ResourceId:        pointerize(groupId),
+		ResourceNamespace: pointerize("rbac"),
+		ResourceType:      pointerize("group"),
+		Relation:          pointerize("member"),
+	}
+}
+
+func createGroupMembers(t *testing.T, spiceDbRepo *SpiceDbRepository, groupId string, members ...string) {
+	var rels []*apiV1beta1.Relationship
+	for _, member := range members {
+		rels = append(rels, createRelationship("rbac", "group", groupId, "member", "rbac", "principal", member, ""))
+	}
+	_, err := spiceDbRepo.CreateRelationships(context.Background(), rels, biz.TouchSemantics(true), nil)
+	assert.NoError(t, err)
+}
+
+func TestDeleteRelationships_DryRunDeletesNothing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	createGroupMembers(t, spiceDbRepo, "dry_run_club", "alice", "bob", "carol")
+
+	resp, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("dry_run_club"), nil, biz.DeleteOptions{DryRun: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(3), resp.GetDeletedCount())
+	assert.Len(t, resp.GetSample(), 3)
+	assert.Equal(t, "member", resp.GetSample()[0].GetRelation())
+	assert.False(t, resp.GetMoreRemaining())
+
+	resp, err = spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("dry_run_club"), nil, biz.DeleteOptions{DryRun: true, Limit: 2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), resp.GetDeletedCount())
+	assert.True(t, resp.GetMoreRemaining())
+
+	container.WaitForQuantizationInterval()
+
+	readRelChan, _, err := spiceDbRepo.ReadRelationships(ctx, groupMembersFilter("dry_run_club"), 0, "", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 3, len(spiceRelChanToSlice(readRelChan)))
+}
+
+func TestDeleteRelationships_DryRunCountsAtMostMaxDeletions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	spiceDbRepo.maxDeletions = 2
+	createGroupMembers(t, spiceDbRepo, "capped_dry_run_club", "alice", "bob", "carol")
+
+	resp, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("capped_dry_run_club"), nil, biz.DeleteOptions{DryRun: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), resp.GetDeletedCount())
+	assert.True(t, resp.GetMoreRemaining())
+
+	resp, err = spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("capped_dry_run_club"), nil, biz.DeleteOptions{DryRun: true, Limit: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), resp.GetDeletedCount())
+	assert.True(t, resp.GetMoreRemaining())
+}
+
+func TestDeleteRelationships_ListsDeletedTuples(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	createGroupMembers(t, spiceDbRepo, "listed_club", "alice", "bob", "carol")
+
+	resp, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("listed_club"), nil, biz.DeleteOptions{Limit: 2, ListDeleted: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), resp.GetDeletedCount())
+	assert.Len(t, resp.Deleted, 2, "only the tuples the limit deletes are listed")
+	assert.Empty(t, resp.GetSample(), "the response has no sample outside of a dry run")
+
+	resp, err = spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("listed_club"), nil, biz.DeleteOptions{ListDeleted: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(1), resp.GetDeletedCount())
+	if assert.Len(t, resp.Deleted, 1) {
+		assert.Equal(t, "member", resp.Deleted[0].GetRelation())
+		assert.Equal(t, "listed_club", resp.Deleted[0].GetResource().GetId())
+	}
+}
+
+func TestDeleteRelationships_ListsTuplesDeletedByFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	createGroupMembers(t, spiceDbRepo, "watched_club", "alice", "bob", "carol")
+
+	resp, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("watched_club"), nil, biz.DeleteOptions{ListDeleted: 2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(3), resp.GetDeletedCount(), "more tuples match than are listed, all are deleted")
+	if assert.Len(t, resp.Deleted, 2, "the tuples deleted are read back from the watch api") {
+		for _, tuple := range resp.Deleted {
+			assert.Equal(t, "watched_club", tuple.GetResource().GetId())
+			assert.Contains(t, []string{"alice", "bob", "carol"}, tuple.GetSubject().GetSubject().GetId())
+		}
+	}
+}
+
+func TestDeleteRelationships_ListedDeletionIsFenced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = spiceDbRepo.AcquireLock(ctx, "listed-delete-lock")
+	if !assert.NoError(t, err) {
+		return
+	}
+	createGroupMembers(t, spiceDbRepo, "fenced_club", "alice")
+
+	_, err = spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("fenced_club"),
+		&apiV1beta1.FencingCheck{LockId: "listed-delete-lock", LockToken: "stale"}, biz.DeleteOptions{ListDeleted: 10})
+	assert.Error(t, err)
+
+	resp, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("fenced_club"), nil, biz.DeleteOptions{DryRun: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(1), resp.GetDeletedCount(), "nothing is deleted without the lock")
+	}
+}
+
+func TestRelationshipMatching_MatchesSubjectsWithoutRelationOnly(t *testing.T) {
+	t.Parallel()
+
+	filter := relationshipMatching(&v1.Relationship{
+		Resource: &v1.ObjectReference{ObjectType: "rbac/group", ObjectId: "g1"},
+		Relation: "t_member",
+		Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "rbac/principal", ObjectId: "bob"}},
+	})
+
+	assert.Equal(t, "rbac/group", filter.GetResourceType())
+	assert.Equal(t, "g1", filter.GetOptionalResourceId())
+	assert.Equal(t, "t_member", filter.GetOptionalRelation())
+	assert.Equal(t, "bob", filter.GetOptionalSubjectFilter().GetOptionalSubjectId())
+	if assert.NotNil(t, filter.GetOptionalSubjectFilter().GetOptionalRelation()) {
+		assert.Empty(t, filter.GetOptionalSubjectFilter().GetOptionalRelation().GetRelation())
+	}
+}
+
+func TestDeleteRelationships_LimitDeletesPartially(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	createGroupMembers(t, spiceDbRepo, "partial_club", "alice", "bob", "carol")
+
+	resp, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("partial_club"), nil, biz.DeleteOptions{Limit: 2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), resp.GetDeletedCount())
+	assert.True(t, resp.GetMoreRemaining())
+
+	resp, err = spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("partial_club"), nil, biz.DeleteOptions{Limit: 2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(1), resp.GetDeletedCount())
+	assert.False(t, resp.GetMoreRemaining())
+}
+
+func TestDeleteRelationships_ThresholdRequiresOverride(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	spiceDbRepo.maxDeletions = 2
+	createGroupMembers(t, spiceDbRepo, "threshold_club", "alice", "bob", "carol")
+
+	_, err = spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("threshold_club"), nil, biz.DeleteOptions{})
+	assert.Equal(t, biz.DeleteThresholdExceededReason, kerrors.Reason(err))
+
+	_, err = spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("threshold_club"), nil, biz.DeleteOptions{Limit: 3})
+	assert.Equal(t, biz.DeleteThresholdExceededReason, kerrors.Reason(err))
+
+	resp, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("threshold_club"), nil, biz.DeleteOptions{OverrideThreshold: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(3), resp.GetDeletedCount())
+}
+
+func TestReloadSchema_KeepsSchemaRejectedBySpiceDb(t *testing.T) {
+	t.Parallel()
+
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	require.NoError(t, err)
+
+	schema, err := os.ReadFile(spiceDbRepo.schemaFilePath)
+	require.NoError(t, err)
+	spiceDbRepo.schemaFilePath = filepath.Join(t.TempDir(), "schema.zed")
+	require.NoError(t, os.WriteFile(spiceDbRepo.schemaFilePath, schema, 0600))
+	require.NoError(t, spiceDbRepo.InitializeSchema(context.Background()))
+
+	assert.ErrorIs(t, spiceDbRepo.reloadSchema(context.Background()), errUnchanged)
+
+	require.NoError(t, os.WriteFile(spiceDbRepo.schemaFilePath, []byte("definition rbac/group { relation"), 0600))
+	assert.Error(t, spiceDbRepo.reloadSchema(context.Background()))
+	assert.Equal(t, string(schema), spiceDbRepo.appliedSchema)
+}
+
+func TestCountRelationships_StopsAtLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	createGroupMembers(t, spiceDbRepo, "counted_club", "alice", "bob", "carol")
+	filter := schema.TupleFilter{ResourceType: "rbac/group", Relation: "t_member", SubjectType: "rbac/principal"}
+
+	count, truncated, err := spiceDbRepo.CountRelationships(ctx, filter, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+	assert.False(t, truncated)
+
+	count, truncated, err = spiceDbRepo.CountRelationships(ctx, filter, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+	assert.True(t, truncated)
+
+	filter.SubjectRelation = "member"
+	count, _, err = spiceDbRepo.CountRelationships(ctx, filter, 10)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestReadSchema_ReturnsWrittenSchema(t *testing.T) {
+	t.Parallel()
+
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, spiceDbRepo.InitializeSchema(context.Background()))
+
+	text, err := spiceDbRepo.ReadSchema(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, text, "definition rbac/group")
+}
+
+func TestSpiceDbRepository_RewriteRelationships_WithFencing(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	lockResp, err := spiceDbRepo.AcquireLock(ctx, "rewrite-lock")
+	if !assert.NoError(t, err) {
+		return
+	}
+	from := createRelationship("rbac", "role_binding", "rewritten", "subject", "rbac", "principal", "bob", "")
+	_, err = spiceDbRepo.CreateRelationships(ctx, []*apiV1beta1.Relationship{proto.Clone(from).(*apiV1beta1.Relationship)}, biz.TouchSemantics(true), nil)
+	assert.NoError(t, err)
+	to := createRelationship("rbac", "role_binding", "rewritten", "subject", "rbac", "group", "admins", "member")
+
+	_, err = spiceDbRepo.RewriteRelationships(ctx, []*apiV1beta1.Relationship{from}, []*apiV1beta1.Relationship{to},
+		&apiV1beta1.FencingCheck{LockId: "rewrite-lock", LockToken: "stale"})
+	assert.Equal(t, biz.LockLostReason, kerrors.Reason(err))
+
+	token, err := spiceDbRepo.RewriteRelationships(ctx, []*apiV1beta1.Relationship{from}, []*apiV1beta1.Relationship{to},
+		&apiV1beta1.FencingCheck{LockId: "rewrite-lock", LockToken: lockResp.GetLockToken()})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "subject", from.GetRelation(), "the given tuples are not modified")
+
+	results, errs, err := spiceDbRepo.ReadRelationships(ctx, &apiV1beta1.RelationTupleFilter{
+		ResourceNamespace: pointerize("rbac"),
+		ResourceType:      pointerize("role_binding"),
+		ResourceId:        pointerize("rewritten"),
+	}, 0, "", &apiV1beta1.Consistency{Requirement: &apiV1beta1.Consistency_AtLeastAsFresh{AtLeastAsFresh: token}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	read := spiceRelChanToSlice(results)
+	assert.NoError(t, <-errs)
+	if assert.Len(t, read, 1) {
+		assert.Equal(t, "admins", read[0].Relationship.GetSubject().GetSubject().GetId())
+		assert.Equal(t, "member", read[0].Relationship.GetSubject().GetRelation())
+	}
+}
+
+func TestTupleEventRelay_PublishesChangesWithTheirPrincipalAndDeletedTuples(t *testing.T) {
+	t.Parallel()
+	ctx := biz.NewPrincipalContext(context.Background(), "alice")
+	spiceDbRepo, err := container.CreateSpiceDbRepository()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, spiceDbRepo.InitializeSchema(ctx)) {
+		return
+	}
+	// starting from a known revision rather than the head, which would race with the writes below
+	schema, err := spiceDbRepo.client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, cursorFileName), []byte(schema.GetReadAt().GetToken()), 0o600))
+	sink := &recordingSink{}
+	o, err := openTupleEventOutbox(dir, sink, outbox.Options{RetryBackoff: time.Millisecond}, log.DefaultLogger)
+	require.NoError(t, err)
+	o.outbox.Start()
+	defer o.Close()
+	lease := newRelayLease(spiceDbRepo, "relay-test", time.Minute, log.DefaultLogger)
+	relay := newTupleEventRelay(spiceDbRepo.client, spiceDbRepo.tokens, o, lease, dir, time.Millisecond, log.DefaultLogger)
+	go func() { _ = relay.Start(context.Background()) }()
+	defer func() { _ = relay.Stop(context.Background()) }()
+
+	createGroupMembers(t, spiceDbRepo, "evented_club", "alice", "bob")
+	_, err = spiceDbRepo.CreateRelationships(ctx, []*apiV1beta1.Relationship{
+		createRelationship("rbac", "group", "evented_club", "member", "rbac", "principal", "carol", ""),
+	}, biz.TouchSemantics(true), nil)
+	require.NoError(t, err)
+	deleted, err := spiceDbRepo.DeleteRelationships(ctx, groupMembersFilter("evented_club"), nil, biz.DeleteOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return len(sink.events()) == 3 }, 10*time.Second, 10*time.Millisecond)
+	events := sink.events()
+	if !assert.Len(t, events, 3) {
+		return
+	}
+	assert.Empty(t, events[0].GetPrincipal(), "changes made without a principal have none")
+	assert.Len(t, events[0].GetTuples(), 2)
+	assert.Equal(t, "alice", events[1].GetPrincipal())
+	assert.Equal(t, "carol", events[1].GetTuples()[0].GetSubject().GetSubject().GetId())
+	assert.Equal(t, "member", events[1].GetTuples()[0].GetRelation())
+
+	assert.Equal(t, apiV1beta1.TupleEvent_OPERATION_DELETE, events[2].GetOperation())
+	assert.Equal(t, "alice", events[2].GetPrincipal())
+	assert.Equal(t, uint64(3), events[2].GetDeletedCount())
+	assert.Len(t, events[2].GetTuples(), 3, "the deleted tuples are listed")
+	assert.Equal(t, "member", events[2].GetFilter().GetRelation(), "the filter is reported as the caller gave it")
+	deletedAt, err := spiceDbRepo.tokens.decode(deleted.GetConsistencyToken().GetToken())
+	require.NoError(t, err)
+	revision, err := spiceDbRepo.tokens.decode(events[2].GetConsistencyToken().GetToken())
+	require.NoError(t, err)
+	assert.Equal(t, deletedAt, revision)
+}
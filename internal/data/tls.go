@@ -0,0 +1,142 @@
+package data
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+// tlsReloader holds the CA pool and client certificate used to connect to SpiceDB. Files are stat'ed on every
+// handshake and re-read when their modification time changes, so rotated credentials are picked up by new
+// connections without restarting. If a rotated file cannot be loaded the previous credentials stay in use.
+type tlsReloader struct {
+	caFile   string
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	caMod   time.Time
+	certMod time.Time
+	keyMod  time.Time
+	roots   *x509.CertPool
+	cert    *tls.Certificate
+}
+
+// newSpiceDbTLSConfig creates the TLS configuration of the SpiceDB connection. Without a CA file the system roots
+// are used.
+func newSpiceDbTLSConfig(c *conf.Data_SpiceDb_TLS) (*tls.Config, error) {
+	if (c.GetCertFile() == "") != (c.GetKeyFile() == "") {
+		return nil, fmt.Errorf("spicedb tls certFile and keyFile must be set together")
+	}
+
+	r := &tlsReloader{caFile: c.GetCaFile(), certFile: c.GetCertFile(), keyFile: c.GetKeyFile()}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.GetServerName(),
+	}
+	if r.certFile != "" {
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			_, cert := r.current()
+			return cert, nil
+		}
+	}
+	if r.caFile == "" {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("error loading system CA pool: %w", err)
+		}
+		tlsConfig.RootCAs = roots
+		return tlsConfig, nil
+	}
+
+	// the standard verification only accepts a fixed RootCAs pool, so verify against the current pool ourselves
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		roots, _ := r.current()
+		return verifyServerCertificate(cs, roots)
+	}
+	return tlsConfig, nil
+}
+
+func verifyServerCertificate(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("spicedb presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// current returns the credentials to use for a handshake, reloading any that changed on disk.
+func (r *tlsReloader) current() (*x509.CertPool, *tls.Certificate) {
+	_ = r.reload() // on failure the previously loaded credentials are kept
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.roots, r.cert
+}
+
+func (r *tlsReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.caFile != "" {
+		if mod, changed, err := modifiedSince(r.caFile, r.caMod); err != nil {
+			return err
+		} else if changed {
+			pem, err := os.ReadFile(r.caFile)
+			if err != nil {
+				return fmt.Errorf("error loading spicedb CA file: %w", err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("spicedb CA file %s contains no PEM certificates", r.caFile)
+			}
+			r.roots, r.caMod = roots, mod
+		}
+	}
+
+	if r.certFile != "" {
+		certMod, certChanged, err := modifiedSince(r.certFile, r.certMod)
+		if err != nil {
+			return err
+		}
+		keyMod, keyChanged, err := modifiedSince(r.keyFile, r.keyMod)
+		if err != nil {
+			return err
+		}
+		if certChanged || keyChanged {
+			cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+			if err != nil {
+				return fmt.Errorf("error loading spicedb client certificate: %w", err)
+			}
+			r.cert, r.certMod, r.keyMod = &cert, certMod, keyMod
+		}
+	}
+
+	return nil
+}
+
+func modifiedSince(file string, since time.Time) (time.Time, bool, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("error reading %s: %w", file, err)
+	}
+	return info.ModTime(), !info.ModTime().Equal(since), nil
+}
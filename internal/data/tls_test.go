@@ -0,0 +1,140 @@
+package data
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert issues a certificate for name, self-signed when parent is nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeFile writes data and moves the modification time forward so the change is seen regardless of timestamp
+// resolution.
+func writeFile(t *testing.T, path string, data []byte, mod time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	require.NoError(t, os.Chtimes(path, mod, mod))
+}
+
+func TestSpiceDbTLSConfig_ReloadsRotatedClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := newTestCert(t, "ca", nil)
+	first := newTestCert(t, "client-1", ca)
+	second := newTestCert(t, "client-2", ca)
+
+	start := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, first.certPEM, start)
+	writeFile(t, keyFile, first.keyPEM, start)
+
+	tlsConfig, err := newSpiceDbTLSConfig(&conf.Data_SpiceDb_TLS{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	cert, err := tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, cert.Certificate[0])
+
+	// a half-written rotation keeps the previous certificate
+	writeFile(t, certFile, second.certPEM, start.Add(time.Second))
+	cert, err = tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, cert.Certificate[0])
+
+	writeFile(t, keyFile, second.keyPEM, start.Add(2*time.Second))
+	cert, err = tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, cert.Certificate[0])
+}
+
+func TestSpiceDbTLSConfig_VerifiesAgainstReloadedCA(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	oldCA := newTestCert(t, "old-ca", nil)
+	newCA := newTestCert(t, "new-ca", nil)
+	server := newTestCert(t, "spicedb.internal", newCA)
+
+	start := time.Now().Add(-time.Minute)
+	writeFile(t, caFile, oldCA.certPEM, start)
+
+	tlsConfig, err := newSpiceDbTLSConfig(&conf.Data_SpiceDb_TLS{CaFile: caFile, ServerName: "spicedb.internal"})
+	require.NoError(t, err)
+	assert.Equal(t, "spicedb.internal", tlsConfig.ServerName)
+
+	state := tls.ConnectionState{ServerName: "spicedb.internal", PeerCertificates: []*x509.Certificate{server.cert}}
+	assert.Error(t, tlsConfig.VerifyConnection(state))
+
+	writeFile(t, caFile, newCA.certPEM, start.Add(time.Second))
+	assert.NoError(t, tlsConfig.VerifyConnection(state))
+
+	state.ServerName = "other.internal"
+	assert.Error(t, tlsConfig.VerifyConnection(state))
+}
+
+func TestSpiceDbTLSConfig_RejectsIncompleteConfiguration(t *testing.T) {
+	t.Parallel()
+
+	_, err := newSpiceDbTLSConfig(&conf.Data_SpiceDb_TLS{CertFile: "tls.crt"})
+	assert.Error(t, err)
+
+	_, err = newSpiceDbTLSConfig(&conf.Data_SpiceDb_TLS{CaFile: filepath.Join(t.TempDir(), "missing.crt")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/project-kessel/relations-api/internal/slowlog"
+)
+
+const spiceDbTracerName = "github.com/project-kessel/relations-api/internal/data"
+
+// Attributes of SpiceDB client spans describing the request.
+const (
+	permissionKey   = attribute.Key("kessel.permission")
+	resourceTypeKey = attribute.Key("kessel.resource_type")
+	consistencyKey  = attribute.Key("kessel.consistency")
+	bulkItemsKey    = attribute.Key("kessel.bulk_items")
+)
+
+// spiceDbTracer records a client span for every SpiceDB call and propagates the trace context to SpiceDB in the
+// request metadata, so SpiceDB's own spans join the trace of the request being served. It also records the duration
+// of every call, and the work SpiceDB reports for it in the slow request stats of the request being served.
+type spiceDbTracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	metrics    *repositoryMetrics
+}
+
+func newSpiceDbTracer(provider trace.TracerProvider, propagator propagation.TextMapPropagator, metrics *repositoryMetrics) *spiceDbTracer {
+	return &spiceDbTracer{tracer: provider.Tracer(spiceDbTracerName), propagator: propagator, metrics: metrics}
+}
+
+// spiceDbCall is a SpiceDB call in progress.
+type spiceDbCall struct {
+	ctx     context.Context
+	span    trace.Span
+	method  string
+	start   time.Time
+	metrics *repositoryMetrics
+	stats   *slowlog.Stats
+	// trailer of the call, once it ended normally
+	trailer metadata.MD
+}
+
+func (t *spiceDbTracer) start(ctx context.Context, method string) (context.Context, *spiceDbCall) {
+	name := strings.TrimPrefix(method, "/")
+	attrs := []attribute.KeyValue{semconv.RPCSystemGRPC}
+	rpcMethod := name
+	if service, m, ok := strings.Cut(name, "/"); ok {
+		attrs = append(attrs, semconv.RPCService(service), semconv.RPCMethod(m))
+		rpcMethod = m
+	}
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	t.propagator.Inject(ctx, metadataCarrier(md))
+	call := &spiceDbCall{ctx: ctx, span: span, method: rpcMethod, start: time.Now(), metrics: t.metrics, stats: slowlog.FromContext(ctx)}
+	return metadata.NewOutgoingContext(ctx, md), call
+}
+
+// describe records the attributes of the request of the call.
+func (c *spiceDbCall) describe(req any) {
+	c.span.SetAttributes(requestAttributes(req)...)
+	consistency := ""
+	if r, ok := req.(interface{ GetConsistency() *v1.Consistency }); ok {
+		consistency = consistencyMode(r.GetConsistency())
+	}
+	c.stats.SpiceDbCalled(consistency)
+}
+
+func (t *spiceDbTracer) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, call := t.start(ctx, method)
+		call.describe(req)
+		if call.stats != nil {
+			opts = append(opts, grpc.Trailer(&call.trailer))
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		call.end(err)
+		return err
+	}
+}
+
+func (t *spiceDbTracer) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, call := t.start(ctx, method)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			call.end(err)
+			return nil, err
+		}
+		s := &tracingClientStream{ClientStream: stream, call: call, serverStreams: desc.ServerStreams, done: make(chan struct{})}
+		// a stream abandoned before it is drained ends with its context
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.end(ctx.Err())
+			case <-s.done:
+			}
+		}()
+		return s, nil
+	}
+}
+
+// tracingClientStream describes the stream by its first request and ends its span once the stream ends.
+type tracingClientStream struct {
+	grpc.ClientStream
+	call          *spiceDbCall
+	serverStreams bool
+	described     sync.Once
+	ended         sync.Once
+	done          chan struct{}
+}
+
+func (s *tracingClientStream) SendMsg(m any) error {
+	s.described.Do(func() { s.call.describe(m) })
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.end(err)
+	}
+	return err
+}
+
+func (s *tracingClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		s.endWithTrailer(nil, s.ClientStream.Trailer())
+	case err != nil:
+		s.end(err)
+	case !s.serverStreams:
+		// client-streaming calls end with their only response
+		s.end(nil)
+	}
+	return err
+}
+
+func (s *tracingClientStream) end(err error) {
+	s.endWithTrailer(err, nil)
+}
+
+func (s *tracingClientStream) endWithTrailer(err error, trailer metadata.MD) {
+	s.ended.Do(func() {
+		s.call.trailer = trailer
+		s.call.end(err)
+		close(s.done)
+	})
+}
+
+func (c *spiceDbCall) end(err error) {
+	if c.metrics != nil {
+		c.metrics.spiceDbCallFinished(c.ctx, c.method, c.start, err)
+	}
+	c.stats.SpiceDbResponded(c.trailer)
+	span := c.span
+	if err != nil {
+		st, _ := status.FromError(err)
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(st.Code())))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, st.Message())
+	} else {
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(0))
+	}
+	span.End()
+}
+
+// requestAttributes describes a SpiceDB request by the permission it checks, the type of the resources it addresses
+// and the consistency it asks for, whichever apply.
+func requestAttributes(req any) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if r, ok := req.(interface{ GetPermission() string }); ok && r.GetPermission() != "" {
+		attrs = append(attrs, permissionKey.String(r.GetPermission()))
+	}
+	var resourceType string
+	switch r := req.(type) {
+	case interface{ GetResource() *v1.ObjectReference }:
+		resourceType = r.GetResource().GetObjectType()
+	case interface{ GetResourceObjectType() string }:
+		resourceType = r.GetResourceObjectType()
+	case interface{ GetRelationshipFilter() *v1.RelationshipFilter }:
+		resourceType = r.GetRelationshipFilter().GetResourceType()
+	}
+	if resourceType != "" {
+		attrs = append(attrs, resourceTypeKey.String(resourceType))
+	}
+	if r, ok := req.(interface{ GetConsistency() *v1.Consistency }); ok {
+		attrs = append(attrs, consistencyKey.String(consistencyMode(r.GetConsistency())))
+	}
+	if r, ok := req.(*v1.CheckBulkPermissionsRequest); ok {
+		attrs = append(attrs, bulkItemsKey.Int(len(r.GetItems())))
+	}
+	return attrs
+}
+
+// consistencyMode names the consistency requirement of a request.
+func consistencyMode(c *v1.Consistency) string {
+	switch c.GetRequirement().(type) {
+	case *v1.Consistency_FullyConsistent:
+		return "fully_consistent"
+	case *v1.Consistency_AtLeastAsFresh:
+		return "at_least_as_fresh"
+	case *v1.Consistency_AtExactSnapshot:
+		return "at_exact_snapshot"
+	}
+	// SpiceDB treats a missing requirement as minimize latency
+	return "minimize_latency"
+}
+
+// metadataCarrier adapts outgoing gRPC metadata for trace context propagation.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
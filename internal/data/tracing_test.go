@@ -0,0 +1,197 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/slowlog"
+)
+
+const checkPermission = "/authzed.api.v1.PermissionsService/CheckPermission"
+
+func newTestTracer() (*spiceDbTracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return newSpiceDbTracer(provider, propagation.TraceContext{}, nil), recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSpiceDbTracer_TracesUnaryCalls(t *testing.T) {
+	t.Parallel()
+	tracer, recorder := newTestTracer()
+
+	var traceparent []string
+	req := &v1.CheckPermissionRequest{
+		Resource:    &v1.ObjectReference{ObjectType: "rbac/group", ObjectId: "g1"},
+		Permission:  "member",
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+	}
+	err := tracer.unaryInterceptor()(context.Background(), checkPermission, req, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			traceparent = md.Get("traceparent")
+			return nil
+		})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "authzed.api.v1.PermissionsService/CheckPermission", span.Name())
+	attrs := spanAttributes(span)
+	assert.Equal(t, "member", attrs[permissionKey].AsString())
+	assert.Equal(t, "rbac/group", attrs[resourceTypeKey].AsString())
+	assert.Equal(t, "fully_consistent", attrs[consistencyKey].AsString())
+	assert.Equal(t, "CheckPermission", attrs["rpc.method"].AsString())
+	if assert.Len(t, traceparent, 1, "the trace context is propagated to SpiceDB") {
+		assert.Contains(t, traceparent[0], span.SpanContext().TraceID().String())
+		assert.Contains(t, traceparent[0], span.SpanContext().SpanID().String())
+	}
+}
+
+func TestSpiceDbTracer_RecordsFailures(t *testing.T) {
+	t.Parallel()
+	tracer, recorder := newTestTracer()
+
+	err := tracer.unaryInterceptor()(context.Background(), checkPermission, &v1.CheckPermissionRequest{}, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.Unavailable, "connection refused")
+		})
+	require.Error(t, err)
+
+	span := recorder.Ended()[0]
+	assert.Equal(t, otelcodes.Error, span.Status().Code)
+	assert.Equal(t, "connection refused", span.Status().Description)
+	assert.Equal(t, int64(codes.Unavailable), spanAttributes(span)["rpc.grpc.status_code"].AsInt64())
+	assert.Equal(t, "minimize_latency", spanAttributes(span)[consistencyKey].AsString())
+}
+
+// fakeClientStream returns the given number of responses before io.EOF.
+type fakeClientStream struct {
+	grpc.ClientStream
+	responses int
+	trailer   metadata.MD
+}
+
+func (s *fakeClientStream) SendMsg(any) error    { return nil }
+func (s *fakeClientStream) CloseSend() error     { return nil }
+func (s *fakeClientStream) Trailer() metadata.MD { return s.trailer }
+
+func (s *fakeClientStream) RecvMsg(any) error {
+	if s.responses == 0 {
+		return io.EOF
+	}
+	s.responses--
+	return nil
+}
+
+func TestSpiceDbTracer_EndsStreamSpansWhenDrained(t *testing.T) {
+	t.Parallel()
+	tracer, recorder := newTestTracer()
+
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	stream, err := tracer.streamInterceptor()(context.Background(), desc, nil, "/authzed.api.v1.PermissionsService/LookupResources",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{responses: 2}, nil
+		})
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&v1.LookupResourcesRequest{ResourceObjectType: "rbac/workspace", Permission: "view"}))
+	require.NoError(t, stream.RecvMsg(nil))
+	require.NoError(t, stream.RecvMsg(nil))
+	assert.Empty(t, recorder.Ended(), "the span lasts until the stream is drained")
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, "view", attrs[permissionKey].AsString())
+	assert.Equal(t, "rbac/workspace", attrs[resourceTypeKey].AsString())
+	assert.Equal(t, otelcodes.Unset, spans[0].Status().Code)
+}
+
+func TestSpiceDbTracer_EndsAbandonedStreamSpans(t *testing.T) {
+	t.Parallel()
+	tracer, recorder := newTestTracer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := tracer.streamInterceptor()(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/authzed.api.v1.PermissionsService/ReadRelationships",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{responses: 5}, nil
+		})
+	require.NoError(t, err)
+	cancel()
+
+	assert.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, time.Second, time.Millisecond)
+}
+
+func TestSpiceDbTracer_CollectsSpiceDbWorkForSlowRequestLogging(t *testing.T) {
+	t.Parallel()
+	tracer, _ := newTestTracer()
+	stats := &slowlog.Stats{}
+	ctx := slowlog.NewContext(context.Background(), stats)
+
+	req := &v1.CheckPermissionRequest{Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}}
+	err := tracer.unaryInterceptor()(ctx, checkPermission, req, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			for _, opt := range opts {
+				if trailer, ok := opt.(grpc.TrailerCallOption); ok {
+					*trailer.TrailerAddr = metadata.Pairs("io.spicedb.respmeta.dispatchedoperationscount", "12")
+				}
+			}
+			return nil
+		})
+	require.NoError(t, err)
+
+	stream, err := tracer.streamInterceptor()(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/authzed.api.v1.PermissionsService/LookupResources",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{trailer: metadata.Pairs("io.spicedb.respmeta.dispatchedoperationscount", "30")}, nil
+		})
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&v1.LookupResourcesRequest{}))
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF)
+
+	logger := &slowRequestEntry{}
+	slowlog.NewLogger(&conf.Server_SlowRequests{Threshold: durationpb.New(0)}, logger).
+		Finished(ctx, slowlog.Request{Args: func() string { return "" }})
+	assert.Equal(t, 2, logger.keyvals["spicedb_calls"])
+	assert.Equal(t, "fully_consistent,minimize_latency", logger.keyvals["consistency"])
+	assert.Equal(t, 42, logger.keyvals["dispatched_operations"])
+}
+
+// slowRequestEntry keeps the key values of the slow request logged.
+type slowRequestEntry struct {
+	keyvals map[string]any
+}
+
+func (e *slowRequestEntry) Log(level log.Level, keyvals ...interface{}) error {
+	e.keyvals = map[string]any{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		e.keyvals[keyvals[i].(string)] = keyvals[i+1]
+	}
+	return nil
+}
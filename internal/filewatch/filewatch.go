@@ -0,0 +1,109 @@
+// Package filewatch notifies about changes to files that are replaced on disk, such as secrets and config maps
+// mounted into a pod.
+package filewatch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// settleDelay batches the events of a single update, e.g. Kubernetes swapping the ..data symlink of a volume.
+const settleDelay = 500 * time.Millisecond
+
+// Watcher calls a function when a watched file may have changed. The directories containing the files are watched
+// rather than the files themselves, so files replaced by a rename or a symlink swap keep being watched. Events do
+// not guarantee that the content changed; callers are expected to re-read the files and compare.
+type Watcher struct {
+	fw       *fsnotify.Watcher
+	onChange func()
+	log      *log.Helper
+
+	mu    sync.Mutex
+	timer *time.Timer
+	done  chan struct{}
+}
+
+// New watches the given paths, which may be files or directories, calling onChange after they were modified.
+// onChange is never called concurrently with itself.
+func New(paths []string, onChange func(), logger log.Logger) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating file watcher: %w", err)
+	}
+	w := &Watcher{fw: fw, onChange: onChange, log: log.NewHelper(logger), done: make(chan struct{})}
+
+	for _, path := range paths {
+		dir := path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+		if err := fw.Add(dir); err != nil {
+			_ = fw.Close()
+			return nil, fmt.Errorf("error watching %s: %w", dir, err)
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *Watcher) run() {
+	changes := make(chan struct{}, 1)
+	go func() {
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-changes:
+				w.onChange()
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.fw.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			w.mu.Lock()
+			if w.timer != nil {
+				w.timer.Stop()
+			}
+			w.timer = time.AfterFunc(settleDelay, func() {
+				select {
+				case changes <- struct{}{}:
+				default: // a change is already pending
+				}
+			})
+			w.mu.Unlock()
+		case err, ok := <-w.fw.Errors:
+			if !ok {
+				return
+			}
+			w.log.Warnf("error watching files: %v", err)
+		}
+	}
+}
+
+// Close stops watching. A change already being handled completes.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	close(w.done)
+	return w.fw.Close()
+}
@@ -0,0 +1,55 @@
+package filewatch
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_NotifiesOnWrite(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("first"), 0600))
+
+	var changes atomic.Int32
+	w, err := New([]string{file}, func() { changes.Add(1) }, log.NewStdLogger(io.Discard))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	require.NoError(t, os.WriteFile(file, []byte("second"), 0600))
+	assert.Eventually(t, func() bool { return changes.Load() == 1 }, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestWatcher_FollowsSymlinkSwap(t *testing.T) {
+	t.Parallel()
+
+	// mimics how Kubernetes updates a mounted secret: the file is a symlink through ..data, which is swapped
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "v1"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v1", "token"), []byte("first"), 0600))
+	require.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "token"), filepath.Join(dir, "token")))
+
+	var changes atomic.Int32
+	w, err := New([]string{filepath.Join(dir, "token")}, func() { changes.Add(1) }, log.NewStdLogger(io.Discard))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	for i, version := range []string{"v2", "v3"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, version), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version, "token"), []byte(version), 0600))
+		require.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+		want := int32(i + 1)
+		assert.Eventually(t, func() bool { return changes.Load() == want }, 5*time.Second, 50*time.Millisecond)
+	}
+}
@@ -0,0 +1,300 @@
+// Package outbox buffers entries on disk until they are delivered, so entries survive their destination being
+// unavailable and the service restarting.
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	DefaultBatchSize    = 100
+	DefaultRetryBackoff = time.Second
+	maxRetryBackoff     = time.Minute
+
+	FileName       = "outbox.jsonl"
+	offsetFileName = "outbox.offset"
+)
+
+// PublishFunc delivers a batch of entries. A batch whose delivery fails is published again in full, so it need not
+// handle partial failures.
+type PublishFunc func(ctx context.Context, entries [][]byte) error
+
+// Options tune delivery, zero values select the defaults.
+type Options struct {
+	BatchSize    int
+	RetryBackoff time.Duration
+	// Name describes the entries in log messages, e.g. "tuple events"
+	Name string
+}
+
+// Outbox records entries, single lines such as JSON documents, in an append-only file before they are delivered.
+// The byte offset of the first undelivered entry is kept in a separate file that is only advanced once a batch is
+// published, so delivery is at least once. The file is truncated whenever every entry in it has been delivered.
+type Outbox struct {
+	dir          string
+	publish      PublishFunc
+	batchSize    int
+	retryBackoff time.Duration
+	name         string
+	log          *log.Helper
+
+	mu     sync.Mutex
+	file   *os.File
+	notify chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Open opens the outbox in dir, creating it if needed. Entries left undelivered by a previous run are delivered once
+// Start is called.
+func Open(dir string, publish PublishFunc, opts Options, logger log.Logger) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating outbox directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening outbox: %w", err)
+	}
+	if err := truncateTornWrite(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error repairing outbox: %w", err)
+	}
+	o := &Outbox{
+		dir:          dir,
+		publish:      publish,
+		batchSize:    opts.BatchSize,
+		retryBackoff: opts.RetryBackoff,
+		name:         opts.Name,
+		log:          log.NewHelper(logger),
+		file:         f,
+		notify:       make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = DefaultBatchSize
+	}
+	if o.retryBackoff <= 0 {
+		o.retryBackoff = DefaultRetryBackoff
+	}
+	if o.name == "" {
+		o.name = "outbox entries"
+	}
+	return o, nil
+}
+
+// truncateTornWrite removes a trailing partial line left by a crash during Append, which never reported the entry as
+// recorded, so the next entry is not appended to it.
+func truncateTornWrite(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 64*1024)
+	for end := info.Size(); end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		chunk := buf[:end-start]
+		if _, err := f.ReadAt(chunk, start); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			if start+int64(i)+1 == info.Size() {
+				return nil
+			}
+			return f.Truncate(start + int64(i) + 1)
+		}
+		end = start
+	}
+	return f.Truncate(0)
+}
+
+// Append records the entry, which must not contain newlines, returning once it is synced to disk.
+func (o *Outbox) Append(entry []byte) error {
+	if bytes.IndexByte(entry, '\n') >= 0 {
+		return errors.New("outbox entries must be single lines")
+	}
+	line := append(entry[:len(entry):len(entry)], '\n')
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, err := o.file.Write(line); err != nil {
+		return fmt.Errorf("error writing to outbox: %w", err)
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing outbox: %w", err)
+	}
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start delivers entries in the background until Close.
+func (o *Outbox) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	go o.relay(ctx)
+}
+
+// Close stops delivering, leaving undelivered entries to be delivered on the next start.
+func (o *Outbox) Close() error {
+	if o.cancel != nil {
+		o.cancel()
+		<-o.done
+	}
+	return o.file.Close()
+}
+
+func (o *Outbox) relay(ctx context.Context) {
+	defer close(o.done)
+	offset, err := o.readOffset()
+	if err != nil {
+		o.log.Errorf("error reading outbox offset, redelivering every entry in the outbox: %v", err)
+	}
+	backoff := o.retryBackoff
+
+	for {
+		entries, next, err := o.read(offset)
+		if err == nil && len(entries) == 0 {
+			if offset, err = o.compact(next); err == nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-o.notify:
+				}
+				continue
+			}
+		}
+		if err == nil {
+			err = o.publish(ctx, entries)
+		}
+		if err == nil {
+			offset = next
+			err = o.writeOffset(offset)
+		}
+		if err == nil {
+			backoff = o.retryBackoff
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		o.log.Warnf("error delivering %s, retrying in %s: %v", o.name, backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// read returns up to batchSize entries starting at offset and the offset after the last of them. A trailing line
+// without a newline is a write still in progress, or torn by a crash, and is not read.
+func (o *Outbox) read(offset int64) ([][]byte, int64, error) {
+	f, err := os.Open(o.file.Name())
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	var entries [][]byte
+	r := bufio.NewReader(f)
+	for len(entries) < o.batchSize {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, offset, err
+		}
+		offset += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			entries = append(entries, line)
+		}
+	}
+	return entries, offset, nil
+}
+
+// compact truncates the outbox if nothing was appended after offset, the end of the delivered entries, returning the
+// offset to read from next.
+func (o *Outbox) compact(offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	info, err := o.file.Stat()
+	if err != nil {
+		return offset, err
+	}
+	if info.Size() != offset {
+		// appended since it was read
+		return offset, nil
+	}
+	if err := o.file.Truncate(0); err != nil {
+		return offset, err
+	}
+	// a crash before the offset is reset leaves it past the end of the outbox, which readOffset treats as zero
+	return 0, o.writeOffset(0)
+}
+
+func (o *Outbox) readOffset() (int64, error) {
+	b, err := os.ReadFile(filepath.Join(o.dir, offsetFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseInt(string(bytes.TrimSpace(b)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	info, err := o.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if offset > info.Size() {
+		return 0, nil
+	}
+	return offset, nil
+}
+
+// writeOffset replaces the offset file atomically, so a crash leaves either the old or the new offset.
+func (o *Outbox) writeOffset(offset int64) error {
+	path := filepath.Join(o.dir, offsetFileName)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strconv.FormatInt(offset, 10)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,127 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects published entries, failing while failures remain.
+type recorder struct {
+	mu        sync.Mutex
+	failures  int
+	batches   int
+	published []string
+}
+
+func (r *recorder) publish(_ context.Context, entries [][]byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("destination unavailable")
+	}
+	r.batches++
+	for _, e := range entries {
+		r.published = append(r.published, string(e))
+	}
+	return nil
+}
+
+func (r *recorder) entries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.published...)
+}
+
+func startTestOutbox(t *testing.T, dir string, r *recorder) *Outbox {
+	t.Helper()
+	o, err := Open(dir, r.publish, Options{BatchSize: 2, RetryBackoff: time.Millisecond}, log.DefaultLogger)
+	require.NoError(t, err)
+	o.Start()
+	return o
+}
+
+func appendEntries(t *testing.T, o *Outbox, entries ...string) {
+	t.Helper()
+	for _, e := range entries {
+		require.NoError(t, o.Append([]byte(e)))
+	}
+}
+
+func TestOutbox_DeliversInBatchesAndCompacts(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	r := &recorder{}
+	o := startTestOutbox(t, dir, r)
+
+	appendEntries(t, o, `"a"`, `"b"`, `"c"`)
+
+	assert.Eventually(t, func() bool { return len(r.entries()) == 3 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{`"a"`, `"b"`, `"c"`}, r.entries())
+	assert.GreaterOrEqual(t, r.batches, 2, "batches hold at most two entries")
+	assert.Eventually(t, func() bool {
+		info, err := os.Stat(filepath.Join(dir, FileName))
+		return err == nil && info.Size() == 0
+	}, 5*time.Second, time.Millisecond, "the outbox is truncated once delivered")
+	require.NoError(t, o.Close())
+}
+
+func TestOutbox_RetriesUntilPublished(t *testing.T) {
+	t.Parallel()
+	r := &recorder{failures: 3}
+	o := startTestOutbox(t, t.TempDir(), r)
+	defer o.Close()
+
+	appendEntries(t, o, `"a"`)
+
+	assert.Eventually(t, func() bool { return len(r.entries()) == 1 }, 5*time.Second, time.Millisecond)
+}
+
+func TestOutbox_DeliversUndeliveredEntriesAfterRestart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	down := &recorder{failures: 1 << 30}
+	o := startTestOutbox(t, dir, down)
+	appendEntries(t, o, `"a"`, `"b"`, `"c"`)
+	require.NoError(t, o.Close())
+	assert.Empty(t, down.entries())
+
+	r := &recorder{}
+	o = startTestOutbox(t, dir, r)
+	defer o.Close()
+
+	assert.Eventually(t, func() bool { return len(r.entries()) == 3 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{`"a"`, `"b"`, `"c"`}, r.entries())
+}
+
+func TestOutbox_DropsTornWrite(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(`{"id":"a"}`+"\n"+`{"id":"tor`), 0o600))
+
+	r := &recorder{}
+	o := startTestOutbox(t, dir, r)
+	defer o.Close()
+	appendEntries(t, o, `{"id":"b"}`)
+
+	assert.Eventually(t, func() bool { return len(r.entries()) == 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{`{"id":"a"}`, `{"id":"b"}`}, r.entries())
+}
+
+func TestOutbox_RejectsMultilineEntries(t *testing.T) {
+	t.Parallel()
+	o, err := Open(t.TempDir(), (&recorder{}).publish, Options{}, log.DefaultLogger)
+	require.NoError(t, err)
+	defer o.Close()
+
+	assert.Error(t, o.Append([]byte("a\nb")))
+}
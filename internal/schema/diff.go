@@ -0,0 +1,207 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ChangeKind classifies a schema change.
+type ChangeKind string
+
+const (
+	AddedType             ChangeKind = "added_type"
+	RemovedType           ChangeKind = "removed_type"
+	AddedRelation         ChangeKind = "added_relation"
+	RemovedRelation       ChangeKind = "removed_relation"
+	AddedPermission       ChangeKind = "added_permission"
+	RemovedPermission     ChangeKind = "removed_permission"
+	WidenedSubjectTypes   ChangeKind = "widened_subject_types"
+	NarrowedSubjectTypes  ChangeKind = "narrowed_subject_types"
+	PermissionExprChanged ChangeKind = "permission_expression_changed"
+)
+
+// Change is a difference between two schemas. Breaking changes either orphan tuples, which SpiceDB refuses to
+// apply while such tuples exist, or remove a permission clients may check.
+type Change struct {
+	Kind       ChangeKind
+	Definition string
+	// Name is the relation or permission changed, empty for type changes.
+	Name     string
+	Detail   string
+	Breaking bool
+	// Affected matches the tuples the change orphans.
+	Affected []TupleFilter
+}
+
+func (c Change) String() string {
+	target := c.Definition
+	if c.Name != "" {
+		target += "#" + c.Name
+	}
+	s := string(c.Kind) + " " + target
+	if c.Detail != "" {
+		s += ": " + c.Detail
+	}
+	return s
+}
+
+// TupleFilter matches tuples by resource type and relation and, if SubjectType is set, by subject type, subject
+// relation and whether the subject is a wildcard.
+type TupleFilter struct {
+	ResourceType    string
+	Relation        string
+	SubjectType     string
+	SubjectRelation string
+	Wildcard        bool
+}
+
+// Matches reports whether the filter matches a tuple.
+func (f TupleFilter) Matches(r Relationship) bool {
+	if r.Resource.Type != f.ResourceType || r.Relation != f.Relation {
+		return false
+	}
+	if f.SubjectType == "" {
+		return true
+	}
+	return r.Subject.Type == f.SubjectType && r.Subject.Relation == f.SubjectRelation && (r.Subject.ID == "*") == f.Wildcard
+}
+
+func (f TupleFilter) String() string {
+	s := f.ResourceType + "#" + f.Relation
+	if f.SubjectType != "" {
+		s += "@" + AllowedType{Type: f.SubjectType, Relation: f.SubjectRelation, Wildcard: f.Wildcard}.String()
+	}
+	return s
+}
+
+// Diff lists the changes from old to new, definitions in the order they appear in old followed by new ones.
+func Diff(old, new *Schema) []Change {
+	var changes []Change
+	for _, d := range old.Definitions {
+		nd := new.Definition(d.Name)
+		if nd == nil {
+			c := Change{Kind: RemovedType, Definition: d.Name, Breaking: true}
+			for _, r := range d.Relations {
+				c.Affected = append(c.Affected, TupleFilter{ResourceType: d.Name, Relation: r.Name})
+			}
+			changes = append(changes, c)
+			continue
+		}
+		changes = append(changes, diffDefinition(d, nd)...)
+	}
+	for _, d := range new.Definitions {
+		if old.Definition(d.Name) == nil {
+			changes = append(changes, Change{Kind: AddedType, Definition: d.Name})
+		}
+	}
+	return changes
+}
+
+func diffDefinition(old, new *Definition) []Change {
+	var changes []Change
+	for _, r := range old.Relations {
+		nr := new.Relation(r.Name)
+		if nr == nil {
+			changes = append(changes, Change{
+				Kind:       RemovedRelation,
+				Definition: old.Name,
+				Name:       r.Name,
+				Breaking:   true,
+				Affected:   []TupleFilter{{ResourceType: old.Name, Relation: r.Name}},
+			})
+			continue
+		}
+		removed, added := typeDifference(r.Types, nr.Types), typeDifference(nr.Types, r.Types)
+		if len(removed) > 0 {
+			c := Change{
+				Kind:       NarrowedSubjectTypes,
+				Definition: old.Name,
+				Name:       r.Name,
+				Detail:     "removed " + joinTypes(removed),
+				Breaking:   true,
+			}
+			for _, t := range removed {
+				c.Affected = append(c.Affected, TupleFilter{
+					ResourceType:    old.Name,
+					Relation:        r.Name,
+					SubjectType:     t.Type,
+					SubjectRelation: t.Relation,
+					Wildcard:        t.Wildcard,
+				})
+			}
+			changes = append(changes, c)
+		}
+		if len(added) > 0 {
+			changes = append(changes, Change{
+				Kind:       WidenedSubjectTypes,
+				Definition: old.Name,
+				Name:       r.Name,
+				Detail:     "added " + joinTypes(added),
+			})
+		}
+	}
+	for _, r := range new.Relations {
+		if old.Relation(r.Name) == nil {
+			changes = append(changes, Change{Kind: AddedRelation, Definition: old.Name, Name: r.Name, Detail: joinTypes(r.Types)})
+		}
+	}
+
+	for _, p := range old.Permissions {
+		np := new.Permission(p.Name)
+		switch {
+		case np == nil:
+			changes = append(changes, Change{Kind: RemovedPermission, Definition: old.Name, Name: p.Name, Breaking: true})
+		case np.Expr.String() != p.Expr.String():
+			changes = append(changes, Change{
+				Kind:       PermissionExprChanged,
+				Definition: old.Name,
+				Name:       p.Name,
+				Detail:     p.Expr.String() + " => " + np.Expr.String(),
+			})
+		}
+	}
+	for _, p := range new.Permissions {
+		if old.Permission(p.Name) == nil {
+			changes = append(changes, Change{Kind: AddedPermission, Definition: old.Name, Name: p.Name, Detail: p.Expr.String()})
+		}
+	}
+	return changes
+}
+
+// typeDifference returns the types in a missing from b.
+func typeDifference(a, b []AllowedType) []AllowedType {
+	var diff []AllowedType
+	for _, t := range a {
+		if !slices.Contains(b, t) {
+			diff = append(diff, t)
+		}
+	}
+	return diff
+}
+
+func joinTypes(types []AllowedType) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, " | ")
+}
+
+// Breaking reports whether any of the changes is breaking.
+func Breaking(changes []Change) bool {
+	return slices.ContainsFunc(changes, func(c Change) bool { return c.Breaking })
+}
+
+// FormatChanges renders changes one per line, breaking ones marked.
+func FormatChanges(changes []Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		marker := " "
+		if c.Breaking {
+			marker = "!"
+		}
+		fmt.Fprintf(&b, "%s %s\n", marker, c)
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diffBase = `
+	definition rbac/principal {}
+	definition rbac/group {
+		relation t_member: rbac/principal | rbac/group#member | rbac/principal:*
+		relation t_owner: rbac/principal
+		permission member = t_member
+		permission admin = t_owner
+	}
+	definition rbac/legacy {
+		relation t_user: rbac/principal
+	}`
+
+func mustParse(t *testing.T, src string) *Schema {
+	t.Helper()
+	s, err := Parse(src)
+	require.NoError(t, err)
+	return s
+}
+
+func TestDiff_IdenticalSchemasHaveNoChanges(t *testing.T) {
+	t.Parallel()
+
+	src, err := os.ReadFile("../../deploy/schema.zed")
+	require.NoError(t, err)
+	assert.Empty(t, Diff(mustParse(t, string(src)), mustParse(t, string(src))))
+}
+
+func TestDiff_ClassifiesChanges(t *testing.T) {
+	t.Parallel()
+
+	changes := Diff(mustParse(t, diffBase), mustParse(t, `
+		definition rbac/principal {}
+		definition rbac/group {
+			relation t_member: rbac/principal | rbac/group#member
+			relation t_viewer: rbac/principal
+			permission member = t_member + t_viewer
+		}
+		definition rbac/team {
+			relation t_member: rbac/principal
+		}`))
+
+	assert.Equal(t, []Change{
+		{
+			Kind: NarrowedSubjectTypes, Definition: "rbac/group", Name: "t_member", Detail: "removed rbac/principal:*", Breaking: true,
+			Affected: []TupleFilter{{ResourceType: "rbac/group", Relation: "t_member", SubjectType: "rbac/principal", Wildcard: true}},
+		},
+		{
+			Kind: RemovedRelation, Definition: "rbac/group", Name: "t_owner", Breaking: true,
+			Affected: []TupleFilter{{ResourceType: "rbac/group", Relation: "t_owner"}},
+		},
+		{Kind: AddedRelation, Definition: "rbac/group", Name: "t_viewer", Detail: "rbac/principal"},
+		{Kind: PermissionExprChanged, Definition: "rbac/group", Name: "member", Detail: "t_member => t_member + t_viewer"},
+		{Kind: RemovedPermission, Definition: "rbac/group", Name: "admin", Breaking: true},
+		{
+			Kind: RemovedType, Definition: "rbac/legacy", Breaking: true,
+			Affected: []TupleFilter{{ResourceType: "rbac/legacy", Relation: "t_user"}},
+		},
+		{Kind: AddedType, Definition: "rbac/team"},
+	}, changes)
+	assert.True(t, Breaking(changes))
+}
+
+func TestDiff_WideningIsNotBreaking(t *testing.T) {
+	t.Parallel()
+
+	changes := Diff(mustParse(t, diffBase), mustParse(t, diffBase+`
+		definition rbac/workspace {
+			relation t_parent: rbac/workspace
+		}`))
+	require.Len(t, changes, 1)
+	assert.Equal(t, AddedType, changes[0].Kind)
+	assert.False(t, Breaking(changes))
+
+	changes = Diff(mustParse(t, `
+		definition rbac/principal {}
+		definition rbac/group {
+			relation t_member: rbac/principal
+		}`), mustParse(t, `
+		definition rbac/principal {}
+		definition rbac/group {
+			relation t_member: rbac/principal | rbac/group#t_member
+		}`))
+	assert.Equal(t, []Change{{Kind: WidenedSubjectTypes, Definition: "rbac/group", Name: "t_member", Detail: "added rbac/group#t_member"}}, changes)
+}
+
+func TestTupleFilter_MatchesWildcardsOnlyWhenAsked(t *testing.T) {
+	t.Parallel()
+
+	direct, err := ParseRelationship("rbac/group:a#t_member@rbac/principal:alice")
+	require.NoError(t, err)
+	wildcard, err := ParseRelationship("rbac/group:a#t_member@rbac/principal:*")
+	require.NoError(t, err)
+
+	filter := TupleFilter{ResourceType: "rbac/group", Relation: "t_member", SubjectType: "rbac/principal"}
+	assert.True(t, filter.Matches(direct))
+	assert.False(t, filter.Matches(wildcard))
+	filter.Wildcard = true
+	assert.False(t, filter.Matches(direct))
+	assert.True(t, filter.Matches(wildcard))
+	assert.True(t, TupleFilter{ResourceType: "rbac/group", Relation: "t_member"}.Matches(wildcard))
+}
+
+func TestFormatChanges_MarksBreakingChanges(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "! removed_type rbac/legacy\n  added_permission rbac/group#view: t_member\n", FormatChanges([]Change{
+		{Kind: RemovedType, Definition: "rbac/legacy", Breaking: true},
+		{Kind: AddedPermission, Definition: "rbac/group", Name: "view", Detail: "t_member"},
+	}))
+}
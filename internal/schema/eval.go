@@ -0,0 +1,314 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Object is an object of a type.
+type Object struct {
+	Type string
+	ID   string
+}
+
+func (o Object) String() string {
+	return o.Type + ":" + o.ID
+}
+
+// Subject is an object, a subject set if Relation is set, or every object of a type if ID is "*".
+type Subject struct {
+	Object
+	Relation string
+}
+
+func (s Subject) String() string {
+	if s.Relation != "" {
+		return s.Object.String() + "#" + s.Relation
+	}
+	return s.Object.String()
+}
+
+// Relationship relates a subject to a resource.
+type Relationship struct {
+	Resource Object
+	Relation string
+	Subject  Subject
+}
+
+func (r Relationship) String() string {
+	return r.Resource.String() + "#" + r.Relation + "@" + r.Subject.String()
+}
+
+// ParseObject parses type:id.
+func ParseObject(s string) (Object, error) {
+	typ, id, ok := strings.Cut(s, ":")
+	if !ok || typ == "" || id == "" {
+		return Object{}, fmt.Errorf("invalid object %q, expected type:id", s)
+	}
+	return Object{Type: typ, ID: id}, nil
+}
+
+// ParseSubject parses type:id with an optional #relation.
+func ParseSubject(s string) (Subject, error) {
+	object, relation, hasRelation := strings.Cut(s, "#")
+	o, err := ParseObject(object)
+	if err != nil {
+		return Subject{}, err
+	}
+	if hasRelation && relation == "" {
+		return Subject{}, fmt.Errorf("invalid subject %q, empty relation", s)
+	}
+	return Subject{Object: o, Relation: relation}, nil
+}
+
+// ParseRelationship parses type:id#relation@type:id[#relation].
+func ParseRelationship(s string) (Relationship, error) {
+	resource, subject, ok := strings.Cut(s, "@")
+	if !ok {
+		return Relationship{}, fmt.Errorf("invalid relationship %q, expected resource#relation@subject", s)
+	}
+	object, relation, ok := strings.Cut(resource, "#")
+	if !ok || relation == "" {
+		return Relationship{}, fmt.Errorf("invalid relationship %q, missing relation", s)
+	}
+	o, err := ParseObject(object)
+	if err != nil {
+		return Relationship{}, err
+	}
+	sub, err := ParseSubject(subject)
+	if err != nil {
+		return Relationship{}, err
+	}
+	return Relationship{Resource: o, Relation: relation, Subject: sub}, nil
+}
+
+type objectRelation struct {
+	object   Object
+	relation string
+}
+
+// Evaluator answers checks and lookups over a fixed set of relationships.
+type Evaluator struct {
+	schema   *Schema
+	subjects map[objectRelation][]Subject
+	// objects are the ids of every object mentioned, by type, for lookups
+	objects map[string]map[string]struct{}
+}
+
+// NewEvaluator creates an evaluator, rejecting relationships the schema does not allow.
+func NewEvaluator(s *Schema, relationships []Relationship) (*Evaluator, error) {
+	e := &Evaluator{schema: s, subjects: map[objectRelation][]Subject{}, objects: map[string]map[string]struct{}{}}
+	for _, r := range relationships {
+		if err := s.allows(r); err != nil {
+			return nil, fmt.Errorf("relationship %s: %w", r, err)
+		}
+		key := objectRelation{r.Resource, r.Relation}
+		e.subjects[key] = append(e.subjects[key], r.Subject)
+		e.addObject(r.Resource)
+		if r.Subject.ID != "*" {
+			e.addObject(r.Subject.Object)
+		}
+	}
+	return e, nil
+}
+
+func (e *Evaluator) addObject(o Object) {
+	if e.objects[o.Type] == nil {
+		e.objects[o.Type] = map[string]struct{}{}
+	}
+	e.objects[o.Type][o.ID] = struct{}{}
+}
+
+func (s *Schema) allows(r Relationship) error {
+	d := s.Definition(r.Resource.Type)
+	if d == nil {
+		return fmt.Errorf("unknown type %s", r.Resource.Type)
+	}
+	rel := d.Relation(r.Relation)
+	if rel == nil {
+		return fmt.Errorf("%s has no relation %s", d.Name, r.Relation)
+	}
+	for _, t := range rel.Types {
+		if t.Type != r.Subject.Type {
+			continue
+		}
+		if t.Wildcard && r.Subject.ID == "*" || !t.Wildcard && r.Subject.ID != "*" && t.Relation == r.Subject.Relation {
+			return nil
+		}
+	}
+	return fmt.Errorf("subject %s not allowed on %s#%s", r.Subject, d.Name, rel.Name)
+}
+
+// Check reports whether subject has permission, which may also be a relation, on resource.
+func (e *Evaluator) Check(resource Object, permission string, subject Subject) (bool, error) {
+	if err := e.checkTarget(resource.Type, permission); err != nil {
+		return false, err
+	}
+	return e.check(resource, permission, subject, map[string]bool{})
+}
+
+// LookupResources returns the ids of the objects of resourceType on which subject has permission, sorted.
+func (e *Evaluator) LookupResources(resourceType, permission string, subject Subject) ([]string, error) {
+	if err := e.checkTarget(resourceType, permission); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for id := range e.objects[resourceType] {
+		allowed, err := e.check(Object{resourceType, id}, permission, subject, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// LookupSubjects returns the ids of the objects of subjectType, or of subject sets subjectType#subjectRelation, that
+// have permission on resource, sorted. Like Kessel's lookups it does not return wildcards.
+func (e *Evaluator) LookupSubjects(resource Object, permission, subjectType, subjectRelation string) ([]string, error) {
+	if err := e.checkTarget(resource.Type, permission); err != nil {
+		return nil, err
+	}
+	if e.schema.Definition(subjectType) == nil {
+		return nil, fmt.Errorf("unknown type %s", subjectType)
+	}
+	var ids []string
+	for id := range e.objects[subjectType] {
+		subject := Subject{Object: Object{subjectType, id}, Relation: subjectRelation}
+		allowed, err := e.check(resource, permission, subject, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (e *Evaluator) checkTarget(resourceType, permission string) error {
+	d := e.schema.Definition(resourceType)
+	if d == nil {
+		return fmt.Errorf("unknown type %s", resourceType)
+	}
+	if d.Relation(permission) == nil && d.Permission(permission) == nil {
+		return fmt.Errorf("%s has no relation or permission %s", resourceType, permission)
+	}
+	return nil
+}
+
+// check evaluates name on resource for subject. visiting holds the evaluations in progress; like SpiceDB exceeding
+// its maximum depth, a cycle in the data is an error unless another path decides the result. Treating it as false
+// would turn an exclusion of it into a grant.
+func (e *Evaluator) check(resource Object, name string, subject Subject, visiting map[string]bool) (bool, error) {
+	key := resource.String() + "#" + name + "@" + subject.String()
+	if visiting[key] {
+		return false, fmt.Errorf("cycle evaluating %s", key)
+	}
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	d := e.schema.Definition(resource.Type)
+	if d == nil {
+		return false, nil
+	}
+	if p := d.Permission(name); p != nil {
+		return e.eval(resource, p.Expr, subject, visiting)
+	}
+	if d.Relation(name) == nil {
+		// an arrow target missing on this type is the empty set
+		return false, nil
+	}
+
+	if subject.Object == resource && subject.Relation == name {
+		return true, nil
+	}
+	var cycle error
+	for _, s := range e.subjects[objectRelation{resource, name}] {
+		switch {
+		case s == subject:
+			return true, nil
+		case s.ID == "*" && s.Type == subject.Type && subject.Relation == "":
+			return true, nil
+		case s.Relation != "":
+			allowed, err := e.check(s.Object, s.Relation, subject, visiting)
+			if allowed {
+				return true, nil
+			}
+			if cycle == nil {
+				cycle = err
+			}
+		}
+	}
+	return false, cycle
+}
+
+func (e *Evaluator) eval(resource Object, expr Expr, subject Subject, visiting map[string]bool) (bool, error) {
+	switch x := expr.(type) {
+	case *NilExpr:
+		return false, nil
+	case *RefExpr:
+		return e.check(resource, x.Name, subject, visiting)
+	case *ArrowExpr:
+		targets := e.subjects[objectRelation{resource, x.Relation}]
+		if x.All && len(targets) == 0 {
+			return false, nil
+		}
+		var cycle error
+		for _, t := range targets {
+			ok, err := e.check(t.Object, x.Target, subject, visiting)
+			switch {
+			case err != nil:
+				if cycle == nil {
+					cycle = err
+				}
+			case ok && !x.All:
+				return true, nil
+			case !ok && x.All:
+				return false, nil
+			}
+		}
+		if cycle != nil {
+			return false, cycle
+		}
+		return x.All, nil
+	case *BinaryExpr:
+		left, leftErr := e.eval(resource, x.Left, subject, visiting)
+		switch x.Op {
+		case Union:
+			if left {
+				return true, nil
+			}
+			right, rightErr := e.eval(resource, x.Right, subject, visiting)
+			if right {
+				return true, nil
+			}
+			return false, errors.Join(leftErr, rightErr)
+		case Intersection:
+			if !left && leftErr == nil {
+				return false, nil
+			}
+			right, rightErr := e.eval(resource, x.Right, subject, visiting)
+			if !right && rightErr == nil {
+				return false, nil
+			}
+			return leftErr == nil && rightErr == nil, errors.Join(leftErr, rightErr)
+		case Exclusion:
+			if leftErr != nil || !left {
+				return false, leftErr
+			}
+			right, rightErr := e.eval(resource, x.Right, subject, visiting)
+			if rightErr != nil {
+				return false, rightErr
+			}
+			return !right, nil
+		}
+	}
+	return false, nil
+}
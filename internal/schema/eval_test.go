@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+definition user {}
+
+definition group {
+	relation member: user | group#member
+	relation everyone: user:*
+	permission outsider = everyone - member
+}
+
+definition folder {
+	relation parent: folder
+	relation viewer: user | user:* | group#member
+	relation banned: user
+	relation owner: user
+	permission view = (viewer + parent->view) - banned
+	permission manage = owner & parent.all(manage_root)
+	permission manage_root = owner
+}
+`
+
+func newTestEvaluator(t *testing.T, relationships ...string) *Evaluator {
+	t.Helper()
+	s, err := Parse(testSchema)
+	require.NoError(t, err)
+	var rels []Relationship
+	for _, text := range relationships {
+		r, err := ParseRelationship(text)
+		require.NoError(t, err)
+		rels = append(rels, r)
+	}
+	e, err := NewEvaluator(s, rels)
+	require.NoError(t, err)
+	return e
+}
+
+func check(t *testing.T, e *Evaluator, text string) bool {
+	t.Helper()
+	c, err := ParseRelationship(text)
+	require.NoError(t, err)
+	allowed, err := e.Check(c.Resource, c.Relation, c.Subject)
+	require.NoError(t, err)
+	return allowed
+}
+
+func TestEvaluator_Check(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEvaluator(t,
+		"group:eng#member@user:alice",
+		"group:all#member@group:eng#member",
+		"folder:root#viewer@group:all#member",
+		"folder:root#owner@user:alice",
+		"folder:docs#parent@folder:root",
+		"folder:docs#banned@user:mallory",
+		"folder:docs#owner@user:alice",
+		"folder:docs#owner@user:bob",
+		"folder:public#viewer@user:*",
+	)
+
+	assert.True(t, check(t, e, "folder:docs#view@user:alice"), "inherited through nested groups and the parent")
+	assert.False(t, check(t, e, "folder:docs#view@user:bob"))
+	assert.True(t, check(t, e, "folder:public#view@user:bob"), "wildcard")
+	assert.False(t, check(t, e, "folder:public#view@group:eng#member"), "wildcards only match objects")
+	assert.True(t, check(t, e, "folder:root#view@group:eng#member"), "subject sets")
+	assert.True(t, check(t, e, "folder:docs#manage@user:alice"), "owner of every parent")
+	assert.False(t, check(t, e, "folder:docs#manage@user:bob"), "not owner of the parent")
+	assert.False(t, check(t, e, "folder:root#manage@user:alice"), "all() without subjects is false")
+}
+
+func TestEvaluator_CheckExcludes(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEvaluator(t,
+		"folder:docs#viewer@user:*",
+		"folder:docs#banned@user:mallory",
+	)
+
+	assert.True(t, check(t, e, "folder:docs#view@user:alice"))
+	assert.False(t, check(t, e, "folder:docs#view@user:mallory"))
+}
+
+func TestEvaluator_CheckReportsCycles(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEvaluator(t,
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+		"group:a#everyone@user:*",
+		"group:c#member@group:c#member",
+		"group:c#member@user:alice",
+		"folder:x#parent@folder:y",
+		"folder:y#parent@folder:x",
+	)
+	checkErr := func(text string) error {
+		c, err := ParseRelationship(text)
+		require.NoError(t, err)
+		_, err = e.Check(c.Resource, c.Relation, c.Subject)
+		return err
+	}
+
+	assert.ErrorContains(t, checkErr("group:a#member@user:alice"), "cycle evaluating")
+	assert.ErrorContains(t, checkErr("folder:x#view@user:alice"), "cycle evaluating")
+	assert.ErrorContains(t, checkErr("group:a#outsider@user:alice"), "cycle evaluating", "excluding a cycle grants nothing")
+	assert.True(t, check(t, e, "group:c#member@user:alice"), "another path decides the result")
+
+	_, err := e.LookupResources("group", "outsider", Subject{Object: Object{"user", "alice"}})
+	assert.ErrorContains(t, err, "cycle evaluating")
+}
+
+func TestEvaluator_Lookups(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEvaluator(t,
+		"group:eng#member@user:alice",
+		"group:eng#member@user:bob",
+		"folder:root#viewer@group:eng#member",
+		"folder:docs#parent@folder:root",
+		"folder:docs#banned@user:bob",
+		"folder:other#viewer@user:carol",
+	)
+
+	resources, err := e.LookupResources("folder", "view", Subject{Object: Object{"user", "bob"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root"}, resources)
+
+	subjects, err := e.LookupSubjects(Object{"folder", "docs"}, "view", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, subjects)
+
+	_, err = e.LookupSubjects(Object{"folder", "docs"}, "edit", "user", "")
+	assert.EqualError(t, err, "folder has no relation or permission edit")
+}
+
+func TestNewEvaluator_RejectsRelationshipsNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse(testSchema)
+	require.NoError(t, err)
+
+	for text, expected := range map[string]string{
+		"folder:a#view@user:alice":       "folder has no relation view",
+		"folder:a#owner@group:eng":       "subject group:eng not allowed on folder#owner",
+		"folder:a#owner@user:*":          "subject user:* not allowed on folder#owner",
+		"folder:a#viewer@group:eng":      "subject group:eng not allowed on folder#viewer",
+		"widget:a#owner@user:alice":      "unknown type widget",
+		"group:a#member@group:b#member":  "",
+		"folder:a#viewer@group:b#member": "",
+		"folder:a#viewer@user:*":         "",
+	} {
+		r, err := ParseRelationship(text)
+		require.NoError(t, err)
+		_, err = NewEvaluator(s, []Relationship{r})
+		if expected == "" {
+			assert.NoError(t, err, text)
+		} else {
+			assert.ErrorContains(t, err, expected, text)
+		}
+	}
+}
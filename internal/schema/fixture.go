@@ -0,0 +1,188 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// relationPrefix is prepended to the relations of tuples written through the Kessel API, see data.SpiceDbRepository.
+const relationPrefix = "t_"
+
+// Fixture is a schema test: relationships plus the checks and lookups expected to hold over them. Everything is
+// written in Kessel terms, i.e. namespace/type:id#relation@namespace/type:id, with tuple relations unprefixed.
+type Fixture struct {
+	// SchemaFile is relative to the fixture file.
+	SchemaFile string `yaml:"schemaFile"`
+	// Schema is used instead of SchemaFile if set.
+	Schema        string   `yaml:"schema"`
+	Relationships []string `yaml:"relationships"`
+	Assertions    struct {
+		AssertTrue  []string `yaml:"assertTrue"`
+		AssertFalse []string `yaml:"assertFalse"`
+	} `yaml:"assertions"`
+	Lookups struct {
+		Resources []ResourceLookup `yaml:"resources"`
+		Subjects  []SubjectLookup  `yaml:"subjects"`
+	} `yaml:"lookups"`
+
+	dir string
+}
+
+// ResourceLookup expects Subject to have Permission on exactly the Expected objects of ResourceType.
+type ResourceLookup struct {
+	ResourceType string   `yaml:"resourceType"`
+	Permission   string   `yaml:"permission"`
+	Subject      string   `yaml:"subject"`
+	Expected     []string `yaml:"expected"`
+}
+
+// SubjectLookup expects exactly the Expected objects of SubjectType to have Permission on Resource.
+type SubjectLookup struct {
+	Resource        string   `yaml:"resource"`
+	Permission      string   `yaml:"permission"`
+	SubjectType     string   `yaml:"subjectType"`
+	SubjectRelation string   `yaml:"subjectRelation"`
+	Expected        []string `yaml:"expected"`
+}
+
+// LoadFixture reads a fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fixture{dir: filepath.Dir(path)}
+	if err := yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// LoadSchema parses the fixture's schema, from schemaFile unless overridden.
+func (f *Fixture) LoadSchema(schemaFile string) (*Schema, error) {
+	src := f.Schema
+	if schemaFile == "" && src == "" {
+		if f.SchemaFile == "" {
+			return nil, fmt.Errorf("fixture has neither schema nor schemaFile")
+		}
+		schemaFile = filepath.Join(f.dir, f.SchemaFile)
+	}
+	if schemaFile != "" {
+		b, err := os.ReadFile(schemaFile)
+		if err != nil {
+			return nil, err
+		}
+		src = string(b)
+	}
+	s, err := Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return s, nil
+}
+
+// Run evaluates the fixture against s, returning a description of every expectation that does not hold. An error is
+// returned if the fixture itself is invalid.
+func (f *Fixture) Run(s *Schema) ([]string, error) {
+	relationships := make([]Relationship, 0, len(f.Relationships))
+	for _, text := range f.Relationships {
+		r, err := ParseRelationship(text)
+		if err != nil {
+			return nil, err
+		}
+		r.Relation = relationPrefix + r.Relation
+		if err := s.allows(r); err != nil {
+			return nil, fmt.Errorf("relationship %s is not allowed by the schema: %w", text, err)
+		}
+		relationships = append(relationships, r)
+	}
+	e, err := NewEvaluator(s, relationships)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []string
+	for _, assertion := range []struct {
+		name     string
+		expected bool
+		checks   []string
+	}{
+		{"assertTrue", true, f.Assertions.AssertTrue},
+		{"assertFalse", false, f.Assertions.AssertFalse},
+	} {
+		for _, text := range assertion.checks {
+			c, err := ParseRelationship(text)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", assertion.name, err)
+			}
+			allowed, err := e.Check(c.Resource, c.Relation, c.Subject)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", assertion.name, text, err)
+			}
+			if allowed != assertion.expected {
+				failures = append(failures, fmt.Sprintf("%s %s: %s", assertion.name, text, describeCheck(allowed)))
+			}
+		}
+	}
+
+	for _, l := range f.Lookups.Resources {
+		subject, err := ParseSubject(l.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("lookup resources: %w", err)
+		}
+		got, err := e.LookupResources(l.ResourceType, l.Permission, subject)
+		if err != nil {
+			return nil, fmt.Errorf("lookup resources: %w", err)
+		}
+		if diff := diffIDs(l.Expected, got); diff != "" {
+			failures = append(failures, fmt.Sprintf("lookup resources %s#%s@%s:\n%s", l.ResourceType, l.Permission, l.Subject, diff))
+		}
+	}
+	for _, l := range f.Lookups.Subjects {
+		resource, err := ParseObject(l.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("lookup subjects: %w", err)
+		}
+		got, err := e.LookupSubjects(resource, l.Permission, l.SubjectType, l.SubjectRelation)
+		if err != nil {
+			return nil, fmt.Errorf("lookup subjects: %w", err)
+		}
+		target := l.SubjectType
+		if l.SubjectRelation != "" {
+			target += "#" + l.SubjectRelation
+		}
+		if diff := diffIDs(l.Expected, got); diff != "" {
+			failures = append(failures, fmt.Sprintf("lookup subjects %s#%s@%s:\n%s", l.Resource, l.Permission, target, diff))
+		}
+	}
+	return failures, nil
+}
+
+func describeCheck(allowed bool) string {
+	if allowed {
+		return "allowed, expected denied"
+	}
+	return "denied, expected allowed"
+}
+
+// diffIDs lists the expected ids missing from got with "-" and the unexpected ones with "+", or returns "" if both
+// hold the same ids.
+func diffIDs(expected, got []string) string {
+	var b strings.Builder
+	for _, id := range expected {
+		if !slices.Contains(got, id) {
+			fmt.Fprintf(&b, "  - %s (expected, missing)\n", id)
+		}
+	}
+	for _, id := range got {
+		if !slices.Contains(expected, id) {
+			fmt.Fprintf(&b, "  + %s (unexpected)\n", id)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
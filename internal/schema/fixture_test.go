@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFixture(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func runFixture(t *testing.T, content string) ([]string, error) {
+	t.Helper()
+	f, err := LoadFixture(writeFixture(t, content))
+	require.NoError(t, err)
+	s, err := f.LoadSchema("")
+	require.NoError(t, err)
+	return f.Run(s)
+}
+
+const fixtureSchema = `
+schema: |
+  definition rbac/principal {}
+  definition rbac/group {
+    permission member = t_member
+    relation t_member: rbac/principal
+  }
+relationships:
+  - rbac/group:admins#member@rbac/principal:alice
+  - rbac/group:admins#member@rbac/principal:bob
+`
+
+func TestFixture_DeploySchemaPasses(t *testing.T) {
+	t.Parallel()
+
+	f, err := LoadFixture("../../deploy/schema-tests.yaml")
+	require.NoError(t, err)
+	s, err := f.LoadSchema("")
+	require.NoError(t, err)
+
+	failures, err := f.Run(s)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+}
+
+func TestFixture_ReportsFailures(t *testing.T) {
+	t.Parallel()
+
+	failures, err := runFixture(t, fixtureSchema+`
+assertions:
+  assertTrue:
+    - rbac/group:admins#member@rbac/principal:alice
+    - rbac/group:admins#member@rbac/principal:carol
+  assertFalse:
+    - rbac/group:admins#member@rbac/principal:bob
+lookups:
+  subjects:
+    - resource: rbac/group:admins
+      permission: member
+      subjectType: rbac/principal
+      expected: [alice, carol]
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"assertTrue rbac/group:admins#member@rbac/principal:carol: denied, expected allowed",
+		"assertFalse rbac/group:admins#member@rbac/principal:bob: allowed, expected denied",
+		"lookup subjects rbac/group:admins#member@rbac/principal:\n  - carol (expected, missing)\n  + bob (unexpected)",
+	}, failures)
+}
+
+func TestFixture_RejectsRelationshipsNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	_, err := runFixture(t, fixtureSchema+`
+  - rbac/group:admins#owner@rbac/principal:alice
+`)
+	assert.ErrorContains(t, err, "relationship rbac/group:admins#owner@rbac/principal:alice is not allowed by the schema")
+}
@@ -0,0 +1,496 @@
+// Package schema parses SpiceDB schemas and evaluates them in-process, so schemas can be tested and compared without
+// a SpiceDB instance. It supports the subset of the schema language used by Kessel: definitions, relations with
+// direct, subject set and wildcard types, and permissions built from unions, intersections, exclusions and arrows.
+// Caveats and expiring relations are rejected.
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Schema is a parsed schema.
+type Schema struct {
+	Definitions []*Definition
+	byName      map[string]*Definition
+}
+
+// Definition returns the definition of an object type, or nil.
+func (s *Schema) Definition(name string) *Definition {
+	return s.byName[name]
+}
+
+// Definition is an object type.
+type Definition struct {
+	Name        string
+	Relations   []*Relation
+	Permissions []*Permission
+}
+
+// Relation returns the relation with the given name, or nil.
+func (d *Definition) Relation(name string) *Relation {
+	for _, r := range d.Relations {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// Permission returns the permission with the given name, or nil.
+func (d *Definition) Permission(name string) *Permission {
+	for _, p := range d.Permissions {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Relation is a relation and the subject types allowed on it.
+type Relation struct {
+	Name  string
+	Types []AllowedType
+}
+
+// AllowedType is a subject type allowed on a relation: a type, a subject set type#relation or a wildcard type:*.
+type AllowedType struct {
+	Type     string
+	Relation string
+	Wildcard bool
+}
+
+func (t AllowedType) String() string {
+	switch {
+	case t.Wildcard:
+		return t.Type + ":*"
+	case t.Relation != "":
+		return t.Type + "#" + t.Relation
+	}
+	return t.Type
+}
+
+// Permission is a permission and the expression computing it.
+type Permission struct {
+	Name string
+	Expr Expr
+}
+
+// Expr is a permission expression. String renders it canonically, so equal expressions render equally.
+type Expr interface {
+	String() string
+}
+
+// RefExpr refers to a relation or permission of the same definition.
+type RefExpr struct {
+	Name string
+}
+
+func (e *RefExpr) String() string { return e.Name }
+
+// NilExpr is the empty set.
+type NilExpr struct{}
+
+func (e *NilExpr) String() string { return "nil" }
+
+// ArrowExpr follows Relation to its subjects and evaluates Target on them. With All every subject must have
+// Target, otherwise any one suffices.
+type ArrowExpr struct {
+	Relation string
+	Target   string
+	All      bool
+}
+
+func (e *ArrowExpr) String() string {
+	if e.All {
+		return e.Relation + ".all(" + e.Target + ")"
+	}
+	return e.Relation + "->" + e.Target
+}
+
+// Op is a set operator.
+type Op string
+
+const (
+	Union        Op = "+"
+	Intersection Op = "&"
+	Exclusion    Op = "-"
+)
+
+// BinaryExpr combines two expressions.
+type BinaryExpr struct {
+	Op          Op
+	Left, Right Expr
+}
+
+func (e *BinaryExpr) String() string {
+	return e.operand(e.Left, true) + " " + string(e.Op) + " " + e.operand(e.Right, false)
+}
+
+// operand parenthesizes nested binary expressions unless the operator is associative and the same.
+func (e *BinaryExpr) operand(x Expr, left bool) string {
+	b, ok := x.(*BinaryExpr)
+	if !ok || (b.Op == e.Op && (e.Op != Exclusion || left)) {
+		return x.String()
+	}
+	return "(" + x.String() + ")"
+}
+
+// Parse parses and validates a schema.
+func Parse(src string) (*Schema, error) {
+	p := &parser{lex: newLexer(src)}
+	p.next()
+	s := &Schema{byName: map[string]*Definition{}}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.tok.is("definition"):
+			d, err := p.definition()
+			if err != nil {
+				return nil, err
+			}
+			if s.byName[d.Name] != nil {
+				return nil, fmt.Errorf("duplicate definition %s", d.Name)
+			}
+			s.Definitions = append(s.Definitions, d)
+			s.byName[d.Name] = d
+		case p.tok.is("caveat"):
+			return nil, p.errorf("caveats are not supported")
+		default:
+			return nil, p.errorf("expected definition, found %s", p.tok)
+		}
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validate checks that everything a schema refers to exists.
+func (s *Schema) validate() error {
+	for _, d := range s.Definitions {
+		for _, r := range d.Relations {
+			for _, t := range r.Types {
+				target := s.Definition(t.Type)
+				if target == nil {
+					return fmt.Errorf("definition %s: relation %s: unknown type %s", d.Name, r.Name, t.Type)
+				}
+				if t.Relation != "" && target.Relation(t.Relation) == nil && target.Permission(t.Relation) == nil {
+					return fmt.Errorf("definition %s: relation %s: unknown relation %s", d.Name, r.Name, t)
+				}
+			}
+		}
+		for _, p := range d.Permissions {
+			if err := d.validateExpr(p.Expr); err != nil {
+				return fmt.Errorf("definition %s: permission %s: %w", d.Name, p.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Definition) validateExpr(e Expr) error {
+	switch e := e.(type) {
+	case *RefExpr:
+		if d.Relation(e.Name) == nil && d.Permission(e.Name) == nil {
+			return fmt.Errorf("unknown relation or permission %s", e.Name)
+		}
+	case *ArrowExpr:
+		// SpiceDB only follows relations; a target missing on a subject type evaluates to the empty set
+		if d.Relation(e.Relation) == nil {
+			return fmt.Errorf("arrow %s: %s is not a relation", e, e.Relation)
+		}
+	case *BinaryExpr:
+		if err := d.validateExpr(e.Left); err != nil {
+			return err
+		}
+		return d.validateExpr(e.Right)
+	}
+	return nil
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.next()
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	if p.tok.kind == tokError {
+		return fmt.Errorf("line %d: %s", p.tok.line, p.tok.text)
+	}
+	return fmt.Errorf("line %d: %s", p.tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) expect(text string) error {
+	if p.tok.text != text || p.tok.kind == tokEOF {
+		return p.errorf("expected %q, found %s", text, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	if p.tok.kind != tokIdent {
+		return "", p.errorf("expected a name, found %s", p.tok)
+	}
+	name := p.tok.text
+	p.next()
+	return name, nil
+}
+
+func (p *parser) definition() (*Definition, error) {
+	p.next()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	d := &Definition{Name: name}
+	for !p.tok.is("}") {
+		switch {
+		case p.tok.is("relation"):
+			r, err := p.relation()
+			if err != nil {
+				return nil, err
+			}
+			if d.Relation(r.Name) != nil || d.Permission(r.Name) != nil {
+				return nil, fmt.Errorf("definition %s: duplicate relation or permission %s", d.Name, r.Name)
+			}
+			d.Relations = append(d.Relations, r)
+		case p.tok.is("permission"):
+			perm, err := p.permission()
+			if err != nil {
+				return nil, err
+			}
+			if d.Relation(perm.Name) != nil || d.Permission(perm.Name) != nil {
+				return nil, fmt.Errorf("definition %s: duplicate relation or permission %s", d.Name, perm.Name)
+			}
+			d.Permissions = append(d.Permissions, perm)
+		default:
+			return nil, p.errorf("expected relation, permission or }, found %s", p.tok)
+		}
+	}
+	p.next()
+	return d, nil
+}
+
+func (p *parser) relation() (*Relation, error) {
+	p.next()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	r := &Relation{Name: name}
+	for {
+		t, err := p.allowedType()
+		if err != nil {
+			return nil, err
+		}
+		r.Types = append(r.Types, t)
+		if !p.tok.is("|") {
+			return r, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) allowedType() (AllowedType, error) {
+	name, err := p.ident()
+	if err != nil {
+		return AllowedType{}, err
+	}
+	t := AllowedType{Type: name}
+	switch {
+	case p.tok.is(":"):
+		p.next()
+		if err := p.expect("*"); err != nil {
+			return AllowedType{}, err
+		}
+		t.Wildcard = true
+	case p.tok.is("#"):
+		p.next()
+		if t.Relation, err = p.ident(); err != nil {
+			return AllowedType{}, err
+		}
+	}
+	if p.tok.is("with") {
+		return AllowedType{}, p.errorf("caveats and expiration are not supported")
+	}
+	return t, nil
+}
+
+func (p *parser) permission() (*Permission, error) {
+	p.next()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	e, err := p.expr(0)
+	if err != nil {
+		return nil, err
+	}
+	return &Permission{Name: name, Expr: e}, nil
+}
+
+// precedence follows SpiceDB: exclusion binds tighter than intersection, which binds tighter than union.
+var precedence = map[string]int{"+": 1, "&": 2, "-": 3}
+
+// expr parses an expression whose operators bind at least as tight as min.
+func (p *parser) expr(min int) (Expr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		prec, ok := precedence[p.tok.text]
+		if !ok || p.tok.kind != tokPunct || prec < min {
+			return left, nil
+		}
+		op := Op(p.tok.text)
+		p.next()
+		right, err := p.expr(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: op, Left: left, Right: right}
+	}
+}
+
+func (p *parser) term() (Expr, error) {
+	if p.tok.is("(") {
+		p.next()
+		e, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+	if p.tok.is("nil") {
+		p.next()
+		return &NilExpr{}, nil
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.tok.is("->"):
+		p.next()
+		target, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		return &ArrowExpr{Relation: name, Target: target}, nil
+	case p.tok.is("."):
+		p.next()
+		fn, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if fn != "any" && fn != "all" {
+			return nil, p.errorf("unknown arrow function %s", fn)
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		target, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		return &ArrowExpr{Relation: name, Target: target, All: fn == "all"}, p.expect(")")
+	}
+	return &RefExpr{Name: name}, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokPunct
+	tokError
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) is(text string) bool {
+	return t.kind != tokEOF && t.kind != tokError && t.text == text
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of schema"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '/' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return token{kind: tokError, text: "unterminated comment", line: l.line}
+			}
+			l.line += strings.Count(l.src[l.pos:l.pos+2+end], "\n")
+			l.pos += end + 4
+		case isIdentChar(c):
+			start := l.pos
+			for l.pos < len(l.src) && isIdentChar(l.src[l.pos]) {
+				l.pos++
+			}
+			return token{kind: tokIdent, text: l.src[start:l.pos], line: l.line}
+		case strings.HasPrefix(l.src[l.pos:], "->"):
+			l.pos += 2
+			return token{kind: tokPunct, text: "->", line: l.line}
+		case strings.ContainsRune("{}():|#=+&-*.", rune(c)):
+			l.pos++
+			return token{kind: tokPunct, text: string(c), line: l.line}
+		default:
+			return token{kind: tokError, text: fmt.Sprintf("unexpected character %q", c), line: l.line}
+		}
+	}
+	return token{kind: tokEOF, line: l.line}
+}
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_DeploySchemas(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"../../deploy/schema.zed", "../../deploy/hbi/schema.zed", "../data/spicedb-test-data/basic_schema.zed"} {
+		src, err := os.ReadFile(path)
+		require.NoError(t, err)
+		_, err = Parse(string(src))
+		assert.NoError(t, err, path)
+	}
+}
+
+func TestParse_ReadsDefinitions(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse(`
+		/* groups of principals */
+		definition rbac/group {
+			relation t_member: rbac/principal | rbac/group#member // nested groups
+			permission member = t_member
+		}
+		definition rbac/principal {}
+		definition rbac/role {
+			relation t_view: rbac/principal:*
+		}`)
+	require.NoError(t, err)
+
+	require.Len(t, s.Definitions, 3)
+	group := s.Definition("rbac/group")
+	require.NotNil(t, group)
+	assert.Equal(t, []AllowedType{{Type: "rbac/principal"}, {Type: "rbac/group", Relation: "member"}}, group.Relation("t_member").Types)
+	assert.Equal(t, &RefExpr{Name: "t_member"}, group.Permission("member").Expr)
+	assert.Equal(t, "rbac/principal:*", s.Definition("rbac/role").Relation("t_view").Types[0].String())
+}
+
+func TestParse_OperatorPrecedence(t *testing.T) {
+	t.Parallel()
+
+	for src, expected := range map[string]string{
+		"a + b & c":       "a + (b & c)",
+		"a & b - c":       "a & (b - c)",
+		"(a + b) & c":     "(a + b) & c",
+		"a - (b - c)":     "a - (b - c)",
+		"a - b - c":       "a - b - c",
+		"a + b + c":       "a + b + c",
+		"r->p + r.all(p)": "r->p + r.all(p)",
+		"nil":             "nil",
+	} {
+		s, err := Parse("definition t {\n relation r: t\n relation a: t\n relation b: t\n relation c: t\n permission p = " + src + "\n}")
+		require.NoError(t, err, src)
+		assert.Equal(t, expected, s.Definition("t").Permission("p").Expr.String(), src)
+	}
+}
+
+func TestParse_RejectsInvalidSchemas(t *testing.T) {
+	t.Parallel()
+
+	for src, expected := range map[string]string{
+		"definition a { relation r: b }":                        "unknown type b",
+		"definition a { relation r: a#missing }":                "unknown relation a#missing",
+		"definition a { permission p = missing }":               "unknown relation or permission missing",
+		"definition a { relation r: a\n permission p = p->r }":  "p is not a relation",
+		"definition a { relation r: a\n relation r: a }":        "duplicate relation or permission r",
+		"definition a {}\ndefinition a {}":                      "duplicate definition a",
+		"definition a { relation r: a with is_tuesday }":        "caveats and expiration are not supported",
+		"caveat is_tuesday(day string) { day == 'tuesday' }":    "caveats are not supported",
+		"definition a {\n relation r: a\n permission p = r +\n": "line 4: expected a name, found end of schema",
+		"definition a { relation r: a ! }":                      `unexpected character '!'`,
+	} {
+		_, err := Parse(src)
+		assert.ErrorContains(t, err, expected, src)
+	}
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+// NewAuditor creates the auditor shared by the servers and services, delivering entries to the configured sinks.
+func NewAuditor(c *conf.Server, logger log.Logger) (*audit.Auditor, func(), error) {
+	return audit.New(c.GetAudit(), logger)
+}
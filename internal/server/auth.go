@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+)
+
+// NewTokenVerifier creates the JWT verifier shared by the gRPC and HTTP servers, or nil if authentication is
+// disabled. The cleanup function stops the background JWKS refresh.
+func NewTokenVerifier(c *conf.Server, logger log.Logger) (*auth.Verifier, func(), error) {
+	if !c.GetAuth().GetEnableAuth() {
+		return nil, func() {}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	verifier, err := auth.NewVerifier(ctx, c.GetAuth(), logger)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return verifier, cancel, nil
+}
+
+// newAuthOptions configures JWT authentication for the verifier's current signing methods, letting callers authenticated by
+// a client certificate through when auth.allowClientCertAuth is set.
+func newAuthOptions(c *conf.Server, verifier *auth.Verifier) []auth.AuthOption {
+	opts := []auth.AuthOption{auth.WithSigningMethodsFunc(verifier.SigningMethods)}
+	if c.GetAuth().GetAllowClientCertAuth() {
+		opts = append(opts, auth.WithClientCertBypass())
+	}
+	return opts
+}
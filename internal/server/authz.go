@@ -0,0 +1,21 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/authz"
+)
+
+// NewAuthorizer creates the policy enforcer shared by the gRPC and HTTP servers, or nil if authorization is
+// disabled.
+func NewAuthorizer(c *conf.Server, auditor *audit.Auditor) (*authz.Authorizer, error) {
+	if !c.GetAuth().GetEnableAuthz() {
+		return nil, nil
+	}
+	if !c.GetAuth().GetEnableAuth() && c.GetTls().GetClientAuth() != clientAuthRequire {
+		return nil, fmt.Errorf("auth.enableAuthz requires auth.enableAuth or tls.clientAuth require, policies are evaluated against the authenticated caller")
+	}
+	return authz.NewAuthorizer(c.GetAuth(), auditor), nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel/metric"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/biz"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/kafka"
+)
+
+// NewTupleConsumer creates the consumer applying the tuple changes of a Kafka topic, or nil if it is disabled.
+func NewTupleConsumer(c *conf.Server, changes *biz.ApplyTupleChangesUsecase, locks *biz.AcquireLockUsecase, auditor *audit.Auditor, meter metric.Meter, logger log.Logger) (*kafka.Consumer, error) {
+	if !c.GetConsumer().GetEnabled() {
+		return nil, nil
+	}
+	acquire := func(ctx context.Context, lockId string) (*v1beta1.FencingCheck, error) {
+		resp, err := locks.AcquireLock(ctx, &v1beta1.AcquireLockRequest{LockId: lockId})
+		if err != nil {
+			return nil, err
+		}
+		return &v1beta1.FencingCheck{LockId: lockId, LockToken: resp.GetLockToken()}, nil
+	}
+	return kafka.NewConsumer(c.GetConsumer(), auditedApply(changes.Apply, c.GetConsumer().GetTopic(), auditor), acquire, meter, logger)
+}
+
+// auditedApply records the tuples each application of changes touches and deletes, as the API does for its writes.
+func auditedApply(apply kafka.ApplyFunc, topic string, auditor *audit.Auditor) kafka.ApplyFunc {
+	return func(ctx context.Context, changes []*v1beta1.TupleChange, batchSize int, fencing *v1beta1.FencingCheck) error {
+		err := apply(ctx, changes, batchSize, fencing)
+
+		var touched, deleted []*v1beta1.Relationship
+		for _, change := range changes {
+			switch change.GetOperation() {
+			case v1beta1.TupleChange_OPERATION_TOUCH:
+				touched = append(touched, change.GetTuple())
+			case v1beta1.TupleChange_OPERATION_DELETE:
+				deleted = append(deleted, change.GetTuple())
+			}
+		}
+		// Tuple changes from Kafka - SEC-MON-REQ-1 compliance (EOI-1 pii_manipulation, EOI-4 access_manipulation, EOI-11 warnings_or_errors)
+		recordApplied(ctx, auditor, "CREATE", "Tuples touched from Kafka", "Tuple touch from Kafka failed", touched, topic, err)
+		recordApplied(ctx, auditor, "DELETE", "Tuples deleted from Kafka", "Tuple deletion from Kafka failed", deleted, topic, err)
+		return err
+	}
+}
+
+func recordApplied(ctx context.Context, auditor *audit.Auditor, action, message, failure string, tuples []*v1beta1.Relationship, topic string, err error) {
+	if len(tuples) == 0 {
+		return
+	}
+	e := audit.Event{
+		Message:      message,
+		Action:       action,
+		ResourceType: "relationship_tuple",
+		ResourceID:   fmt.Sprintf("count:%d", len(tuples)),
+		Outcome:      audit.OutcomeSuccess,
+		Details:      map[string]string{"source": "kafka", "topic": topic},
+	}
+	if err != nil {
+		e.Message, e.Outcome, e.Reason = failure, audit.OutcomeFailure, err.Error()
+	}
+	if max := auditor.MaxTupleDetails(); max > 0 {
+		e.Tuples, e.TuplesTruncated = audit.Tuples(tuples, max)
+	}
+	auditor.Record(ctx, e)
+}
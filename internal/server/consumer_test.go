@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/server/kafka"
+)
+
+func tupleChange(operation v1beta1.TupleChange_Operation, id string) *v1beta1.TupleChange {
+	return &v1beta1.TupleChange{
+		Operation: operation,
+		Tuple: &v1beta1.Relationship{
+			Resource: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "group"}, Id: id},
+			Relation: "member",
+			Subject: &v1beta1.SubjectReference{
+				Subject: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "principal"}, Id: "alice"},
+			},
+		},
+	}
+}
+
+func entryValue(entry []any, key string) any {
+	for i := 0; i+1 < len(entry); i += 2 {
+		if entry[i] == key {
+			return entry[i+1]
+		}
+	}
+	return nil
+}
+
+func TestAuditedApply_RecordsTouchedAndDeletedTuples(t *testing.T) {
+	t.Parallel()
+
+	capture := &captureLogger{}
+	apply := auditedApply(func(context.Context, []*v1beta1.TupleChange, int, *v1beta1.FencingCheck) error {
+		return nil
+	}, "tuples", audit.NewLogAuditor(capture))
+
+	err := apply(context.Background(), []*v1beta1.TupleChange{
+		tupleChange(v1beta1.TupleChange_OPERATION_TOUCH, "a"),
+		tupleChange(v1beta1.TupleChange_OPERATION_TOUCH, "b"),
+		tupleChange(v1beta1.TupleChange_OPERATION_DELETE, "c"),
+	}, 10, nil)
+
+	assert.NoError(t, err)
+	if assert.Len(t, capture.entries, 2) {
+		assert.Equal(t, "CREATE", entryValue(capture.entries[0], "action"))
+		assert.Equal(t, "count:2", entryValue(capture.entries[0], "resource_id"))
+		assert.Equal(t, "success", entryValue(capture.entries[0], "outcome"))
+		assert.Equal(t, "kafka", entryValue(capture.entries[0], "source"))
+		assert.Equal(t, "tuples", entryValue(capture.entries[0], "topic"))
+		assert.Equal(t, "DELETE", entryValue(capture.entries[1], "action"))
+		assert.Equal(t, "count:1", entryValue(capture.entries[1], "resource_id"))
+	}
+}
+
+func TestAuditedApply_RecordsFailedWrites(t *testing.T) {
+	t.Parallel()
+
+	capture := &captureLogger{}
+	var apply kafka.ApplyFunc = func(context.Context, []*v1beta1.TupleChange, int, *v1beta1.FencingCheck) error {
+		return errors.New("unavailable")
+	}
+
+	err := auditedApply(apply, "tuples", audit.NewLogAuditor(capture))(context.Background(),
+		[]*v1beta1.TupleChange{tupleChange(v1beta1.TupleChange_OPERATION_DELETE, "a")}, 10, nil)
+
+	assert.EqualError(t, err, "unavailable")
+	if assert.Len(t, capture.entries, 1) {
+		assert.Equal(t, "DELETE", entryValue(capture.entries[0], "action"))
+		assert.Equal(t, "failure", entryValue(capture.entries[0], "outcome"))
+		assert.Equal(t, "unavailable", entryValue(capture.entries[0], "reason"))
+	}
+}
@@ -3,30 +3,36 @@ package server
 import (
 	"buf.build/go/protovalidate"
 	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
-	"github.com/go-kratos/kratos/v2/middleware/logging"
 	"github.com/go-kratos/kratos/v2/middleware/recovery"
 	"github.com/go-kratos/kratos/v2/middleware/selector"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
-	jwtv5 "github.com/golang-jwt/jwt/v5"
 	h "github.com/project-kessel/relations-api/api/kessel/relations/v1"
 	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/biz"
 	"github.com/project-kessel/relations-api/internal/conf"
 	"github.com/project-kessel/relations-api/internal/server/middleware"
 	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+	"github.com/project-kessel/relations-api/internal/server/middleware/authz"
+	"github.com/project-kessel/relations-api/internal/server/middleware/ratelimit"
 	"github.com/project-kessel/relations-api/internal/service"
+	"github.com/project-kessel/relations-api/internal/slowlog"
 
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	kratosmiddleware "github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/middleware/metrics"
 	kesselMetrics "github.com/project-kessel/relations-api/internal/server/middleware/metrics"
 	kesselRecovery "github.com/project-kessel/relations-api/internal/server/middleware/recovery"
+	kesselTracing "github.com/project-kessel/relations-api/internal/server/middleware/tracing"
 	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // NewGRPCServer new a gRPC server.
-func NewGRPCServer(c *conf.Server, relations *service.RelationshipsService, health *service.HealthService, check *service.CheckService, subjects *service.LookupService, meter metric.Meter, logger log.Logger) (*grpc.Server, error) {
+func NewGRPCServer(c *conf.Server, relations *service.RelationshipsService, health *service.HealthService, check *service.CheckService, subjects *service.LookupService, schemas *service.SchemaService, migrations *service.MigrationService, meter metric.Meter, tracer trace.TracerProvider, verifier *auth.Verifier, authorizer *authz.Authorizer, limiter *ratelimit.Limiter, slowLogger *slowlog.Logger, redactor *middleware.Redactor, prober *biz.HealthProber, auditor *audit.Auditor, logger log.Logger) (*grpc.Server, error) {
 	requests, err := metrics.DefaultRequestsCounter(meter, metrics.DefaultServerRequestsCounterName)
 	if err != nil {
 		return nil, err
@@ -42,15 +48,17 @@ func NewGRPCServer(c *conf.Server, relations *service.RelationshipsService, heal
 
 	unaryMiddleware := []kratosmiddleware.Middleware{
 		recovery.Recovery(),
+		tracing.Server(tracing.WithTracerProvider(tracer)),
 		middleware.ValidationMiddleware(validator),
-		logging.Server(logger),
+		middleware.LoggingMiddleware(logger, redactor),
 		metrics.Server(
 			metrics.WithSeconds(seconds),
 			metrics.WithRequests(requests),
 		),
 	}
 	streamingMiddleware := []googlegrpc.StreamServerInterceptor{
-		middleware.StreamLogInterceptor(logger),
+		kesselTracing.StreamTracingInterceptor(tracing.WithTracerProvider(tracer)),
+		middleware.StreamLogInterceptor(logger, redactor),
 		middleware.StreamValidationInterceptor(validator),
 		kesselRecovery.StreamRecoveryInterceptor(logger),
 		kesselMetrics.StreamMetricsInterceptor(
@@ -59,23 +67,53 @@ func NewGRPCServer(c *conf.Server, relations *service.RelationshipsService, heal
 		),
 	}
 
-	if c.Auth.EnableAuth {
-		jwks, err := FetchJwks(c.Auth.JwksUrl)
-		if err != nil {
-			return nil, err
-		}
+	if slowLogger != nil {
+		unaryMiddleware = append(unaryMiddleware, middleware.SlowRequestMiddleware(slowLogger, redactor))
+		streamingMiddleware = append(streamingMiddleware, middleware.StreamSlowRequestInterceptor(slowLogger, redactor))
+	}
+
+	tlsConfig, err := newServerTLSConfig(c.GetTls())
+	if err != nil {
+		return nil, err
+	}
+	if clientCertAuthEnabled(c.GetTls()) {
+		unaryMiddleware = append(unaryMiddleware, auth.ClientCertMiddleware(c.GetTls().GetClientPrincipal()))
+		streamingMiddleware = append(streamingMiddleware, auth.StreamClientCertInterceptor(c.GetTls().GetClientPrincipal()))
+	}
 
+	if verifier != nil {
+		authOpts := newAuthOptions(c, verifier)
 		unaryMiddleware = append(unaryMiddleware,
-			auth.AuthFailureLoggingMiddleware(logger),
-			selector.Server(jwt.Server(jwks.Keyfunc,
-				jwt.WithSigningMethod(jwtv5.SigningMethodRS256))).
+			auth.AuthFailureLoggingMiddleware(auditor),
+			selector.Server(auth.Server(verifier.Keyfunc, authOpts...)).
 				Match(NewWhiteListMatcher).
 				Build(),
 		)
 		streamingMiddleware = append(streamingMiddleware, auth.StreamAuthInterceptor(
-			logger,
-			jwks.Keyfunc,
-			auth.WithSigningMethod(jwtv5.SigningMethodRS256)))
+			auditor,
+			verifier.Keyfunc,
+			authOpts...))
+	}
+
+	if authorizer != nil {
+		unaryMiddleware = append(unaryMiddleware,
+			selector.Server(authz.Server(authorizer)).
+				Match(NewWhiteListMatcher).
+				Build(),
+		)
+		streamingMiddleware = append(streamingMiddleware, authz.StreamAuthzInterceptor(authorizer))
+	}
+	// debug traces are only returned to callers granted the debug operation, and to nobody without authorization
+	unaryMiddleware = append(unaryMiddleware, authz.DebugServer(authorizer, auditor))
+
+	// rate limits are applied after authentication so buckets are keyed by the verified principal
+	if limiter != nil {
+		unaryMiddleware = append(unaryMiddleware,
+			selector.Server(ratelimit.Server(limiter)).
+				Match(NewWhiteListMatcher).
+				Build(),
+		)
+		streamingMiddleware = append(streamingMiddleware, ratelimit.StreamRateLimitInterceptor(limiter))
 	}
 
 	var opts = []grpc.ServerOption{
@@ -85,6 +123,11 @@ func NewGRPCServer(c *conf.Server, relations *service.RelationshipsService, heal
 		grpc.Options(googlegrpc.ChainStreamInterceptor(
 			streamingMiddleware...,
 		)),
+		// grpc.health.v1 reflects the readiness probes rather than always reporting serving
+		grpc.CustomHealth(),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.TLSConfig(tlsConfig))
 	}
 	if c.Grpc.Network != "" {
 		opts = append(opts, grpc.Network(c.Grpc.Network))
@@ -101,5 +144,13 @@ func NewGRPCServer(c *conf.Server, relations *service.RelationshipsService, heal
 	v1beta1.RegisterKesselCheckServiceServer(srv, check)
 	h.RegisterKesselRelationsHealthServiceServer(srv, health)
 	v1beta1.RegisterKesselLookupServiceServer(srv, subjects)
+	v1beta1.RegisterKesselSchemaServiceServer(srv, schemas)
+	v1beta1.RegisterKesselMigrationServiceServer(srv, migrations)
+
+	var services []string
+	for service := range srv.GetServiceInfo() {
+		services = append(services, service)
+	}
+	grpc_health_v1.RegisterHealthServer(srv, newHealthServer(prober, services))
 	return srv, nil
 }
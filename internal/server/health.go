@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/biz"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+)
+
+const (
+	defaultProbeInterval    = 5 * time.Second
+	defaultProbeTimeout     = 5 * time.Second
+	defaultFailureThreshold = 3
+)
+
+// NewHealthProber probes SpiceDB, the schema and, when auth is enabled, the JWKS of the token issuers in the
+// background. Probing the schema writes it to SpiceDB if it was not written yet. The cleanup function stops probing.
+func NewHealthProber(c *conf.Server, backend *biz.IsBackendAvaliableUsecase, verifier *auth.Verifier, auditor *audit.Auditor, logger log.Logger) (*biz.HealthProber, func()) {
+	interval, timeout := defaultProbeInterval, defaultProbeTimeout
+	if d := c.GetHealth().GetProbeInterval().AsDuration(); d > 0 {
+		interval = d
+	}
+	if d := c.GetHealth().GetProbeTimeout().AsDuration(); d > 0 {
+		timeout = d
+	}
+	failureThreshold := c.GetHealth().GetFailureThreshold()
+	if failureThreshold == 0 {
+		failureThreshold = defaultFailureThreshold
+	}
+
+	prober := biz.NewHealthProber(interval, timeout, failureThreshold, c.GetHealth().GetSuccessThreshold(), auditor, logger)
+	prober.Register("spicedb", backend.IsBackendAvailable)
+	prober.Register("schema", backend.InitializeSchema)
+	if verifier != nil {
+		prober.Register("jwks", verifier.Ready)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go prober.Run(ctx)
+	return prober, cancel
+}
+
+// newHealthServer creates the grpc.health.v1 service, reporting every listed service and the server as a whole
+// ("") as serving while the prober reports ready.
+func newHealthServer(prober *biz.HealthProber, services []string) *health.Server {
+	hs := health.NewServer()
+	prober.Subscribe(func(ready bool) {
+		status := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		if ready {
+			status = grpc_health_v1.HealthCheckResponse_SERVING
+		}
+		hs.SetServingStatus("", status)
+		for _, service := range services {
+			hs.SetServingStatus(service, status)
+		}
+	})
+	return hs
+}
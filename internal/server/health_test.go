@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/project-kessel/relations-api/internal/biz"
+)
+
+func TestHealthServer_FollowsReadiness(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var checkErr error
+	prober := biz.NewHealthProber(time.Second, time.Second, 1, 1, nil, log.DefaultLogger)
+	prober.Register("spicedb", func(context.Context) error { return checkErr })
+	hs := newHealthServer(prober, []string{"kessel.relations.v1beta1.KesselCheckService"})
+
+	status := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := hs.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(""))
+
+	prober.Probe(ctx)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status(""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status("kessel.relations.v1beta1.KesselCheckService"))
+
+	checkErr = errors.New("connection refused")
+	prober.Probe(ctx)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status(""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status("kessel.relations.v1beta1.KesselCheckService"))
+}
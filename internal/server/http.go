@@ -4,28 +4,30 @@ import (
 	"context"
 
 	"buf.build/go/protovalidate"
-	"github.com/MicahParks/keyfunc/v3"
 	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
-	"github.com/go-kratos/kratos/v2/middleware/logging"
 	"github.com/go-kratos/kratos/v2/middleware/metrics"
 	"github.com/go-kratos/kratos/v2/middleware/recovery"
 	"github.com/go-kratos/kratos/v2/middleware/selector"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/transport/http"
-	jwt2 "github.com/golang-jwt/jwt/v5"
 	h "github.com/project-kessel/relations-api/api/kessel/relations/v1"
 	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
 	"github.com/project-kessel/relations-api/internal/conf"
 	"github.com/project-kessel/relations-api/internal/server/middleware"
 	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+	"github.com/project-kessel/relations-api/internal/server/middleware/authz"
+	"github.com/project-kessel/relations-api/internal/server/middleware/ratelimit"
 	"github.com/project-kessel/relations-api/internal/service"
+	"github.com/project-kessel/relations-api/internal/slowlog"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewHTTPServer new an HTTP server.
-func NewHTTPServer(c *conf.Server, relationships *service.RelationshipsService, health *service.HealthService, check *service.CheckService, subjects *service.LookupService, meter metric.Meter, logger log.Logger) (*http.Server, error) {
+func NewHTTPServer(c *conf.Server, relationships *service.RelationshipsService, health *service.HealthService, check *service.CheckService, subjects *service.LookupService, schemas *service.SchemaService, migrations *service.MigrationService, meter metric.Meter, tracer trace.TracerProvider, verifier *auth.Verifier, authorizer *authz.Authorizer, limiter *ratelimit.Limiter, slowLogger *slowlog.Logger, redactor *middleware.Redactor, auditor *audit.Auditor, logger log.Logger) (*http.Server, error) {
 	requests, err := metrics.DefaultRequestsCounter(meter, metrics.DefaultServerRequestsCounterName)
 	if err != nil {
 		return nil, err
@@ -41,30 +43,56 @@ func NewHTTPServer(c *conf.Server, relationships *service.RelationshipsService,
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
+			tracing.Server(tracing.WithTracerProvider(tracer)),
 			middleware.ValidationMiddleware(validator),
-			logging.Server(logger),
+			middleware.LoggingMiddleware(logger, redactor),
 			metrics.Server(
 				metrics.WithSeconds(seconds),
 				metrics.WithRequests(requests),
 			),
 		),
 	}
-	if c.Auth.EnableAuth {
-		jwks, err := FetchJwks(c.Auth.JwksUrl)
-		if err != nil {
-			return nil, err
-		}
+	if slowLogger != nil {
+		opts = append(opts, http.Middleware(middleware.SlowRequestMiddleware(slowLogger, redactor)))
+	}
+	tlsConfig, err := newServerTLSConfig(c.GetTls())
+	if err != nil {
+		return nil, err
+	}
+	if clientCertAuthEnabled(c.GetTls()) {
+		opts = append(opts, http.Middleware(auth.ClientCertMiddleware(c.GetTls().GetClientPrincipal())))
+	}
+	if verifier != nil {
 		opts = append(opts, http.Middleware(
-			auth.AuthFailureLoggingMiddleware(logger),
+			auth.AuthFailureLoggingMiddleware(auditor),
 			selector.Server(
-				jwt.Server(
-					jwks.Keyfunc,
-					jwt.WithSigningMethod(jwt2.SigningMethodRS256),
+				auth.Server(
+					verifier.Keyfunc,
+					newAuthOptions(c, verifier)...,
 				)).
 				Match(NewWhiteListMatcher).
 				Build(),
 		))
 	}
+	if authorizer != nil {
+		opts = append(opts, http.Middleware(
+			selector.Server(authz.Server(authorizer)).
+				Match(NewWhiteListMatcher).
+				Build(),
+		))
+	}
+	// debug traces are only returned to callers granted the debug operation, and to nobody without authorization
+	opts = append(opts, http.Middleware(authz.DebugServer(authorizer, auditor)))
+	if limiter != nil {
+		opts = append(opts, http.Middleware(
+			selector.Server(ratelimit.Server(limiter)).
+				Match(NewWhiteListMatcher).
+				Build(),
+		))
+	}
+	if tlsConfig != nil {
+		opts = append(opts, http.TLSConfig(tlsConfig))
+	}
 	if c.Http.Network != "" {
 		opts = append(opts, http.Network(c.Http.Network))
 	}
@@ -79,15 +107,19 @@ func NewHTTPServer(c *conf.Server, relationships *service.RelationshipsService,
 	}
 
 	srv := http.NewServer(opts...)
-	srv.HandlePrefix("/metrics", promhttp.HandlerFor(
-		prometheus.DefaultGatherer,
-		promhttp.HandlerOpts{
-			EnableOpenMetrics: true,
-		},
-	))
+	if servePrometheus, _, _ := metricExporters(c.GetMetrics()); servePrometheus {
+		srv.HandlePrefix("/metrics", promhttp.HandlerFor(
+			prometheus.DefaultGatherer,
+			promhttp.HandlerOpts{
+				EnableOpenMetrics: true,
+			},
+		))
+	}
 
 	v1beta1.RegisterKesselTupleServiceHTTPServer(srv, relationships)
 	v1beta1.RegisterKesselCheckServiceHTTPServer(srv, check)
+	v1beta1.RegisterKesselSchemaServiceHTTPServer(srv, schemas)
+	v1beta1.RegisterKesselMigrationServiceHTTPServer(srv, migrations)
 	h.RegisterKesselRelationsHealthServiceHTTPServer(srv, health)
 	return srv, nil
 }
@@ -102,13 +134,3 @@ func NewWhiteListMatcher(ctx context.Context, operation string) bool {
 	}
 	return true
 }
-
-// Use the JWKS URL to create a JWKSet.
-func FetchJwks(jwksURL string) (keyfunc.Keyfunc, error) {
-	jwks, err := keyfunc.NewDefault([]string{jwksURL})
-	if err != nil {
-		log.Fatalf("Failed to create JWK Set from resource at the given URL: %s.\nError: %s", jwksURL, err)
-		return nil, err
-	}
-	return jwks, nil
-}
@@ -0,0 +1,500 @@
+// Package kafka applies tuple changes read from a Kafka topic, through a Kafka REST proxy.
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"buf.build/go/protovalidate"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/biz"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const (
+	// LagGaugeName is the gauge of the messages of each partition not yet applied.
+	LagGaugeName = "kessel_relations_consumer_lag"
+	// LockHeldGaugeName is 1 while the consumer holds its lock and 0 while it waits to acquire it, e.g. after it was
+	// acquired elsewhere. It is only reported by consumers with a lock.
+	LockHeldGaugeName = "kessel_relations_consumer_lock_held"
+	// ChangesCounterName counts the tuple changes read, by outcome: applied, or rejected if the message could not be
+	// decoded or SpiceDB refused the change.
+	ChangesCounterName = "kessel_relations_consumer_changes"
+
+	defaultGroup        = "kessel-relations"
+	defaultPollTimeout  = time.Second
+	defaultRetryBackoff = time.Second
+	maxRetryBackoff     = time.Minute
+	lagRefreshInterval  = 10 * time.Second
+)
+
+var errLockLost = errors.New("consumer lock was acquired elsewhere")
+
+// ApplyFunc writes tuple changes in order, fenced by the lock of the consumer if it has one.
+type ApplyFunc func(ctx context.Context, changes []*v1beta1.TupleChange, batchSize int, fencing *v1beta1.FencingCheck) error
+
+// AcquireLockFunc acquires the lock fencing the writes of the consumer.
+type AcquireLockFunc func(ctx context.Context, lockId string) (*v1beta1.FencingCheck, error)
+
+// Consumer is a transport.Server applying the tuple changes of a topic. Offsets are committed only once the changes
+// before them are written to SpiceDB, so changes are applied at least once; as creates touch and deletes ignore
+// missing tuples, applying a change again has no effect.
+type Consumer struct {
+	proxy        *restProxy
+	topic        string
+	group        string
+	protobuf     bool
+	lockId       string
+	batchSize    int
+	pollTimeout  time.Duration
+	retryBackoff time.Duration
+	apply        ApplyFunc
+	acquire      AcquireLockFunc
+	validator    protovalidate.Validator
+	changes      metric.Int64Counter
+	log          *log.Helper
+
+	lockHeld atomic.Bool
+
+	mu      sync.Mutex
+	lag     map[int32]int64
+	next    map[int32]int64 // offset after the last committed record, by partition
+	lagRead time.Time
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started atomic.Bool
+	done    chan struct{}
+}
+
+func NewConsumer(c *conf.Server_Consumer, apply ApplyFunc, acquire AcquireLockFunc, meter metric.Meter, logger log.Logger) (*Consumer, error) {
+	if c.GetRestProxyUrl() == "" || c.GetTopic() == "" {
+		return nil, fmt.Errorf("consumer.restProxyUrl and consumer.topic must be set when the consumer is enabled")
+	}
+	format := "json"
+	switch c.GetFormat() {
+	case "", "json":
+	case "protobuf":
+		format = "binary"
+	default:
+		return nil, fmt.Errorf("unknown consumer.format %q, expected json or protobuf", c.GetFormat())
+	}
+	validator, err := protovalidate.New()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := meter.Int64Counter(
+		ChangesCounterName,
+		metric.WithUnit("{change}"),
+		metric.WithDescription("The total number of tuple changes read from Kafka, by outcome"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cons := &Consumer{
+		proxy:        newRestProxy(c.GetRestProxyUrl(), format),
+		topic:        c.GetTopic(),
+		group:        c.GetGroup(),
+		protobuf:     format == "binary",
+		lockId:       c.GetLockId(),
+		batchSize:    int(c.GetBatchSize()),
+		pollTimeout:  durationOr(c.GetPollTimeout().AsDuration(), defaultPollTimeout),
+		retryBackoff: durationOr(c.GetRetryBackoff().AsDuration(), defaultRetryBackoff),
+		apply:        apply,
+		acquire:      acquire,
+		validator:    validator,
+		changes:      changes,
+		log:          log.NewHelper(log.With(logger, "topic", c.GetTopic())),
+		lag:          map[int32]int64{},
+		next:         map[int32]int64{},
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+	if cons.group == "" {
+		cons.group = defaultGroup
+	}
+
+	_, err = meter.Int64ObservableGauge(
+		LagGaugeName,
+		metric.WithUnit("{message}"),
+		metric.WithDescription("The number of messages of each partition of the consumed topic not yet applied"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			cons.mu.Lock()
+			defer cons.mu.Unlock()
+			for partition, lag := range cons.lag {
+				o.Observe(lag, metric.WithAttributes(attribute.Int("partition", int(partition))))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if cons.lockId != "" {
+		_, err = meter.Int64ObservableGauge(
+			LockHeldGaugeName,
+			metric.WithDescription("Whether the consumer holds its lock, 1 if it does and 0 while it waits to acquire it"),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				var held int64
+				if cons.lockHeld.Load() {
+					held = 1
+				}
+				o.Observe(held, metric.WithAttributes(attribute.String("lock", cons.lockId)))
+				return nil
+			}),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cons, nil
+}
+
+// Start consumes until Stop is called. A consumer whose lock is acquired elsewhere pauses rather than failing the
+// service, whose API is unaffected, and acquires the lock again after a backoff that doubles while it keeps losing it.
+func (c *Consumer) Start(ctx context.Context) error {
+	c.started.Store(true)
+	defer close(c.done)
+	stop := context.AfterFunc(ctx, c.cancel)
+	defer stop()
+
+	c.log.Infof("consuming tuple changes in group %s", c.group)
+	backoff := c.retryBackoff
+	for {
+		started := time.Now()
+		err := c.run(c.ctx)
+		c.lockHeld.Store(false)
+		if !errors.Is(err, errLockLost) || c.ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(started) > maxRetryBackoff {
+			// the lock was held for a while, it is not being contended
+			backoff = c.retryBackoff
+		}
+		c.log.Errorf("lock %s was acquired elsewhere, acquiring it again in %s", c.lockId, backoff)
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+func (c *Consumer) Stop(ctx context.Context) error {
+	c.cancel()
+	if !c.started.Load() {
+		return nil
+	}
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Consumer) run(ctx context.Context) error {
+	var fencing *v1beta1.FencingCheck
+	if c.lockId != "" {
+		err := c.retry(ctx, "acquiring consumer lock", func() (err error) {
+			fencing, err = c.acquire(ctx, c.lockId)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		c.lockHeld.Store(true)
+	}
+
+	var instance string
+	defer func() {
+		if instance != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := c.proxy.deleteInstance(ctx, instance); err != nil {
+				c.log.Warnf("error leaving consumer group: %v", err)
+			}
+		}
+	}()
+
+	for ctx.Err() == nil {
+		if instance == "" {
+			err := c.retry(ctx, "joining consumer group", func() (err error) {
+				instance, err = c.join(ctx)
+				return err
+			})
+			if errors.Is(err, errInstanceGone) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			c.seedLag(ctx, instance)
+		}
+
+		var records []record
+		err := c.retry(ctx, "polling kafka", func() (err error) {
+			records, err = c.proxy.poll(ctx, instance, c.pollTimeout)
+			return err
+		})
+		if errors.Is(err, errInstanceGone) {
+			instance = ""
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(records) > 0 {
+			if err := c.applyRecords(ctx, records, fencing); err != nil {
+				return err
+			}
+			err := c.retry(ctx, "committing kafka offsets", func() error {
+				return c.proxy.commit(ctx, instance, lastOffsets(records))
+			})
+			if errors.Is(err, errInstanceGone) {
+				// the records are redelivered to the next instance, applying them again has no effect
+				instance = ""
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			c.committed(records)
+		}
+		c.refreshLag(ctx)
+	}
+	return ctx.Err()
+}
+
+func (c *Consumer) join(ctx context.Context) (string, error) {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		name = "kessel-relations"
+	}
+	instance, err := c.proxy.createInstance(ctx, c.group, name+"-"+uuid.NewString())
+	if err != nil {
+		return "", err
+	}
+	if err := c.proxy.subscribe(ctx, instance, c.topic); err != nil {
+		_ = c.proxy.deleteInstance(ctx, instance)
+		return "", err
+	}
+	return instance, nil
+}
+
+// retry calls f until it succeeds, the context ends, or it fails with an error that retrying does not resolve.
+func (c *Consumer) retry(ctx context.Context, what string, f func() error) error {
+	backoff := c.retryBackoff
+	for {
+		err := f()
+		if err == nil || errors.Is(err, errInstanceGone) || errors.Is(err, errLockLost) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.log.Warnf("error %s, retrying in %s: %v", what, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// applyRecords decodes and applies the changes of the records, skipping records that are not valid changes.
+func (c *Consumer) applyRecords(ctx context.Context, records []record, fencing *v1beta1.FencingCheck) error {
+	changes := make([]*v1beta1.TupleChange, 0, len(records))
+	for _, r := range records {
+		change, err := c.decode(r.Value)
+		if err != nil {
+			c.log.Errorf("skipping message at partition %d offset %d: %v", r.Partition, r.Offset, err)
+			c.count(ctx, "rejected", 1)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return c.applyChanges(ctx, changes, fencing)
+}
+
+// applyChanges retries failed writes until they succeed. Changes SpiceDB refuses, e.g. for types or relations the
+// schema does not define, are found by applying the changes one at a time and are skipped.
+func (c *Consumer) applyChanges(ctx context.Context, changes []*v1beta1.TupleChange, fencing *v1beta1.FencingCheck) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var rejected error
+	err := c.retry(ctx, "applying tuple changes", func() error {
+		err := c.apply(ctx, changes, c.batchSize, fencing)
+		switch {
+		case kerrors.Reason(err) == biz.LockLostReason:
+			return errLockLost
+		case isRejected(err):
+			rejected = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if rejected == nil {
+		c.count(ctx, "applied", len(changes))
+		return nil
+	}
+	if len(changes) == 1 {
+		c.log.Errorf("skipping tuple change rejected by spicedb: %v", rejected)
+		c.count(ctx, "rejected", 1)
+		return nil
+	}
+	for _, change := range changes {
+		if err := c.applyChanges(ctx, []*v1beta1.TupleChange{change}, fencing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
+
+func (c *Consumer) decode(value json.RawMessage) (*v1beta1.TupleChange, error) {
+	change := &v1beta1.TupleChange{}
+	if c.protobuf {
+		var encoded string
+		if err := json.Unmarshal(value, &encoded); err != nil {
+			return nil, err
+		}
+		b, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if err := proto.Unmarshal(b, change); err != nil {
+			return nil, err
+		}
+	} else if err := protojson.Unmarshal(value, change); err != nil {
+		return nil, err
+	}
+	if err := c.validator.Validate(change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (c *Consumer) count(ctx context.Context, outcome string, n int) {
+	c.changes.Add(ctx, int64(n), metric.WithAttributes(attribute.String("outcome", outcome)))
+}
+
+// lastOffsets returns the offset of the last of the records of each partition.
+func lastOffsets(records []record) []partitionOffset {
+	last := map[int32]int{}
+	var offsets []partitionOffset
+	for _, r := range records {
+		if i, ok := last[r.Partition]; ok {
+			offsets[i].Offset = max(offsets[i].Offset, r.Offset)
+			continue
+		}
+		last[r.Partition] = len(offsets)
+		offsets = append(offsets, partitionOffset{Topic: r.Topic, Partition: r.Partition, Offset: r.Offset})
+	}
+	return offsets
+}
+
+func (c *Consumer) committed(records []record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, o := range lastOffsets(records) {
+		c.next[o.Partition] = o.Offset + 1
+	}
+}
+
+// seedLag reads the offsets the group committed before the consumer joined it, so lag is reported for partitions the
+// consumer has not committed to yet.
+func (c *Consumer) seedLag(ctx context.Context, instance string) {
+	partitions, err := c.proxy.partitions(ctx, c.topic)
+	if err != nil {
+		c.log.Warnf("error reading consumer lag: %v", err)
+		return
+	}
+	offsets, err := c.proxy.committedOffsets(ctx, instance, c.topic, partitions)
+	if err != nil {
+		c.log.Warnf("error reading consumer lag: %v", err)
+		return
+	}
+	c.mu.Lock()
+	for _, o := range offsets {
+		if _, ok := c.next[o.Partition]; !ok {
+			c.next[o.Partition] = o.Offset
+		}
+	}
+	c.mu.Unlock()
+	c.readLag(ctx)
+}
+
+// refreshLag reads the lag at most every lagRefreshInterval.
+func (c *Consumer) refreshLag(ctx context.Context) {
+	c.mu.Lock()
+	if time.Since(c.lagRead) < lagRefreshInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.lagRead = time.Now()
+	c.mu.Unlock()
+	c.readLag(ctx)
+}
+
+// readLag reads the end offsets of the partitions the group has committed to.
+func (c *Consumer) readLag(ctx context.Context) {
+	c.mu.Lock()
+	next := make(map[int32]int64, len(c.next))
+	for partition, offset := range c.next {
+		next[partition] = offset
+	}
+	c.mu.Unlock()
+
+	for partition, offset := range next {
+		end, err := c.proxy.endOffset(ctx, c.topic, partition)
+		if err != nil {
+			c.log.Warnf("error reading consumer lag: %v", err)
+			return
+		}
+		c.mu.Lock()
+		c.lag[partition] = max(end-offset, 0)
+		c.mu.Unlock()
+	}
+}
+
+func durationOr(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
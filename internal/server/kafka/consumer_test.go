@@ -0,0 +1,324 @@
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/biz"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+// fakeProxy is an in-process stand-in for a Kafka REST proxy serving a single-partition topic to one consumer group.
+type fakeProxy struct {
+	*httptest.Server
+	mu        sync.Mutex
+	values    []json.RawMessage
+	committed int64            // offset after the last committed record
+	positions map[string]int64 // offset of the next record, by instance
+}
+
+func newFakeProxy(t *testing.T) *fakeProxy {
+	p := &fakeProxy{positions: map[string]int64{}}
+	p.Server = httptest.NewServer(http.HandlerFunc(p.serve))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *fakeProxy) produce(values ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, v := range values {
+		p.values = append(p.values, json.RawMessage(v))
+	}
+}
+
+func (p *fakeProxy) committedOffset() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.committed
+}
+
+func (p *fakeProxy) serve(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	path := r.URL.Path
+	instance, action, _ := strings.Cut(strings.TrimPrefix(path, "/consumers/group/instances/"), "/")
+
+	switch {
+	case r.Method == http.MethodPost && path == "/consumers/group":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		p.positions[body["name"]] = p.committed
+		_ = json.NewEncoder(w).Encode(map[string]string{"instance_id": body["name"], "base_uri": p.URL + "/consumers/group/instances/" + body["name"]})
+	case path == "/topics/tuples/partitions":
+		_ = json.NewEncoder(w).Encode([]map[string]int32{{"partition": 0}})
+	case path == "/topics/tuples/partitions/0/offsets":
+		_ = json.NewEncoder(w).Encode(map[string]int64{"beginning_offset": 0, "end_offset": int64(len(p.values))})
+	case strings.HasPrefix(path, "/consumers/group/instances/"):
+		position, ok := p.positions[instance]
+		if !ok {
+			http.Error(w, `{"error_code":40403,"message":"Consumer instance not found."}`, http.StatusNotFound)
+			return
+		}
+		switch {
+		case r.Method == http.MethodDelete:
+			delete(p.positions, instance)
+			w.WriteHeader(http.StatusNoContent)
+		case action == "subscription":
+			w.WriteHeader(http.StatusNoContent)
+		case action == "records":
+			var records []record
+			for o := position; o < int64(len(p.values)) && len(records) < 2; o++ {
+				records = append(records, record{Topic: "tuples", Partition: 0, Offset: o, Value: p.values[o]})
+			}
+			p.positions[instance] = position + int64(len(records))
+			_ = json.NewEncoder(w).Encode(records)
+		case action == "offsets" && r.Method == http.MethodGet:
+			offsets := []partitionOffset{{Topic: "tuples", Partition: 0, Offset: p.committed}}
+			_ = json.NewEncoder(w).Encode(map[string][]partitionOffset{"offsets": offsets})
+		case action == "offsets":
+			var body struct {
+				Offsets []partitionOffset `json:"offsets"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			for _, o := range body.Offsets {
+				p.committed = o.Offset + 1
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// appliedChanges records the changes applied, failing while failures remain.
+type appliedChanges struct {
+	mu       sync.Mutex
+	changes  []string
+	calls    int
+	failures int
+	err      func(changes []*v1beta1.TupleChange) error
+}
+
+func (a *appliedChanges) apply(_ context.Context, changes []*v1beta1.TupleChange, _ int, _ *v1beta1.FencingCheck) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls++
+	if a.failures > 0 {
+		a.failures--
+		return status.Error(codes.Unavailable, "spicedb unavailable")
+	}
+	if a.err != nil {
+		if err := a.err(changes); err != nil {
+			return err
+		}
+	}
+	for _, c := range changes {
+		a.changes = append(a.changes, fmt.Sprintf("%s %s", c.GetOperation(), c.GetTuple().GetResource().GetId()))
+	}
+	return nil
+}
+
+func (a *appliedChanges) applied() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.changes...)
+}
+
+func change(op, id string) string {
+	return fmt.Sprintf(`{"operation":%q,"tuple":{"resource":{"type":{"namespace":"rbac","name":"group"},"id":%q},"relation":"member","subject":{"subject":{"type":{"namespace":"rbac","name":"principal"},"id":"bob"}}}}`, op, id)
+}
+
+func startConsumer(t *testing.T, proxy *fakeProxy, format string, apply ApplyFunc, acquire AcquireLockFunc) (*Consumer, chan error) {
+	t.Helper()
+	c, err := NewConsumer(&conf.Server_Consumer{
+		Enabled:      true,
+		RestProxyUrl: proxy.URL,
+		Topic:        "tuples",
+		Group:        "group",
+		Format:       format,
+		LockId:       "consumer",
+		PollTimeout:  durationpb.New(time.Millisecond),
+		RetryBackoff: durationpb.New(time.Millisecond),
+	}, apply, acquire, noop.NewMeterProvider().Meter("test"), log.DefaultLogger)
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- c.Start(context.Background()) }()
+	t.Cleanup(func() { _ = c.Stop(context.Background()) })
+	return c, done
+}
+
+func acquireLock(context.Context, string) (*v1beta1.FencingCheck, error) {
+	return &v1beta1.FencingCheck{LockId: "consumer", LockToken: "token"}, nil
+}
+
+func TestConsumer_AppliesChangesAndCommits(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeProxy(t)
+	proxy.produce(change("OPERATION_TOUCH", "g1"), change("OPERATION_DELETE", "g2"), change("OPERATION_TOUCH", "g3"))
+	applied := &appliedChanges{}
+
+	c, _ := startConsumer(t, proxy, "json", applied.apply, acquireLock)
+
+	assert.Eventually(t, func() bool { return proxy.committedOffset() == 3 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"OPERATION_TOUCH g1", "OPERATION_DELETE g2", "OPERATION_TOUCH g3"}, applied.applied())
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		lag, ok := c.lag[0]
+		return ok && lag <= 1
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestConsumer_CommitsOnlyOnceApplied(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeProxy(t)
+	proxy.produce(change("OPERATION_TOUCH", "g1"))
+	applied := &appliedChanges{failures: 2}
+
+	startConsumer(t, proxy, "json", func(ctx context.Context, changes []*v1beta1.TupleChange, batchSize int, fencing *v1beta1.FencingCheck) error {
+		err := applied.apply(ctx, changes, batchSize, fencing)
+		if err != nil {
+			assert.Equal(t, int64(0), proxy.committedOffset(), "nothing is committed before the changes are written")
+		}
+		return err
+	}, acquireLock)
+
+	assert.Eventually(t, func() bool { return proxy.committedOffset() == 1 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, 3, applied.calls)
+	assert.Equal(t, []string{"OPERATION_TOUCH g1"}, applied.applied())
+}
+
+func TestConsumer_SkipsInvalidMessages(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeProxy(t)
+	proxy.produce(`"not a change"`, `{"operation":"OPERATION_TOUCH"}`, change("OPERATION_TOUCH", "g1"))
+	applied := &appliedChanges{}
+
+	startConsumer(t, proxy, "json", applied.apply, acquireLock)
+
+	assert.Eventually(t, func() bool { return proxy.committedOffset() == 3 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"OPERATION_TOUCH g1"}, applied.applied())
+}
+
+func TestConsumer_SkipsChangesRejectedBySpiceDB(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeProxy(t)
+	proxy.produce(change("OPERATION_TOUCH", "g1"), change("OPERATION_TOUCH", "unknown"))
+	applied := &appliedChanges{err: func(changes []*v1beta1.TupleChange) error {
+		for _, c := range changes {
+			if c.GetTuple().GetResource().GetId() == "unknown" {
+				return status.Error(codes.FailedPrecondition, "object definition not found")
+			}
+		}
+		return nil
+	}}
+
+	startConsumer(t, proxy, "json", applied.apply, acquireLock)
+
+	assert.Eventually(t, func() bool { return proxy.committedOffset() == 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"OPERATION_TOUCH g1"}, applied.applied())
+}
+
+func TestConsumer_AcquiresLostLockAgain(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeProxy(t)
+	proxy.produce(change("OPERATION_TOUCH", "g1"))
+	applied := &appliedChanges{}
+	lost := true
+	applied.err = func([]*v1beta1.TupleChange) error {
+		if lost {
+			lost = false
+			return kerrors.Conflict(biz.LockLostReason, "lock consumer was acquired by another writer")
+		}
+		return nil
+	}
+	var acquired atomic.Int32
+	acquire := func(ctx context.Context, lockId string) (*v1beta1.FencingCheck, error) {
+		acquired.Add(1)
+		return acquireLock(ctx, lockId)
+	}
+
+	c, _ := startConsumer(t, proxy, "json", applied.apply, acquire)
+
+	assert.Eventually(t, func() bool { return proxy.committedOffset() == 1 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"OPERATION_TOUCH g1"}, applied.applied())
+	assert.Equal(t, int32(2), acquired.Load(), "the lock is acquired again once lost")
+	assert.True(t, c.lockHeld.Load())
+}
+
+func TestConsumer_ReportsLagFromCommittedOffsets(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeProxy(t)
+	proxy.produce(change("OPERATION_TOUCH", "g1"), change("OPERATION_TOUCH", "g2"), change("OPERATION_TOUCH", "g3"))
+	proxy.committed = 1
+	release := make(chan struct{})
+	blocked := func(ctx context.Context, _ []*v1beta1.TupleChange, _ int, _ *v1beta1.FencingCheck) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return ctx.Err()
+	}
+
+	c, _ := startConsumer(t, proxy, "json", blocked, acquireLock)
+	defer close(release)
+
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.lag[0] == 2
+	}, 5*time.Second, time.Millisecond, "lag is reported before the consumer commits")
+}
+
+func TestConsumer_DecodesProtobufMessages(t *testing.T) {
+	t.Parallel()
+	proxy := newFakeProxy(t)
+	b, err := proto.Marshal(&v1beta1.TupleChange{
+		Operation: v1beta1.TupleChange_OPERATION_DELETE,
+		Tuple: &v1beta1.Relationship{
+			Resource: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "group"}, Id: "g1"},
+			Relation: "member",
+			Subject:  &v1beta1.SubjectReference{Subject: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "principal"}, Id: "bob"}},
+		},
+	})
+	require.NoError(t, err)
+	proxy.produce(strconv.Quote(base64.StdEncoding.EncodeToString(b)))
+	applied := &appliedChanges{}
+
+	startConsumer(t, proxy, "protobuf", applied.apply, acquireLock)
+
+	assert.Eventually(t, func() bool { return proxy.committedOffset() == 1 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"OPERATION_DELETE g1"}, applied.applied())
+}
+
+func TestNewConsumer_RejectsIncompleteConfig(t *testing.T) {
+	t.Parallel()
+	meter := noop.NewMeterProvider().Meter("test")
+
+	_, err := NewConsumer(&conf.Server_Consumer{Enabled: true, Topic: "tuples"}, nil, nil, meter, log.DefaultLogger)
+	assert.ErrorContains(t, err, "restProxyUrl")
+	_, err = NewConsumer(&conf.Server_Consumer{Enabled: true, RestProxyUrl: "http://proxy", Topic: "tuples", Format: "avro"}, nil, nil, meter, log.DefaultLogger)
+	assert.ErrorContains(t, err, "avro")
+}
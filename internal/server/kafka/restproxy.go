@@ -0,0 +1,189 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	contentTypeV2 = "application/vnd.kafka.v2+json"
+)
+
+// errInstanceGone is returned when the REST proxy no longer knows the consumer instance, e.g. after it restarted or
+// expired the instance for being idle. Uncommitted records are redelivered to the next instance.
+var errInstanceGone = errors.New("kafka consumer instance no longer exists")
+
+// record is a message returned by the REST proxy. Values are JSON in the json format and base64 in the binary format.
+type record struct {
+	Topic     string          `json:"topic"`
+	Partition int32           `json:"partition"`
+	Offset    int64           `json:"offset"`
+	Value     json.RawMessage `json:"value"`
+}
+
+type partitionOffset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// restProxy is a client of the consumer API of a Kafka REST proxy speaking the Confluent v2 API.
+type restProxy struct {
+	baseURL string
+	format  string // "json" or "binary"
+	client  *http.Client
+}
+
+func newRestProxy(baseURL, format string) *restProxy {
+	return &restProxy{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		format:  format,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// createInstance creates a consumer instance in the group, committing offsets manually and starting at the earliest
+// offset of partitions the group has not committed to, and returns its base URI.
+func (p *restProxy) createInstance(ctx context.Context, group, name string) (string, error) {
+	var created struct {
+		BaseURI string `json:"base_uri"`
+	}
+	err := p.do(ctx, http.MethodPost, p.baseURL+"/consumers/"+url.PathEscape(group), map[string]string{
+		"name":               name,
+		"format":             p.format,
+		"auto.offset.reset":  "earliest",
+		"auto.commit.enable": "false",
+	}, contentTypeV2, &created)
+	if err != nil {
+		return "", fmt.Errorf("error creating kafka consumer: %w", err)
+	}
+	return created.BaseURI, nil
+}
+
+func (p *restProxy) subscribe(ctx context.Context, instance, topic string) error {
+	if err := p.do(ctx, http.MethodPost, instance+"/subscription", map[string][]string{"topics": {topic}}, contentTypeV2, nil); err != nil {
+		return fmt.Errorf("error subscribing to %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (p *restProxy) poll(ctx context.Context, instance string, timeout time.Duration) ([]record, error) {
+	var records []record
+	u := instance + "/records?timeout=" + strconv.FormatInt(timeout.Milliseconds(), 10)
+	if err := p.do(ctx, http.MethodGet, u, nil, "application/vnd.kafka."+p.format+".v2+json", &records); err != nil {
+		return nil, fmt.Errorf("error polling kafka: %w", err)
+	}
+	return records, nil
+}
+
+// commit commits the offsets of the last records processed, the group resumes after them.
+func (p *restProxy) commit(ctx context.Context, instance string, offsets []partitionOffset) error {
+	if err := p.do(ctx, http.MethodPost, instance+"/offsets", map[string][]partitionOffset{"offsets": offsets}, contentTypeV2, nil); err != nil {
+		return fmt.Errorf("error committing kafka offsets: %w", err)
+	}
+	return nil
+}
+
+// endOffset returns the offset the next message produced to the partition will have.
+func (p *restProxy) endOffset(ctx context.Context, topic string, partition int32) (int64, error) {
+	var offsets struct {
+		EndOffset int64 `json:"end_offset"`
+	}
+	u := fmt.Sprintf("%s/topics/%s/partitions/%d/offsets", p.baseURL, url.PathEscape(topic), partition)
+	if err := p.do(ctx, http.MethodGet, u, nil, contentTypeV2, &offsets); err != nil {
+		return 0, fmt.Errorf("error reading kafka partition offsets: %w", err)
+	}
+	return offsets.EndOffset, nil
+}
+
+// partitions returns the partitions of the topic.
+func (p *restProxy) partitions(ctx context.Context, topic string) ([]int32, error) {
+	var partitions []struct {
+		Partition int32 `json:"partition"`
+	}
+	if err := p.do(ctx, http.MethodGet, p.baseURL+"/topics/"+url.PathEscape(topic)+"/partitions", nil, contentTypeV2, &partitions); err != nil {
+		return nil, fmt.Errorf("error reading kafka partitions: %w", err)
+	}
+	ids := make([]int32, 0, len(partitions))
+	for _, partition := range partitions {
+		ids = append(ids, partition.Partition)
+	}
+	return ids, nil
+}
+
+// committedOffsets returns the offsets the group of the instance resumes the partitions at, partitions it has not
+// committed to are left out.
+func (p *restProxy) committedOffsets(ctx context.Context, instance, topic string, partitions []int32) ([]partitionOffset, error) {
+	type topicPartition struct {
+		Topic     string `json:"topic"`
+		Partition int32  `json:"partition"`
+	}
+	in := struct {
+		Partitions []topicPartition `json:"partitions"`
+	}{}
+	for _, partition := range partitions {
+		in.Partitions = append(in.Partitions, topicPartition{Topic: topic, Partition: partition})
+	}
+	var out struct {
+		Offsets []partitionOffset `json:"offsets"`
+	}
+	if err := p.do(ctx, http.MethodGet, instance+"/offsets", in, contentTypeV2, &out); err != nil {
+		return nil, fmt.Errorf("error reading committed kafka offsets: %w", err)
+	}
+	offsets := out.Offsets[:0]
+	for _, o := range out.Offsets {
+		if o.Offset >= 0 {
+			offsets = append(offsets, o)
+		}
+	}
+	return offsets, nil
+}
+
+func (p *restProxy) deleteInstance(ctx context.Context, instance string) error {
+	return p.do(ctx, http.MethodDelete, instance, nil, contentTypeV2, nil)
+}
+
+func (p *restProxy) do(ctx context.Context, method, u string, in any, accept string, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", contentTypeV2)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && strings.Contains(u, "/instances/") {
+		return errInstanceGone
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, u, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
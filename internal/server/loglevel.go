@@ -0,0 +1,38 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+// LogLevel is the minimum level of the service logger, which follows server.minLogLevel when the configuration
+// is reloaded.
+type LogLevel struct {
+	level atomic.Int32
+}
+
+// NewLogLevel returns the level configured in server.minLogLevel, defaulting to info.
+func NewLogLevel(c *conf.Server) *LogLevel {
+	l := &LogLevel{}
+	l.Set(c.GetMinLogLevel())
+	return l
+}
+
+// Set changes the level to the named one, falling back to info for unknown names.
+func (l *LogLevel) Set(name string) {
+	l.level.Store(int32(log.ParseLevel(name)))
+}
+
+// Level returns the current level.
+func (l *LogLevel) Level() log.Level {
+	return log.Level(l.level.Load())
+}
+
+// Filter is a log filter option dropping entries below the current level.
+func (l *LogLevel) Filter() log.FilterOption {
+	return log.FilterFunc(func(level log.Level, _ ...any) bool {
+		return level < l.Level()
+	})
+}
@@ -2,16 +2,18 @@ package auth
 
 import (
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/go-kratos/kratos/v2/errors"
-	"github.com/go-kratos/kratos/v2/log"
 	kratosMiddleware "github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
 	"github.com/go-kratos/kratos/v2/transport"
 	jwtv5 "github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+
+	"github.com/project-kessel/relations-api/internal/audit"
 )
 
 type authKey struct{}
@@ -25,9 +27,11 @@ const (
 )
 
 type authOptions struct {
-	signingMethod jwtv5.SigningMethod
-	claims        func() jwtv5.Claims
-	tokenHeader   map[string]interface{}
+	signingMethods []jwtv5.SigningMethod
+	methodsFunc    func() []jwtv5.SigningMethod
+	claims         func() jwtv5.Claims
+	tokenHeader    map[string]interface{}
+	certBypass     bool
 }
 
 type AuthOption func(*authOptions)
@@ -47,35 +51,128 @@ func WithTokenHeader(header map[string]interface{}) AuthOption {
 
 func WithSigningMethod(signingMethod jwtv5.SigningMethod) AuthOption {
 	return func(o *authOptions) {
-		o.signingMethod = signingMethod
+		o.signingMethods = []jwtv5.SigningMethod{signingMethod}
 	}
 }
 
-// StreamAuthInterceptor is a gRPC stream server interceptor for JWT authentication.
-func StreamAuthInterceptor(logger log.Logger, keyFunc jwtv5.Keyfunc, opts ...AuthOption) grpc.StreamServerInterceptor {
+// WithSigningMethods accepts tokens signed with any of the given methods.
+func WithSigningMethods(signingMethods ...jwtv5.SigningMethod) AuthOption {
+	return func(o *authOptions) {
+		o.signingMethods = signingMethods
+	}
+}
+
+// WithSigningMethodsFunc accepts tokens signed with any of the methods returned by f, which is called for every
+// token so the accepted methods can change at runtime.
+func WithSigningMethodsFunc(f func() []jwtv5.SigningMethod) AuthOption {
+	return func(o *authOptions) {
+		o.methodsFunc = f
+	}
+}
+
+// WithClientCertBypass lets callers already authenticated by a client certificate through without a JWT.
+func WithClientCertBypass() AuthOption {
+	return func(o *authOptions) {
+		o.certBypass = true
+	}
+}
+
+func newAuthOptions(opts []AuthOption) *authOptions {
 	o := &authOptions{
-		signingMethod: jwtv5.SigningMethodRS256,
+		signingMethods: []jwtv5.SigningMethod{jwtv5.SigningMethodRS256},
 	}
 	for _, opt := range opts {
 		opt(o)
 	}
+	return o
+}
+
+// parseToken verifies the bearer token in authHeader. On failure it returns the Kratos JWT error to send to the
+// caller together with the SEC-MON reason to log.
+func parseToken(authHeader string, keyFunc jwtv5.Keyfunc, o *authOptions) (*jwtv5.Token, string, error) {
+	if keyFunc == nil {
+		return nil, "missing_key_func", jwt.ErrMissingKeyFunc
+	}
+	auths := strings.SplitN(authHeader, " ", 2)
+	if len(auths) != 2 || !strings.EqualFold(auths[0], bearerWord) {
+		return nil, "missing_token", jwt.ErrMissingJwtToken
+	}
+	jwtToken := auths[1]
+	var (
+		tokenInfo *jwtv5.Token
+		err       error
+	)
+	if o.claims != nil {
+		tokenInfo, err = jwtv5.ParseWithClaims(jwtToken, o.claims(), keyFunc)
+	} else {
+		tokenInfo, err = jwtv5.Parse(jwtToken, keyFunc)
+	}
+	if err != nil {
+		if errors.Is(err, jwtv5.ErrTokenMalformed) || errors.Is(err, jwtv5.ErrTokenUnverifiable) {
+			return nil, "token_invalid", jwt.ErrTokenInvalid
+		}
+		if errors.Is(err, jwtv5.ErrTokenNotValidYet) || errors.Is(err, jwtv5.ErrTokenExpired) {
+			return nil, "token_expired", jwt.ErrTokenExpired
+		}
+		return nil, "token_parse_failed", jwt.ErrTokenParseFail
+	}
+	if !tokenInfo.Valid {
+		return nil, "token_invalid", jwt.ErrTokenInvalid
+	}
+	methods := o.signingMethods
+	if o.methodsFunc != nil {
+		methods = o.methodsFunc()
+	}
+	if !slices.Contains(methods, tokenInfo.Method) {
+		return nil, "unsupported_signing_method", jwt.ErrUnSupportSigningMethod
+	}
+	return tokenInfo, "", nil
+}
+
+// Server is a unary middleware for JWT authentication. Unlike the Kratos JWT middleware it accepts several signing
+// methods, so tokens from issuers using different algorithms can be verified. Claims are stored in the Kratos JWT
+// context, and failures are reported with the Kratos JWT errors understood by AuthFailureLoggingMiddleware.
+func Server(keyFunc jwtv5.Keyfunc, opts ...AuthOption) kratosMiddleware.Middleware {
+	o := newAuthOptions(opts)
+	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if o.certBypass && CertPrincipalFromContext(ctx) != "" {
+				return handler(ctx, req)
+			}
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, jwt.ErrWrongContext
+			}
+			tokenInfo, _, err := parseToken(tr.RequestHeader().Get(authorizationKey), keyFunc, o)
+			if err != nil {
+				return nil, err
+			}
+			return handler(jwt.NewContext(ctx, tokenInfo.Claims), req)
+		}
+	}
+}
+
+// StreamAuthInterceptor is a gRPC stream server interceptor for JWT authentication.
+func StreamAuthInterceptor(auditor *audit.Auditor, keyFunc jwtv5.Keyfunc, opts ...AuthOption) grpc.StreamServerInterceptor {
+	o := newAuthOptions(opts)
 
 	// Authentication failure - SEC-MON-REQ-1 compliance (EOI-7 invalid_login, EOI-8 authorization_failure)
 	logAuthFailure := func(ctx context.Context, operation, reason string) {
-		log.NewHelper(log.WithContext(ctx, logger)).Warnw(
-			"msg", "Authentication failed",
-			"action", "AUTHENTICATE",
-			"resource_type", "api_endpoint",
-			"resource_id", operation,
-			"outcome", "failure",
-			"reason", reason,
-		)
+		auditor.Record(ctx, audit.Event{
+			Message:      "Authentication failed",
+			Action:       "AUTHENTICATE",
+			ResourceType: "api_endpoint",
+			ResourceID:   operation,
+			Outcome:      audit.OutcomeFailure,
+			Reason:       reason,
+		})
 	}
 
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		var newCtx context.Context
-		var err error
-		newCtx = ss.Context()
+		newCtx := ss.Context()
+		if o.certBypass && CertPrincipalFromContext(newCtx) != "" {
+			return handler(srv, ss)
+		}
 		if keyFunc == nil {
 			logAuthFailure(newCtx, info.FullMethod, "missing_key_func")
 			return jwt.ErrMissingKeyFunc
@@ -90,39 +187,10 @@ func StreamAuthInterceptor(logger log.Logger, keyFunc jwtv5.Keyfunc, opts ...Aut
 			logAuthFailure(newCtx, info.FullMethod, "missing_token")
 			return jwt.ErrMissingJwtToken
 		}
-		auths := strings.SplitN(authHeader[0], " ", 2)
-		if len(auths) != 2 || !strings.EqualFold(auths[0], bearerWord) {
-			logAuthFailure(newCtx, info.FullMethod, "missing_token")
-			return jwt.ErrMissingJwtToken
-		}
-		jwtToken := auths[1]
-		var (
-			tokenInfo *jwtv5.Token
-		)
-		if o.claims != nil {
-			tokenInfo, err = jwtv5.ParseWithClaims(jwtToken, o.claims(), keyFunc)
-		} else {
-			tokenInfo, err = jwtv5.Parse(jwtToken, keyFunc)
-		}
+		tokenInfo, reason, err := parseToken(authHeader[0], keyFunc, o)
 		if err != nil {
-			if errors.Is(err, jwtv5.ErrTokenMalformed) || errors.Is(err, jwtv5.ErrTokenUnverifiable) {
-				logAuthFailure(newCtx, info.FullMethod, "token_invalid")
-				return jwt.ErrTokenInvalid
-			}
-			if errors.Is(err, jwtv5.ErrTokenNotValidYet) || errors.Is(err, jwtv5.ErrTokenExpired) {
-				logAuthFailure(newCtx, info.FullMethod, "token_expired")
-				return jwt.ErrTokenExpired
-			}
-			logAuthFailure(newCtx, info.FullMethod, "token_parse_failed")
-			return jwt.ErrTokenParseFail
-		}
-		if !tokenInfo.Valid {
-			logAuthFailure(newCtx, info.FullMethod, "token_invalid")
-			return jwt.ErrTokenInvalid
-		}
-		if tokenInfo.Method != o.signingMethod {
-			logAuthFailure(newCtx, info.FullMethod, "unsupported_signing_method")
-			return jwt.ErrUnSupportSigningMethod
+			logAuthFailure(newCtx, info.FullMethod, reason)
+			return err
 		}
 		newCtx = NewContext(newCtx, tokenInfo.Claims)
 		wrappedStream := &authServerStream{ServerStream: ss, ctx: newCtx}
@@ -149,7 +217,7 @@ func FromContext(ctx context.Context) (jwtv5.Claims, bool) {
 }
 
 // AuthFailureLoggingMiddleware logs JWT authentication failures for unary RPCs (SEC-MON-REQ-1 compliance).
-func AuthFailureLoggingMiddleware(logger log.Logger) kratosMiddleware.Middleware {
+func AuthFailureLoggingMiddleware(auditor *audit.Auditor) kratosMiddleware.Middleware {
 	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
 		return func(ctx context.Context, req any) (any, error) {
 			resp, err := handler(ctx, req)
@@ -159,14 +227,14 @@ func AuthFailureLoggingMiddleware(logger log.Logger) kratosMiddleware.Middleware
 					if tr, ok := transport.FromServerContext(ctx); ok {
 						operation = tr.Operation()
 					}
-					log.NewHelper(log.WithContext(ctx, logger)).Warnw(
-						"msg", "Authentication failed",
-						"action", "AUTHENTICATE",
-						"resource_type", "api_endpoint",
-						"resource_id", operation,
-						"outcome", "failure",
-						"reason", reason,
-					)
+					auditor.Record(ctx, audit.Event{
+						Message:      "Authentication failed",
+						Action:       "AUTHENTICATE",
+						ResourceType: "api_endpoint",
+						ResourceID:   operation,
+						Outcome:      audit.OutcomeFailure,
+						Reason:       reason,
+					})
 				}
 			}
 			return resp, err
@@ -192,3 +260,41 @@ func jwtErrorReason(err error) string {
 		return ""
 	}
 }
+
+// PrincipalFromContext returns the JWT `sub` of the authenticated caller, or failing that the principal of its
+// client certificate, or an empty string if the request is unauthenticated. Claims placed by either the Kratos JWT
+// middleware or StreamAuthInterceptor are considered.
+func PrincipalFromContext(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		if sub := SubjectFromClaims(claims); sub != "" {
+			return sub
+		}
+	}
+	return CertPrincipalFromContext(ctx)
+}
+
+// ClaimsFromContext returns the verified JWT claims of the caller, placed by either the Kratos JWT middleware or
+// StreamAuthInterceptor.
+func ClaimsFromContext(ctx context.Context) (jwtv5.MapClaims, bool) {
+	if token, ok := jwt.FromContext(ctx); ok {
+		if mc, ok := token.(jwtv5.MapClaims); ok {
+			return mc, true
+		}
+	}
+	if claims, ok := FromContext(ctx); ok {
+		if mc, ok := claims.(jwtv5.MapClaims); ok {
+			return mc, true
+		}
+	}
+	return nil, false
+}
+
+// SubjectFromClaims returns the `sub` claim of a JWT, or an empty string if it is missing or not a string.
+func SubjectFromClaims(claims any) string {
+	if mc, ok := claims.(jwtv5.MapClaims); ok {
+		if sub, ok := mc["sub"].(string); ok {
+			return sub
+		}
+	}
+	return ""
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/project-kessel/relations-api/internal/audit"
 )
 
 type logEntry struct {
@@ -67,7 +69,7 @@ func TestAuthFailureLoggingMiddleware_PassesThroughSuccess(t *testing.T) {
 	t.Parallel()
 
 	logger := log.NewStdLogger(io.Discard)
-	m := AuthFailureLoggingMiddleware(logger)
+	m := AuthFailureLoggingMiddleware(audit.NewLogAuditor(logger))
 
 	handler := func(ctx context.Context, req any) (any, error) {
 		return "ok", nil
@@ -82,7 +84,7 @@ func TestAuthFailureLoggingMiddleware_PassesThroughNonJwtError(t *testing.T) {
 	t.Parallel()
 
 	logger := log.NewStdLogger(io.Discard)
-	m := AuthFailureLoggingMiddleware(logger)
+	m := AuthFailureLoggingMiddleware(audit.NewLogAuditor(logger))
 
 	handler := func(ctx context.Context, req any) (any, error) {
 		return nil, assert.AnError
@@ -111,7 +113,7 @@ func TestAuthFailureLoggingMiddleware_LogsAndPassesThroughJwtErrors(t *testing.T
 			t.Parallel()
 
 			cl := &captureLogger{}
-			m := AuthFailureLoggingMiddleware(cl)
+			m := AuthFailureLoggingMiddleware(audit.NewLogAuditor(cl))
 
 			handler := func(ctx context.Context, req any) (any, error) {
 				return nil, jwtErr
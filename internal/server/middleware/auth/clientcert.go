@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+
+	kratosMiddleware "github.com/go-kratos/kratos/v2/middleware"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type certPrincipalKey struct{}
+
+const (
+	// CertPrincipalSubject uses the certificate subject common name as principal.
+	CertPrincipalSubject = "subject"
+	// CertPrincipalURI uses the first URI SAN, e.g. a SPIFFE ID, as principal.
+	CertPrincipalURI = "uri"
+	// CertPrincipalDNS uses the first DNS SAN as principal.
+	CertPrincipalDNS = "dns"
+	// CertPrincipalEmail uses the first email SAN as principal.
+	CertPrincipalEmail = "email"
+)
+
+// PrincipalFromCertificate returns the principal named by the given certificate field, or an empty string if the
+// certificate does not carry it.
+func PrincipalFromCertificate(cert *x509.Certificate, field string) string {
+	switch field {
+	case CertPrincipalURI:
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String()
+		}
+	case CertPrincipalDNS:
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+	case CertPrincipalEmail:
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+	default:
+		return cert.Subject.CommonName
+	}
+	return ""
+}
+
+// VerifiedClientCertificate returns the leaf certificate the caller authenticated the connection with. Certificates
+// that were presented but not verified against the configured client CA are ignored.
+func VerifiedClientCertificate(ctx context.Context) (*x509.Certificate, bool) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			return info.State.VerifiedChains[0][0], true
+		}
+	}
+	if r, ok := khttp.RequestFromServerContext(ctx); ok && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return r.TLS.VerifiedChains[0][0], true
+	}
+	return nil, false
+}
+
+// NewCertPrincipalContext records the principal authenticated by a client certificate.
+func NewCertPrincipalContext(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, certPrincipalKey{}, principal)
+}
+
+// CertPrincipalFromContext returns the principal authenticated by a client certificate, if any.
+func CertPrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(certPrincipalKey{}).(string)
+	return principal
+}
+
+func withCertPrincipal(ctx context.Context, field string) context.Context {
+	cert, ok := VerifiedClientCertificate(ctx)
+	if !ok {
+		return ctx
+	}
+	if principal := PrincipalFromCertificate(cert, field); principal != "" {
+		return NewCertPrincipalContext(ctx, principal)
+	}
+	return ctx
+}
+
+// ClientCertMiddleware maps the verified client certificate of the caller, if any, to a principal using the given
+// certificate field.
+func ClientCertMiddleware(field string) kratosMiddleware.Middleware {
+	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			return handler(withCertPrincipal(ctx, field), req)
+		}
+	}
+}
+
+// StreamClientCertInterceptor is the streaming counterpart of ClientCertMiddleware.
+func StreamClientCertInterceptor(field string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withCertPrincipal(ss.Context(), field)
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
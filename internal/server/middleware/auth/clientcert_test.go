@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func testCertificate() *x509.Certificate {
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/notifications/sa/notifications")
+	return &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "notifications-service"},
+		URIs:           []*url.URL{spiffe},
+		DNSNames:       []string{"notifications.svc"},
+		EmailAddresses: []string{"notifications@example.com"},
+	}
+}
+
+func ctxWithPeerCertificate(cert *x509.Certificate, verified bool) context.Context {
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if verified {
+		state.VerifiedChains = [][]*x509.Certificate{{cert}}
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestPrincipalFromCertificate(t *testing.T) {
+	t.Parallel()
+
+	cert := testCertificate()
+	assert.Equal(t, "notifications-service", PrincipalFromCertificate(cert, ""))
+	assert.Equal(t, "notifications-service", PrincipalFromCertificate(cert, CertPrincipalSubject))
+	assert.Equal(t, "spiffe://cluster.local/ns/notifications/sa/notifications", PrincipalFromCertificate(cert, CertPrincipalURI))
+	assert.Equal(t, "notifications.svc", PrincipalFromCertificate(cert, CertPrincipalDNS))
+	assert.Equal(t, "notifications@example.com", PrincipalFromCertificate(cert, CertPrincipalEmail))
+	assert.Equal(t, "", PrincipalFromCertificate(&x509.Certificate{}, CertPrincipalURI))
+}
+
+func TestClientCertMiddleware_MapsVerifiedCertificateToPrincipal(t *testing.T) {
+	t.Parallel()
+
+	m := ClientCertMiddleware(CertPrincipalURI)(func(ctx context.Context, req any) (any, error) {
+		return PrincipalFromContext(ctx), nil
+	})
+
+	principal, err := m(ctxWithPeerCertificate(testCertificate(), true), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://cluster.local/ns/notifications/sa/notifications", principal)
+
+	principal, err = m(ctxWithPeerCertificate(testCertificate(), false), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", principal, "unverified certificates must be ignored")
+}
+
+func TestPrincipalFromContext_PrefersJwtOverCertificate(t *testing.T) {
+	t.Parallel()
+
+	ctx := NewCertPrincipalContext(context.Background(), "cert-user")
+	assert.Equal(t, "cert-user", PrincipalFromContext(ctx))
+
+	ctx = NewContext(ctx, jwtv5.MapClaims{"sub": "jwt-user"})
+	assert.Equal(t, "jwt-user", PrincipalFromContext(ctx))
+}
+
+func TestServer_ClientCertBypass(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, req any) (any, error) { return PrincipalFromContext(ctx), nil }
+	ctx := transport.NewServerContext(
+		NewCertPrincipalContext(context.Background(), "cert-user"),
+		&headerTransporter{header: headerCarrier{}},
+	)
+
+	principal, err := Server(nil, WithClientCertBypass())(handler)(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "cert-user", principal)
+
+	_, err = Server(nil)(handler)(ctx, nil)
+	assert.Error(t, err, "without the bypass a JWT is still required")
+}
+
+type certServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *certServerStream) Context() context.Context { return c.ctx }
+
+func TestStreamClientCertInterceptor(t *testing.T) {
+	t.Parallel()
+
+	interceptor := StreamClientCertInterceptor(CertPrincipalSubject)
+	stream := &certServerStream{ctx: ctxWithPeerCertificate(testCertificate(), true)}
+
+	var principal string
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{}, func(srv any, ss grpc.ServerStream) error {
+		principal = PrincipalFromContext(ss.Context())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "notifications-service", principal)
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/go-kratos/kratos/v2/log"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const defaultJwksRefreshInterval = time.Hour
+
+var (
+	errUnknownIssuer           = errors.New("token issuer is not trusted")
+	errAudienceNotAccepted     = errors.New("token audience is not accepted")
+	errSigningMethodNotAllowed = errors.New("token signing method is not allowed for its issuer")
+)
+
+type issuer struct {
+	keys       keyfunc.Keyfunc
+	audiences  []string
+	algorithms []string
+}
+
+// keySet is the set of issuers trusted by a Verifier, replaced as a whole when the configuration changes.
+type keySet struct {
+	issuers map[string]*issuer
+	// anyIssuer verifies tokens without checking iss or aud, configured through jwksUrl without jwksIssuer
+	anyIssuer *issuer
+	methods   []jwtv5.SigningMethod
+	// cancel stops the JWKS refresh of the issuers
+	cancel context.CancelFunc
+}
+
+// Verifier resolves the key used to verify a JWT from the JWKS of the issuer named in its iss claim, and rejects
+// tokens whose audience or signing algorithm that issuer does not allow. JWKS are refreshed in the background;
+// when an issuer is unreachable the keys from its last successful fetch remain in use, and tokens from other
+// issuers are unaffected.
+type Verifier struct {
+	ctx  context.Context
+	log  *log.Helper
+	keys atomic.Pointer[keySet]
+}
+
+// NewVerifier fetches the JWKS of every configured issuer. Unreachable issuers do not fail startup; their keys are
+// fetched on the next refresh. The refresh goroutines stop when ctx is done.
+func NewVerifier(ctx context.Context, c *conf.Server_Auth, logger log.Logger) (*Verifier, error) {
+	v := &Verifier{ctx: ctx, log: log.NewHelper(logger)}
+	if err := v.Update(c); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Update replaces the trusted issuers with those configured in c. Tokens in flight are verified against either the
+// previous or the new issuers, never a mix. On error the current issuers stay in effect.
+func (v *Verifier) Update(c *conf.Server_Auth) error {
+	ctx, cancel := context.WithCancel(v.ctx)
+	keys, err := v.newKeySet(ctx, c)
+	if err != nil {
+		cancel()
+		return err
+	}
+	keys.cancel = cancel
+	if previous := v.keys.Swap(keys); previous != nil {
+		previous.cancel()
+	}
+	return nil
+}
+
+func (v *Verifier) newKeySet(ctx context.Context, c *conf.Server_Auth) (*keySet, error) {
+	refreshInterval := defaultJwksRefreshInterval
+	if c.GetJwksRefreshInterval() != nil {
+		refreshInterval = c.GetJwksRefreshInterval().AsDuration()
+	}
+
+	newIssuer := func(jwksURL string, audiences, algorithms []string) (*issuer, error) {
+		if len(algorithms) == 0 {
+			algorithms = []string{jwtv5.SigningMethodRS256.Alg()}
+		}
+		for _, alg := range algorithms {
+			if err := validateAlgorithm(alg); err != nil {
+				return nil, err
+			}
+		}
+		keys, err := keyfunc.NewDefaultOverrideCtx(ctx, []string{jwksURL}, keyfunc.Override{
+			RefreshInterval: refreshInterval,
+			RefreshErrorHandlerFunc: func(u string) func(ctx context.Context, err error) {
+				return func(ctx context.Context, err error) {
+					v.log.WithContext(ctx).Warnf("failed to refresh JWKS from %s, keys from the last successful fetch remain in use: %v", u, err)
+				}
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating JWKS client for %s: %w", jwksURL, err)
+		}
+		return &issuer{keys: keys, audiences: audiences, algorithms: algorithms}, nil
+	}
+
+	k := &keySet{issuers: make(map[string]*issuer)}
+	issuers := c.GetIssuers()
+	if len(issuers) == 0 {
+		if c.GetJwksUrl() == "" {
+			return nil, fmt.Errorf("auth is enabled but neither jwksUrl nor issuers are configured")
+		}
+		if c.GetJwksIssuer() == "" {
+			if len(c.GetJwksAudiences()) > 0 {
+				return nil, fmt.Errorf("auth jwksAudiences require jwksIssuer")
+			}
+			v.log.Warn("auth jwksUrl accepts tokens of any issuer and audience, set jwksIssuer and jwksAudiences or configure issuers")
+			is, err := newIssuer(c.GetJwksUrl(), nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			k.anyIssuer = is
+			k.addMethods(is.algorithms)
+			return k, nil
+		}
+		if len(c.GetJwksAudiences()) == 0 {
+			return nil, fmt.Errorf("auth jwksIssuer requires jwksAudiences")
+		}
+		issuers = []*conf.Server_Auth_Issuer{{Issuer: c.GetJwksIssuer(), JwksUrl: c.GetJwksUrl(), Audiences: c.GetJwksAudiences()}}
+	}
+
+	for _, ic := range issuers {
+		if ic.GetIssuer() == "" || ic.GetJwksUrl() == "" {
+			return nil, fmt.Errorf("auth issuers require both issuer and jwksUrl")
+		}
+		if _, ok := k.issuers[ic.GetIssuer()]; ok {
+			return nil, fmt.Errorf("auth issuer %s is configured more than once", ic.GetIssuer())
+		}
+		is, err := newIssuer(ic.GetJwksUrl(), ic.GetAudiences(), ic.GetAlgorithms())
+		if err != nil {
+			return nil, err
+		}
+		k.issuers[ic.GetIssuer()] = is
+		k.addMethods(is.algorithms)
+	}
+	return k, nil
+}
+
+// validateAlgorithm accepts the asymmetric algorithms that can be verified with keys published in a JWKS.
+func validateAlgorithm(alg string) error {
+	switch jwtv5.GetSigningMethod(alg).(type) {
+	case *jwtv5.SigningMethodRSA, *jwtv5.SigningMethodRSAPSS, *jwtv5.SigningMethodECDSA, *jwtv5.SigningMethodEd25519:
+		return nil
+	}
+	return fmt.Errorf("unsupported JWT signing algorithm %q", alg)
+}
+
+func (k *keySet) addMethods(algorithms []string) {
+	for _, alg := range algorithms {
+		method := jwtv5.GetSigningMethod(alg)
+		if !slices.Contains(k.methods, method) {
+			k.methods = append(k.methods, method)
+		}
+	}
+}
+
+// Ready returns an error naming the issuers for which no keys have been fetched yet.
+func (v *Verifier) Ready(ctx context.Context) error {
+	keys := v.keys.Load()
+	var missing []string
+	if keys.anyIssuer != nil && !keys.anyIssuer.hasKeys(ctx) {
+		missing = append(missing, "jwksUrl")
+	}
+	for name, is := range keys.issuers {
+		if !is.hasKeys(ctx) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("no JWKS keys loaded for %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (i *issuer) hasKeys(ctx context.Context) bool {
+	jwks, err := i.keys.Storage().KeyReadAll(ctx)
+	return err == nil && len(jwks) > 0
+}
+
+// SigningMethods returns every signing method allowed by at least one issuer.
+func (v *Verifier) SigningMethods() []jwtv5.SigningMethod {
+	return v.keys.Load().methods
+}
+
+// Keyfunc is a jwt.Keyfunc returning the verification key for token. The claims inspected here are not yet
+// verified, but the token is rejected by the parser unless its signature matches the returned key.
+func (v *Verifier) Keyfunc(token *jwtv5.Token) (any, error) {
+	keys := v.keys.Load()
+	is := keys.anyIssuer
+	if is == nil {
+		iss, _ := token.Claims.GetIssuer()
+		var ok bool
+		if is, ok = keys.issuers[iss]; !ok {
+			return nil, errUnknownIssuer
+		}
+	}
+
+	if !slices.Contains(is.algorithms, token.Method.Alg()) {
+		return nil, errSigningMethodNotAllowed
+	}
+
+	if len(is.audiences) > 0 {
+		aud, _ := token.Claims.GetAudience()
+		if !slices.ContainsFunc(aud, func(a string) bool { return slices.Contains(is.audiences, a) }) {
+			return nil, errAudienceNotAccepted
+		}
+	}
+
+	return is.keys.Keyfunc(token)
+}
@@ -0,0 +1,296 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MicahParks/jwkset"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+type testIssuer struct {
+	kid    string
+	key    any
+	method jwtv5.SigningMethod
+	server *httptest.Server
+}
+
+// newTestIssuer serves a JWKS containing the public half of key.
+func newTestIssuer(t *testing.T, kid string, key any, method jwtv5.SigningMethod) *testIssuer {
+	t.Helper()
+
+	jwk, err := jwkset.NewJWKFromKey(key, jwkset.JWKOptions{Metadata: jwkset.JWKMetadataOptions{KID: kid}})
+	require.NoError(t, err)
+	store := jwkset.NewMemoryStorage()
+	require.NoError(t, store.KeyWrite(context.Background(), jwk))
+	raw, err := store.JSONPublic(context.Background())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(raw)
+	}))
+	t.Cleanup(server.Close)
+
+	return &testIssuer{kid: kid, key: key, method: method, server: server}
+}
+
+func (i *testIssuer) sign(t *testing.T, claims jwtv5.MapClaims) string {
+	t.Helper()
+
+	token := jwtv5.NewWithClaims(i.method, claims)
+	token.Header["kid"] = i.kid
+	signed, err := token.SignedString(i.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newRSAIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return newTestIssuer(t, "rsa-key", key, jwtv5.SigningMethodRS256)
+}
+
+func newECIssuer(t *testing.T) *testIssuer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return newTestIssuer(t, "ec-key", key, jwtv5.SigningMethodES256)
+}
+
+func newTestVerifier(t *testing.T, c *conf.Server_Auth) *Verifier {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	v, err := NewVerifier(ctx, c, log.NewStdLogger(io.Discard))
+	require.NoError(t, err)
+	return v
+}
+
+func claimsFor(iss string, aud ...string) jwtv5.MapClaims {
+	return jwtv5.MapClaims{
+		"sub": "alice",
+		"iss": iss,
+		"aud": aud,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func parse(v *Verifier, token string) error {
+	_, err := jwtv5.Parse(token, v.Keyfunc)
+	return err
+}
+
+func TestVerifier_MultipleIssuers(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	ecIssuer := newECIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth: true,
+		Issuers: []*conf.Server_Auth_Issuer{
+			{Issuer: "https://sso.example.com", JwksUrl: rsaIssuer.server.URL, Audiences: []string{"relations"}},
+			{Issuer: "https://workload.example.com", JwksUrl: ecIssuer.server.URL, Algorithms: []string{"ES256"}},
+		},
+	})
+
+	assert.NoError(t, parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com", "relations"))))
+	assert.NoError(t, parse(v, ecIssuer.sign(t, claimsFor("https://workload.example.com"))))
+	assert.NoError(t, v.Ready(context.Background()))
+	assert.ElementsMatch(t, []jwtv5.SigningMethod{jwtv5.SigningMethodRS256, jwtv5.SigningMethodES256}, v.SigningMethods())
+}
+
+func TestVerifier_RejectsUnknownIssuer(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth: true,
+		Issuers:    []*conf.Server_Auth_Issuer{{Issuer: "https://sso.example.com", JwksUrl: rsaIssuer.server.URL}},
+	})
+
+	err := parse(v, rsaIssuer.sign(t, claimsFor("https://evil.example.com")))
+	assert.ErrorIs(t, err, errUnknownIssuer)
+}
+
+func TestVerifier_RejectsUnexpectedAudience(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth: true,
+		Issuers: []*conf.Server_Auth_Issuer{
+			{Issuer: "https://sso.example.com", JwksUrl: rsaIssuer.server.URL, Audiences: []string{"relations"}},
+		},
+	})
+
+	assert.ErrorIs(t, parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com", "inventory"))), errAudienceNotAccepted)
+	assert.ErrorIs(t, parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com"))), errAudienceNotAccepted)
+}
+
+func TestVerifier_RejectsAlgorithmNotAllowedForIssuer(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth: true,
+		Issuers: []*conf.Server_Auth_Issuer{
+			{Issuer: "https://sso.example.com", JwksUrl: rsaIssuer.server.URL, Algorithms: []string{"ES256"}},
+		},
+	})
+
+	err := parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com")))
+	assert.ErrorIs(t, err, errSigningMethodNotAllowed)
+}
+
+func TestVerifier_SingleJwksUrlChecksConfiguredIssuer(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth:    true,
+		JwksUrl:       rsaIssuer.server.URL,
+		JwksIssuer:    "https://sso.example.com",
+		JwksAudiences: []string{"relations"},
+	})
+
+	assert.NoError(t, parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com", "relations"))))
+	assert.ErrorIs(t, parse(v, rsaIssuer.sign(t, claimsFor("https://anything.example.com", "relations"))), errUnknownIssuer)
+	assert.ErrorIs(t, parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com", "inventory"))), errAudienceNotAccepted)
+}
+
+func TestVerifier_SingleJwksUrlSkipsIssuerChecks(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{EnableAuth: true, JwksUrl: rsaIssuer.server.URL})
+
+	assert.NoError(t, parse(v, rsaIssuer.sign(t, claimsFor("https://anything.example.com"))))
+}
+
+func TestVerifier_UnreachableIssuerDoesNotFailStartup(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth:          true,
+		JwksRefreshInterval: durationpb.New(time.Minute),
+		Issuers: []*conf.Server_Auth_Issuer{
+			{Issuer: "https://sso.example.com", JwksUrl: rsaIssuer.server.URL},
+			{Issuer: "https://down.example.com", JwksUrl: down.URL},
+		},
+	})
+
+	assert.NoError(t, parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com"))))
+	assert.Error(t, parse(v, rsaIssuer.sign(t, claimsFor("https://down.example.com"))))
+	assert.EqualError(t, v.Ready(context.Background()), "no JWKS keys loaded for https://down.example.com")
+}
+
+func TestVerifier_UpdateReplacesIssuers(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	ecIssuer := newECIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth: true,
+		Issuers:    []*conf.Server_Auth_Issuer{{Issuer: "https://sso.example.com", JwksUrl: rsaIssuer.server.URL}},
+	})
+
+	require.NoError(t, v.Update(&conf.Server_Auth{
+		EnableAuth: true,
+		Issuers: []*conf.Server_Auth_Issuer{
+			{Issuer: "https://workload.example.com", JwksUrl: ecIssuer.server.URL, Algorithms: []string{"ES256"}},
+		},
+	}))
+
+	assert.ErrorIs(t, parse(v, rsaIssuer.sign(t, claimsFor("https://sso.example.com"))), errUnknownIssuer)
+	assert.NoError(t, parse(v, ecIssuer.sign(t, claimsFor("https://workload.example.com"))))
+	assert.Equal(t, []jwtv5.SigningMethod{jwtv5.SigningMethodES256}, v.SigningMethods())
+
+	// an invalid configuration keeps the current issuers
+	assert.Error(t, v.Update(&conf.Server_Auth{EnableAuth: true}))
+	assert.NoError(t, parse(v, ecIssuer.sign(t, claimsFor("https://workload.example.com"))))
+}
+
+func TestNewVerifier_RejectsInvalidConfiguration(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	for name, c := range map[string]*conf.Server_Auth{
+		"no issuers":        {EnableAuth: true},
+		"missing jwks url":  {EnableAuth: true, Issuers: []*conf.Server_Auth_Issuer{{Issuer: "https://sso.example.com"}}},
+		"symmetric alg":     {EnableAuth: true, JwksUrl: rsaIssuer.server.URL, Issuers: []*conf.Server_Auth_Issuer{{Issuer: "a", JwksUrl: rsaIssuer.server.URL, Algorithms: []string{"HS256"}}}},
+		"duplicate issuers": {EnableAuth: true, Issuers: []*conf.Server_Auth_Issuer{{Issuer: "a", JwksUrl: rsaIssuer.server.URL}, {Issuer: "a", JwksUrl: rsaIssuer.server.URL}}},
+		"jwks issuer alone": {EnableAuth: true, JwksUrl: rsaIssuer.server.URL, JwksIssuer: "a"},
+		"jwks audiences":    {EnableAuth: true, JwksUrl: rsaIssuer.server.URL, JwksAudiences: []string{"relations"}},
+	} {
+		_, err := NewVerifier(context.Background(), c, log.NewStdLogger(io.Discard))
+		assert.Error(t, err, name)
+	}
+}
+
+type headerTransporter struct {
+	mockTransporter
+	header headerCarrier
+}
+
+func (h *headerTransporter) RequestHeader() transport.Header { return h.header }
+
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string      { return h[key] }
+func (h headerCarrier) Set(key, value string)      { h[key] = value }
+func (h headerCarrier) Add(key, value string)      { h[key] = value }
+func (h headerCarrier) Keys() []string             { return nil }
+func (h headerCarrier) Values(key string) []string { return []string{h[key]} }
+
+func TestServer_AcceptsAnyConfiguredSigningMethod(t *testing.T) {
+	t.Parallel()
+
+	rsaIssuer := newRSAIssuer(t)
+	ecIssuer := newECIssuer(t)
+	v := newTestVerifier(t, &conf.Server_Auth{
+		EnableAuth: true,
+		Issuers: []*conf.Server_Auth_Issuer{
+			{Issuer: "rsa", JwksUrl: rsaIssuer.server.URL},
+			{Issuer: "ec", JwksUrl: ecIssuer.server.URL, Algorithms: []string{"ES256"}},
+		},
+	})
+	m := Server(v.Keyfunc, WithSigningMethods(v.SigningMethods()...))(func(ctx context.Context, req any) (any, error) {
+		return PrincipalFromContext(ctx), nil
+	})
+
+	for _, token := range []string{rsaIssuer.sign(t, claimsFor("rsa")), ecIssuer.sign(t, claimsFor("ec"))} {
+		tr := &headerTransporter{header: headerCarrier{authorizationKey: "Bearer " + token}}
+		principal, err := m(transport.NewServerContext(context.Background(), tr), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", principal)
+	}
+
+	tr := &headerTransporter{header: headerCarrier{}}
+	_, err := m(transport.NewServerContext(context.Background(), tr), nil)
+	assert.Equal(t, jwt.ErrMissingJwtToken, err)
+
+	tr = &headerTransporter{header: headerCarrier{authorizationKey: "Bearer " + rsaIssuer.sign(t, claimsFor("unknown"))}}
+	_, err = m(transport.NewServerContext(context.Background(), tr), nil)
+	assert.Equal(t, jwt.ErrTokenInvalid, err)
+}
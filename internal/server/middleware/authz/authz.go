@@ -0,0 +1,282 @@
+package authz
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	kratosMiddleware "github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+)
+
+const (
+	// Reason is the error reason returned to callers denied by policy.
+	Reason = "PERMISSION_DENIED"
+
+	wildcard = "*"
+)
+
+var (
+	errOperationNotAllowed = errors.Forbidden(Reason, "caller is not allowed to invoke this operation")
+	errNamespaceNotAllowed = errors.Forbidden(Reason, "caller is not allowed to access the requested namespace")
+)
+
+type policy struct {
+	subject    string
+	clientID   string
+	scopes     []string
+	roles      []string
+	operations []string
+	namespaces []string
+}
+
+// Authorizer decides which operations and resource namespaces an authenticated caller may use, based on the
+// policies configured in conf.Server.Auth. Callers matching no policy are denied.
+type Authorizer struct {
+	policies atomic.Pointer[[]policy]
+	auditor  *audit.Auditor
+}
+
+// NewAuthorizer creates an Authorizer from the auth configuration.
+func NewAuthorizer(c *conf.Server_Auth, auditor *audit.Auditor) *Authorizer {
+	a := &Authorizer{auditor: auditor}
+	a.Update(c)
+	return a
+}
+
+// Update replaces the policies with those configured in c. Requests in flight are evaluated against either the
+// previous or the new policies, never a mix.
+func (a *Authorizer) Update(c *conf.Server_Auth) {
+	policies := make([]policy, 0, len(c.GetPolicies()))
+	for _, p := range c.GetPolicies() {
+		policies = append(policies, policy{
+			subject:    p.GetSubject(),
+			clientID:   p.GetClientId(),
+			scopes:     p.GetScopes(),
+			roles:      p.GetRoles(),
+			operations: p.GetOperations(),
+			namespaces: p.GetNamespaces(),
+		})
+	}
+	a.policies.Store(&policies)
+}
+
+// namespaces returns the resource namespaces granted to the caller for the operation, and whether any policy
+// granted the operation at all.
+func (a *Authorizer) namespaces(ctx context.Context, operation string) ([]string, bool) {
+	principal := auth.PrincipalFromContext(ctx)
+	claims, _ := auth.ClaimsFromContext(ctx)
+
+	var granted []string
+	allowed := false
+	for _, p := range *a.policies.Load() {
+		if !p.matchesCaller(principal, claims) || !p.allowsOperation(operation) {
+			continue
+		}
+		allowed = true
+		granted = append(granted, p.namespaces...)
+	}
+	return granted, allowed
+}
+
+// matchesCaller matches the principal, which may come from a JWT or a client certificate, and the JWT claims of the
+// caller. Callers authenticated by certificate have no claims, so only policies selecting by subject match them.
+func (p *policy) matchesCaller(principal string, claims jwtv5.MapClaims) bool {
+	if p.subject != "" && p.subject != principal {
+		return false
+	}
+	if p.clientID != "" && p.clientID != clientID(claims) {
+		return false
+	}
+	if len(p.scopes) > 0 && !containsAny(scopes(claims), p.scopes) {
+		return false
+	}
+	if len(p.roles) > 0 && !containsAny(roles(claims), p.roles) {
+		return false
+	}
+	return true
+}
+
+func (p *policy) allowsOperation(operation string) bool {
+	for _, o := range p.operations {
+		if o == wildcard || o == operation {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(o, "/"+wildcard); ok && strings.HasPrefix(operation, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize checks the caller in ctx against the operation and the resource namespaces referenced by req, returning
+// the namespaces granted for the operation.
+func (a *Authorizer) authorize(ctx context.Context, operation string, req any) ([]string, error) {
+	granted, ok := a.namespaces(ctx, operation)
+	if !ok {
+		a.logDenied(ctx, operation, "operation_not_allowed")
+		return nil, errOperationNotAllowed
+	}
+	if reason := namespaceDenial(granted, req); reason != "" {
+		a.logDenied(ctx, operation, reason)
+		return nil, errNamespaceNotAllowed
+	}
+	return granted, nil
+}
+
+// namespaceDenial returns why granted does not cover the namespaces req addresses, or "" if it does.
+func namespaceDenial(granted []string, req any) string {
+	if slices.Contains(granted, wildcard) {
+		return ""
+	}
+	requested, scoped := Namespaces(req)
+	if !scoped {
+		return "unscoped_request"
+	}
+	for _, ns := range requested {
+		if !slices.Contains(granted, ns) {
+			return "namespace_not_allowed"
+		}
+	}
+	return ""
+}
+
+type grantedKey struct{}
+
+// AllowsNamespaces reports whether the namespaces granted to the caller of the request in ctx cover those req
+// addresses. Handlers use it for resources whose namespaces are only known once loaded. Without enforced policies
+// every namespace is allowed.
+func AllowsNamespaces(ctx context.Context, req any) bool {
+	granted, enforced := ctx.Value(grantedKey{}).([]string)
+	return !enforced || namespaceDenial(granted, req) == ""
+}
+
+// Authorization failure - SEC-MON-REQ-1 compliance (EOI-8 authorization_failure)
+func (a *Authorizer) logDenied(ctx context.Context, operation, reason string) {
+	recordDenied(ctx, a.auditor, operation, reason)
+}
+
+func recordDenied(ctx context.Context, auditor *audit.Auditor, operation, reason string) {
+	auditor.Record(ctx, audit.Event{
+		Message:      "Authorization denied",
+		Action:       "AUTHORIZE",
+		ResourceType: "api_endpoint",
+		ResourceID:   operation,
+		Outcome:      audit.OutcomeFailure,
+		Principal:    auth.PrincipalFromContext(ctx),
+		Reason:       reason,
+	})
+}
+
+// Server is a unary middleware enforcing the policies of a. It must run after authentication.
+func Server(a *Authorizer) kratosMiddleware.Middleware {
+	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			operation := ""
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+			}
+			granted, err := a.authorize(ctx, operation, req)
+			if err != nil {
+				return nil, err
+			}
+			return handler(context.WithValue(ctx, grantedKey{}, granted), req)
+		}
+	}
+}
+
+// StreamAuthzInterceptor is a gRPC stream server interceptor enforcing the policies of a. Every message received on
+// the stream is checked, so client-streaming RPCs cannot smuggle tuples for other namespaces after the first message.
+func StreamAuthzInterceptor(a *Authorizer) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		if _, ok := a.namespaces(ctx, info.FullMethod); !ok {
+			a.logDenied(ctx, info.FullMethod, "operation_not_allowed")
+			return errOperationNotAllowed
+		}
+		return handler(srv, &authzServerStream{ServerStream: ss, authorizer: a, operation: info.FullMethod})
+	}
+}
+
+type authzServerStream struct {
+	grpc.ServerStream
+	authorizer *Authorizer
+	operation  string
+}
+
+func (s *authzServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	_, err := s.authorizer.authorize(s.Context(), s.operation, m)
+	return err
+}
+
+func stringClaim(claims jwtv5.MapClaims, name string) string {
+	if v, ok := claims[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// clientID returns the OAuth client of the token, which identity providers expose as either client_id or azp.
+func clientID(claims jwtv5.MapClaims) string {
+	if id := stringClaim(claims, "client_id"); id != "" {
+		return id
+	}
+	return stringClaim(claims, "azp")
+}
+
+// scopes returns the OAuth scopes of the token, given either as a space separated `scope` or a `scp` list.
+func scopes(claims jwtv5.MapClaims) []string {
+	if scope := stringClaim(claims, "scope"); scope != "" {
+		return strings.Fields(scope)
+	}
+	return stringList(claims["scp"])
+}
+
+// roles returns the roles of the token, given either as a top-level `roles` list or Keycloak's realm_access.roles.
+func roles(claims jwtv5.MapClaims) []string {
+	if r := stringList(claims["roles"]); len(r) > 0 {
+		return r
+	}
+	if realm, ok := claims["realm_access"].(map[string]any); ok {
+		return stringList(realm["roles"])
+	}
+	return nil
+}
+
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		var out []string
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		return strings.Fields(list)
+	}
+	return nil
+}
+
+func containsAny(have, want []string) bool {
+	for _, w := range want {
+		if slices.Contains(have, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,309 @@
+package authz
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	kratosJwt "github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+)
+
+const (
+	createTuples     = "/kessel.relations.v1beta1.KesselTupleService/CreateTuples"
+	deleteTuples     = "/kessel.relations.v1beta1.KesselTupleService/DeleteTuples"
+	importBulkTuples = "/kessel.relations.v1beta1.KesselTupleService/ImportBulkTuples"
+	acquireLock      = "/kessel.relations.v1beta1.KesselTupleService/AcquireLock"
+	getMigration     = "/kessel.relations.v1beta1.KesselMigrationService/GetMigration"
+	check            = "/kessel.relations.v1beta1.KesselCheckService/Check"
+)
+
+// mockTransporter satisfies transport.Transporter for injecting operation names in tests.
+type mockTransporter struct {
+	operation string
+}
+
+func (m *mockTransporter) Kind() transport.Kind            { return transport.KindGRPC }
+func (m *mockTransporter) Endpoint() string                { return "" }
+func (m *mockTransporter) Operation() string               { return m.operation }
+func (m *mockTransporter) RequestHeader() transport.Header { return nil }
+func (m *mockTransporter) ReplyHeader() transport.Header   { return nil }
+
+func ctxFor(operation string, claims jwtv5.MapClaims) context.Context {
+	ctx := transport.NewServerContext(context.Background(), &mockTransporter{operation: operation})
+	return kratosJwt.NewContext(ctx, claims)
+}
+
+func okHandler(ctx context.Context, req any) (any, error) {
+	return "ok", nil
+}
+
+func newTestAuthorizer() *Authorizer {
+	return NewAuthorizer(&conf.Server_Auth{
+		EnableAuth:  true,
+		EnableAuthz: true,
+		Policies: []*conf.Server_Auth_Policy{
+			{
+				ClientId:   "notifications",
+				Operations: []string{"/kessel.relations.v1beta1.KesselTupleService/*"},
+				Namespaces: []string{"notifications"},
+			},
+			{
+				Roles:      []string{"relations-admin"},
+				Operations: []string{"*"},
+				Namespaces: []string{"*"},
+			},
+			{
+				Scopes:     []string{"relations:check"},
+				Operations: []string{check},
+				Namespaces: []string{"rbac", "notifications"},
+			},
+		},
+	}, audit.NewLogAuditor(log.NewStdLogger(io.Discard)))
+}
+
+func tuplesIn(namespaces ...string) *v1beta1.CreateTuplesRequest {
+	req := &v1beta1.CreateTuplesRequest{}
+	for _, ns := range namespaces {
+		req.Tuples = append(req.Tuples, &v1beta1.Relationship{
+			Resource: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: ns, Name: "integration"}, Id: "1"},
+			Relation: "owner",
+			Subject: &v1beta1.SubjectReference{
+				Subject: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "principal"}, Id: "u1"},
+			},
+		})
+	}
+	return req
+}
+
+func assertForbidden(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, int32(403), errors.FromError(err).Code)
+	assert.Equal(t, Reason, errors.FromError(err).Reason)
+}
+
+func TestServer_AllowsGrantedNamespace(t *testing.T) {
+	t.Parallel()
+
+	m := Server(newTestAuthorizer())(okHandler)
+	ctx := ctxFor(createTuples, jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"})
+
+	resp, err := m(ctx, tuplesIn("notifications"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestServer_DeniesOtherNamespace(t *testing.T) {
+	t.Parallel()
+
+	m := Server(newTestAuthorizer())(okHandler)
+	ctx := ctxFor(createTuples, jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"})
+
+	_, err := m(ctx, tuplesIn("notifications", "rbac"))
+	assertForbidden(t, err)
+}
+
+func TestServer_DeniesUnscopedDeleteWithoutWildcard(t *testing.T) {
+	t.Parallel()
+
+	m := Server(newTestAuthorizer())(okHandler)
+	ctx := ctxFor(deleteTuples, jwtv5.MapClaims{"sub": "svc", "azp": "notifications"})
+
+	_, err := m(ctx, &v1beta1.DeleteTuplesRequest{Filter: &v1beta1.RelationTupleFilter{}})
+	assertForbidden(t, err)
+
+	ns := "notifications"
+	_, err = m(ctx, &v1beta1.DeleteTuplesRequest{Filter: &v1beta1.RelationTupleFilter{ResourceNamespace: &ns}})
+	assert.NoError(t, err)
+}
+
+func TestServer_WildcardAllowsUnscopedDelete(t *testing.T) {
+	t.Parallel()
+
+	m := Server(newTestAuthorizer())(okHandler)
+	ctx := ctxFor(deleteTuples, jwtv5.MapClaims{"sub": "admin", "realm_access": map[string]any{"roles": []any{"relations-admin"}}})
+
+	_, err := m(ctx, &v1beta1.DeleteTuplesRequest{Filter: &v1beta1.RelationTupleFilter{}})
+	assert.NoError(t, err)
+}
+
+func TestServer_AllowsRequestsAddressingNoResources(t *testing.T) {
+	t.Parallel()
+
+	m := Server(newTestAuthorizer())(okHandler)
+	ctx := ctxFor(acquireLock, jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"})
+
+	_, err := m(ctx, &v1beta1.AcquireLockRequest{LockId: "notifications-import"})
+	assert.NoError(t, err)
+}
+
+func TestServer_DeniesUnknownRequestsWithoutWildcard(t *testing.T) {
+	t.Parallel()
+
+	a := NewAuthorizer(&conf.Server_Auth{
+		EnableAuth:  true,
+		EnableAuthz: true,
+		Policies: []*conf.Server_Auth_Policy{
+			{ClientId: "notifications", Operations: []string{"*"}, Namespaces: []string{"notifications"}},
+			{Roles: []string{"relations-admin"}, Operations: []string{"*"}, Namespaces: []string{"*"}},
+		},
+	}, audit.NewLogAuditor(log.NewStdLogger(io.Discard)))
+	m := Server(a)(okHandler)
+
+	ctx := ctxFor(getMigration, jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"})
+	_, err := m(ctx, struct{}{})
+	assertForbidden(t, err)
+
+	ctx = ctxFor(getMigration, jwtv5.MapClaims{"sub": "admin", "realm_access": map[string]any{"roles": []any{"relations-admin"}}})
+	_, err = m(ctx, struct{}{})
+	assert.NoError(t, err)
+}
+
+func TestAllowsNamespaces_ChecksNamespacesGrantedToTheRequest(t *testing.T) {
+	t.Parallel()
+
+	migrationIn := func(ns string) *v1beta1.StartMigrationRequest {
+		return &v1beta1.StartMigrationRequest{Spec: &v1beta1.MigrationSpec{
+			Operation: &v1beta1.MigrationSpec_RenameRelation{RenameRelation: &v1beta1.RenameRelation{
+				ResourceType: &v1beta1.ObjectType{Namespace: ns, Name: "integration"},
+			}},
+		}}
+	}
+	a := NewAuthorizer(&conf.Server_Auth{
+		EnableAuth:  true,
+		EnableAuthz: true,
+		Policies: []*conf.Server_Auth_Policy{
+			{ClientId: "notifications", Operations: []string{getMigration}, Namespaces: []string{"notifications"}},
+			{Roles: []string{"relations-admin"}, Operations: []string{"*"}, Namespaces: []string{"*"}},
+		},
+	}, audit.NewLogAuditor(log.NewStdLogger(io.Discard)))
+	var allowed []bool
+	m := Server(a)(func(ctx context.Context, req any) (any, error) {
+		allowed = []bool{AllowsNamespaces(ctx, migrationIn("notifications")), AllowsNamespaces(ctx, migrationIn("rbac"))}
+		return "ok", nil
+	})
+
+	_, err := m(ctxFor(getMigration, jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"}), &v1beta1.GetMigrationRequest{Id: "m1"})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, allowed)
+
+	_, err = m(ctxFor(getMigration, jwtv5.MapClaims{"sub": "admin", "realm_access": map[string]any{"roles": []any{"relations-admin"}}}), &v1beta1.GetMigrationRequest{Id: "m1"})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true}, allowed)
+
+	assert.True(t, AllowsNamespaces(context.Background(), migrationIn("rbac")), "no policies were enforced")
+}
+
+func TestServer_DeniesOperationNotGranted(t *testing.T) {
+	t.Parallel()
+
+	m := Server(newTestAuthorizer())(okHandler)
+
+	ctx := ctxFor(createTuples, jwtv5.MapClaims{"sub": "reader", "scope": "openid relations:check"})
+	_, err := m(ctx, tuplesIn("rbac"))
+	assertForbidden(t, err)
+
+	ctx = ctxFor(check, jwtv5.MapClaims{"sub": "reader", "scope": "openid relations:check"})
+	_, err = m(ctx, &v1beta1.CheckRequest{
+		Resource: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "workspace"}, Id: "w1"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestServer_DeniesCallerMatchingNoPolicy(t *testing.T) {
+	t.Parallel()
+
+	m := Server(newTestAuthorizer())(okHandler)
+	ctx := ctxFor(createTuples, jwtv5.MapClaims{"sub": "someone"})
+
+	_, err := m(ctx, tuplesIn("notifications"))
+	assertForbidden(t, err)
+}
+
+type dummyServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*v1beta1.ImportBulkTuplesRequest
+}
+
+func (d *dummyServerStream) Context() context.Context { return d.ctx }
+func (d *dummyServerStream) RecvMsg(m any) error {
+	next := d.msgs[0]
+	d.msgs = d.msgs[1:]
+	m.(*v1beta1.ImportBulkTuplesRequest).Tuples = next.Tuples
+	return nil
+}
+
+func TestAuthorizer_UpdateReplacesPolicies(t *testing.T) {
+	t.Parallel()
+
+	a := newTestAuthorizer()
+	m := Server(a)(okHandler)
+	ctx := ctxFor(createTuples, jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"})
+
+	_, err := m(ctx, tuplesIn("rbac"))
+	assertForbidden(t, err)
+
+	a.Update(&conf.Server_Auth{
+		EnableAuth:  true,
+		EnableAuthz: true,
+		Policies: []*conf.Server_Auth_Policy{
+			{ClientId: "notifications", Operations: []string{createTuples}, Namespaces: []string{"rbac"}},
+		},
+	})
+
+	_, err = m(ctx, tuplesIn("rbac"))
+	assert.NoError(t, err)
+	_, err = m(ctx, tuplesIn("notifications"))
+	assertForbidden(t, err)
+}
+
+func TestStreamAuthzInterceptor_ChecksEveryMessage(t *testing.T) {
+	t.Parallel()
+
+	interceptor := StreamAuthzInterceptor(newTestAuthorizer())
+	info := &grpc.StreamServerInfo{FullMethod: importBulkTuples}
+	stream := &dummyServerStream{
+		ctx: auth.NewContext(context.Background(), jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"}),
+		msgs: []*v1beta1.ImportBulkTuplesRequest{
+			{Tuples: tuplesIn("notifications").Tuples},
+			{Tuples: tuplesIn("rbac").Tuples},
+		},
+	}
+
+	err := interceptor(nil, stream, info, func(srv any, ss grpc.ServerStream) error {
+		if err := ss.RecvMsg(&v1beta1.ImportBulkTuplesRequest{}); err != nil {
+			return err
+		}
+		return ss.RecvMsg(&v1beta1.ImportBulkTuplesRequest{})
+	})
+	assertForbidden(t, err)
+}
+
+func TestStreamAuthzInterceptor_DeniesOperationNotGranted(t *testing.T) {
+	t.Parallel()
+
+	interceptor := StreamAuthzInterceptor(newTestAuthorizer())
+	info := &grpc.StreamServerInfo{FullMethod: importBulkTuples}
+	stream := &dummyServerStream{ctx: auth.NewContext(context.Background(), jwtv5.MapClaims{"sub": "someone"})}
+
+	called := false
+	err := interceptor(nil, stream, info, func(srv any, ss grpc.ServerStream) error {
+		called = true
+		return nil
+	})
+	assertForbidden(t, err)
+	assert.False(t, called)
+}
@@ -0,0 +1,60 @@
+package authz
+
+import (
+	"context"
+	"slices"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	kratosMiddleware "github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
+)
+
+// DebugOperation is the operation a policy must grant, by name or with the wildcard, for callers to request debug
+// traces of checks.
+const DebugOperation = "debug"
+
+var errDebugNotAllowed = errors.Forbidden(Reason, "caller is not allowed to request debug traces")
+
+// RequestsDebug reports whether req asks for the debug trace of a check.
+func RequestsDebug(req any) bool {
+	switch r := req.(type) {
+	case interface{ GetDebug() bool }:
+		return r.GetDebug()
+	case interface {
+		GetItems() []*v1beta1.CheckBulkRequestItem
+	}:
+		return slices.ContainsFunc(r.GetItems(), (*v1beta1.CheckBulkRequestItem).GetDebug)
+	}
+	return false
+}
+
+// DebugServer is a unary middleware denying requests for debug traces, which reveal the schema and tuples behind a
+// check, unless a grants the caller DebugOperation for every namespace the request addresses. Without an authorizer
+// no caller is privileged, so every request for a trace is denied. It must run after authentication.
+func DebugServer(a *Authorizer, auditor *audit.Auditor) kratosMiddleware.Middleware {
+	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			if !RequestsDebug(req) {
+				return handler(ctx, req)
+			}
+			reason := "debug_not_allowed"
+			if a != nil {
+				if granted, allowed := a.namespaces(ctx, DebugOperation); allowed {
+					reason = namespaceDenial(granted, req)
+				}
+			}
+			if reason != "" {
+				operation := ""
+				if tr, ok := transport.FromServerContext(ctx); ok {
+					operation = tr.Operation()
+				}
+				recordDenied(ctx, auditor, operation, reason)
+				return nil, errDebugNotAllowed
+			}
+			return handler(ctx, req)
+		}
+	}
+}
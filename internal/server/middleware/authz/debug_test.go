@@ -0,0 +1,101 @@
+package authz
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const checkBulk = "/kessel.relations.v1beta1.KesselCheckService/CheckBulk"
+
+func TestRequestsDebug(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, RequestsDebug(&v1beta1.CheckRequest{Debug: true}))
+	assert.False(t, RequestsDebug(&v1beta1.CheckRequest{}))
+	assert.True(t, RequestsDebug(&v1beta1.CheckBulkRequest{Items: []*v1beta1.CheckBulkRequestItem{{}, {Debug: true}}}))
+	assert.False(t, RequestsDebug(&v1beta1.CheckBulkRequest{Items: []*v1beta1.CheckBulkRequestItem{{}}}))
+	assert.True(t, RequestsDebug(&v1beta1.CheckForUpdateBulkRequest{Items: []*v1beta1.CheckBulkRequestItem{{Debug: true}}}))
+	assert.False(t, RequestsDebug(tuplesIn("rbac")))
+}
+
+func TestDebugServer_AllowsCallersGrantedDebug(t *testing.T) {
+	t.Parallel()
+
+	m := DebugServer(newTestAuthorizer(), audit.NewLogAuditor(log.NewStdLogger(io.Discard)))(okHandler)
+	ctx := ctxFor(check, jwtv5.MapClaims{"sub": "admin", "roles": []any{"relations-admin"}})
+
+	resp, err := m(ctx, &v1beta1.CheckRequest{Debug: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestDebugServer_DeniesOtherCallersAndAuditsIt(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	m := DebugServer(newTestAuthorizer(), audit.NewLogAuditor(log.NewStdLogger(&buf)))(okHandler)
+	ctx := ctxFor(checkBulk, jwtv5.MapClaims{"sub": "reader", "scope": "relations:check"})
+
+	_, err := m(ctx, &v1beta1.CheckBulkRequest{Items: []*v1beta1.CheckBulkRequestItem{{}, {Debug: true}}})
+	assertForbidden(t, err)
+	assert.Contains(t, buf.String(), "debug_not_allowed")
+	assert.Contains(t, buf.String(), checkBulk)
+
+	resp, err := m(ctx, &v1beta1.CheckBulkRequest{Items: []*v1beta1.CheckBulkRequestItem{{}}})
+	assert.NoError(t, err, "requests without debug are left to the operation policies")
+	assert.Equal(t, "ok", resp)
+}
+
+func TestDebugServer_ScopesDebugToGrantedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	auditor := audit.NewLogAuditor(log.NewStdLogger(&buf))
+	a := NewAuthorizer(&conf.Server_Auth{
+		EnableAuth:  true,
+		EnableAuthz: true,
+		Policies: []*conf.Server_Auth_Policy{{
+			ClientId:   "notifications",
+			Operations: []string{check, checkBulk, DebugOperation},
+			Namespaces: []string{"notifications"},
+		}},
+	}, auditor)
+	m := DebugServer(a, auditor)(okHandler)
+	ctx := ctxFor(checkBulk, jwtv5.MapClaims{"sub": "svc", "client_id": "notifications"})
+	item := func(ns string) *v1beta1.CheckBulkRequestItem {
+		return &v1beta1.CheckBulkRequestItem{
+			Resource: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: ns, Name: "integration"}, Id: "1"},
+			Debug:    true,
+		}
+	}
+
+	resp, err := m(ctx, &v1beta1.CheckBulkRequest{Items: []*v1beta1.CheckBulkRequestItem{item("notifications")}})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = m(ctx, &v1beta1.CheckBulkRequest{Items: []*v1beta1.CheckBulkRequestItem{item("notifications"), item("rbac")}})
+	assertForbidden(t, err)
+	assert.Contains(t, buf.String(), "namespace_not_allowed")
+}
+
+func TestDebugServer_DeniesEveryoneWithoutAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	m := DebugServer(nil, nil)(okHandler)
+	ctx := ctxFor(check, jwtv5.MapClaims{"sub": "admin", "roles": []any{"relations-admin"}})
+
+	_, err := m(ctx, &v1beta1.CheckRequest{Debug: true})
+	assertForbidden(t, err)
+
+	_, err = m(ctx, &v1beta1.CheckRequest{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,84 @@
+package authz
+
+import (
+	"slices"
+
+	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+// Namespaces returns the distinct resource namespaces a request addresses. The second result is false when the
+// request is not restricted to a namespace at all, e.g. a tuple filter without resource_namespace, which only
+// callers granted every namespace may send, and for request types not listed here. Requests that do not address
+// resources return no namespaces.
+func Namespaces(req any) ([]string, bool) {
+	var namespaces []string
+	add := func(ns string) {
+		if !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	switch r := req.(type) {
+	case *v1beta1.CreateTuplesRequest:
+		for _, t := range r.GetTuples() {
+			add(t.GetResource().GetType().GetNamespace())
+		}
+	case *v1beta1.ImportBulkTuplesRequest:
+		for _, t := range r.GetTuples() {
+			add(t.GetResource().GetType().GetNamespace())
+		}
+	case *v1beta1.ReadTuplesRequest:
+		if r.GetFilter().ResourceNamespace == nil {
+			return nil, false
+		}
+		add(r.GetFilter().GetResourceNamespace())
+	case *v1beta1.DeleteTuplesRequest:
+		if r.GetFilter().ResourceNamespace == nil {
+			return nil, false
+		}
+		add(r.GetFilter().GetResourceNamespace())
+	case *v1beta1.CheckRequest:
+		add(r.GetResource().GetType().GetNamespace())
+	case *v1beta1.CheckForUpdateRequest:
+		add(r.GetResource().GetType().GetNamespace())
+	case *v1beta1.CheckBulkRequest:
+		for _, item := range r.GetItems() {
+			add(item.GetResource().GetType().GetNamespace())
+		}
+	case *v1beta1.CheckForUpdateBulkRequest:
+		for _, item := range r.GetItems() {
+			add(item.GetResource().GetType().GetNamespace())
+		}
+	case *v1beta1.LookupSubjectsRequest:
+		add(r.GetResource().GetType().GetNamespace())
+	case *v1beta1.LookupResourcesRequest:
+		add(r.GetResourceType().GetNamespace())
+	case *v1beta1.StartMigrationRequest:
+		spec := r.GetSpec()
+		switch {
+		case spec.GetRenameRelation() != nil:
+			add(spec.GetRenameRelation().GetResourceType().GetNamespace())
+		case spec.GetRetypeSubject() != nil:
+			add(spec.GetRetypeSubject().GetResourceType().GetNamespace())
+		case spec.GetRewriteTuples() != nil:
+			rewrite := spec.GetRewriteTuples()
+			if rewrite.GetFilter().ResourceNamespace == nil {
+				return nil, false
+			}
+			add(rewrite.GetFilter().GetResourceNamespace())
+			if rewrite.ResourceType != nil {
+				add(rewrite.GetResourceType().GetNamespace())
+			}
+		}
+	case *v1beta1.GetMigrationRequest:
+		// the namespaces of the migration read are only known to the handler, which checks them with AllowsNamespaces
+	case *v1beta1.DiffSchemaRequest:
+		// the schema and the tuples counted span every namespace
+		return nil, false
+	case *v1beta1.AcquireLockRequest:
+		// locks guard imports, not resources
+	default:
+		return nil, false
+	}
+	return namespaces, true
+}
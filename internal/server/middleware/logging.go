@@ -1,16 +1,59 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
+	kratosMiddleware "github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
-func StreamLogInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
+// LoggingMiddleware logs every request as the Kratos logging middleware does, with its arguments redacted by r.
+func LoggingMiddleware(logger log.Logger, r *Redactor) kratosMiddleware.Middleware {
+	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var (
+				kind      string
+				operation string
+				reason    string
+			)
+			code := int32(status.FromGRPCCode(codes.OK))
+			startTime := time.Now()
+			if info, ok := transport.FromServerContext(ctx); ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+			}
+			reply, err := handler(ctx, req)
+			if se := errors.FromError(err); se != nil {
+				code = se.Code
+				reason = se.Reason
+			}
+			level, stack := extractError(err)
+
+			log.NewHelper(log.WithContext(ctx, logger)).Log(level,
+				"kind", "server",
+				"component", kind,
+				"operation", operation,
+				"args", r.Args(req),
+				"code", code,
+				"reason", reason,
+				"stack", stack,
+				"latency", time.Since(startTime).Seconds())
+
+			return reply, err
+		}
+	}
+}
+
+// StreamLogInterceptor logs every stream with its first request redacted by r.
+func StreamLogInterceptor(logger log.Logger, r *Redactor) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		var (
 			code      int32
@@ -35,7 +78,7 @@ func StreamLogInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
 			"kind", kind,
 			"component", kind,
 			"operation", operation,
-			"args", extractArgs(wrapper.req),
+			"args", r.Args(wrapper.req),
 			"code", code,
 			"reason", reason,
 			"stack", stack,
@@ -0,0 +1,261 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	kratosMiddleware "github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+)
+
+const (
+	// Reason is the error reason returned to throttled callers.
+	Reason = "RATE_LIMITED"
+
+	// retryAfterKey is the reply header (HTTP) and header metadata key (gRPC) carrying the suggested back-off in seconds.
+	retryAfterKey = "retry-after"
+
+	// ThrottledCounterName is the name of the counter incremented for every rejected request.
+	ThrottledCounterName = "kessel_relations_throttled_requests"
+
+	// idleTimeout is how long a principal/operation bucket may go unused before it is evicted.
+	idleTimeout = 10 * time.Minute
+
+	// unauthenticatedPrincipal prefixes the peer address unauthenticated callers are keyed by.
+	unauthenticatedPrincipal = "anonymous"
+)
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type rule struct {
+	operation string
+	principal string
+	limit     rate.Limit
+	burst     int
+}
+
+// Limiter keeps one token bucket per principal and operation.
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	rules     []rule
+	limit     rate.Limit
+	burst     int
+	lastSweep time.Time
+	now       func() time.Time
+	throttled metric.Int64Counter
+	auditor   *audit.Auditor
+}
+
+type Option func(*Limiter)
+
+// WithThrottledCounter records every rejected request on the given counter.
+func WithThrottledCounter(c metric.Int64Counter) Option {
+	return func(l *Limiter) {
+		l.throttled = c
+	}
+}
+
+// NewLimiter creates a Limiter from the rate limit configuration.
+func NewLimiter(c *conf.Server_RateLimit, auditor *audit.Auditor, opts ...Option) *Limiter {
+	l := &Limiter{
+		buckets: make(map[string]*bucket),
+		limit:   limitOf(c.GetRequestsPerSecond()),
+		burst:   burstOf(c.GetRequestsPerSecond(), c.GetBurst()),
+		now:     time.Now,
+		auditor: auditor,
+	}
+	for _, r := range c.GetRules() {
+		l.rules = append(l.rules, rule{
+			operation: r.GetOperation(),
+			principal: r.GetPrincipal(),
+			limit:     limitOf(r.GetRequestsPerSecond()),
+			burst:     burstOf(r.GetRequestsPerSecond(), r.GetBurst()),
+		})
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// limitOf converts a configured rate, where 0 means unlimited, into a token bucket limit.
+func limitOf(requestsPerSecond float64) rate.Limit {
+	if requestsPerSecond <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(requestsPerSecond)
+}
+
+// burstOf returns the configured burst, or one second of requests if it is unset. A burst of 0 would reject every
+// request of a limited bucket.
+func burstOf(requestsPerSecond float64, burst uint32) int {
+	if burst == 0 {
+		return int(math.Max(1, math.Ceil(requestsPerSecond)))
+	}
+	return int(burst)
+}
+
+// NewThrottledCounter creates the counter used to export throttling metrics.
+func NewThrottledCounter(meter metric.Meter) (metric.Int64Counter, error) {
+	return meter.Int64Counter(
+		ThrottledCounterName,
+		metric.WithUnit("{call}"),
+		metric.WithDescription("The total number of requests rejected by rate limiting"),
+	)
+}
+
+// allow takes a token from the bucket of the given principal and operation. When the bucket is empty it returns
+// false and the time after which a retry may succeed.
+func (l *Limiter) allow(principal, operation string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	key := principal + "|" + operation
+	b, ok := l.buckets[key]
+	if !ok {
+		limit, burst := l.limitFor(principal, operation)
+		b = &bucket{limiter: rate.NewLimiter(limit, burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return false, time.Second
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+func (l *Limiter) limitFor(principal, operation string) (rate.Limit, int) {
+	for _, r := range l.rules {
+		if (r.operation == "" || r.operation == operation) && (r.principal == "" || r.principal == principal) {
+			return r.limit, r.burst
+		}
+	}
+	return l.limit, l.burst
+}
+
+// sweep evicts idle buckets so memory stays bounded by the number of active callers. Must be called with mu held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTimeout {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// check applies the limit for the caller in ctx, returning a RESOURCE_EXHAUSTED (HTTP 429) error when throttled.
+func (l *Limiter) check(ctx context.Context, operation string) (time.Duration, error) {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == "" {
+		principal = unauthenticatedPrincipal + ":" + peerHost(ctx)
+	}
+
+	ok, retryAfter := l.allow(principal, operation)
+	if ok {
+		return 0, nil
+	}
+
+	if l.throttled != nil {
+		l.throttled.Add(ctx, 1, metric.WithAttributes(attribute.String("operation", operation)))
+	}
+	l.auditor.Record(ctx, audit.Event{
+		Message:      "Request rate limited",
+		Action:       "THROTTLE",
+		ResourceType: "api_endpoint",
+		ResourceID:   operation,
+		Outcome:      audit.OutcomeFailure,
+		Principal:    principal,
+		Reason:       "rate_limited",
+	})
+
+	seconds := retryAfterSeconds(retryAfter)
+	return retryAfter, errors.New(429, Reason, "rate limit exceeded, retry later").
+		WithMetadata(map[string]string{retryAfterKey: seconds})
+}
+
+// Server is a unary middleware enforcing the rate limits of l.
+func Server(l *Limiter) kratosMiddleware.Middleware {
+	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			operation := ""
+			tr, hasTransport := transport.FromServerContext(ctx)
+			if hasTransport {
+				operation = tr.Operation()
+			}
+			if retryAfter, err := l.check(ctx, operation); err != nil {
+				if hasTransport {
+					tr.ReplyHeader().Set(retryAfterKey, retryAfterSeconds(retryAfter))
+				}
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// StreamRateLimitInterceptor is a gRPC stream server interceptor enforcing the rate limits of l once per stream.
+func StreamRateLimitInterceptor(l *Limiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if retryAfter, err := l.check(ss.Context(), info.FullMethod); err != nil {
+			_ = ss.SetHeader(metadata.Pairs(retryAfterKey, retryAfterSeconds(retryAfter)))
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// peerHost returns the host unauthenticated callers are keyed by, so they do not share one bucket. The port is
+// dropped as every new connection gets its own.
+func peerHost(ctx context.Context) string {
+	addr := ""
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if ht, ok := tr.(http.Transporter); ok {
+			addr = ht.Request().RemoteAddr
+		}
+	}
+	if addr == "" {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			addr = p.Addr.String()
+		}
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(d.Seconds()))))
+}
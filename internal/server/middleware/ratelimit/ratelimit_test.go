@@ -0,0 +1,268 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	kratosJwt "github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string      { return h[key] }
+func (h headerCarrier) Set(key, value string)      { h[key] = value }
+func (h headerCarrier) Add(key, value string)      { h[key] = value }
+func (h headerCarrier) Keys() []string             { return nil }
+func (h headerCarrier) Values(key string) []string { return []string{h[key]} }
+
+// mockTransporter satisfies transport.Transporter for injecting operation names in tests.
+type mockTransporter struct {
+	operation   string
+	replyHeader headerCarrier
+}
+
+func (m *mockTransporter) Kind() transport.Kind            { return transport.KindGRPC }
+func (m *mockTransporter) Endpoint() string                { return "" }
+func (m *mockTransporter) Operation() string               { return m.operation }
+func (m *mockTransporter) RequestHeader() transport.Header { return nil }
+func (m *mockTransporter) ReplyHeader() transport.Header   { return m.replyHeader }
+
+func ctxFor(operation, principal string) (context.Context, *mockTransporter) {
+	tr := &mockTransporter{operation: operation, replyHeader: headerCarrier{}}
+	ctx := transport.NewServerContext(context.Background(), tr)
+	if principal != "" {
+		ctx = kratosJwt.NewContext(ctx, jwtv5.MapClaims{"sub": principal})
+	}
+	return ctx, tr
+}
+
+func okHandler(ctx context.Context, req any) (any, error) {
+	return "ok", nil
+}
+
+func newTestLimiter(c *conf.Server_RateLimit) *Limiter {
+	l := NewLimiter(c, audit.NewLogAuditor(log.NewStdLogger(io.Discard)))
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	return l
+}
+
+func TestServer_ThrottlesAfterBurst(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{RequestsPerSecond: 1, Burst: 2})
+	m := Server(l)(okHandler)
+
+	for i := 0; i < 2; i++ {
+		ctx, _ := ctxFor("/kessel.relations.v1beta1.KesselCheckService/CheckBulk", "alice")
+		resp, err := m(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	}
+
+	ctx, tr := ctxFor("/kessel.relations.v1beta1.KesselCheckService/CheckBulk", "alice")
+	_, err := m(ctx, nil)
+	require.Error(t, err)
+
+	se := errors.FromError(err)
+	assert.Equal(t, int32(429), se.Code)
+	assert.Equal(t, Reason, se.Reason)
+	assert.Equal(t, "1", se.Metadata[retryAfterKey])
+	assert.Equal(t, "1", tr.replyHeader.Get(retryAfterKey))
+}
+
+func TestServer_BucketsArePerPrincipalAndOperation(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{RequestsPerSecond: 1, Burst: 1})
+	m := Server(l)(okHandler)
+
+	ctx, _ := ctxFor("/op/A", "alice")
+	_, err := m(ctx, nil)
+	assert.NoError(t, err)
+
+	ctx, _ = ctxFor("/op/A", "bob")
+	_, err = m(ctx, nil)
+	assert.NoError(t, err, "a different principal has its own bucket")
+
+	ctx, _ = ctxFor("/op/B", "alice")
+	_, err = m(ctx, nil)
+	assert.NoError(t, err, "a different operation has its own bucket")
+
+	ctx, _ = ctxFor("/op/A", "alice")
+	_, err = m(ctx, nil)
+	assert.Error(t, err)
+}
+
+func TestServer_RulesOverrideDefault(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{
+		RequestsPerSecond: 100,
+		Burst:             100,
+		Rules: []*conf.Server_RateLimit_Rule{
+			{Operation: "/op/Import", Principal: "noisy", RequestsPerSecond: 1, Burst: 1},
+		},
+	})
+	m := Server(l)(okHandler)
+
+	ctx, _ := ctxFor("/op/Import", "noisy")
+	_, err := m(ctx, nil)
+	assert.NoError(t, err)
+	_, err = m(ctx, nil)
+	assert.Error(t, err)
+
+	ctx, _ = ctxFor("/op/Import", "quiet")
+	for i := 0; i < 10; i++ {
+		_, err = m(ctx, nil)
+		assert.NoError(t, err)
+	}
+}
+
+func TestServer_UnauthenticatedCallersAreKeyedByPeer(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{RequestsPerSecond: 1, Burst: 1})
+	m := Server(l)(okHandler)
+	fromPeer := func(ip string, port int) context.Context {
+		ctx, _ := ctxFor("/op/A", "")
+		return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: port}})
+	}
+
+	_, err := m(fromPeer("10.0.0.1", 40000), nil)
+	assert.NoError(t, err)
+	_, err = m(fromPeer("10.0.0.2", 40000), nil)
+	assert.NoError(t, err, "another peer has its own bucket")
+	_, err = m(fromPeer("10.0.0.1", 40001), nil)
+	assert.Error(t, err, "a new connection from the same host shares its bucket")
+}
+
+func TestServer_ZeroRateIsUnlimited(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		Rules: []*conf.Server_RateLimit_Rule{
+			{Principal: "batch"},
+		},
+	})
+	m := Server(l)(okHandler)
+
+	ctx, _ := ctxFor("/op/A", "batch")
+	for i := 0; i < 10; i++ {
+		_, err := m(ctx, nil)
+		assert.NoError(t, err)
+	}
+
+	l = newTestLimiter(&conf.Server_RateLimit{})
+	m = Server(l)(okHandler)
+	ctx, _ = ctxFor("/op/A", "alice")
+	for i := 0; i < 10; i++ {
+		_, err := m(ctx, nil)
+		assert.NoError(t, err)
+	}
+}
+
+func TestServer_RefillsOverTime(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{RequestsPerSecond: 1, Burst: 1})
+	m := Server(l)(okHandler)
+	start := l.now()
+
+	ctx, _ := ctxFor("/op/A", "alice")
+	_, err := m(ctx, nil)
+	assert.NoError(t, err)
+	_, err = m(ctx, nil)
+	assert.Error(t, err)
+
+	l.now = func() time.Time { return start.Add(time.Second) }
+	_, err = m(ctx, nil)
+	assert.NoError(t, err)
+}
+
+func TestServer_UnsetBurstAllowsOneSecondOfRequests(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{
+		RequestsPerSecond: 0.5,
+		Rules:             []*conf.Server_RateLimit_Rule{{Principal: "batch", RequestsPerSecond: 2.5}},
+	})
+	m := Server(l)(okHandler)
+
+	ctx, _ := ctxFor("/op/A", "alice")
+	_, err := m(ctx, nil)
+	assert.NoError(t, err, "a rate below one still allows a request")
+	_, err = m(ctx, nil)
+	assert.Error(t, err)
+
+	ctx, _ = ctxFor("/op/A", "batch")
+	for i := 0; i < 3; i++ {
+		_, err := m(ctx, nil)
+		assert.NoError(t, err)
+	}
+	_, err = m(ctx, nil)
+	assert.Error(t, err)
+}
+
+func TestLimiter_EvictsIdleBuckets(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{RequestsPerSecond: 1, Burst: 1})
+	start := l.now()
+
+	l.allow("alice", "/op/A")
+	l.allow("bob", "/op/A")
+	assert.Len(t, l.buckets, 2)
+
+	l.now = func() time.Time { return start.Add(2 * idleTimeout) }
+	l.allow("carol", "/op/A")
+	assert.Len(t, l.buckets, 1)
+}
+
+type dummyServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (d *dummyServerStream) Context() context.Context { return d.ctx }
+func (d *dummyServerStream) SetHeader(md metadata.MD) error {
+	d.header = metadata.Join(d.header, md)
+	return nil
+}
+
+func TestStreamRateLimitInterceptor(t *testing.T) {
+	t.Parallel()
+
+	l := newTestLimiter(&conf.Server_RateLimit{RequestsPerSecond: 1, Burst: 1})
+	interceptor := StreamRateLimitInterceptor(l)
+	info := &grpc.StreamServerInfo{FullMethod: "/kessel.relations.v1beta1.KesselTupleService/ImportBulkTuples"}
+	handler := func(srv any, stream grpc.ServerStream) error { return nil }
+
+	ctx, _ := ctxFor(info.FullMethod, "alice")
+	stream := &dummyServerStream{ctx: ctx}
+
+	assert.NoError(t, interceptor(nil, stream, info, handler))
+
+	err := interceptor(nil, stream, info, handler)
+	require.Error(t, err)
+	assert.Equal(t, int32(429), errors.FromError(err).Code)
+	assert.Equal(t, []string{"1"}, stream.header.Get(retryAfterKey))
+}
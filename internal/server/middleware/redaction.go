@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const redacted = "REDACTED"
+
+// Redactor renders requests for the application log with the fields its rules name masked, hashed or dropped. The
+// rules of a message apply wherever it appears in a request, so a rule on SubjectReference covers the subjects of
+// every request.
+type Redactor struct {
+	rules map[protoreflect.FullName][]redactionRule
+}
+
+type redactionRule struct {
+	path   []protoreflect.FieldDescriptor
+	action string
+	key    []byte // of the HMAC hashed values are replaced with
+}
+
+// NewRedactor creates a Redactor from the log redaction configuration, checking every rule names an existing field.
+// Hashing requires a key, so the digests of guessable values such as user ids cannot be reversed by hashing guesses.
+func NewRedactor(c *conf.Server_LogRedaction) (*Redactor, error) {
+	key, err := redactionHashKey(c)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[protoreflect.FullName][]redactionRule)
+	for _, rc := range c.GetRules() {
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(rc.GetMessage()))
+		if err != nil {
+			return nil, fmt.Errorf("unknown log redaction message %q", rc.GetMessage())
+		}
+		md, ok := desc.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("log redaction message %q is not a message", rc.GetMessage())
+		}
+		path, err := fieldPath(md, rc.GetField())
+		if err != nil {
+			return nil, err
+		}
+		switch rc.GetAction() {
+		case "mask", "hash":
+			if path[len(path)-1].Kind() != protoreflect.StringKind {
+				return nil, fmt.Errorf("log redaction field %s.%s is not a string and can only be dropped", md.FullName(), rc.GetField())
+			}
+			if rc.GetAction() == "hash" && len(key) == 0 {
+				return nil, errors.New("a log redaction hash key is required to hash fields, set hashKey or hashKeyFile")
+			}
+		case "drop":
+		default:
+			return nil, fmt.Errorf("unknown log redaction action %q, expected mask, hash or drop", rc.GetAction())
+		}
+		rules[md.FullName()] = append(rules[md.FullName()], redactionRule{path: path, action: rc.GetAction(), key: key})
+	}
+	return &Redactor{rules: rules}, nil
+}
+
+// redactionHashKey returns the configured hash key, hashKey taking precedence over hashKeyFile.
+func redactionHashKey(c *conf.Server_LogRedaction) ([]byte, error) {
+	if c.GetHashKey() != "" {
+		return []byte(c.GetHashKey()), nil
+	}
+	if c.GetHashKeyFile() == "" {
+		return nil, nil
+	}
+	key, err := os.ReadFile(c.GetHashKeyFile())
+	if err != nil {
+		return nil, fmt.Errorf("error loading log redaction hash key file: %w", err)
+	}
+	return []byte(strings.TrimSpace(string(key))), nil
+}
+
+// fieldPath resolves a dot separated path of fields from md.
+func fieldPath(md protoreflect.MessageDescriptor, field string) ([]protoreflect.FieldDescriptor, error) {
+	var path []protoreflect.FieldDescriptor
+	for _, name := range strings.Split(field, ".") {
+		if md == nil {
+			return nil, fmt.Errorf("log redaction field %q goes through a field that is not a message", field)
+		}
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, fmt.Errorf("unknown log redaction field %q of %s", name, md.FullName())
+		}
+		if fd.IsMap() {
+			return nil, fmt.Errorf("log redaction field %q of %s is a map, which is not supported", name, md.FullName())
+		}
+		path = append(path, fd)
+		md = fd.Message()
+	}
+	return path, nil
+}
+
+// Args returns the string logged for req, redacted if it is a protobuf message.
+func (r *Redactor) Args(req interface{}) string {
+	if m, ok := req.(proto.Message); ok && r != nil && len(r.rules) > 0 {
+		m = proto.Clone(m)
+		r.redact(m.ProtoReflect())
+		req = m
+	}
+	return extractArgs(req)
+}
+
+// redact applies the rules of m and of every message nested in it.
+func (r *Redactor) redact(m protoreflect.Message) {
+	for _, rule := range r.rules[m.Descriptor().FullName()] {
+		rule.apply(m, rule.path)
+	}
+	var nested []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if fd.Message() != nil {
+			nested = append(nested, fd)
+		}
+		return true
+	})
+	for _, fd := range nested {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				m.Mutable(fd).Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					r.redact(v.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			list := m.Mutable(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				r.redact(list.Get(i).Message())
+			}
+		default:
+			r.redact(m.Mutable(fd).Message())
+		}
+	}
+}
+
+// apply redacts the field at path from m, in every element of the repeated fields along it.
+func (r redactionRule) apply(m protoreflect.Message, path []protoreflect.FieldDescriptor) {
+	fd := path[0]
+	if !m.Has(fd) {
+		return
+	}
+	if len(path) > 1 {
+		if fd.IsList() {
+			list := m.Mutable(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				r.apply(list.Get(i).Message(), path[1:])
+			}
+			return
+		}
+		r.apply(m.Mutable(fd).Message(), path[1:])
+		return
+	}
+	switch {
+	case r.action == "drop":
+		m.Clear(fd)
+	case fd.IsList():
+		list := m.Mutable(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			list.Set(i, protoreflect.ValueOfString(r.value(list.Get(i).String())))
+		}
+	default:
+		m.Set(fd, protoreflect.ValueOfString(r.value(m.Get(fd).String())))
+	}
+}
+
+func (r redactionRule) value(v string) string {
+	if r.action == "hash" {
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(v))
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return redacted
+}
@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	_ "github.com/project-kessel/relations-api/api/kessel/relations/v1"
+	"github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+// identifiers are the fields holding the IDs of principals and resources.
+var identifiers = map[protoreflect.FullName]bool{
+	"kessel.relations.v1beta1.ObjectReference.id":              true,
+	"kessel.relations.v1beta1.RelationTupleFilter.resource_id": true,
+	"kessel.relations.v1beta1.SubjectFilter.subject_id":        true,
+}
+
+// identifierRules redact every identifier.
+var identifierRules = &conf.Server_LogRedaction{HashKey: "test-key", Rules: []*conf.Server_LogRedaction_Rule{
+	{Message: "kessel.relations.v1beta1.ObjectReference", Field: "id", Action: "hash"},
+	{Message: "kessel.relations.v1beta1.RelationTupleFilter", Field: "resource_id", Action: "mask"},
+	{Message: "kessel.relations.v1beta1.SubjectFilter", Field: "subject_id", Action: "mask"},
+}}
+
+// requestTypes returns the request message of every RPC of the API.
+func requestTypes() []protoreflect.MessageDescriptor {
+	var types []protoreflect.MessageDescriptor
+	for _, pkg := range []protoreflect.FullName{"kessel.relations.v1", "kessel.relations.v1beta1"} {
+		protoregistry.GlobalFiles.RangeFilesByPackage(pkg, func(fd protoreflect.FileDescriptor) bool {
+			for i := 0; i < fd.Services().Len(); i++ {
+				methods := fd.Services().Get(i).Methods()
+				for j := 0; j < methods.Len(); j++ {
+					types = append(types, methods.Get(j).Input())
+				}
+			}
+			return true
+		})
+	}
+	return types
+}
+
+// populate sets every field of m, the first of each oneof, to a distinct value. Identifiers are set to secret-<n>
+// and other strings to value-<n>.
+func populate(m protoreflect.Message, n *int, depth int) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if oneof := fd.ContainingOneof(); oneof != nil && m.WhichOneof(oneof) != nil {
+			continue
+		}
+		switch {
+		case fd.IsMap():
+			continue
+		case fd.IsList():
+			list := m.Mutable(fd).List()
+			for range 2 {
+				if v, ok := fieldValue(m, fd, list.NewElement(), n, depth); ok {
+					list.Append(v)
+				}
+			}
+		default:
+			if fd.Message() != nil {
+				if depth > 0 {
+					populate(m.Mutable(fd).Message(), n, depth-1)
+				}
+				continue
+			}
+			if v, ok := fieldValue(m, fd, m.NewField(fd), n, depth); ok {
+				m.Set(fd, v)
+			}
+		}
+	}
+}
+
+func fieldValue(m protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Value, n *int, depth int) (protoreflect.Value, bool) {
+	*n++
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if depth == 0 {
+			return v, false
+		}
+		populate(v.Message(), n, depth-1)
+		return v, true
+	case protoreflect.StringKind:
+		if identifiers[fd.FullName()] {
+			return protoreflect.ValueOfString(fmt.Sprintf("secret-%d", *n)), true
+		}
+		return protoreflect.ValueOfString(fmt.Sprintf("value-%d", *n)), true
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(true), true
+	case protoreflect.EnumKind:
+		return protoreflect.ValueOfEnum(fd.Enum().Values().Get(fd.Enum().Values().Len() - 1).Number()), true
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(7), true
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(7), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(7), true
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(7), true
+	}
+	return v, false
+}
+
+func TestRedactor_RedactsIdentifiersOfEveryRequestType(t *testing.T) {
+	t.Parallel()
+	redactor, err := NewRedactor(identifierRules)
+	require.NoError(t, err)
+
+	types := requestTypes()
+	var names []protoreflect.FullName
+	for _, md := range types {
+		names = append(names, md.FullName())
+	}
+	require.Len(t, names, 16, "every RPC of the API is covered")
+	for _, md := range types {
+		t.Run(string(md.Name()), func(t *testing.T) {
+			t.Parallel()
+			req, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+			require.NoError(t, err)
+			m := req.New()
+			n := 0
+			populate(m, &n, 6)
+			original := proto.Clone(m.Interface())
+
+			args := redactor.Args(m.Interface())
+
+			assert.NotContains(t, args, "secret-", "identifiers are redacted")
+			for _, value := range regexp.MustCompile(`value-\d+`).FindAllString(extractArgs(m.Interface()), -1) {
+				assert.Contains(t, args, value, "other fields are logged")
+			}
+			if regexp.MustCompile(`secret-\d+`).MatchString(extractArgs(m.Interface())) {
+				assert.Regexp(t, `hmac-sha256:[0-9a-f]{64}|REDACTED`, args)
+			}
+			assert.True(t, proto.Equal(original, m.Interface()), "the request itself is left untouched")
+		})
+	}
+}
+
+func checkRequest() *v1beta1.CheckRequest {
+	return &v1beta1.CheckRequest{
+		Resource: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "workspace"}, Id: "ws-1"},
+		Relation: "view",
+		Subject: &v1beta1.SubjectReference{
+			Subject: &v1beta1.ObjectReference{Type: &v1beta1.ObjectType{Namespace: "rbac", Name: "principal"}, Id: "alice"},
+		},
+	}
+}
+
+func TestRedactor_HashesSubjectIds(t *testing.T) {
+	t.Parallel()
+	rules := []*conf.Server_LogRedaction_Rule{
+		{Message: "kessel.relations.v1beta1.SubjectReference", Field: "subject.id", Action: "hash"},
+	}
+	redactor, err := NewRedactor(&conf.Server_LogRedaction{HashKey: "test-key", Rules: rules})
+	require.NoError(t, err)
+
+	args := redactor.Args(checkRequest())
+	assert.NotContains(t, args, "alice")
+	assert.Contains(t, args, "hmac-sha256:ff7a3cd2cfcd73da2f3d9350cdbdfd2b8b6546ffd2b7296de6c86d373ebb71a6")
+	assert.Contains(t, args, "ws-1", "resource ids are not subject ids")
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("other-key\n"), 0o600))
+	redactor, err = NewRedactor(&conf.Server_LogRedaction{HashKeyFile: keyFile, Rules: rules})
+	require.NoError(t, err)
+	assert.NotContains(t, redactor.Args(checkRequest()), "ff7a3cd2", "digests depend on the key")
+}
+
+func TestRedactor_DropsFields(t *testing.T) {
+	t.Parallel()
+	redactor, err := NewRedactor(&conf.Server_LogRedaction{Rules: []*conf.Server_LogRedaction_Rule{
+		{Message: "kessel.relations.v1beta1.CheckRequest", Field: "subject", Action: "drop"},
+	}})
+	require.NoError(t, err)
+
+	args := redactor.Args(checkRequest())
+	assert.NotContains(t, args, "alice")
+	assert.NotContains(t, args, "principal")
+	assert.Contains(t, args, "ws-1")
+}
+
+func TestRedactor_MasksThroughRepeatedFields(t *testing.T) {
+	t.Parallel()
+	redactor, err := NewRedactor(&conf.Server_LogRedaction{Rules: []*conf.Server_LogRedaction_Rule{
+		{Message: "kessel.relations.v1beta1.CreateTuplesRequest", Field: "tuples.resource.id", Action: "mask"},
+	}})
+	require.NoError(t, err)
+
+	tuple := func(id string) *v1beta1.Relationship {
+		return &v1beta1.Relationship{Resource: &v1beta1.ObjectReference{Id: id}, Subject: checkRequest().GetSubject()}
+	}
+	args := redactor.Args(&v1beta1.CreateTuplesRequest{Tuples: []*v1beta1.Relationship{tuple("w1"), tuple("w2")}})
+	assert.NotContains(t, args, "w1")
+	assert.NotContains(t, args, "w2")
+	assert.Contains(t, args, "alice", "only the fields of the rule are redacted")
+	assert.Len(t, regexp.MustCompile(redacted).FindAllString(args, -1), 2)
+}
+
+func TestNewRedactor_RejectsInvalidRules(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		rule *conf.Server_LogRedaction_Rule
+		err  string
+	}{
+		{&conf.Server_LogRedaction_Rule{Message: "kessel.relations.v1beta1.Nope", Field: "id", Action: "mask"}, "unknown log redaction message"},
+		{&conf.Server_LogRedaction_Rule{Message: "kessel.relations.v1beta1.KesselCheckService", Field: "id", Action: "mask"}, "is not a message"},
+		{&conf.Server_LogRedaction_Rule{Message: "kessel.relations.v1beta1.ObjectReference", Field: "uuid", Action: "mask"}, "unknown log redaction field"},
+		{&conf.Server_LogRedaction_Rule{Message: "kessel.relations.v1beta1.ObjectReference", Field: "id.value", Action: "mask"}, "not a message"},
+		{&conf.Server_LogRedaction_Rule{Message: "kessel.relations.v1beta1.CheckRequest", Field: "subject", Action: "hash"}, "can only be dropped"},
+		{&conf.Server_LogRedaction_Rule{Message: "kessel.relations.v1beta1.ObjectReference", Field: "id", Action: "encrypt"}, "unknown log redaction action"},
+		{&conf.Server_LogRedaction_Rule{Message: "kessel.relations.v1beta1.ObjectReference", Field: "id", Action: "hash"}, "hash key is required"},
+	} {
+		_, err := NewRedactor(&conf.Server_LogRedaction{Rules: []*conf.Server_LogRedaction_Rule{tc.rule}})
+		assert.ErrorContains(t, err, tc.err, tc.rule.String())
+	}
+}
+
+func TestLoggingMiddleware_LogsRedactedArgs(t *testing.T) {
+	t.Parallel()
+	redactor, err := NewRedactor(identifierRules)
+	require.NoError(t, err)
+	logger := &capturingLogger{}
+
+	_, err = LoggingMiddleware(logger, redactor)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &v1beta1.CheckResponse{}, nil
+	})(context.Background(), checkRequest())
+	require.NoError(t, err)
+
+	assert.NotContains(t, logger.keyvals["args"], "alice")
+	assert.Contains(t, logger.keyvals["args"], "view")
+	assert.Equal(t, int32(200), logger.keyvals["code"])
+}
+
+func TestStreamLogInterceptor_LogsRedactedArgs(t *testing.T) {
+	t.Parallel()
+	redactor, err := NewRedactor(identifierRules)
+	require.NoError(t, err)
+	logger := &capturingLogger{}
+
+	stream := &lookupStream{DummyServerStream: DummyServerStream{RecvMsgFunc: func(msg interface{}) error {
+		*msg.(*v1beta1.LookupResourcesRequest) = v1beta1.LookupResourcesRequest{Subject: checkRequest().GetSubject(), Relation: "view"}
+		return nil
+	}}}
+	err = StreamLogInterceptor(logger, redactor)(nil, stream, &grpc.StreamServerInfo{FullMethod: lookupResources}, func(srv interface{}, ss grpc.ServerStream) error {
+		return ss.RecvMsg(&v1beta1.LookupResourcesRequest{})
+	})
+	require.NoError(t, err)
+
+	assert.NotContains(t, logger.keyvals["args"], "alice")
+	assert.Contains(t, logger.keyvals["args"], "view")
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	kratosMiddleware "github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc"
+
+	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/slowlog"
+)
+
+// SlowRequestMiddleware collects the SpiceDB work caused by each request and logs the requests that were slow, with
+// their arguments redacted by r.
+func SlowRequestMiddleware(l *slowlog.Logger, r *Redactor) kratosMiddleware.Middleware {
+	return func(handler kratosMiddleware.Handler) kratosMiddleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var operation string
+			if info, ok := transport.FromServerContext(ctx); ok {
+				operation = info.Operation()
+			}
+			ctx = slowlog.NewContext(ctx, &slowlog.Stats{})
+			startTime := time.Now()
+			reply, err := handler(ctx, req)
+
+			l.Finished(ctx, slowlog.Request{
+				Operation: operation,
+				Args:      func() string { return r.Args(req) },
+				Latency:   time.Since(startTime),
+				Err:       err,
+				Results:   unaryResults(reply, err),
+			})
+			return reply, err
+		}
+	}
+}
+
+// unaryResults counts the checks answered by a reply.
+func unaryResults(reply interface{}, err error) int {
+	if err != nil {
+		return 0
+	}
+	if bulk, ok := reply.(interface {
+		GetPairs() []*pb.CheckBulkResponsePair
+	}); ok {
+		return len(bulk.GetPairs())
+	}
+	return 1
+}
+
+// StreamSlowRequestInterceptor is the stream counterpart of SlowRequestMiddleware. The results of a stream are the
+// messages it sent.
+func StreamSlowRequestInterceptor(l *slowlog.Logger, r *Redactor) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapper := &slowRequestStream{
+			requestInterceptingWrapper: requestInterceptingWrapper{ServerStream: ss},
+			ctx:                        slowlog.NewContext(ss.Context(), &slowlog.Stats{}),
+		}
+		startTime := time.Now()
+		err := handler(srv, wrapper)
+
+		l.Finished(wrapper.ctx, slowlog.Request{
+			Operation: info.FullMethod,
+			Args:      func() string { return r.Args(wrapper.req) },
+			Latency:   time.Since(startTime),
+			Err:       err,
+			Results:   wrapper.sent,
+		})
+		return err
+	}
+}
+
+type slowRequestStream struct {
+	requestInterceptingWrapper
+	ctx  context.Context
+	sent int
+}
+
+func (s *slowRequestStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *slowRequestStream) SendMsg(m interface{}) error {
+	err := s.requestInterceptingWrapper.SendMsg(m)
+	if err == nil {
+		s.sent++
+	}
+	return err
+}
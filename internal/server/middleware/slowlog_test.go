@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/slowlog"
+)
+
+const lookupResources = "/kessel.relations.v1beta1.KesselLookupService/LookupResources"
+
+// capturingLogger keeps the key values of the last entry logged.
+type capturingLogger struct {
+	keyvals map[string]any
+}
+
+func (l *capturingLogger) Log(level log.Level, keyvals ...interface{}) error {
+	l.keyvals = map[string]any{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		l.keyvals[keyvals[i].(string)] = keyvals[i+1]
+	}
+	return nil
+}
+
+type lookupStream struct {
+	DummyServerStream
+	sent int
+}
+
+func (s *lookupStream) Context() context.Context  { return context.Background() }
+func (s *lookupStream) SendMsg(interface{}) error { s.sent++; return nil }
+
+func TestStreamSlowRequestInterceptor_LogsResultsAndSpiceDbWork(t *testing.T) {
+	t.Parallel()
+	logger := &capturingLogger{}
+	interceptor := StreamSlowRequestInterceptor(slowlog.NewLogger(&conf.Server_SlowRequests{Threshold: durationpb.New(time.Nanosecond)}, logger), nil)
+
+	stream := &lookupStream{DummyServerStream: DummyServerStream{RecvMsgFunc: func(msg interface{}) error {
+		*msg.(*v1beta1.LookupResourcesRequest) = v1beta1.LookupResourcesRequest{Relation: "view"}
+		return nil
+	}}}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: lookupResources}, func(srv interface{}, ss grpc.ServerStream) error {
+		if err := ss.RecvMsg(&v1beta1.LookupResourcesRequest{}); err != nil {
+			return err
+		}
+		slowlog.FromContext(ss.Context()).SpiceDbCalled("minimize_latency")
+		for range 3 {
+			if err := ss.SendMsg(&v1beta1.LookupResourcesResponse{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stream.sent)
+	assert.Equal(t, lookupResources, logger.keyvals["operation"])
+	assert.Equal(t, 3, logger.keyvals["results"])
+	assert.Equal(t, 1, logger.keyvals["spicedb_calls"])
+	assert.Equal(t, "minimize_latency", logger.keyvals["consistency"])
+	assert.Contains(t, logger.keyvals["args"], "view")
+}
+
+func TestSlowRequestMiddleware_CountsBulkCheckResults(t *testing.T) {
+	t.Parallel()
+	logger := &capturingLogger{}
+	m := SlowRequestMiddleware(slowlog.NewLogger(&conf.Server_SlowRequests{Threshold: durationpb.New(time.Nanosecond)}, logger), nil)
+
+	_, err := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		assert.NotNil(t, slowlog.FromContext(ctx), "SpiceDB work is collected while the request is served")
+		return &v1beta1.CheckBulkResponse{Pairs: make([]*v1beta1.CheckBulkResponsePair, 4)}, nil
+	})(context.Background(), &v1beta1.CheckBulkRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "slow request", logger.keyvals["msg"])
+	assert.Equal(t, 4, logger.keyvals["results"])
+}
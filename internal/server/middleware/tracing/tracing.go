@@ -1,6 +1,8 @@
 package tracing
 
 import (
+	"context"
+
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"google.golang.org/grpc"
 
@@ -21,7 +23,18 @@ func StreamTracingInterceptor(opts ...tracing.Option) grpc.StreamServerIntercept
 			//Alternatively could sum request/response sizes for the stream
 			setServerSpan(ctx, span, nil)
 			defer func() { tracer.End(ctx, span, nil, err) }()
+			// the handler and the calls it makes, e.g. to SpiceDB, are children of the span
+			ss = &tracingServerStream{ServerStream: ss, ctx: ctx}
 		}
 		return handler(srv, ss)
 	}
 }
+
+type tracingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracingServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const importBulkTuples = "/kessel.relations.v1beta1.KesselTupleService/ImportBulkTuples"
+
+type header map[string]string
+
+func (h header) Get(key string) string      { return h[key] }
+func (h header) Set(key, value string)      { h[key] = value }
+func (h header) Add(key, value string)      { h[key] = value }
+func (h header) Keys() []string             { return nil }
+func (h header) Values(key string) []string { return []string{h[key]} }
+
+type testTransport struct {
+	header header
+}
+
+func (t *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (t *testTransport) Endpoint() string                { return "" }
+func (t *testTransport) Operation() string               { return importBulkTuples }
+func (t *testTransport) RequestHeader() transport.Header { return t.header }
+func (t *testTransport) ReplyHeader() transport.Header   { return header{} }
+
+type dummyServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (d *dummyServerStream) Context() context.Context { return d.ctx }
+
+func TestStreamTracingInterceptor_HandlerRunsInTheServerSpan(t *testing.T) {
+	t.Parallel()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	interceptor := StreamTracingInterceptor(tracing.WithTracerProvider(provider), tracing.WithPropagator(propagation.TraceContext{}))
+
+	caller := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := transport.NewServerContext(context.Background(), &testTransport{header: header{"traceparent": caller}})
+	var handled trace.SpanContext
+	err := interceptor(nil, &dummyServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: importBulkTuples},
+		func(srv any, ss grpc.ServerStream) error {
+			handled = trace.SpanContextFromContext(ss.Context())
+			return nil
+		})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, importBulkTuples, spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String(), "the trace of the caller is continued")
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, spans[0].SpanContext(), handled)
+}
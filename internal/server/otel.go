@@ -3,40 +3,137 @@ package server
 // Taken from Kratos examples: https://github.com/go-kratos/examples/blob/main/otel/internal/dep/otel.go
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
 	a "github.com/go-kratos/kratos/v2/middleware/metrics"
 	"github.com/project-kessel/relations-api/internal/conf"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
+const (
+	// defaultMetricsInterval is how often metrics are pushed over OTLP unless configured.
+	defaultMetricsInterval = 60 * time.Second
+	// meterShutdownTimeout bounds pushing the metrics recorded since the last export when the service stops.
+	meterShutdownTimeout = 5 * time.Second
+)
+
 func NewMeter(c *conf.Server, provider metric.MeterProvider) (metric.Meter, error) {
 	return provider.Meter("relations-api"), nil
 }
 
-func NewMeterProvider(c *conf.Server) (metric.MeterProvider, error) {
-	exporter, err := prometheus.New()
+// NewMeterProvider returns the provider of every metric of the service, served at /metrics for Prometheus, pushed
+// over OTLP, or both, as server.metrics.exporters selects.
+func NewMeterProvider(c *conf.Server, logger log.Logger) (metric.MeterProvider, func(), error) {
+	mc := c.GetMetrics()
+	servePrometheus, pushOtlp, err := metricExporters(mc)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	provider := sdkmetric.NewMeterProvider(
-		sdkmetric.WithResource(
-			resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String("relations-api"),
-				attribute.String("environment", c.Http.Pathprefix),
-			),
-		),
-		sdkmetric.WithReader(exporter),
+	filter := exemplar.AlwaysOffFilter
+	if mc.GetExemplars() {
+		filter = exemplar.TraceBasedFilter
+	}
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(newResource(c)),
 		sdkmetric.WithView(
 			a.DefaultSecondsHistogramView(a.DefaultServerSecondsHistogramName),
 		),
-	)
+		sdkmetric.WithExemplarFilter(filter),
+	}
+	if servePrometheus {
+		exporter, err := prometheus.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, sdkmetric.WithReader(exporter))
+	}
+	if pushOtlp {
+		exporter, err := newMetricExporter(mc)
+		if err != nil {
+			return nil, nil, err
+		}
+		interval := defaultMetricsInterval
+		if mc.GetInterval() != nil {
+			interval = mc.GetInterval().AsDuration()
+		}
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))))
+	}
+
+	provider := sdkmetric.NewMeterProvider(opts...)
 	otel.SetMeterProvider(provider)
-	return provider, nil
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), meterShutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			log.NewHelper(logger).Warnf("error flushing metrics: %v", err)
+		}
+	}
+	return provider, cleanup, nil
+}
+
+// metricExporters returns whether metrics are served to Prometheus and whether they are pushed over OTLP.
+func metricExporters(c *conf.Server_Metrics) (servePrometheus bool, pushOtlp bool, err error) {
+	if len(c.GetExporters()) == 0 {
+		return true, false, nil
+	}
+	for _, exporter := range c.GetExporters() {
+		switch exporter {
+		case "prometheus":
+			servePrometheus = true
+		case "otlp":
+			pushOtlp = true
+		default:
+			return false, false, fmt.Errorf("unknown metrics exporter %q, expected prometheus or otlp", exporter)
+		}
+	}
+	return servePrometheus, pushOtlp, nil
+}
+
+func newMetricExporter(c *conf.Server_Metrics) (sdkmetric.Exporter, error) {
+	// exporters are created without connecting, a collector that is down only delays metrics
+	switch c.GetProtocol() {
+	case "", "grpc":
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(c.GetHeaders())}
+		if c.GetEndpoint() != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(c.GetEndpoint()))
+		}
+		if c.GetInsecure() {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(context.Background(), opts...)
+	case "http":
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(c.GetHeaders())}
+		if c.GetEndpoint() != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(c.GetEndpoint()))
+		}
+		if c.GetInsecure() {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(context.Background(), opts...)
+	}
+	return nil, fmt.Errorf("unknown metrics protocol %q, expected grpc or http", c.GetProtocol())
+}
+
+// newResource describes the service in exported metrics and spans. Configured resource attributes override the
+// service name.
+func newResource(c *conf.Server) *resource.Resource {
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String("relations-api")}
+	for key, value := range c.GetResourceAttributes() {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...)
 }
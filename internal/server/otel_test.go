@@ -0,0 +1,71 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+func TestMetricExporters(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		exporters       []string
+		servePrometheus bool
+		pushOtlp        bool
+	}{
+		{nil, true, false},
+		{[]string{"prometheus"}, true, false},
+		{[]string{"otlp"}, false, true},
+		{[]string{"prometheus", "otlp"}, true, true},
+	} {
+		servePrometheus, pushOtlp, err := metricExporters(&conf.Server_Metrics{Exporters: tc.exporters})
+		require.NoError(t, err)
+		assert.Equal(t, tc.servePrometheus, servePrometheus, tc.exporters)
+		assert.Equal(t, tc.pushOtlp, pushOtlp, tc.exporters)
+	}
+}
+
+func TestNewMeterProvider_RejectsUnknownExporterAndProtocol(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := NewMeterProvider(&conf.Server{Metrics: &conf.Server_Metrics{Exporters: []string{"statsd"}}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "statsd")
+	_, _, err = NewMeterProvider(&conf.Server{Metrics: &conf.Server_Metrics{Exporters: []string{"otlp"}, Protocol: "udp"}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "udp")
+}
+
+func TestNewMeterProvider_PushesOverOtlp(t *testing.T) {
+	t.Parallel()
+
+	provider, cleanup, err := NewMeterProvider(&conf.Server{Metrics: &conf.Server_Metrics{
+		Exporters: []string{"otlp"},
+		Protocol:  "http",
+		Endpoint:  "localhost:1",
+		Insecure:  true,
+		Exemplars: true,
+	}}, log.DefaultLogger)
+	require.NoError(t, err)
+	assert.NotNil(t, provider)
+	cleanup()
+}
+
+func TestNewResource_AddsConfiguredAttributes(t *testing.T) {
+	t.Parallel()
+
+	res := newResource(&conf.Server{ResourceAttributes: map[string]string{"deployment.environment.name": "stage"}})
+	env, ok := res.Set().Value("deployment.environment.name")
+	assert.True(t, ok)
+	assert.Equal(t, "stage", env.AsString())
+	name, _ := res.Set().Value(semconv.ServiceNameKey)
+	assert.Equal(t, "relations-api", name.AsString())
+
+	res = newResource(&conf.Server{ResourceAttributes: map[string]string{string(semconv.ServiceNameKey): "relations-api-canary"}})
+	name, _ = res.Set().Value(semconv.ServiceNameKey)
+	assert.Equal(t, "relations-api-canary", name.AsString(), "configured attributes override the service name")
+}
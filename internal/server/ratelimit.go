@@ -0,0 +1,20 @@
+package server
+
+import (
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/ratelimit"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// NewRateLimiter creates the limiter shared by the gRPC and HTTP servers, or nil if rate limiting is disabled.
+func NewRateLimiter(c *conf.Server, meter metric.Meter, auditor *audit.Auditor) (*ratelimit.Limiter, error) {
+	if !c.GetRateLimit().GetEnabled() {
+		return nil, nil
+	}
+	throttled, err := ratelimit.NewThrottledCounter(meter)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewLimiter(c.GetRateLimit(), auditor, ratelimit.WithThrottledCounter(throttled)), nil
+}
@@ -0,0 +1,11 @@
+package server
+
+import (
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware"
+)
+
+// NewLogRedactor creates the redactor of the requests the gRPC and HTTP servers log.
+func NewLogRedactor(c *conf.Server) (*middleware.Redactor, error) {
+	return middleware.NewRedactor(c.GetLogRedaction())
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"io"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-kessel/relations-api/internal/audit"
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/authz"
+)
+
+func newTestServerConf(level string, namespaces ...string) *conf.Server {
+	return &conf.Server{
+		MinLogLevel: &level,
+		Grpc:        &conf.Server_GRPC{Addr: "0.0.0.0:9000"},
+		Auth: &conf.Server_Auth{
+			EnableAuthz: true,
+			Policies: []*conf.Server_Auth_Policy{
+				{Subject: "svc", Operations: []string{"*"}, Namespaces: namespaces},
+			},
+		},
+	}
+}
+
+// captureLogger records the entries logged through it.
+type captureLogger struct {
+	entries [][]any
+}
+
+func (c *captureLogger) Log(level log.Level, keyvals ...any) error {
+	c.entries = append(c.entries, keyvals)
+	return nil
+}
+
+func (c *captureLogger) value(key string) any {
+	for _, entry := range c.entries {
+		for i := 0; i+1 < len(entry); i += 2 {
+			if entry[i] == key {
+				return entry[i+1]
+			}
+		}
+	}
+	return nil
+}
+
+func TestLogLevel_FiltersBelowCurrentLevel(t *testing.T) {
+	t.Parallel()
+
+	level := NewLogLevel(&conf.Server{})
+	capture := &captureLogger{}
+	logger := log.NewHelper(log.NewFilter(capture, log.FilterLevel(log.LevelDebug), level.Filter()))
+
+	logger.Debug("dropped")
+	assert.Empty(t, capture.entries)
+
+	level.Set("debug")
+	logger.Debug("logged")
+	assert.Len(t, capture.entries, 1)
+
+	level.Set("ERROR")
+	logger.Warn("dropped")
+	assert.Len(t, capture.entries, 1)
+}
+
+func TestConfigReloader_AppliesLogLevelAndPolicies(t *testing.T) {
+	t.Parallel()
+
+	c := newTestServerConf("info", "rbac")
+	level := NewLogLevel(c)
+	capture := &captureLogger{}
+	authorizer := authz.NewAuthorizer(c.GetAuth(), audit.NewLogAuditor(log.NewStdLogger(io.Discard)))
+	reloader := NewConfigReloader(c, level, nil, authorizer, audit.NewLogAuditor(capture))
+
+	assert.NoError(t, reloader.Reload(newTestServerConf("warn", "notifications")))
+	assert.Equal(t, log.LevelWarn, level.Level())
+	assert.Equal(t, "RELOAD", capture.value("action"))
+	assert.Equal(t, "minLogLevel,auth", capture.value("applied"))
+	assert.Empty(t, capture.value("restart_required"))
+}
+
+func TestConfigReloader_ReportsSettingsRequiringRestart(t *testing.T) {
+	t.Parallel()
+
+	c := newTestServerConf("info", "rbac")
+	capture := &captureLogger{}
+	reloader := NewConfigReloader(c, NewLogLevel(c), nil, nil, audit.NewLogAuditor(capture))
+
+	next := newTestServerConf("info", "notifications")
+	next.Grpc.Addr = "0.0.0.0:9001"
+	next.Auth.EnableAuth = true
+	assert.NoError(t, reloader.Reload(next))
+	assert.Empty(t, capture.value("applied"))
+	assert.Equal(t, "auth,server", capture.value("restart_required"))
+}
+
+func TestConfigReloader_IgnoresUnchangedConfiguration(t *testing.T) {
+	t.Parallel()
+
+	capture := &captureLogger{}
+	reloader := NewConfigReloader(newTestServerConf("info", "rbac"), NewLogLevel(&conf.Server{}), nil, nil, audit.NewLogAuditor(capture))
+
+	assert.NoError(t, reloader.Reload(newTestServerConf("info", "rbac")))
+	assert.Empty(t, capture.entries)
+}
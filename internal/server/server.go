@@ -5,4 +5,4 @@ import (
 )
 
 // ProviderSet is server providers.
-var ProviderSet = wire.NewSet(NewMeter, NewMeterProvider, NewGRPCServer, NewHTTPServer)
+var ProviderSet = wire.NewSet(NewMeter, NewMeterProvider, NewTracerProvider, NewAuditor, NewTokenVerifier, NewHealthProber, NewAuthorizer, NewRateLimiter, NewSlowRequestLogger, NewLogRedactor, NewConfigReloader, NewGRPCServer, NewHTTPServer, NewTupleConsumer)
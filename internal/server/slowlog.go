@@ -0,0 +1,17 @@
+package server
+
+import (
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/slowlog"
+)
+
+// NewSlowRequestLogger creates the slow request logger shared by the gRPC and HTTP servers, or nil if slow requests
+// are not logged.
+func NewSlowRequestLogger(c *conf.Server, logger log.Logger) *slowlog.Logger {
+	if !c.GetSlowRequests().GetEnabled() {
+		return nil
+	}
+	return slowlog.NewLogger(c.GetSlowRequests(), logger)
+}
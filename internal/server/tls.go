@@ -0,0 +1,73 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+	"github.com/project-kessel/relations-api/internal/server/middleware/auth"
+)
+
+const (
+	clientAuthNone    = "none"
+	clientAuthRequest = "request"
+	clientAuthRequire = "require"
+)
+
+// newServerTLSConfig creates the TLS configuration of the gRPC and HTTP listeners, or nil if TLS is disabled.
+func newServerTLSConfig(c *conf.Server_TLS) (*tls.Config, error) {
+	if c.GetCertFile() == "" {
+		if c.GetClientAuth() != "" && c.GetClientAuth() != clientAuthNone {
+			return nil, fmt.Errorf("tls.clientAuth requires tls.certFile and tls.keyFile")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.GetCertFile(), c.GetKeyFile())
+	if err != nil {
+		return nil, fmt.Errorf("error loading server certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	switch c.GetClientAuth() {
+	case "", clientAuthNone:
+		return tlsConfig, nil
+	case clientAuthRequest:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	case clientAuthRequire:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown tls.clientAuth %q, expected none, request or require", c.GetClientAuth())
+	}
+
+	switch c.GetClientPrincipal() {
+	case "", auth.CertPrincipalSubject, auth.CertPrincipalURI, auth.CertPrincipalDNS, auth.CertPrincipalEmail:
+	default:
+		return nil, fmt.Errorf("unknown tls.clientPrincipal %q, expected subject, uri, dns or email", c.GetClientPrincipal())
+	}
+
+	if c.GetClientCaFile() == "" {
+		return nil, fmt.Errorf("tls.clientAuth %s requires tls.clientCaFile", c.GetClientAuth())
+	}
+	ca, err := os.ReadFile(c.GetClientCaFile())
+	if err != nil {
+		return nil, fmt.Errorf("error loading client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("client CA file %s contains no PEM certificates", c.GetClientCaFile())
+	}
+	tlsConfig.ClientCAs = pool
+
+	return tlsConfig, nil
+}
+
+// clientCertAuthEnabled reports whether callers may be authenticated by client certificate.
+func clientCertAuthEnabled(c *conf.Server_TLS) bool {
+	return c.GetClientAuth() == clientAuthRequest || c.GetClientAuth() == clientAuthRequire
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+// tracerShutdownTimeout bounds flushing the spans still buffered when the service stops.
+const tracerShutdownTimeout = 5 * time.Second
+
+// NewTracerProvider returns the provider of the server and SpiceDB client spans, exporting them over OTLP when
+// server.tracing is enabled. W3C trace context and baggage are propagated either way, so traces of callers continue
+// into SpiceDB even when this service exports nothing.
+func NewTracerProvider(c *conf.Server, logger log.Logger) (trace.TracerProvider, func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	tc := c.GetTracing()
+	if !tc.GetEnabled() {
+		return noop.NewTracerProvider(), func() {}, nil
+	}
+
+	exporter, err := newTraceExporter(tc)
+	if err != nil {
+		return nil, nil, err
+	}
+	ratio := 1.0
+	if tc.SampleRatio != nil {
+		ratio = tc.GetSampleRatio()
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(newResource(c)),
+	)
+	otel.SetTracerProvider(provider)
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracerShutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			log.NewHelper(logger).Warnf("error flushing spans: %v", err)
+		}
+	}
+	return provider, cleanup, nil
+}
+
+func newTraceExporter(c *conf.Server_Tracing) (sdktrace.SpanExporter, error) {
+	// exporters are created without connecting, a collector that is down only delays spans
+	switch c.GetProtocol() {
+	case "", "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(c.GetHeaders())}
+		if c.GetEndpoint() != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(c.GetEndpoint()))
+		}
+		if c.GetInsecure() {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(context.Background(), opts...)
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(c.GetHeaders())}
+		if c.GetEndpoint() != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(c.GetEndpoint()))
+		}
+		if c.GetInsecure() {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	}
+	return nil, fmt.Errorf("unknown tracing protocol %q, expected grpc or http", c.GetProtocol())
+}
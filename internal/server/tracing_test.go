@@ -0,0 +1,28 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+func TestNewTracerProvider_ExportsNothingUnlessEnabled(t *testing.T) {
+	t.Parallel()
+
+	provider, cleanup, err := NewTracerProvider(&conf.Server{}, log.DefaultLogger)
+	require.NoError(t, err)
+	defer cleanup()
+	assert.IsType(t, noop.TracerProvider{}, provider)
+}
+
+func TestNewTracerProvider_RejectsUnknownProtocol(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := NewTracerProvider(&conf.Server{Tracing: &conf.Server_Tracing{Enabled: true, Protocol: "zipkin"}}, log.DefaultLogger)
+	assert.ErrorContains(t, err, "zipkin")
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+
+	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
+)
+
+// auditRead records the entry of a read-only call, if reads are audited, as failed if err is set.
+func auditRead(ctx context.Context, auditor *audit.Auditor, e audit.Event, err error) {
+	e.Principal = extractPrincipal(ctx)
+	e.Outcome = audit.OutcomeSuccess
+	if err != nil {
+		e.Outcome = audit.OutcomeFailure
+		e.Reason = kerrors.Reason(err)
+		if e.Reason == "" {
+			e.Reason = "spicedb_error"
+		}
+	}
+	auditor.RecordRead(ctx, e)
+}
+
+// auditObject formats a resource or subject as "ns/type:id".
+func auditObject(o *pb.ObjectReference) string {
+	return fmt.Sprintf("%s/%s:%s", o.GetType().GetNamespace(), o.GetType().GetName(), o.GetId())
+}
+
+// auditTuple formats a relationship, or a check of a relation or permission, as
+// "ns/type:id#relation ns/type:id[#relation]".
+func auditTuple(resource *pb.ObjectReference, relation string, subject *pb.SubjectReference) string {
+	return audit.Tuple(resource, relation, subject)
+}
+
+// auditTuples formats up to max of the tuples, reporting whether some were left out.
+func auditTuples(tuples []*pb.Relationship, max int) ([]string, bool) {
+	return audit.Tuples(tuples, max)
+}
+
+// auditBulkChecks formats the items of a bulk check.
+func auditBulkChecks(items []*pb.CheckBulkRequestItem) []string {
+	tuples := make([]string, 0, len(items))
+	for _, item := range items {
+		tuples = append(tuples, auditTuple(item.GetResource(), item.GetRelation(), item.GetSubject()))
+	}
+	return tuples
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 
 	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
 )
 
 type CheckService struct {
@@ -17,21 +18,27 @@ type CheckService struct {
 	checkForUpdate    *biz.CheckForUpdateUsecase
 	checkBulk         *biz.CheckBulkUsecase
 	checkForUpdateBulk *biz.CheckForUpdateBulkUsecase
+	auditor           *audit.Auditor
+	metrics           *Metrics
 	log               *log.Helper
 }
 
-func NewCheckService(logger log.Logger, checkUseCase *biz.CheckUsecase, checkForUpdateUseCase *biz.CheckForUpdateUsecase, checkBulkUseCase *biz.CheckBulkUsecase, checkForUpdateBulkUseCase *biz.CheckForUpdateBulkUsecase) *CheckService {
+func NewCheckService(logger log.Logger, checkUseCase *biz.CheckUsecase, checkForUpdateUseCase *biz.CheckForUpdateUsecase, checkBulkUseCase *biz.CheckBulkUsecase, checkForUpdateBulkUseCase *biz.CheckForUpdateBulkUsecase, auditor *audit.Auditor, metrics *Metrics) *CheckService {
 	return &CheckService{
 		check:              checkUseCase,
 		checkForUpdate:     checkForUpdateUseCase,
 		checkBulk:          checkBulkUseCase,
 		checkForUpdateBulk: checkForUpdateBulkUseCase,
+		auditor:            auditor,
+		metrics:            metrics,
 		log:                log.NewHelper(logger),
 	}
 }
 
 func (s *CheckService) Check(ctx context.Context, req *pb.CheckRequest) (*pb.CheckResponse, error) {
 	resp, err := s.check.Check(ctx, req)
+	s.auditCheck(ctx, "Permission checked", req.GetResource(), req.GetRelation(), req.GetSubject(), resp.GetAllowed().String(), err)
+	s.metrics.checked(ctx, req.GetResource(), req.GetRelation(), false, checkResult(resp.GetAllowed().String(), err))
 	if err != nil {
 		return resp, fmt.Errorf("failed to perform check: %w", err)
 	}
@@ -40,6 +47,8 @@ func (s *CheckService) Check(ctx context.Context, req *pb.CheckRequest) (*pb.Che
 
 func (s *CheckService) CheckForUpdate(ctx context.Context, req *pb.CheckForUpdateRequest) (*pb.CheckForUpdateResponse, error) {
 	resp, err := s.checkForUpdate.CheckForUpdate(ctx, req)
+	s.auditCheck(ctx, "Permission checked for update", req.GetResource(), req.GetRelation(), req.GetSubject(), resp.GetAllowed().String(), err)
+	s.metrics.checked(ctx, req.GetResource(), req.GetRelation(), true, checkResult(resp.GetAllowed().String(), err))
 	if err != nil {
 		return resp, fmt.Errorf("failed to perform checkForUpdate: %w", err)
 	}
@@ -48,6 +57,8 @@ func (s *CheckService) CheckForUpdate(ctx context.Context, req *pb.CheckForUpdat
 
 func (s *CheckService) CheckBulk(ctx context.Context, req *pb.CheckBulkRequest) (*pb.CheckBulkResponse, error) {
 	resp, err := s.checkBulk.CheckBulk(ctx, req)
+	s.auditBulkCheck(ctx, "Permissions checked", req.GetItems(), err)
+	s.metrics.bulkChecked(ctx, req.GetItems(), resp.GetPairs(), false, err)
 	if err != nil {
 		return resp, fmt.Errorf("failed to perform checkBulk: %w", err)
 	}
@@ -56,8 +67,36 @@ func (s *CheckService) CheckBulk(ctx context.Context, req *pb.CheckBulkRequest)
 
 func (s *CheckService) CheckForUpdateBulk(ctx context.Context, req *pb.CheckForUpdateBulkRequest) (*pb.CheckForUpdateBulkResponse, error) {
 	resp, err := s.checkForUpdateBulk.CheckForUpdateBulk(ctx, req)
+	s.auditBulkCheck(ctx, "Permissions checked for update", req.GetItems(), err)
+	s.metrics.bulkChecked(ctx, req.GetItems(), resp.GetPairs(), true, err)
 	if err != nil {
 		return resp, fmt.Errorf("failed to perform checkForUpdateBulk: %w", err)
 	}
 	return resp, nil
 }
+
+// auditCheck records a check, if reads are audited.
+func (s *CheckService) auditCheck(ctx context.Context, message string, resource *pb.ObjectReference, relation string, subject *pb.SubjectReference, allowed string, err error) {
+	e := audit.Event{
+		Message:      message,
+		Action:       "CHECK",
+		ResourceType: "permission",
+		ResourceID:   auditObject(resource) + "#" + relation,
+		Tuples:       []string{auditTuple(resource, relation, subject)},
+	}
+	if err == nil {
+		e.Details = map[string]string{"allowed": allowed}
+	}
+	auditRead(ctx, s.auditor, e, err)
+}
+
+// auditBulkCheck records a bulk check, if reads are audited.
+func (s *CheckService) auditBulkCheck(ctx context.Context, message string, items []*pb.CheckBulkRequestItem, err error) {
+	auditRead(ctx, s.auditor, audit.Event{
+		Message:      message,
+		Action:       "CHECK",
+		ResourceType: "permission",
+		ResourceID:   fmt.Sprintf("count:%d", len(items)),
+		Tuples:       auditBulkChecks(items),
+	}, err)
+}
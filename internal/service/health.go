@@ -10,12 +10,13 @@ import (
 type HealthService struct {
 	pb.UnimplementedKesselRelationsHealthServiceServer
 	backendUseCase *biz.IsBackendAvaliableUsecase
-	isReady        bool
+	prober         *biz.HealthProber
 }
 
-func NewHealthService(backendUsecase *biz.IsBackendAvaliableUsecase) *HealthService {
+func NewHealthService(backendUsecase *biz.IsBackendAvaliableUsecase, prober *biz.HealthProber) *HealthService {
 	return &HealthService{
 		backendUseCase: backendUsecase,
+		prober:         prober,
 	}
 }
 
@@ -23,13 +24,27 @@ func (s *HealthService) GetLivez(ctx context.Context, req *pb.GetLivezRequest) (
 	return &pb.GetLivezResponse{Status: "OK", Code: 200}, nil
 }
 
+// GetReadyz reports the state of the background readiness probes. An open circuit breaker makes the service
+// unavailable immediately, without waiting for the probes to reach their failure threshold.
 func (s *HealthService) GetReadyz(ctx context.Context, req *pb.GetReadyzRequest) (*pb.GetReadyzResponse, error) {
-	if !s.isReady {
-		err := s.backendUseCase.IsBackendAvailable()
-		if err != nil {
-			return &pb.GetReadyzResponse{Status: "Unavailable", Code: 503}, nil
-		}
-		s.isReady = true
+	resp := &pb.GetReadyzResponse{Status: "OK", Code: 200}
+	for _, c := range s.prober.Components() {
+		resp.Components = append(resp.Components, componentStatus(c.Name, c.Healthy, c.Message))
 	}
-	return &pb.GetReadyzResponse{Status: "OK", Code: 200}, nil
+	circuitOpen := s.backendUseCase.IsCircuitOpen()
+	if circuitOpen {
+		resp.Components = append(resp.Components, componentStatus("circuitBreaker", false, "spicedb circuit breaker is open"))
+	}
+	if !s.prober.Ready() || circuitOpen {
+		resp.Status, resp.Code = "Unavailable", 503
+	}
+	return resp, nil
+}
+
+func componentStatus(name string, healthy bool, message string) *pb.ComponentStatus {
+	status := "OK"
+	if !healthy {
+		status = "Unavailable"
+	}
+	return &pb.ComponentStatus{Name: name, Status: status, Message: message}
 }
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
 
 	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1"
 	"github.com/project-kessel/relations-api/internal/biz"
@@ -19,7 +22,7 @@ func TestHealthService_GetLivez(t *testing.T) {
 	spicedb, err := container.CreateSpiceDbRepository()
 	assert.NoError(t, err)
 
-	service := createHealthService(spicedb)
+	service, _ := createHealthService(spicedb)
 	resp, err := service.GetLivez(ctx, &pb.GetLivezRequest{})
 
 	assert.NoError(t, err)
@@ -33,11 +36,14 @@ func TestHealthService_GetReadyz_SpiceDBAvailable(t *testing.T) {
 	spicedb, err := container.CreateSpiceDbRepository()
 	assert.NoError(t, err)
 
-	service := createHealthService(spicedb)
+	service, prober := createHealthService(spicedb)
+	prober.Probe(ctx)
 	resp, err := service.GetReadyz(ctx, &pb.GetReadyzRequest{})
 
 	assert.NoError(t, err)
-	assert.Equal(t, &pb.GetReadyzResponse{Status: "OK", Code: 200}, resp)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "OK", Code: 200, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "OK"},
+	}}, resp)
 }
 
 func TestHealthService_GetReadyz_SpiceDBUnavailable(t *testing.T) {
@@ -46,49 +52,113 @@ func TestHealthService_GetReadyz_SpiceDBUnavailable(t *testing.T) {
 	ctx := context.TODO()
 
 	d := &DummyZanzibar{}
-	service := createDummyHealthService(d)
+	service, prober := createDummyHealthService(d)
 	d.SetAvailable(false)
+	prober.Probe(ctx)
 	resp, err := service.GetReadyz(ctx, &pb.GetReadyzRequest{})
 
 	assert.NoError(t, err)
-	assert.Equal(t, &pb.GetReadyzResponse{Status: "Unavailable", Code: 503}, resp)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "Unavailable", Code: 503, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "Unavailable", Message: "Unavailable"},
+	}}, resp)
 }
 
-func TestHealthService_GetReadyz_StillReadyAfterBackendLaterUnavailable(t *testing.T) {
+func TestHealthService_GetReadyz_NotReadyBeforeFirstProbe(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+
+	d := &DummyZanzibar{available: true}
+	service, _ := createDummyHealthService(d)
+	resp, err := service.GetReadyz(ctx, &pb.GetReadyzRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "Unavailable", Code: 503, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "Unavailable", Message: "not probed yet"},
+	}}, resp)
+}
+
+func TestHealthService_GetReadyz_UnavailableAfterBackendLaterUnavailable(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.TODO()
 
 	d := &DummyZanzibar{}
-	service := createDummyHealthService(d)
+	service, prober := createDummyHealthService(d)
+	prober.Probe(ctx)
 	resp, err := service.GetReadyz(ctx, &pb.GetReadyzRequest{})
 
 	assert.NoError(t, err)
-	assert.Equal(t, &pb.GetReadyzResponse{Status: "Unavailable", Code: 503}, resp)
+	assert.Equal(t, "Unavailable", resp.Status)
 
 	d.SetAvailable(true)
+	prober.Probe(ctx)
 	resp, err = service.GetReadyz(ctx, &pb.GetReadyzRequest{})
 
 	assert.NoError(t, err)
-	assert.Equal(t, &pb.GetReadyzResponse{Status: "OK", Code: 200}, resp)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "OK", Code: 200, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "OK"},
+	}}, resp)
 
 	d.SetAvailable(false)
+	prober.Probe(ctx)
 	resp, err = service.GetReadyz(ctx, &pb.GetReadyzRequest{})
 
 	assert.NoError(t, err)
-	assert.Equal(t, &pb.GetReadyzResponse{Status: "OK", Code: 200}, resp)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "Unavailable", Code: 503, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "Unavailable", Message: "Unavailable"},
+	}}, resp)
+}
+
+func TestHealthService_GetReadyz_UnavailableWhileCircuitOpen(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+
+	d := &DummyZanzibar{available: true}
+	service, prober := createDummyHealthService(d)
+	prober.Probe(ctx)
+	resp, err := service.GetReadyz(ctx, &pb.GetReadyzRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "OK", Code: 200, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "OK"},
+	}}, resp)
+
+	// unavailable without waiting for the next probe
+	d.SetCircuitOpen(true)
+	resp, err = service.GetReadyz(ctx, &pb.GetReadyzRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "Unavailable", Code: 503, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "OK"},
+		{Name: "circuitBreaker", Status: "Unavailable", Message: "spicedb circuit breaker is open"},
+	}}, resp)
+
+	d.SetCircuitOpen(false)
+	resp, err = service.GetReadyz(ctx, &pb.GetReadyzRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GetReadyzResponse{Status: "OK", Code: 200, Components: []*pb.ComponentStatus{
+		{Name: "spicedb", Status: "OK"},
+	}}, resp)
 }
 
 type DummyZanzibar struct {
 	biz.ZanzibarRepository
-	available bool
+	available   bool
+	circuitOpen bool
 }
 
 func (dz *DummyZanzibar) SetAvailable(available bool) {
 	dz.available = available
 }
 
-func (dz *DummyZanzibar) IsBackendAvailable() error {
+func (dz *DummyZanzibar) SetCircuitOpen(open bool) {
+	dz.circuitOpen = open
+}
+
+func (dz *DummyZanzibar) IsBackendAvailable(ctx context.Context) error {
 	if !dz.available {
 		return fmt.Errorf("Unavailable")
 	} else {
@@ -96,10 +166,21 @@ func (dz *DummyZanzibar) IsBackendAvailable() error {
 	}
 }
 
-func createDummyHealthService(d *DummyZanzibar) *HealthService {
-	return NewHealthService(biz.NewIsBackendAvailableUsecase(d))
+func (dz *DummyZanzibar) IsCircuitOpen() bool {
+	return dz.circuitOpen
+}
+
+func createDummyHealthService(d *DummyZanzibar) (*HealthService, *biz.HealthProber) {
+	return newHealthService(biz.NewIsBackendAvailableUsecase(d))
+}
+
+func createHealthService(spicedb *data.SpiceDbRepository) (*HealthService, *biz.HealthProber) {
+	return newHealthService(biz.NewIsBackendAvailableUsecase(spicedb))
 }
 
-func createHealthService(spicedb *data.SpiceDbRepository) *HealthService {
-	return NewHealthService(biz.NewIsBackendAvailableUsecase(spicedb))
+// newHealthService creates a service whose prober only probes the backend, and only when the test calls Probe.
+func newHealthService(backend *biz.IsBackendAvaliableUsecase) (*HealthService, *biz.HealthProber) {
+	prober := biz.NewHealthProber(time.Second, time.Second, 1, 1, nil, log.DefaultLogger)
+	prober.Register("spicedb", backend.IsBackendAvailable)
+	return NewHealthService(backend, prober), prober
 }
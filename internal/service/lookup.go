@@ -2,9 +2,12 @@ package service
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
 	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
 	"github.com/project-kessel/relations-api/internal/biz"
 )
 
@@ -12,20 +15,38 @@ type LookupService struct {
 	pb.UnimplementedKesselLookupServiceServer
 	subjectsUsecase  *biz.GetSubjectsUsecase
 	resourcesUsecase *biz.GetResourcesUsecase
+	auditor          *audit.Auditor
+	metrics          *Metrics
 	log              *log.Helper
 }
 
-func NewLookupService(logger log.Logger, subjectsUseCase *biz.GetSubjectsUsecase, resourcesUsecase *biz.GetResourcesUsecase) *LookupService {
+func NewLookupService(logger log.Logger, subjectsUseCase *biz.GetSubjectsUsecase, resourcesUsecase *biz.GetResourcesUsecase, auditor *audit.Auditor, metrics *Metrics) *LookupService {
 	return &LookupService{
 		subjectsUsecase:  subjectsUseCase,
 		resourcesUsecase: resourcesUsecase,
+		auditor:          auditor,
+		metrics:          metrics,
 		log:              log.NewHelper(logger),
 	}
 
 }
 
-func (s *LookupService) LookupSubjects(req *pb.LookupSubjectsRequest, conn pb.KesselLookupService_LookupSubjectsServer) error {
+func (s *LookupService) LookupSubjects(req *pb.LookupSubjectsRequest, conn pb.KesselLookupService_LookupSubjectsServer) (err error) {
 	ctx := conn.Context()
+	start := time.Now()
+	sent := 0
+	defer func() {
+		s.metrics.lookedUp(ctx, "subjects", req.GetSubjectType(), sent, start, err)
+		subjects := &pb.SubjectReference{Subject: &pb.ObjectReference{Type: req.GetSubjectType(), Id: "*"}, Relation: req.SubjectRelation}
+		auditRead(ctx, s.auditor, audit.Event{
+			Message:      "Subjects looked up",
+			Action:       "LOOKUP",
+			ResourceType: "permission",
+			ResourceID:   auditObject(req.GetResource()) + "#" + req.GetRelation(),
+			Tuples:       []string{auditTuple(req.GetResource(), req.GetRelation(), subjects)},
+			Details:      map[string]string{"result_count": strconv.Itoa(sent)},
+		}, err)
+	}()
 
 	subs, errs, err := s.subjectsUsecase.Get(ctx, req)
 
@@ -42,6 +63,7 @@ func (s *LookupService) LookupSubjects(req *pb.LookupSubjectsRequest, conn pb.Ke
 		if err != nil {
 			return fmt.Errorf("error sending retrieved subject to the client: %w", err)
 		}
+		sent++
 	}
 
 	err, ok := <-errs
@@ -52,8 +74,22 @@ func (s *LookupService) LookupSubjects(req *pb.LookupSubjectsRequest, conn pb.Ke
 	return nil
 }
 
-func (s *LookupService) LookupResources(req *pb.LookupResourcesRequest, conn pb.KesselLookupService_LookupResourcesServer) error {
+func (s *LookupService) LookupResources(req *pb.LookupResourcesRequest, conn pb.KesselLookupService_LookupResourcesServer) (err error) {
 	ctx := conn.Context()
+	start := time.Now()
+	sent := 0
+	defer func() {
+		s.metrics.lookedUp(ctx, "resources", req.GetResourceType(), sent, start, err)
+		resources := &pb.ObjectReference{Type: req.GetResourceType(), Id: "*"}
+		auditRead(ctx, s.auditor, audit.Event{
+			Message:      "Resources looked up",
+			Action:       "LOOKUP",
+			ResourceType: "permission",
+			ResourceID:   auditObject(resources) + "#" + req.GetRelation(),
+			Tuples:       []string{auditTuple(resources, req.GetRelation(), req.GetSubject())},
+			Details:      map[string]string{"result_count": strconv.Itoa(sent)},
+		}, err)
+	}()
 
 	res, errs, err := s.resourcesUsecase.Get(ctx, req)
 
@@ -69,6 +105,7 @@ func (s *LookupService) LookupResources(req *pb.LookupResourcesRequest, conn pb.
 		if err != nil {
 			return fmt.Errorf("error sending retrieved resource to the client: %w", err)
 		}
+		sent++
 	}
 	err, ok := <-errs
 	if ok {
@@ -6,12 +6,14 @@ import (
 	"testing"
 
 	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
 	"github.com/project-kessel/relations-api/internal/biz"
 	"github.com/project-kessel/relations-api/internal/data"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/metric/noop"
 	"google.golang.org/grpc"
 )
 
@@ -471,7 +473,11 @@ func createLookupService(spicedb *data.SpiceDbRepository) *LookupService {
 		"trace.id", tracing.TraceID(),
 		"span.id", tracing.SpanID(),
 	)
-	return NewLookupService(logger, biz.NewGetSubjectsUseCase(spicedb), biz.NewGetResourcesUseCase(spicedb))
+	metrics, err := NewMetrics(noop.NewMeterProvider().Meter(""))
+	if err != nil {
+		panic(err)
+	}
+	return NewLookupService(logger, biz.NewGetSubjectsUseCase(spicedb), biz.NewGetResourcesUseCase(spicedb), audit.NewLogAuditor(logger), metrics)
 }
 func seedWidgetInDefaultWorkspace(ctx context.Context, spicedb *data.SpiceDbRepository, thing string) (*v1beta1.CreateTuplesResponse, error) {
 	return spicedb.CreateRelationships(ctx, []*v1beta1.Relationship{
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+const (
+	// ChecksCounterName counts permission checks, bulk items included, by resource type, permission, result and
+	// whether they were checked for update.
+	ChecksCounterName = "kessel_relations_checks"
+	// CheckBulkSizeHistogramName is the number of items of bulk checks.
+	CheckBulkSizeHistogramName = "kessel_relations_check_bulk_size"
+	// LookupResultsHistogramName is the number of results streamed by lookups, by lookup and type looked up.
+	LookupResultsHistogramName = "kessel_relations_lookup_results"
+	// LookupDurationHistogramName is the duration of lookup streams, by lookup and type looked up.
+	LookupDurationHistogramName = "kessel_relations_lookup_duration_seconds"
+)
+
+// Attributes of the service metrics. Types and permissions are recorded only for calls that succeeded, whose values
+// SpiceDB checked against the schema, so callers cannot grow the number of series.
+const (
+	resourceTypeAttr = attribute.Key("resource_type")
+	permissionAttr   = attribute.Key("permission")
+	resultAttr       = attribute.Key("result")
+	forUpdateAttr    = attribute.Key("for_update")
+	lookupAttr       = attribute.Key("lookup")
+	typeAttr         = attribute.Key("type")
+	outcomeAttr      = attribute.Key("outcome")
+)
+
+// Metrics records the authorization decisions and lookups served.
+type Metrics struct {
+	checks         metric.Int64Counter
+	bulkSize       metric.Int64Histogram
+	lookupResults  metric.Int64Histogram
+	lookupDuration metric.Float64Histogram
+}
+
+func NewMetrics(meter metric.Meter) (*Metrics, error) {
+	checks, err := meter.Int64Counter(
+		ChecksCounterName,
+		metric.WithUnit("{check}"),
+		metric.WithDescription("The total number of permission checks, by resource type, permission and result"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	bulkSize, err := meter.Int64Histogram(
+		CheckBulkSizeHistogramName,
+		metric.WithUnit("{item}"),
+		metric.WithDescription("The number of items of bulk checks"),
+		metric.WithExplicitBucketBoundaries(1, 2, 5, 10, 25, 50, 100, 250, 500, 1000),
+	)
+	if err != nil {
+		return nil, err
+	}
+	lookupResults, err := meter.Int64Histogram(
+		LookupResultsHistogramName,
+		metric.WithUnit("{result}"),
+		metric.WithDescription("The number of results streamed by lookups"),
+		metric.WithExplicitBucketBoundaries(0, 1, 10, 100, 1000, 10000, 100000, 1000000),
+	)
+	if err != nil {
+		return nil, err
+	}
+	lookupDuration, err := meter.Float64Histogram(
+		LookupDurationHistogramName,
+		metric.WithUnit("s"),
+		metric.WithDescription("The duration of lookup streams"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Metrics{checks: checks, bulkSize: bulkSize, lookupResults: lookupResults, lookupDuration: lookupDuration}, nil
+}
+
+// checkResult names the outcome of a check from the name of its Allowed value, which every check response shares.
+func checkResult(allowed string, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case allowed == pb.CheckResponse_ALLOWED_TRUE.String():
+		return "allowed"
+	case allowed == pb.CheckResponse_ALLOWED_FALSE.String():
+		return "denied"
+	}
+	return "unspecified"
+}
+
+func objectType(t *pb.ObjectType) string {
+	return t.GetNamespace() + "/" + t.GetName()
+}
+
+func (m *Metrics) checked(ctx context.Context, resource *pb.ObjectReference, permission string, forUpdate bool, result string) {
+	attrs := []attribute.KeyValue{resultAttr.String(result), forUpdateAttr.Bool(forUpdate)}
+	if result != "error" {
+		attrs = append(attrs, resourceTypeAttr.String(objectType(resource.GetType())), permissionAttr.String(permission))
+	}
+	m.checks.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// bulkChecked records the size of a bulk check and the outcome of each of its items.
+func (m *Metrics) bulkChecked(ctx context.Context, items []*pb.CheckBulkRequestItem, pairs []*pb.CheckBulkResponsePair, forUpdate bool, err error) {
+	m.bulkSize.Record(ctx, int64(len(items)), metric.WithAttributes(forUpdateAttr.Bool(forUpdate)))
+	if err != nil {
+		m.checks.Add(ctx, int64(len(items)), metric.WithAttributes(resultAttr.String("error"), forUpdateAttr.Bool(forUpdate)))
+		return
+	}
+	for _, pair := range pairs {
+		if pair.GetError() != nil {
+			m.checked(ctx, pair.GetRequest().GetResource(), pair.GetRequest().GetRelation(), forUpdate, "error")
+			continue
+		}
+		m.checked(ctx, pair.GetRequest().GetResource(), pair.GetRequest().GetRelation(), forUpdate, checkResult(pair.GetItem().GetAllowed().String(), nil))
+	}
+}
+
+// lookedUp records the results and duration of a lookup of the given type of subjects or resources.
+func (m *Metrics) lookedUp(ctx context.Context, lookup string, t *pb.ObjectType, results int, start time.Time, err error) {
+	attrs := []attribute.KeyValue{lookupAttr.String(lookup), outcomeAttr.String("success")}
+	if err != nil {
+		attrs[1] = outcomeAttr.String("error")
+	} else {
+		attrs = append(attrs, typeAttr.String(objectType(t)))
+	}
+	m.lookupResults.Record(ctx, int64(results), metric.WithAttributes(attrs...))
+	m.lookupDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/genproto/googleapis/rpc/status"
+
+	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+)
+
+func newTestMetrics(t *testing.T) (*Metrics, *sdkmetric.ManualReader) {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	m, err := NewMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	require.NoError(t, err)
+	return m, reader
+}
+
+// collectMetrics returns the metrics recorded so far by name.
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func attrs(kvs ...attribute.KeyValue) string {
+	set := attribute.NewSet(kvs...)
+	return set.Encoded(attribute.DefaultEncoder())
+}
+
+func checkCounts(data metricdata.Aggregation) map[string]int64 {
+	values := map[string]int64{}
+	if sum, ok := data.(metricdata.Sum[int64]); ok {
+		for _, dp := range sum.DataPoints {
+			values[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp.Value
+		}
+	}
+	return values
+}
+
+func widget(id string) *pb.ObjectReference {
+	return &pb.ObjectReference{Type: &pb.ObjectType{Namespace: "rbac", Name: "widget"}, Id: id}
+}
+
+func TestCheckResult(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "allowed", checkResult(pb.CheckResponse_ALLOWED_TRUE.String(), nil))
+	assert.Equal(t, "denied", checkResult(pb.CheckForUpdateResponse_ALLOWED_FALSE.String(), nil))
+	assert.Equal(t, "unspecified", checkResult(pb.CheckBulkResponseItem_ALLOWED_UNSPECIFIED.String(), nil))
+	assert.Equal(t, "error", checkResult(pb.CheckResponse_ALLOWED_TRUE.String(), errors.New("unavailable")))
+}
+
+func TestMetrics_CountsChecksWithoutTypesOnError(t *testing.T) {
+	t.Parallel()
+	m, reader := newTestMetrics(t)
+	ctx := context.Background()
+
+	m.checked(ctx, widget("1"), "view", false, "allowed")
+	m.checked(ctx, widget("2"), "view", false, "allowed")
+	m.checked(ctx, widget("3"), "edit", true, "denied")
+	m.checked(ctx, &pb.ObjectReference{Type: &pb.ObjectType{Namespace: "made", Name: "up"}}, "anything", false, "error")
+
+	assert.Equal(t, map[string]int64{
+		attrs(resourceTypeAttr.String("rbac/widget"), permissionAttr.String("view"), resultAttr.String("allowed"), forUpdateAttr.Bool(false)): 2,
+		attrs(resourceTypeAttr.String("rbac/widget"), permissionAttr.String("edit"), resultAttr.String("denied"), forUpdateAttr.Bool(true)):   1,
+		attrs(resultAttr.String("error"), forUpdateAttr.Bool(false)):                                                                          1,
+	}, checkCounts(collectMetrics(t, reader)[ChecksCounterName]))
+}
+
+func TestMetrics_CountsEachItemOfBulkChecks(t *testing.T) {
+	t.Parallel()
+	m, reader := newTestMetrics(t)
+	ctx := context.Background()
+
+	items := []*pb.CheckBulkRequestItem{
+		{Resource: widget("1"), Relation: "view"},
+		{Resource: widget("2"), Relation: "view"},
+		{Resource: widget("3"), Relation: "view"},
+	}
+	m.bulkChecked(ctx, items, []*pb.CheckBulkResponsePair{
+		{Request: items[0], Response: &pb.CheckBulkResponsePair_Item{Item: &pb.CheckBulkResponseItem{Allowed: pb.CheckBulkResponseItem_ALLOWED_TRUE}}},
+		{Request: items[1], Response: &pb.CheckBulkResponsePair_Item{Item: &pb.CheckBulkResponseItem{Allowed: pb.CheckBulkResponseItem_ALLOWED_FALSE}}},
+		{Request: items[2], Response: &pb.CheckBulkResponsePair_Error{Error: &status.Status{Code: 5}}},
+	}, false, nil)
+	m.bulkChecked(ctx, items[:2], nil, false, errors.New("unavailable"))
+
+	metrics := collectMetrics(t, reader)
+	assert.Equal(t, map[string]int64{
+		attrs(resourceTypeAttr.String("rbac/widget"), permissionAttr.String("view"), resultAttr.String("allowed"), forUpdateAttr.Bool(false)): 1,
+		attrs(resourceTypeAttr.String("rbac/widget"), permissionAttr.String("view"), resultAttr.String("denied"), forUpdateAttr.Bool(false)):  1,
+		attrs(resultAttr.String("error"), forUpdateAttr.Bool(false)):                                                                          3,
+	}, checkCounts(metrics[ChecksCounterName]))
+
+	sizes, ok := metrics[CheckBulkSizeHistogramName].(metricdata.Histogram[int64])
+	require.True(t, ok)
+	require.Len(t, sizes.DataPoints, 1)
+	assert.Equal(t, uint64(2), sizes.DataPoints[0].Count)
+	assert.Equal(t, int64(5), sizes.DataPoints[0].Sum)
+}
+
+func TestMetrics_RecordsLookupResultsByType(t *testing.T) {
+	t.Parallel()
+	m, reader := newTestMetrics(t)
+	ctx := context.Background()
+
+	m.lookedUp(ctx, "resources", widget("").GetType(), 3, time.Now(), nil)
+	m.lookedUp(ctx, "resources", widget("").GetType(), 5, time.Now(), nil)
+	m.lookedUp(ctx, "subjects", &pb.ObjectType{Namespace: "made", Name: "up"}, 0, time.Now(), errors.New("unavailable"))
+
+	results, ok := collectMetrics(t, reader)[LookupResultsHistogramName].(metricdata.Histogram[int64])
+	require.True(t, ok)
+	sums := map[string]int64{}
+	for _, dp := range results.DataPoints {
+		sums[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp.Sum
+	}
+	assert.Equal(t, map[string]int64{
+		attrs(lookupAttr.String("resources"), typeAttr.String("rbac/widget"), outcomeAttr.String("success")): 8,
+		attrs(lookupAttr.String("subjects"), outcomeAttr.String("error")):                                    0,
+	}, sums)
+}
@@ -3,11 +3,15 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/project-kessel/relations-api/internal/audit"
 	"github.com/project-kessel/relations-api/internal/biz"
 
+	kerrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 
 	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
@@ -20,10 +24,11 @@ type RelationshipsService struct {
 	deleteUsecase      *biz.DeleteRelationshipsUsecase
 	importBulkUsecase  *biz.ImportBulkTuplesUsecase
 	acquireLockUsecase *biz.AcquireLockUsecase
+	auditor            *audit.Auditor
 	log                *log.Helper
 }
 
-func NewRelationshipsService(logger log.Logger, createUseCase *biz.CreateRelationshipsUsecase, readUsecase *biz.ReadRelationshipsUsecase, deleteUsecase *biz.DeleteRelationshipsUsecase, importBulkUsecase *biz.ImportBulkTuplesUsecase, acquireLockUsecase *biz.AcquireLockUsecase) *RelationshipsService {
+func NewRelationshipsService(logger log.Logger, createUseCase *biz.CreateRelationshipsUsecase, readUsecase *biz.ReadRelationshipsUsecase, deleteUsecase *biz.DeleteRelationshipsUsecase, importBulkUsecase *biz.ImportBulkTuplesUsecase, acquireLockUsecase *biz.AcquireLockUsecase, auditor *audit.Auditor) *RelationshipsService {
 	return &RelationshipsService{
 		log:                log.NewHelper(logger),
 		createUsecase:      createUseCase,
@@ -31,34 +36,42 @@ func NewRelationshipsService(logger log.Logger, createUseCase *biz.CreateRelatio
 		deleteUsecase:      deleteUsecase,
 		importBulkUsecase:  importBulkUsecase,
 		acquireLockUsecase: acquireLockUsecase,
+		auditor:            auditor,
 	}
 }
 
 func (s *RelationshipsService) CreateTuples(ctx context.Context, req *pb.CreateTuplesRequest) (*pb.CreateTuplesResponse, error) {
-	resp, err := s.createUsecase.CreateRelationships(ctx, req.Tuples, req.GetUpsert(), req.GetFencingCheck()) //The generated .GetUpsert() defaults to false
+	// copied for the audit event before the repository prefixes their relations
+	tuples := cloneTuples(req.Tuples)
+	resp, err := s.createUsecase.CreateRelationships(biz.NewPrincipalContext(ctx, extractPrincipal(ctx)), req.Tuples, req.GetUpsert(), req.GetFencingCheck()) //The generated .GetUpsert() defaults to false
 	if err != nil {
 		// Tuple creation failure - SEC-MON-REQ-1 compliance (EOI-1 pii_manipulation, EOI-4 access_manipulation, EOI-11 warnings_or_errors)
-		s.log.WithContext(ctx).Warnw(
-			"msg", "Tuple creation failed",
-			"action", "CREATE",
-			"resource_type", "relationship_tuple",
-			"resource_id", fmt.Sprintf("count:%d", len(req.Tuples)),
-			"outcome", "failure",
-			"principal", extractPrincipal(ctx),
-			"reason", "spicedb_error",
-		)
+		s.auditor.Record(ctx, audit.Event{
+			Message:      "Tuple creation failed",
+			Action:       "CREATE",
+			ResourceType: "relationship_tuple",
+			ResourceID:   fmt.Sprintf("count:%d", len(req.Tuples)),
+			Outcome:      audit.OutcomeFailure,
+			Principal:    extractPrincipal(ctx),
+			Reason:       "spicedb_error",
+		})
 		return nil, fmt.Errorf("error creating tuples: %w", err)
 	}
 
 	// Tuple creation - SEC-MON-REQ-1 compliance (EOI-1 pii_manipulation, EOI-4 access_manipulation)
-	s.log.WithContext(ctx).Infow(
-		"msg", "Tuples created",
-		"action", "CREATE",
-		"resource_type", "relationship_tuple",
-		"resource_id", fmt.Sprintf("count:%d", len(req.Tuples)),
-		"outcome", "success",
-		"principal", extractPrincipal(ctx),
-	)
+	created := audit.Event{
+		Message:          "Tuples created",
+		Action:           "CREATE",
+		ResourceType:     "relationship_tuple",
+		ResourceID:       fmt.Sprintf("count:%d", len(req.Tuples)),
+		Outcome:          audit.OutcomeSuccess,
+		Principal:        extractPrincipal(ctx),
+		ConsistencyToken: resp.GetConsistencyToken().GetToken(),
+	}
+	if max := s.auditor.MaxTupleDetails(); max > 0 {
+		created.Tuples, created.TuplesTruncated = auditTuples(tuples, max)
+	}
+	s.auditor.Record(ctx, created)
 
 	return &pb.CreateTuplesResponse{ConsistencyToken: resp.GetConsistencyToken()}, nil
 }
@@ -93,33 +106,56 @@ func (s *RelationshipsService) ReadTuples(req *pb.ReadTuplesRequest, conn pb.Kes
 
 func (s *RelationshipsService) DeleteTuples(ctx context.Context, req *pb.DeleteTuplesRequest) (*pb.DeleteTuplesResponse, error) {
 	resourceID := deleteFilterResourceID(req.Filter)
-
-	resp, err := s.deleteUsecase.DeleteRelationships(ctx, req.Filter, req.GetFencingCheck())
+	resp, err := s.deleteUsecase.DeleteRelationships(biz.NewPrincipalContext(ctx, extractPrincipal(ctx)), req.Filter, req.GetFencingCheck(), biz.DeleteOptions{
+		DryRun:            req.GetDryRun(),
+		Limit:             req.GetLimit(),
+		OverrideThreshold: req.GetOverrideThreshold(),
+		ListDeleted:       uint32(s.auditor.MaxTupleDetails()),
+	})
 	if err != nil {
+		reason := "spicedb_error"
+		if kerrors.Reason(err) == biz.DeleteThresholdExceededReason {
+			reason = "threshold_exceeded"
+		}
 		// Tuple deletion failure - SEC-MON-REQ-1 compliance (EOI-1 pii_manipulation, EOI-4 access_manipulation, EOI-11 warnings_or_errors)
-		s.log.WithContext(ctx).Warnw(
-			"msg", "Tuple deletion failed",
-			"action", "DELETE",
-			"resource_type", "relationship_tuple",
-			"resource_id", resourceID,
-			"outcome", "failure",
-			"principal", extractPrincipal(ctx),
-			"reason", "spicedb_error",
-		)
+		s.auditor.Record(ctx, audit.Event{
+			Message:      "Tuple deletion failed",
+			Action:       "DELETE",
+			ResourceType: "relationship_tuple",
+			ResourceID:   resourceID,
+			Outcome:      audit.OutcomeFailure,
+			Principal:    extractPrincipal(ctx),
+			Reason:       reason,
+		})
 		return nil, fmt.Errorf("error deleting tuples: %w", err)
 	}
 
+	if req.GetDryRun() {
+		return resp.DeleteTuplesResponse, nil
+	}
+
 	// Tuple deletion - SEC-MON-REQ-1 compliance (EOI-1 pii_manipulation, EOI-4 access_manipulation)
-	s.log.WithContext(ctx).Infow(
-		"msg", "Tuples deleted",
-		"action", "DELETE",
-		"resource_type", "relationship_tuple",
-		"resource_id", resourceID,
-		"outcome", "success",
-		"principal", extractPrincipal(ctx),
-	)
-
-	return &pb.DeleteTuplesResponse{ConsistencyToken: resp.GetConsistencyToken()}, nil
+	deleted := audit.Event{
+		Message:          "Tuples deleted",
+		Action:           "DELETE",
+		ResourceType:     "relationship_tuple",
+		ResourceID:       resourceID,
+		Outcome:          audit.OutcomeSuccess,
+		Principal:        extractPrincipal(ctx),
+		ConsistencyToken: resp.GetConsistencyToken().GetToken(),
+	}
+	if max := s.auditor.MaxTupleDetails(); max > 0 {
+		// the repository lists the tuples deleted, up to the maximum
+		deleted.Tuples, _ = auditTuples(resp.Deleted, max)
+		deleted.TuplesTruncated = uint64(len(deleted.Tuples)) < resp.GetDeletedCount()
+	}
+	s.auditor.Record(ctx, deleted)
+
+	return &pb.DeleteTuplesResponse{
+		ConsistencyToken: resp.GetConsistencyToken(),
+		DeletedCount:     resp.GetDeletedCount(),
+		MoreRemaining:    resp.GetMoreRemaining(),
+	}, nil
 }
 
 func deleteFilterResourceID(filter *pb.RelationTupleFilter) string {
@@ -141,59 +177,94 @@ func deleteFilterResourceID(filter *pb.RelationTupleFilter) string {
 	return "filtered"
 }
 
+func cloneTuples(tuples []*pb.Relationship) []*pb.Relationship {
+	clones := make([]*pb.Relationship, 0, len(tuples))
+	for _, t := range tuples {
+		clones = append(clones, proto.Clone(t).(*pb.Relationship))
+	}
+	return clones
+}
+
 func (s *RelationshipsService) ImportBulkTuples(stream grpc.ClientStreamingServer[pb.ImportBulkTuplesRequest, pb.ImportBulkTuplesResponse]) error {
 	ctx := stream.Context()
-	err := s.importBulkUsecase.ImportBulkTuples(stream)
+	counted := &countingImportStream{ClientStreamingServer: stream, counts: map[string]int{}}
+	err := s.importBulkUsecase.ImportBulkTuples(counted)
 	if err != nil {
 		// Bulk tuple import failure - SEC-MON-REQ-1 compliance (EOI-1 pii_manipulation, EOI-4 access_manipulation, EOI-11 warnings_or_errors)
-		s.log.WithContext(ctx).Warnw(
-			"msg", "Bulk tuple import failed",
-			"action", "IMPORT",
-			"resource_type", "relationship_tuple",
-			"resource_id", "bulk_import",
-			"outcome", "failure",
-			"principal", extractPrincipal(ctx),
-			"reason", "import_error",
-		)
+		s.auditor.Record(ctx, audit.Event{
+			Message:      "Bulk tuple import failed",
+			Action:       "IMPORT",
+			ResourceType: "relationship_tuple",
+			ResourceID:   "bulk_import",
+			Outcome:      audit.OutcomeFailure,
+			Principal:    extractPrincipal(ctx),
+			Reason:       "import_error",
+			Details:      counted.details(),
+		})
 		return fmt.Errorf("error import bulk tuples: %w", err)
 	}
 
 	// Bulk tuple import - SEC-MON-REQ-1 compliance (EOI-1 pii_manipulation, EOI-4 access_manipulation)
-	s.log.WithContext(ctx).Infow(
-		"msg", "Bulk tuples imported",
-		"action", "IMPORT",
-		"resource_type", "relationship_tuple",
-		"resource_id", "bulk_import",
-		"outcome", "success",
-		"principal", extractPrincipal(ctx),
-	)
+	s.auditor.Record(ctx, audit.Event{
+		Message:      "Bulk tuples imported",
+		Action:       "IMPORT",
+		ResourceType: "relationship_tuple",
+		ResourceID:   "bulk_import",
+		Outcome:      audit.OutcomeSuccess,
+		Principal:    extractPrincipal(ctx),
+		Details:      counted.details(),
+	})
 	return nil
 }
 
+// countingImportStream counts the tuples an import receives per resource type, for its audit entry.
+type countingImportStream struct {
+	grpc.ClientStreamingServer[pb.ImportBulkTuplesRequest, pb.ImportBulkTuplesResponse]
+	counts map[string]int
+}
+
+func (s *countingImportStream) Recv() (*pb.ImportBulkTuplesRequest, error) {
+	req, err := s.ClientStreamingServer.Recv()
+	for _, tuple := range req.GetTuples() {
+		resourceType := tuple.GetResource().GetType()
+		s.counts[resourceType.GetNamespace()+"/"+resourceType.GetName()]++
+	}
+	return req, err
+}
+
+// details returns the number of tuples received per resource type as "tuples.ns/type".
+func (s *countingImportStream) details() map[string]string {
+	details := make(map[string]string, len(s.counts))
+	for resourceType, count := range s.counts {
+		details["tuples."+resourceType] = strconv.Itoa(count)
+	}
+	return details
+}
+
 func (s *RelationshipsService) AcquireLock(ctx context.Context, req *pb.AcquireLockRequest) (*pb.AcquireLockResponse, error) {
 	resp, err := s.acquireLockUsecase.AcquireLock(ctx, req)
 	if err != nil {
 		// Lock acquisition failure - SEC-MON-REQ-1 compliance (EOI-4 access_manipulation, EOI-11 warnings_or_errors)
-		s.log.WithContext(ctx).Warnw(
-			"msg", "Lock acquisition failed",
-			"action", "CREATE",
-			"resource_type", "lock",
-			"resource_id", req.GetLockId(),
-			"outcome", "failure",
-			"principal", extractPrincipal(ctx),
-			"reason", "lock_error",
-		)
+		s.auditor.Record(ctx, audit.Event{
+			Message:      "Lock acquisition failed",
+			Action:       "CREATE",
+			ResourceType: "lock",
+			ResourceID:   req.GetLockId(),
+			Outcome:      audit.OutcomeFailure,
+			Principal:    extractPrincipal(ctx),
+			Reason:       "lock_error",
+		})
 		return nil, fmt.Errorf("error acquiring lock: %w", err)
 	}
 
 	// Lock acquisition - SEC-MON-REQ-1 compliance (EOI-4 access_manipulation)
-	s.log.WithContext(ctx).Infow(
-		"msg", "Lock acquired",
-		"action", "CREATE",
-		"resource_type", "lock",
-		"resource_id", req.GetLockId(),
-		"outcome", "success",
-		"principal", extractPrincipal(ctx),
-	)
+	s.auditor.Record(ctx, audit.Event{
+		Message:      "Lock acquired",
+		Action:       "CREATE",
+		ResourceType: "lock",
+		ResourceID:   req.GetLockId(),
+		Outcome:      audit.OutcomeSuccess,
+		Principal:    extractPrincipal(ctx),
+	})
 	return resp, nil
 }
@@ -2,18 +2,25 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	v1beta1 "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/audit"
 	"github.com/project-kessel/relations-api/internal/biz"
+	"github.com/project-kessel/relations-api/internal/conf"
 	"github.com/project-kessel/relations-api/internal/data"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -435,7 +442,7 @@ func setup(t *testing.T) (*RelationshipsService, error) {
 	deleteRelationshipsUsecase := biz.NewDeleteRelationshipsUsecase(spiceDbRepository, logger)
 	importBulkUsecase := biz.NewImportBulkTuplesUsecase(spiceDbRepository, logger)
 	acquireLockUsecase := biz.NewAcquireLockUsecase(spiceDbRepository, logger)
-	relationshipsService := NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, importBulkUsecase, acquireLockUsecase)
+	relationshipsService := NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, importBulkUsecase, acquireLockUsecase, audit.NewLogAuditor(logger))
 	return relationshipsService, err
 }
 
@@ -457,7 +464,7 @@ func TestRelationshipsService_ReadRelationships(t *testing.T) {
 	deleteRelationshipsUsecase := biz.NewDeleteRelationshipsUsecase(spiceDbRepository, logger)
 	bulkImportTuplesUsecase := biz.NewImportBulkTuplesUsecase(spiceDbRepository, logger)
 	acquireLockUsecase := biz.NewAcquireLockUsecase(spiceDbRepository, logger)
-	relationshipsService := NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, bulkImportTuplesUsecase, acquireLockUsecase)
+	relationshipsService := NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, bulkImportTuplesUsecase, acquireLockUsecase, audit.NewLogAuditor(logger))
 
 	expected := createRelationship(rbac_ns_type("group"), "bob_club", "member", rbac_ns_type("principal"), "bob", "")
 
@@ -513,7 +520,7 @@ func TestRelationshipsService_ReadRelationships_Paginated(t *testing.T) {
 	deleteRelationshipsUsecase := biz.NewDeleteRelationshipsUsecase(spiceDbRepository, logger)
 	bulkImportTuplesUsecase := biz.NewImportBulkTuplesUsecase(spiceDbRepository, logger)
 	acquireLockUsecase := biz.NewAcquireLockUsecase(spiceDbRepository, logger)
-	relationshipsService := NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, bulkImportTuplesUsecase, acquireLockUsecase)
+	relationshipsService := NewRelationshipsService(logger, createRelationshipsUsecase, readRelationshipsUsecase, deleteRelationshipsUsecase, bulkImportTuplesUsecase, acquireLockUsecase, audit.NewLogAuditor(logger))
 
 	expected1 := createRelationship(rbac_ns_type("group"), "bob_club", "member", rbac_ns_type("principal"), "bob", "")
 	expected2 := createRelationship(rbac_ns_type("group"), "other_bob_club", "member", rbac_ns_type("principal"), "bob", "")
@@ -597,6 +604,67 @@ func createRelationship(objectType *v1beta1.ObjectType, objectId string, relatio
 	}
 }
 
+func TestRelationshipsService_AuditsEveryTupleChanged(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := log.NewStdLogger(os.Stdout)
+	spiceDbRepository, err := container.CreateSpiceDbRepository()
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditor, closeAuditor, err := audit.New(&conf.Server_Audit{
+		Sinks:           []*conf.Server_Audit_Sink{{Type: "file", Path: path}},
+		TupleDetails:    true,
+		MaxTupleDetails: 2,
+	}, logger)
+	require.NoError(t, err)
+	relationshipsService := NewRelationshipsService(logger,
+		biz.NewCreateRelationshipsUsecase(spiceDbRepository, logger),
+		biz.NewReadRelationshipsUsecase(spiceDbRepository, logger),
+		biz.NewDeleteRelationshipsUsecase(spiceDbRepository, logger),
+		biz.NewImportBulkTuplesUsecase(spiceDbRepository, logger),
+		biz.NewAcquireLockUsecase(spiceDbRepository, logger),
+		auditor,
+	)
+
+	var tuples []*v1beta1.Relationship
+	for _, member := range []string{"alice", "bob", "carol"} {
+		tuples = append(tuples, createRelationship(rbac_ns_type("group"), "audited_club", "member", rbac_ns_type("principal"), member, ""))
+	}
+	created, err := relationshipsService.CreateTuples(ctx, &v1beta1.CreateTuplesRequest{Upsert: true, Tuples: tuples[:2]})
+	require.NoError(t, err)
+	_, err = relationshipsService.CreateTuples(ctx, &v1beta1.CreateTuplesRequest{Upsert: true, Tuples: tuples[2:]})
+	require.NoError(t, err)
+	deleted, err := relationshipsService.DeleteTuples(ctx, &v1beta1.DeleteTuplesRequest{Filter: &v1beta1.RelationTupleFilter{
+		ResourceNamespace: pointerize("rbac"),
+		ResourceType:      pointerize("group"),
+		ResourceId:        pointerize("audited_club"),
+		Relation:          pointerize("member"),
+	}})
+	require.NoError(t, err)
+	closeAuditor()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entries []audit.Event
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var e audit.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{
+		"rbac/group:audited_club#member rbac/principal:alice",
+		"rbac/group:audited_club#member rbac/principal:bob",
+	}, entries[0].Tuples)
+	assert.False(t, entries[0].TuplesTruncated)
+	assert.Equal(t, created.GetConsistencyToken().GetToken(), entries[0].ConsistencyToken)
+	assert.Equal(t, "DELETE", entries[2].Action)
+	assert.Len(t, entries[2].Tuples, 2)
+	assert.True(t, entries[2].TuplesTruncated, "three tuples were deleted, two are listed")
+	assert.Equal(t, deleted.GetConsistencyToken().GetToken(), entries[2].ConsistencyToken)
+}
+
 // Below is the boilerplate for creating test servers for streaming ReadTuples rpc
 
 func NewRelationships_ReadRelationshipsServerStub(ctx context.Context) *Relationships_ReadRelationshipsServerStub {
@@ -607,6 +675,46 @@ func NewRelationships_ReadRelationshipsServerStub(ctx context.Context) *Relation
 	}
 }
 
+// importStreamStub sends the requests of an import, one per Recv.
+type importStreamStub struct {
+	grpc.ClientStreamingServer[v1beta1.ImportBulkTuplesRequest, v1beta1.ImportBulkTuplesResponse]
+	requests []*v1beta1.ImportBulkTuplesRequest
+}
+
+func (x *importStreamStub) Recv() (*v1beta1.ImportBulkTuplesRequest, error) {
+	if len(x.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := x.requests[0]
+	x.requests = x.requests[1:]
+	return req, nil
+}
+
+func TestCountingImportStream_CountsTuplesPerResourceType(t *testing.T) {
+	t.Parallel()
+
+	stream := &countingImportStream{
+		ClientStreamingServer: &importStreamStub{requests: []*v1beta1.ImportBulkTuplesRequest{
+			{Tuples: []*v1beta1.Relationship{
+				createRelationship(rbac_ns_type("group"), "g1", "member", rbac_ns_type("principal"), "alice", ""),
+				createRelationship(rbac_ns_type("workspace"), "w1", "parent", rbac_ns_type("workspace"), "root", ""),
+			}},
+			{Tuples: []*v1beta1.Relationship{
+				createRelationship(rbac_ns_type("group"), "g2", "member", rbac_ns_type("principal"), "bob", ""),
+			}},
+		}},
+		counts: map[string]int{},
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+	}
+
+	assert.Equal(t, map[string]string{"tuples.rbac/group": "2", "tuples.rbac/workspace": "1"}, stream.details())
+}
+
 type Relationships_ReadRelationshipsServerStub struct {
 	grpc.ServerStream
 	responses []*v1beta1.ReadTuplesResponse
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	pb "github.com/project-kessel/relations-api/api/kessel/relations/v1beta1"
+	"github.com/project-kessel/relations-api/internal/biz"
+)
+
+type SchemaService struct {
+	pb.UnimplementedKesselSchemaServiceServer
+	diff *biz.DiffSchemaUsecase
+	log  *log.Helper
+}
+
+func NewSchemaService(logger log.Logger, diffUsecase *biz.DiffSchemaUsecase) *SchemaService {
+	return &SchemaService{
+		diff: diffUsecase,
+		log:  log.NewHelper(logger),
+	}
+}
+
+func (s *SchemaService) DiffSchema(ctx context.Context, req *pb.DiffSchemaRequest) (*pb.DiffSchemaResponse, error) {
+	resp, err := s.diff.DiffSchema(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff schema: %w", err)
+	}
+	return resp, nil
+}
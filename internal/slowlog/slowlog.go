@@ -0,0 +1,151 @@
+// Package slowlog logs requests slower than the threshold of their operation, along with the SpiceDB work they
+// caused, sampling them so that busy operations do not flood the log.
+package slowlog
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"golang.org/x/time/rate"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const defaultThreshold = time.Second
+
+type policy struct {
+	threshold   time.Duration
+	sampleRatio float64
+}
+
+// Logger decides which slow requests are logged and logs them.
+type Logger struct {
+	fallback     policy
+	policies     map[string]policy
+	maxPerSecond rate.Limit
+	logger       log.Logger
+	random       func() float64
+	now          func() time.Time
+
+	mu         sync.Mutex
+	limiters   map[string]*rate.Limiter
+	suppressed map[string]int
+}
+
+// NewLogger creates a Logger from the slow request configuration.
+func NewLogger(c *conf.Server_SlowRequests, logger log.Logger) *Logger {
+	fallback := policy{threshold: defaultThreshold, sampleRatio: 1}
+	if c.GetThreshold() != nil {
+		fallback.threshold = c.GetThreshold().AsDuration()
+	}
+	if c.SampleRatio != nil {
+		fallback.sampleRatio = c.GetSampleRatio()
+	}
+	policies := make(map[string]policy, len(c.GetRules()))
+	for _, r := range c.GetRules() {
+		p := fallback
+		if r.GetThreshold() != nil {
+			p.threshold = r.GetThreshold().AsDuration()
+		}
+		if r.SampleRatio != nil {
+			p.sampleRatio = r.GetSampleRatio()
+		}
+		policies[r.GetOperation()] = p
+	}
+	return &Logger{
+		fallback:     fallback,
+		policies:     policies,
+		maxPerSecond: rate.Limit(c.GetMaxPerSecond()),
+		logger:       logger,
+		random:       rand.Float64,
+		now:          time.Now,
+		limiters:     make(map[string]*rate.Limiter),
+		suppressed:   make(map[string]int),
+	}
+}
+
+func (l *Logger) policyFor(operation string) policy {
+	if p, ok := l.policies[operation]; ok {
+		return p
+	}
+	return l.fallback
+}
+
+// Request describes a finished request.
+type Request struct {
+	Operation string
+	// Args renders the request, only called for requests that are logged.
+	Args    func() string
+	Latency time.Duration
+	Err     error
+	// Results is the number of results returned: checks answered or resources and subjects streamed.
+	Results int
+}
+
+// Finished logs r if it was slow and is sampled, with the SpiceDB work collected in the stats of ctx.
+func (l *Logger) Finished(ctx context.Context, r Request) {
+	p := l.policyFor(r.Operation)
+	if r.Latency < p.threshold || p.sampleRatio <= 0 {
+		return
+	}
+	suppressed, ok := l.admit(r.Operation, p.sampleRatio)
+	if !ok {
+		return
+	}
+
+	var (
+		code   int32
+		reason string
+	)
+	if se := errors.FromError(r.Err); se != nil {
+		code = se.Code
+		reason = se.Reason
+	}
+	keyvals := []any{
+		"msg", "slow request",
+		"kind", "server",
+		"operation", r.Operation,
+		"args", r.Args(),
+		"code", code,
+		"reason", reason,
+		"latency", r.Latency.Seconds(),
+		"threshold", p.threshold.Seconds(),
+		"results", r.Results,
+	}
+	if s := FromContext(ctx); s != nil {
+		keyvals = append(keyvals, s.keyvals()...)
+	}
+	if suppressed > 0 {
+		keyvals = append(keyvals, "suppressed", suppressed)
+	}
+	log.NewHelper(log.WithContext(ctx, l.logger)).Log(log.LevelWarn, keyvals...)
+}
+
+// admit samples a slow request of operation and applies the per operation rate of entries. It returns whether the
+// request is logged and, if so, how many slow requests of the operation were left out since the last one logged.
+func (l *Logger) admit(operation string, sampleRatio float64) (int, bool) {
+	if sampleRatio < 1 && l.random() >= sampleRatio {
+		return 0, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.maxPerSecond > 0 {
+		limiter, ok := l.limiters[operation]
+		if !ok {
+			limiter = rate.NewLimiter(l.maxPerSecond, int(math.Max(1, math.Ceil(float64(l.maxPerSecond)))))
+			l.limiters[operation] = limiter
+		}
+		if !limiter.AllowN(l.now(), 1) {
+			l.suppressed[operation]++
+			return 0, false
+		}
+	}
+	suppressed := l.suppressed[operation]
+	delete(l.suppressed, operation)
+	return suppressed, true
+}
@@ -0,0 +1,157 @@
+package slowlog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/project-kessel/relations-api/internal/conf"
+)
+
+const (
+	check           = "/kessel.relations.v1beta1.KesselCheckService/Check"
+	lookupResources = "/kessel.relations.v1beta1.KesselLookupService/LookupResources"
+)
+
+// entries captures what is logged as maps of key values.
+type entries struct {
+	mu     sync.Mutex
+	logged []map[string]any
+}
+
+func (e *entries) Log(level log.Level, keyvals ...interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	entry := map[string]any{"level": level}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		entry[keyvals[i].(string)] = keyvals[i+1]
+	}
+	e.logged = append(e.logged, entry)
+	return nil
+}
+
+func request(operation string, latency time.Duration) Request {
+	return Request{Operation: operation, Args: func() string { return "args" }, Latency: latency}
+}
+
+func TestLogger_LogsRequestsSlowerThanTheThresholdOfTheirOperation(t *testing.T) {
+	t.Parallel()
+	logged := &entries{}
+	l := NewLogger(&conf.Server_SlowRequests{
+		Threshold: durationpb.New(time.Second),
+		Rules: []*conf.Server_SlowRequests_Rule{
+			{Operation: check, Threshold: durationpb.New(100 * time.Millisecond)},
+		},
+	}, logged)
+	ctx := context.Background()
+
+	l.Finished(ctx, request(check, 50*time.Millisecond))
+	l.Finished(ctx, request(lookupResources, 500*time.Millisecond))
+	assert.Empty(t, logged.logged)
+
+	l.Finished(ctx, request(check, 150*time.Millisecond))
+	r := request(lookupResources, 2*time.Second)
+	r.Results = 250
+	r.Err = errors.GatewayTimeout("TIMEOUT", "deadline exceeded")
+	l.Finished(ctx, r)
+
+	require.Len(t, logged.logged, 2)
+	assert.Equal(t, log.LevelWarn, logged.logged[0]["level"])
+	assert.Equal(t, "slow request", logged.logged[0]["msg"])
+	assert.Equal(t, check, logged.logged[0]["operation"])
+	assert.Equal(t, 0.1, logged.logged[0]["threshold"])
+	assert.Equal(t, "args", logged.logged[0]["args"])
+	assert.Equal(t, 250, logged.logged[1]["results"])
+	assert.Equal(t, 1.0, logged.logged[1]["threshold"])
+	assert.Equal(t, int32(504), logged.logged[1]["code"])
+	assert.Equal(t, "TIMEOUT", logged.logged[1]["reason"])
+}
+
+func TestLogger_SamplesSlowRequests(t *testing.T) {
+	t.Parallel()
+	logged := &entries{}
+	none := 0.0
+	half := 0.5
+	l := NewLogger(&conf.Server_SlowRequests{
+		Threshold:   durationpb.New(time.Millisecond),
+		SampleRatio: &half,
+		Rules:       []*conf.Server_SlowRequests_Rule{{Operation: check, SampleRatio: &none}},
+	}, logged)
+	draws := []float64{0.2, 0.7, 0.49}
+	l.random = func() float64 {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+	ctx := context.Background()
+
+	for range 3 {
+		l.Finished(ctx, request(check, time.Second))
+		l.Finished(ctx, request(lookupResources, time.Second))
+	}
+	assert.Len(t, logged.logged, 2, "slow lookups are sampled and slow checks are not logged")
+}
+
+func TestLogger_LimitsEntriesPerOperation(t *testing.T) {
+	t.Parallel()
+	logged := &entries{}
+	l := NewLogger(&conf.Server_SlowRequests{Threshold: durationpb.New(time.Millisecond), MaxPerSecond: 1}, logged)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 3 {
+		l.Finished(ctx, request(check, time.Second))
+	}
+	l.Finished(ctx, request(lookupResources, time.Second))
+	require.Len(t, logged.logged, 2, "each operation has its own limit")
+	assert.NotContains(t, logged.logged[0], "suppressed")
+
+	now = now.Add(time.Second)
+	l.Finished(ctx, request(check, time.Second))
+	require.Len(t, logged.logged, 3)
+	assert.Equal(t, 2, logged.logged[2]["suppressed"], "entries left out are counted in the next one")
+}
+
+func TestLogger_LogsSpiceDbWorkOfTheRequest(t *testing.T) {
+	t.Parallel()
+	logged := &entries{}
+	l := NewLogger(&conf.Server_SlowRequests{Threshold: durationpb.New(time.Millisecond)}, logged)
+
+	stats := &Stats{}
+	ctx := NewContext(context.Background(), stats)
+	FromContext(ctx).SpiceDbCalled("at_least_as_fresh")
+	FromContext(ctx).SpiceDbResponded(metadata.Pairs(
+		"io.spicedb.respmeta.dispatchedoperationscount", "42",
+		"io.spicedb.respmeta.cachedoperationscount", "7",
+		"x-request-id", "spicedb-1",
+	))
+	FromContext(ctx).SpiceDbCalled("minimize_latency")
+	FromContext(ctx).SpiceDbCalled("at_least_as_fresh")
+	FromContext(ctx).SpiceDbResponded(metadata.Pairs("io.spicedb.respmeta.dispatchedoperationscount", "3", "x-request-id", "spicedb-2"))
+	l.Finished(ctx, request(lookupResources, time.Second))
+
+	require.Len(t, logged.logged, 1)
+	entry := logged.logged[0]
+	assert.Equal(t, 3, entry["spicedb_calls"])
+	assert.Equal(t, "at_least_as_fresh,minimize_latency", entry["consistency"])
+	assert.Equal(t, 45, entry["dispatched_operations"])
+	assert.Equal(t, 7, entry["cached_operations"])
+	assert.Equal(t, "spicedb-1,spicedb-2", entry["spicedb_request_ids"])
+}
+
+func TestStats_IgnoresRequestsWithoutStats(t *testing.T) {
+	t.Parallel()
+	stats := FromContext(context.Background())
+	assert.Nil(t, stats)
+	stats.SpiceDbCalled("fully_consistent")
+	stats.SpiceDbResponded(metadata.Pairs("io.spicedb.respmeta.dispatchedoperationscount", "1"))
+}
@@ -0,0 +1,94 @@
+package slowlog
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	"google.golang.org/grpc/metadata"
+)
+
+type statsKey struct{}
+
+// Stats accumulates the SpiceDB work caused by a request: the calls made, the consistency they asked for and the
+// operations SpiceDB dispatched or answered from its cache to serve them.
+type Stats struct {
+	mu           sync.Mutex
+	calls        int
+	consistency  []string
+	dispatched   int
+	cached       int
+	spiceDbIDs   []string
+	withTrailers int
+}
+
+// NewContext returns a context collecting the SpiceDB work done on its behalf into s.
+func NewContext(ctx context.Context, s *Stats) context.Context {
+	return context.WithValue(ctx, statsKey{}, s)
+}
+
+// FromContext returns the stats collected for the request of ctx, or nil if nothing is collected.
+func FromContext(ctx context.Context) *Stats {
+	s, _ := ctx.Value(statsKey{}).(*Stats)
+	return s
+}
+
+// SpiceDbCalled records a SpiceDB call and the consistency it asked for, empty if the call takes none.
+func (s *Stats) SpiceDbCalled(consistency string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if consistency != "" && !slices.Contains(s.consistency, consistency) {
+		s.consistency = append(s.consistency, consistency)
+	}
+}
+
+// SpiceDbResponded records the response metadata SpiceDB sent in the trailer of a call.
+func (s *Stats) SpiceDbResponded(trailer metadata.MD) {
+	if s == nil || len(trailer) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.withTrailers++
+	s.dispatched += trailerInt(trailer, responsemeta.DispatchedOperationsCount)
+	s.cached += trailerInt(trailer, responsemeta.CachedOperationsCount)
+	if id, err := responsemeta.GetResponseTrailerMetadata(trailer, responsemeta.RequestID); err == nil {
+		s.spiceDbIDs = append(s.spiceDbIDs, id)
+	}
+}
+
+func trailerInt(trailer metadata.MD, key responsemeta.ResponseMetadataTrailerKey) int {
+	value, err := responsemeta.GetResponseTrailerMetadata(trailer, key)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// keyvals returns the stats as log key value pairs. Dispatch counts are only included when SpiceDB reported them.
+func (s *Stats) keyvals() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keyvals := []any{"spicedb_calls", s.calls}
+	if len(s.consistency) > 0 {
+		keyvals = append(keyvals, "consistency", strings.Join(s.consistency, ","))
+	}
+	if s.withTrailers > 0 {
+		keyvals = append(keyvals,
+			"dispatched_operations", s.dispatched,
+			"cached_operations", s.cached,
+		)
+	}
+	if len(s.spiceDbIDs) > 0 {
+		keyvals = append(keyvals, "spicedb_request_ids", strings.Join(s.spiceDbIDs, ","))
+	}
+	return keyvals
+}
@@ -27,15 +27,15 @@ const (
 
 // LocalKesselContainer holds the testcontainers for the Kessel API, SpiceDB, and Keycloak
 type LocalKesselContainer struct {
-	Name               string
-	logger             log.Logger
-	HTTPport           string
-	gRPCport           string
-	KeycloakHTTPPort   string // host-mapped port for Keycloak HTTP (8080), for use in GetJWTToken etc.
-	container          *testcontainers.DockerContainer
-	spicedbContainer    *data.LocalSpiceDbContainer
-	net                *testcontainers.DockerNetwork
-	kccontainer        *testcontainers.DockerContainer
+	Name             string
+	logger           log.Logger
+	HTTPport         string
+	gRPCport         string
+	KeycloakHTTPPort string // host-mapped port for Keycloak HTTP (8080), for use in GetJWTToken etc.
+	container        *testcontainers.DockerContainer
+	spicedbContainer *data.LocalSpiceDbContainer
+	net              *testcontainers.DockerNetwork
+	kccontainer      *testcontainers.DockerContainer
 }
 
 // CreateKesselAPIContainer creates the network, SpiceDB, Keycloak, and Kessel API containers using testcontainers-go
@@ -64,7 +64,7 @@ func CreateKesselAPIContainer(ctx context.Context, logger log.Logger) (_ *LocalK
 		testcontainers.WithCmd("start-dev", "--health-enabled=true"),
 		testcontainers.WithExposedPorts("8080/tcp", "9000/tcp"),
 		testcontainers.WithEnv(map[string]string{
-			"KEYCLOAK_ADMIN":         "admin",
+			"KEYCLOAK_ADMIN":          "admin",
 			"KEYCLOAK_ADMIN_PASSWORD": "admin",
 		}),
 		network.WithNetwork([]string{keycloakNetworkAlias}, nw),
@@ -117,11 +117,12 @@ func CreateKesselAPIContainer(ctx context.Context, logger log.Logger) (_ *LocalK
 	}
 	for _, opt := range []testcontainers.ContainerCustomizer{
 		testcontainers.WithEnv(map[string]string{
-			"SPICEDB_ENDPOINT":    fmt.Sprintf("%s:50051", spicedbContainer.NetworkAlias()),
-			"SPICEDB_PRESHARED":   "spicedbpreshared",
-			"SPICEDB_JWKSURL":     kcurl,
-			"SPICEDB_ENABLEAUTH":  "true",
-			"SPICEDB_SCHEMA_FILE": "/tmp/spicedb_schema.zed",
+			"SPICEDB_ENDPOINT":                      fmt.Sprintf("%s:50051", spicedbContainer.NetworkAlias()),
+			"SPICEDB_PRESHARED":                     "spicedbpreshared",
+			"SPICEDB_JWKSURL":                       kcurl,
+			"SPICEDB_ENABLEAUTH":                    "true",
+			"SPICEDB_SCHEMA_FILE":                   "/tmp/spicedb_schema.zed",
+			"SPICEDB_CONSISTENCY_TOKEN_SIGNING_KEY": "e2e-consistency-token-key",
 		}),
 		testcontainers.WithFiles(testcontainers.ContainerFile{
 			Reader:            schemaFile,